@@ -1,30 +1,38 @@
 package account
 
 import (
+	"bank/config"
 	"bank/entity"
 	"bank/internal/db/sqlc"
 	"bank/internal/logger"
+	"bank/internal/tracing"
+	"bank/outbox"
+	"bank/store"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AccountDomain struct {
-	db      *sql.DB
-	queries *sqlc.Queries
-	logger  *logger.Logger
+	store store.Store
+	// systemAccountIDs are the configured system accounts, customers can't open accounts with them
+	systemAccountIDs []uint64
+	logger           *logger.Logger
 }
 
-func NewAccountDomain(db *sql.DB, sqlc *sqlc.Queries, logger *logger.Logger) (*AccountDomain, error) {
-	if db == nil {
-		return nil, errors.New("db is nil")
+func NewAccountDomain(store store.Store, cfg *config.Config, logger *logger.Logger) (*AccountDomain, error) {
+	if store == nil {
+		return nil, errors.New("store is nil")
 	}
 
-	if sqlc == nil {
-		return nil, errors.New("sqlc is nil")
+	if cfg == nil {
+		return nil, errors.New("config is nil")
 	}
 
 	if logger == nil {
@@ -33,35 +41,81 @@ func NewAccountDomain(db *sql.DB, sqlc *sqlc.Queries, logger *logger.Logger) (*A
 
 	log := logger.WithField("domain", "account")
 
+	systemAccountIDs := []uint64{}
+	for _, id := range cfg.SystemAccountIDs() {
+		if id != 0 && !slices.Contains(systemAccountIDs, id) {
+			systemAccountIDs = append(systemAccountIDs, id)
+		}
+	}
+
 	return &AccountDomain{
-		db:      db,
-		queries: sqlc,
-		logger:  log,
+		store:            store,
+		systemAccountIDs: systemAccountIDs,
+		logger:           log,
 	}, nil
 }
 
-// CreateAccount creates a new account and initial balance transaction atomically
+// EnsureSystemAccounts creates the system accounts that don't exist yet. It fails when one of
+// their ids is taken by an account that isn't a system account.
+func (d *AccountDomain) EnsureSystemAccounts(ctx context.Context) error {
+	tx, err := d.store.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range d.systemAccountIDs {
+		if err := tx.EnsureAccount(ctx, int64(id)); err != nil {
+			d.logger.Error(ctx, "failed to ensure system account_id=%d: %v", id, err)
+			return fmt.Errorf("failed to ensure system account %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isSystemAccount tells whether id is reserved for a system account
+func (d *AccountDomain) isSystemAccount(id uint64) bool {
+	return slices.Contains(d.systemAccountIDs, id)
+}
+
+// CreateAccount creates a new account and initial balance transaction atomically,
+// defaulting to a SAVINGS account when no type is given
 func (d *AccountDomain) CreateAccount(ctx context.Context, account *entity.CreateAccount) error {
+	if account.AccountType == "" {
+		account.AccountType = entity.AccountTypeSavings
+	}
+
 	if err := account.Validate(); err != nil {
 		return err
 	}
 
-	tx, err := d.db.Begin()
+	if d.isSystemAccount(account.AccountID) {
+		return fmt.Errorf("%w: account id is reserved", entity.ErrValidation)
+	}
+
+	tx, err := d.store.BeginTx(ctx, nil)
 	if err != nil {
 		d.logger.Error(ctx, "failed to begin transaction for account_id=%d: %v", account.AccountID, err)
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
-	qtx := d.queries.WithTx(tx)
 
-	_, err = qtx.CreateAccount(ctx, int64(account.AccountID))
+	_, err = tx.CreateAccount(ctx, sqlc.CreateAccountParams{
+		ID:          int64(account.AccountID),
+		AccountType: string(account.AccountType),
+		CreditLimit: account.CreditLimit.String(),
+	})
 	if err != nil {
 		d.logger.Error(ctx, "failed to create account record for account_id=%d: %v", account.AccountID, err)
 		return fmt.Errorf("failed to create account: %w", err)
 	}
 
 	noTransferID := sql.NullInt64{Valid: false}
-	_, err = qtx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{
+	_, err = tx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{
 		AccountID:  int64(account.AccountID),
 		TransferID: noTransferID,
 		Amount:     account.InitialBalance,
@@ -71,6 +125,17 @@ func (d *AccountDomain) CreateAccount(ctx context.Context, account *entity.Creat
 		return fmt.Errorf("failed to create initial transaction: %w", err)
 	}
 
+	err = outbox.Write(ctx, tx, entity.AccountCreated{
+		AccountID:      account.AccountID,
+		AccountType:    account.AccountType,
+		InitialBalance: account.InitialBalance,
+		CreditLimit:    account.CreditLimit,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "failed to write account created event for account_id=%d: %v", account.AccountID, err)
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		d.logger.Error(ctx, "failed to commit transaction for account_id=%d: %v", account.AccountID, err)
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -82,8 +147,25 @@ func (d *AccountDomain) CreateAccount(ctx context.Context, account *entity.Creat
 // GetAccountBalance retrieves the current balance for the specified account ID.
 // It first checks if the account exists, then fetches the balance with a lock for update
 // to ensure consistency. Returns decimal.Zero and entity.ErrNoRows if the account doesn't exist.
-func (d *AccountDomain) GetAccountBalance(ctx context.Context, accountID uint64) (decimal.Decimal, error) {
-	exists, err := d.queries.CheckAccountExists(ctx, int64(accountID))
+func (d *AccountDomain) GetAccountBalance(ctx context.Context, accountID uint64) (_ decimal.Decimal, err error) {
+	ctx, span := tracing.Start(ctx, "AccountDomain.GetAccountBalance", trace.WithAttributes(attribute.Int64("bank.account_id", int64(accountID))))
+	defer func() {
+		// An unknown account is the caller's mistake, the span didn't fail
+		spanErr := err
+		if errors.Is(err, entity.ErrNoRows) {
+			spanErr = nil
+		}
+		tracing.End(span, spanErr)
+	}()
+	logger.AddAccountIDs(ctx, accountID)
+
+	tx, err := d.store.BeginTx(ctx, nil)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	exists, err := tx.CheckAccountExists(ctx, int64(accountID))
 	if err != nil {
 		return decimal.Zero, fmt.Errorf("failed to check account exists: %w", err)
 	}
@@ -92,7 +174,7 @@ func (d *AccountDomain) GetAccountBalance(ctx context.Context, accountID uint64)
 		return decimal.Zero, entity.ErrNoRows
 	}
 
-	balance, err := d.queries.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{
+	balance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{
 		FilterAccountID:     int64(accountID),
 		FilterLockForUpdate: true,
 	})
@@ -0,0 +1,292 @@
+package account_test
+
+import (
+	"bank/account"
+	"bank/config"
+	"bank/entity"
+	"bank/internal/logger"
+	"bank/store/memory"
+	"bank/transaction"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// systemAccounts are the system accounts of the domains under test
+var systemAccounts = &config.Config{
+	AdjustmentAccountID:     900000001,
+	OpeningBalanceAccountID: 900000004,
+	ClearingAccountID:       900000006,
+}
+
+// newMemoryDomain runs the account domain on the in-memory ledger
+func newMemoryDomain(t *testing.T) (*account.AccountDomain, *memory.Store) {
+	t.Helper()
+	ledger := memory.New()
+	domain, err := account.NewAccountDomain(ledger, systemAccounts, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+	return domain, ledger
+}
+
+func TestCreateAccount(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+
+	err := domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: 100, InitialBalance: decimal.RequireFromString("10.5")})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	balance, err := domain.GetAccountBalance(ctx, 100)
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	if !balance.Equal(decimal.RequireFromString("10.5")) {
+		t.Errorf("expected balance 10.5, got %s", balance)
+	}
+
+	events := ledger.OutboxEvents()
+	if len(events) != 1 || events[0].EventType != string(entity.EventTypeAccountCreated) {
+		t.Errorf("expected one AccountCreated event, got %+v", events)
+	}
+
+	err = domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: 100, InitialBalance: decimal.NewFromInt(1)})
+	if err == nil {
+		t.Error("expected a duplicate account to fail")
+	}
+
+	err = domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: 200, InitialBalance: decimal.NewFromInt(1), CreditLimit: decimal.NewFromInt(5)})
+	if !errors.Is(err, entity.ErrValidation) {
+		t.Errorf("expected a credit limit on a SAVINGS account to fail validation, got %v", err)
+	}
+
+	if _, err := domain.GetAccountBalance(ctx, 200); !errors.Is(err, entity.ErrNoRows) {
+		t.Errorf("expected entity.ErrNoRows, got %v", err)
+	}
+}
+
+func TestCreateAccount_SystemAccountID(t *testing.T) {
+	ctx := context.Background()
+	domain, _ := newMemoryDomain(t)
+
+	err := domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: 900000006, InitialBalance: decimal.NewFromInt(1)})
+	if !errors.Is(err, entity.ErrValidation) {
+		t.Errorf("expected the clearing account id to be reserved, got %v", err)
+	}
+
+	if _, err := domain.GetAccountBalance(ctx, 900000006); !errors.Is(err, entity.ErrNoRows) {
+		t.Errorf("expected entity.ErrNoRows, got %v", err)
+	}
+}
+
+func TestEnsureSystemAccounts(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+
+	for range 2 {
+		if err := domain.EnsureSystemAccounts(ctx); err != nil {
+			t.Fatalf("failed to ensure system accounts: %v", err)
+		}
+	}
+
+	tx, err := ledger.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	for _, id := range []int64{900000001, 900000004, 900000006} {
+		got, err := tx.GetAccountByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get system account %d: %v", id, err)
+		}
+		if got.AccountType != string(entity.AccountTypeSystem) {
+			t.Errorf("expected account %d to be a SYSTEM account, got %s", id, got.AccountType)
+		}
+	}
+
+	// An account opened at a system account id before it was reserved is never taken for one
+	taken := memory.New()
+	unreserved, err := account.NewAccountDomain(taken, &config.Config{}, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+	if err := unreserved.CreateAccount(ctx, &entity.CreateAccount{AccountID: 900000001, InitialBalance: decimal.NewFromInt(1)}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	reserved, err := account.NewAccountDomain(taken, systemAccounts, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+	if err := reserved.EnsureSystemAccounts(ctx); err == nil {
+		t.Error("expected a customer account at a system account id to fail")
+	}
+}
+
+func TestGetAccountUpdates(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	for _, id := range []uint64{100, 200} {
+		if err := domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: id, InitialBalance: decimal.NewFromInt(100)}); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	initial, err := domain.GetAccountUpdates(ctx, 100, 0)
+	if err != nil {
+		t.Fatalf("failed to get updates: %v", err)
+	}
+
+	for _, amount := range []int64{30, 20} {
+		_, err := transactionDomain.CreateTransferFunds(ctx, entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(amount)})
+		if err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+	}
+
+	updates, err := domain.GetAccountUpdates(ctx, 100, initial.LastTransactionID)
+	if err != nil {
+		t.Fatalf("failed to get updates: %v", err)
+	}
+	if !updates.Balance.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("expected balance 50, got %s", updates.Balance)
+	}
+
+	expectedBalances := []int64{70, 50}
+	if len(updates.Transactions) != len(expectedBalances) {
+		t.Fatalf("expected %d transactions, got %d", len(expectedBalances), len(updates.Transactions))
+	}
+	for i, entry := range updates.Transactions {
+		if !entry.Balance.Equal(decimal.NewFromInt(expectedBalances[i])) {
+			t.Errorf("expected balance %d after transaction %d, got %s", expectedBalances[i], i, entry.Balance)
+		}
+		if entry.CounterpartyAccountID.Int64 != 200 {
+			t.Errorf("expected counterparty 200, got %+v", entry.CounterpartyAccountID)
+		}
+	}
+
+	if _, err := domain.GetAccountUpdates(ctx, 999, 0); !errors.Is(err, entity.ErrNoRows) {
+		t.Errorf("expected entity.ErrNoRows, got %v", err)
+	}
+}
+
+func TestGetAccountUpdates_Pages(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, logger.NewLogger("error"))
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	for _, id := range []uint64{100, 200} {
+		if err := domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: id, InitialBalance: decimal.NewFromInt(1000)}); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	initial, err := domain.GetAccountUpdates(ctx, 100, 0)
+	if err != nil {
+		t.Fatalf("failed to get updates: %v", err)
+	}
+
+	// More transactions than a page holds, the oldest must not be dropped
+	const transfers = 700
+	for range transfers {
+		_, err := transactionDomain.CreateTransferFunds(ctx, entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(1)})
+		if err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+	}
+
+	var entries []entity.StatementEntry
+	afterID, pages := initial.LastTransactionID, 0
+	for {
+		updates, err := domain.GetAccountUpdates(ctx, 100, afterID)
+		if err != nil {
+			t.Fatalf("failed to get updates: %v", err)
+		}
+		pages++
+		entries = append(entries, updates.Transactions...)
+		last := updates.Transactions[len(updates.Transactions)-1]
+		if updates.LastTransactionID != last.ID || !updates.Balance.Equal(last.Balance) {
+			t.Errorf("expected the page to end with the balance after transaction %d, got %d with %s", last.ID, updates.LastTransactionID, updates.Balance)
+		}
+		afterID = updates.LastTransactionID
+		if !updates.More {
+			break
+		}
+	}
+
+	if pages != 2 || len(entries) != transfers {
+		t.Fatalf("expected %d transactions in 2 pages, got %d in %d", transfers, len(entries), pages)
+	}
+	for i, entry := range entries {
+		if expected := decimal.NewFromInt(int64(1000 - i - 1)); !entry.Balance.Equal(expected) {
+			t.Fatalf("expected balance %s after transaction %d, got %s", expected, i, entry.Balance)
+		}
+		if i > 0 && entry.ID <= entries[i-1].ID {
+			t.Fatalf("expected transactions in id order, got %d after %d", entry.ID, entries[i-1].ID)
+		}
+	}
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	for _, id := range []uint64{100, 200, 300} {
+		if err := domain.CreateAccount(ctx, &entity.CreateAccount{AccountID: id, InitialBalance: decimal.NewFromInt(100)}); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	snapshotted, err := domain.SnapshotBalances(ctx)
+	if err != nil {
+		t.Fatalf("failed to snapshot balances: %v", err)
+	}
+	if snapshotted != 3 {
+		t.Errorf("expected 3 snapshots, got %d", snapshotted)
+	}
+
+	_, err = transactionDomain.CreateTransferFunds(ctx, entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.RequireFromString("12.5")})
+	if err != nil {
+		t.Fatalf("failed to transfer: %v", err)
+	}
+
+	// Only the accounts of the transfer have transactions after their snapshot
+	snapshotted, err = domain.SnapshotBalances(ctx)
+	if err != nil {
+		t.Fatalf("failed to snapshot balances: %v", err)
+	}
+	if snapshotted != 2 {
+		t.Errorf("expected 2 snapshots, got %d", snapshotted)
+	}
+
+	expectedBalances := map[uint64]string{100: "87.5", 200: "112.5", 300: "100"}
+	for id, expected := range expectedBalances {
+		balance, err := domain.GetAccountBalance(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get balance of account %d: %v", id, err)
+		}
+		if !balance.Equal(decimal.RequireFromString(expected)) {
+			t.Errorf("expected balance of account %d to be %s, got %s", id, expected, balance)
+		}
+	}
+
+	if snapshotted, err := domain.SnapshotBalances(ctx); err != nil || snapshotted != 0 {
+		t.Errorf("expected no snapshots, got %d: %v", snapshotted, err)
+	}
+}
@@ -0,0 +1,335 @@
+package account
+
+import (
+	"bank/entity"
+	"bank/outbox"
+	"bank/store"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultImportChunkSize = 1000
+	// importPrecision matches the decimal places of the ledger
+	importPrecision = 6
+)
+
+// Import CSV columns, account_type and credit_limit are optional
+const (
+	importColumnAccountID      = "account_id"
+	importColumnInitialBalance = "initial_balance"
+	importColumnAccountType    = "account_type"
+	importColumnCreditLimit    = "credit_limit"
+)
+
+type importRow struct {
+	line    int
+	account entity.CreateAccount
+}
+
+// accountImport holds the state of one import run
+type accountImport struct {
+	domain  *AccountDomain
+	tx      store.Tx
+	opts    entity.ImportAccountsOptions
+	columns map[string]int
+	// seen maps every valid account id to its line, to reject duplicates within the file
+	seen   map[uint64]int
+	result entity.ImportAccountsResult
+}
+
+// ImportAccounts loads accounts with their opening balances from CSV. Each row is validated like
+// CreateAccount; rejected rows are reported and skipped. Valid rows are loaded with COPY in
+// chunks, all in one database transaction, with every opening balance posted as a transfer from
+// the opening balance system account and an AccountCreated event written per account. On a dry run
+// the transaction is rolled back.
+func (d *AccountDomain) ImportAccounts(ctx context.Context, r io.Reader, opts entity.ImportAccountsOptions) (entity.ImportAccountsResult, error) {
+	if opts.OpeningBalanceAccountID == 0 {
+		return entity.ImportAccountsResult{}, fmt.Errorf("%w: opening balance account id is required", entity.ErrValidation)
+	}
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultImportChunkSize
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return entity.ImportAccountsResult{}, fmt.Errorf("%w: failed to read header: %v", entity.ErrValidation, err)
+	}
+
+	columns, err := importColumns(header)
+	if err != nil {
+		return entity.ImportAccountsResult{}, err
+	}
+
+	tx, err := d.store.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.ImportAccountsResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	imp := &accountImport{
+		domain:  d,
+		tx:      tx,
+		opts:    opts,
+		columns: columns,
+		seen:    map[uint64]int{},
+		result:  entity.ImportAccountsResult{Rejected: []entity.RejectedAccountRow{}},
+	}
+
+	if !opts.DryRun {
+		if err := imp.tx.EnsureAccount(ctx, int64(opts.OpeningBalanceAccountID)); err != nil {
+			return entity.ImportAccountsResult{}, fmt.Errorf("failed to ensure opening balance account: %w", err)
+		}
+
+		// Like transfers, the import holds the account while it posts to it. Its debits get their
+		// ids long before it commits, a snapshot taken meanwhile would never count them.
+		if _, err := imp.tx.LockAccount(ctx, int64(opts.OpeningBalanceAccountID)); err != nil {
+			return entity.ImportAccountsResult{}, fmt.Errorf("failed to lock opening balance account: %w", err)
+		}
+	}
+
+	chunk := make([]importRow, 0, opts.ChunkSize)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return entity.ImportAccountsResult{}, fmt.Errorf("failed to read csv: %w", err)
+			}
+			imp.reject(parseErr.Line, "", parseErr.Err.Error())
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		row, ok := imp.parseRow(line, record)
+		if !ok {
+			continue
+		}
+
+		chunk = append(chunk, row)
+		if len(chunk) == opts.ChunkSize {
+			if err := imp.load(ctx, chunk); err != nil {
+				d.logger.Error(ctx, "failed to import accounts chunk ending on line %d: %v", line, err)
+				return entity.ImportAccountsResult{}, err
+			}
+			chunk = chunk[:0]
+		}
+	}
+
+	if err := imp.load(ctx, chunk); err != nil {
+		d.logger.Error(ctx, "failed to import last accounts chunk: %v", err)
+		return entity.ImportAccountsResult{}, err
+	}
+
+	if opts.DryRun {
+		return imp.result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.ImportAccountsResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	d.logger.Info(ctx, "imported %d accounts, rejected %d rows", imp.result.Imported, len(imp.result.Rejected))
+	return imp.result, nil
+}
+
+func importColumns(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{importColumnAccountID, importColumnInitialBalance} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", entity.ErrValidation, required)
+		}
+	}
+	return columns, nil
+}
+
+func (imp *accountImport) reject(line int, accountID, reason string) {
+	imp.result.Rejected = append(imp.result.Rejected, entity.RejectedAccountRow{
+		Line:      line,
+		AccountID: accountID,
+		Reason:    reason,
+	})
+}
+
+func (imp *accountImport) field(record []string, column string) string {
+	i, ok := imp.columns[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseRow validates a record with the rules of CreateAccount
+func (imp *accountImport) parseRow(line int, record []string) (importRow, bool) {
+	rawID := imp.field(record, importColumnAccountID)
+	msgs := []string{}
+
+	account := entity.CreateAccount{
+		AccountType: entity.AccountType(strings.ToUpper(imp.field(record, importColumnAccountType))),
+	}
+	if account.AccountType == "" {
+		account.AccountType = entity.AccountTypeSavings
+	}
+
+	if rawID != "" {
+		id, err := strconv.ParseUint(rawID, 10, 64)
+		if err != nil {
+			msgs = append(msgs, "account id must be a valid number")
+		}
+		account.AccountID = id
+	}
+
+	decimals := []struct {
+		column string
+		name   string
+		target *decimal.Decimal
+	}{
+		{importColumnInitialBalance, "initial balance", &account.InitialBalance},
+		{importColumnCreditLimit, "credit limit", &account.CreditLimit},
+	}
+	for _, dec := range decimals {
+		raw := imp.field(record, dec.column)
+		if raw == "" {
+			continue
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			msgs = append(msgs, dec.name+" must be a valid number")
+			continue
+		}
+		if -value.Exponent() > importPrecision {
+			msgs = append(msgs, fmt.Sprintf("%s has too many decimal places (max %d)", dec.name, importPrecision))
+		}
+		*dec.target = value
+	}
+
+	// Fields that didn't parse would only be reported again as missing
+	if len(msgs) == 0 {
+		if err := account.Validate(); err != nil {
+			msgs = append(msgs, strings.TrimPrefix(err.Error(), entity.ErrValidation.Error()+": "))
+		}
+	}
+
+	if len(msgs) == 0 {
+		if account.AccountID == imp.opts.OpeningBalanceAccountID || imp.domain.isSystemAccount(account.AccountID) {
+			msgs = append(msgs, "account id is reserved")
+		} else if first, ok := imp.seen[account.AccountID]; ok {
+			msgs = append(msgs, fmt.Sprintf("duplicate account id, first seen on line %d", first))
+		}
+	}
+
+	if len(msgs) > 0 {
+		imp.reject(line, rawID, strings.Join(msgs, ", "))
+		return importRow{}, false
+	}
+
+	imp.seen[account.AccountID] = line
+	return importRow{line: line, account: account}, true
+}
+
+// load rejects the rows of accounts that exist already and copies the others
+func (imp *accountImport) load(ctx context.Context, chunk []importRow) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(chunk))
+	for _, row := range chunk {
+		ids = append(ids, int64(row.account.AccountID))
+	}
+
+	existing, err := imp.tx.ListExistingAccountIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to check existing accounts: %w", err)
+	}
+
+	exists := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+
+	rows := make([]importRow, 0, len(chunk))
+	for _, row := range chunk {
+		if exists[int64(row.account.AccountID)] {
+			imp.reject(row.line, strconv.FormatUint(row.account.AccountID, 10), "account already exists")
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if imp.opts.DryRun || len(rows) == 0 {
+		imp.result.Imported += len(rows)
+		return nil
+	}
+
+	transferIDs, err := imp.tx.NextTransferIDs(ctx, int32(len(rows)))
+	if err != nil {
+		return fmt.Errorf("failed to allocate transfer ids: %w", err)
+	}
+
+	now := time.Now()
+	accounts := make([][]any, 0, len(rows))
+	transfers := make([][]any, 0, len(rows))
+	transactions := make([][]any, 0, 2*len(rows))
+	events := make([][]any, 0, len(rows))
+	for i, row := range rows {
+		accounts = append(accounts, []any{int64(row.account.AccountID), string(row.account.AccountType), row.account.CreditLimit.String(), now, now})
+		transfers = append(transfers, []any{transferIDs[i], int64(imp.opts.OpeningBalanceAccountID), int64(row.account.AccountID), now})
+
+		amount := row.account.InitialBalance.String()
+		transactions = append(transactions,
+			[]any{int64(imp.opts.OpeningBalanceAccountID), transferIDs[i], amount, string(entity.TrxTypeDebit), now},
+			[]any{int64(row.account.AccountID), transferIDs[i], amount, string(entity.TrxTypeCredit), now},
+		)
+
+		event, err := outbox.NewEventParams(entity.AccountCreated{
+			AccountID:      row.account.AccountID,
+			AccountType:    row.account.AccountType,
+			InitialBalance: row.account.InitialBalance,
+			CreditLimit:    row.account.CreditLimit,
+		})
+		if err != nil {
+			return err
+		}
+		// COPY sends []byte as bytea, the payload goes as text to be parsed as jsonb
+		events = append(events, []any{event.EventType, event.AccountIds, string(event.Payload), now})
+	}
+
+	if err := imp.tx.CopyFrom(ctx, "accounts", []string{"id", "account_type", "credit_limit", "created_at", "updated_at"}, accounts); err != nil {
+		return fmt.Errorf("failed to copy accounts: %w", err)
+	}
+
+	if err := imp.tx.CopyFrom(ctx, "transfers", []string{"id", "from_account_id", "to_account_id", "created_at"}, transfers); err != nil {
+		return fmt.Errorf("failed to copy transfers: %w", err)
+	}
+
+	if err := imp.tx.CopyFrom(ctx, "transactions", []string{"account_id", "transfer_id", "amount", "trx_type", "created_at"}, transactions); err != nil {
+		return fmt.Errorf("failed to copy transactions: %w", err)
+	}
+
+	if err := imp.tx.CopyFrom(ctx, "outbox", []string{"event_type", "account_ids", "payload", "created_at"}, events); err != nil {
+		return fmt.Errorf("failed to copy account created events: %w", err)
+	}
+
+	imp.result.Imported += len(rows)
+	return nil
+}
@@ -0,0 +1,101 @@
+//go:build integration
+
+package account_test
+
+import (
+	"bank/account"
+	"bank/entity"
+	"bank/internal/logger"
+	"bank/store"
+	"bank/test"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestImportAccounts_Postgres(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		domain, err := account.NewAccountDomain(ledger, systemAccounts, logger.NewLogger("debug"))
+		if err != nil {
+			t.Fatalf("failed to create account domain: %v", err)
+		}
+
+		_, err = testDB.DB.Exec("INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES (700, 'SAVINGS', now(), now())")
+		if err != nil {
+			t.Fatalf("failed to seed account: %v", err)
+		}
+
+		csv := strings.Join([]string{
+			"account_id,initial_balance,account_type,credit_limit",
+			"701,100.50,,",
+			"702,300,CREDIT,5000",
+			"703,250,savings,",
+			"704,-1,,",
+			"705,1.1234567,,",
+			"abc,10,,",
+			"701,20,,",
+			"700,10,,",
+			"900000004,10,,",
+			"706,10,SAVINGS,100",
+		}, "\n")
+
+		expectedRejected := []entity.RejectedAccountRow{
+			{Line: 5, AccountID: "704", Reason: "initial balance must be greater than 0"},
+			{Line: 6, AccountID: "705", Reason: "initial balance has too many decimal places (max 6)"},
+			{Line: 7, AccountID: "abc", Reason: "account id must be a valid number"},
+			{Line: 8, AccountID: "701", Reason: "duplicate account id, first seen on line 2"},
+			{Line: 9, AccountID: "700", Reason: "account already exists"},
+			{Line: 10, AccountID: "900000004", Reason: "account id is reserved"},
+			{Line: 11, AccountID: "706", Reason: "credit limit is only allowed on CREDIT accounts"},
+		}
+
+		opts := entity.ImportAccountsOptions{OpeningBalanceAccountID: openingBalanceAccountID, ChunkSize: 2, DryRun: true}
+		result, err := domain.ImportAccounts(ctx, strings.NewReader(csv), opts)
+		if err != nil {
+			t.Fatalf("failed to dry run import: %v", err)
+		}
+		assertImportResult(t, result, 3, expectedRejected)
+
+		var count int
+		if err := testDB.DB.QueryRow("SELECT count(*) FROM accounts WHERE id BETWEEN 701 AND 706").Scan(&count); err != nil {
+			t.Fatalf("failed to count accounts: %v", err)
+		}
+		if count != 0 {
+			t.Fatalf("expected dry run to load nothing, got %d accounts", count)
+		}
+
+		opts.DryRun = false
+		result, err = domain.ImportAccounts(ctx, strings.NewReader(csv), opts)
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		assertImportResult(t, result, 3, expectedRejected)
+
+		expectedBalances := map[uint64]string{701: "100.5", 702: "300", 703: "250"}
+		for id, expected := range expectedBalances {
+			balance, err := domain.GetAccountBalance(ctx, id)
+			if err != nil {
+				t.Fatalf("failed to get balance of account %d: %v", id, err)
+			}
+			if !balance.Equal(decimal.RequireFromString(expected)) {
+				t.Errorf("expected balance of account %d to be %s, got %s", id, expected, balance)
+			}
+		}
+
+		var total string
+		if err := testDB.DB.QueryRow("SELECT COALESCE(SUM(CASE WHEN trx_type = 'CREDIT' THEN amount ELSE -amount END), 0)::text FROM transactions").Scan(&total); err != nil {
+			t.Fatalf("failed to sum transactions: %v", err)
+		}
+		if !decimal.RequireFromString(total).IsZero() {
+			t.Errorf("expected the ledger to be balanced, got %s", total)
+		}
+	})
+}
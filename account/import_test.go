@@ -0,0 +1,106 @@
+package account_test
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+const openingBalanceAccountID = 900000004
+
+func TestImportAccounts(t *testing.T) {
+	ctx := context.Background()
+	domain, ledger := newMemoryDomain(t)
+
+	tx, err := ledger.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.CreateAccount(ctx, sqlc.CreateAccountParams{ID: 700, AccountType: "SAVINGS", CreditLimit: "0"}); err != nil {
+		t.Fatalf("failed to seed account: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	csv := strings.Join([]string{
+		"account_id,initial_balance,account_type,credit_limit",
+		"701,100.50,,",
+		"702,300,CREDIT,5000",
+		"703,250,savings,",
+		"704,-1,,",
+		"705,1.1234567,,",
+		"abc,10,,",
+		"701,20,,",
+		"700,10,,",
+		"900000004,10,,",
+		"900000006,10,,",
+		"706,10,SAVINGS,100",
+	}, "\n")
+
+	expectedRejected := []entity.RejectedAccountRow{
+		{Line: 5, AccountID: "704", Reason: "initial balance must be greater than 0"},
+		{Line: 6, AccountID: "705", Reason: "initial balance has too many decimal places (max 6)"},
+		{Line: 7, AccountID: "abc", Reason: "account id must be a valid number"},
+		{Line: 8, AccountID: "701", Reason: "duplicate account id, first seen on line 2"},
+		{Line: 9, AccountID: "700", Reason: "account already exists"},
+		{Line: 10, AccountID: "900000004", Reason: "account id is reserved"},
+		{Line: 11, AccountID: "900000006", Reason: "account id is reserved"},
+		{Line: 12, AccountID: "706", Reason: "credit limit is only allowed on CREDIT accounts"},
+	}
+
+	opts := entity.ImportAccountsOptions{OpeningBalanceAccountID: openingBalanceAccountID, ChunkSize: 2, DryRun: true}
+	result, err := domain.ImportAccounts(ctx, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("failed to dry run import: %v", err)
+	}
+	assertImportResult(t, result, 3, expectedRejected)
+
+	for _, id := range []uint64{701, 702, 703} {
+		if _, err := domain.GetAccountBalance(ctx, id); !errors.Is(err, entity.ErrNoRows) {
+			t.Fatalf("expected dry run to load nothing, got account %d: %v", id, err)
+		}
+	}
+
+	opts.DryRun = false
+	result, err = domain.ImportAccounts(ctx, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	assertImportResult(t, result, 3, expectedRejected)
+
+	// The opening balance account holds the other side, the ledger stays balanced
+	expectedBalances := map[uint64]string{701: "100.5", 702: "300", 703: "250", openingBalanceAccountID: "-650.5"}
+	for id, expected := range expectedBalances {
+		balance, err := domain.GetAccountBalance(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get balance of account %d: %v", id, err)
+		}
+		if !balance.Equal(decimal.RequireFromString(expected)) {
+			t.Errorf("expected balance of account %d to be %s, got %s", id, expected, balance)
+		}
+	}
+}
+
+func assertImportResult(t *testing.T, result entity.ImportAccountsResult, imported int, rejected []entity.RejectedAccountRow) {
+	t.Helper()
+
+	if result.Imported != imported {
+		t.Errorf("expected %d imported accounts, got %d", imported, result.Imported)
+	}
+
+	if len(result.Rejected) != len(rejected) {
+		t.Fatalf("expected %d rejected rows, got %d: %+v", len(rejected), len(result.Rejected), result.Rejected)
+	}
+
+	for i, expected := range rejected {
+		if result.Rejected[i] != expected {
+			t.Errorf("rejected row %d: expected %+v, got %+v", i, expected, result.Rejected[i])
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package account
+
+import (
+	"bank/internal/db/sqlc"
+	"context"
+	"fmt"
+)
+
+// SnapshotBalances records the balance of every account with transactions after its latest
+// snapshot, so get_account_balance only sums the transactions from there on. Returns the number
+// of snapshots recorded.
+func (d *AccountDomain) SnapshotBalances(ctx context.Context) (int, error) {
+	tx, err := d.store.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	accountIDs, err := tx.ListAccountIDsToSnapshot(ctx)
+	tx.Rollback()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts to snapshot: %w", err)
+	}
+
+	snapshotted := 0
+	for _, accountID := range accountIDs {
+		if err := d.snapshotBalance(ctx, accountID); err != nil {
+			d.logger.Error(ctx, "failed to snapshot balance for account_id=%d: %v", accountID, err)
+			return snapshotted, err
+		}
+		snapshotted++
+	}
+	return snapshotted, nil
+}
+
+// snapshotBalance reads the balance and the last transaction in two statements, holding the lock
+// of the account so no transfer commits in between and ends up counted in neither.
+func (d *AccountDomain) snapshotBalance(ctx context.Context, accountID int64) error {
+	tx, err := d.store.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.LockAccount(ctx, accountID); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	balance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{
+		FilterAccountID: accountID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get account balance: %w", err)
+	}
+
+	lastTransactionID, err := tx.GetLastAccountTransactionID(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to get last transaction id: %w", err)
+	}
+
+	_, err = tx.CreateBalanceSnapshot(ctx, sqlc.CreateBalanceSnapshotParams{
+		AccountID:         accountID,
+		Balance:           balance,
+		LastTransactionID: lastTransactionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create balance snapshot: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
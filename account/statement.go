@@ -0,0 +1,142 @@
+package account
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// statementPageSize is the number of transactions read from the database at a time
+const statementPageSize = 500
+
+// StatementWriter receives a statement as it is read, so a long period never has to be
+// held in memory. Begin is called once before the entries and End once after them.
+type StatementWriter interface {
+	Begin(statement entity.AccountStatement) error
+	WriteEntry(entry entity.StatementEntry) error
+	End(statement entity.AccountStatement) error
+}
+
+// StreamStatement writes the opening balance, every transaction of the period with its
+// running balance and the closing balance. Everything is read from one repeatable read
+// snapshot, so the running balance ends on the closing balance and both boundaries agree
+// with get_account_balance. Returns entity.ErrNoRows if the account doesn't exist.
+func (d *AccountDomain) StreamStatement(ctx context.Context, param entity.GetAccountStatement, w StatementWriter) error {
+	if err := param.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := d.store.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	exists, err := tx.CheckAccountExists(ctx, int64(param.AccountID))
+	if err != nil {
+		return fmt.Errorf("failed to check account exists: %w", err)
+	}
+
+	if !exists {
+		return entity.ErrNoRows
+	}
+
+	opening, err := balanceAt(ctx, tx, param.AccountID, param.From)
+	if err != nil {
+		return err
+	}
+
+	closing, err := balanceAt(ctx, tx, param.AccountID, param.To)
+	if err != nil {
+		return err
+	}
+
+	statement := entity.AccountStatement{
+		GetAccountStatement: param,
+		OpeningBalance:      opening,
+		ClosingBalance:      closing,
+	}
+	if err := w.Begin(statement); err != nil {
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+
+	balance := opening
+	afterID := int64(0)
+	for {
+		transactions, err := tx.ListAccountTransactionsBetween(ctx, sqlc.ListAccountTransactionsBetweenParams{
+			AccountID: int64(param.AccountID),
+			FromTime:  param.From,
+			ToTime:    param.To,
+			AfterID:   afterID,
+			PageSize:  statementPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, t := range transactions {
+			switch entity.TrxType(t.TrxType) {
+			case entity.TrxTypeCredit:
+				balance = balance.Add(t.Amount)
+			case entity.TrxTypeDebit:
+				balance = balance.Sub(t.Amount)
+			}
+
+			err := w.WriteEntry(entity.StatementEntry{
+				Transaction: entity.Transaction{
+					Model: entity.Model{
+						ID:        uint64(t.ID),
+						CreatedAt: t.CreatedAt.Time,
+					},
+					AccountID:  uint64(t.AccountID),
+					TransferID: t.TransferID,
+					Amount:     t.Amount,
+					TrxType:    entity.TrxType(t.TrxType),
+				},
+				CounterpartyAccountID: counterparty(t.CounterpartyAccountID),
+				Balance:               balance,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write statement: %w", err)
+			}
+			afterID = t.ID
+		}
+
+		if len(transactions) < statementPageSize {
+			break
+		}
+	}
+
+	// Only a snapshot that disagrees with the ledger can break the tie-out
+	if !balance.Equal(closing) {
+		d.logger.Error(ctx, "statement of account_id=%d does not tie out: running balance=%s closing balance=%s", param.AccountID, balance, closing)
+	}
+
+	if err := w.End(statement); err != nil {
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+	return nil
+}
+
+// counterparty turns the counterparty column back into a nullable id, transactions outside a
+// transfer have none and read as 0
+func counterparty(accountID int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: accountID, Valid: accountID != 0}
+}
+
+func balanceAt(ctx context.Context, queries store.Queries, accountID uint64, cutoff time.Time) (decimal.Decimal, error) {
+	balance, err := queries.GetAccountBalanceAt(ctx, sqlc.GetAccountBalanceAtParams{
+		AccountID: int64(accountID),
+		Cutoff:    cutoff,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get balance at %s: %w", cutoff.Format(time.RFC3339), err)
+	}
+	return decimal.NewFromString(balance)
+}
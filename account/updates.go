@@ -0,0 +1,130 @@
+package account
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// LatestTransactionID skips the history in GetAccountUpdates, only the balance is read
+const LatestTransactionID = math.MaxInt64
+
+// maxAccountUpdates bounds the transactions a single GetAccountUpdates returns
+const maxAccountUpdates = 500
+
+// GetAccountUpdates returns the oldest transactions of the account after afterTransactionID with
+// their running balance, at most maxAccountUpdates, and the balance after the last one. When more
+// are left updates.More is set and the caller reads on from updates.LastTransactionID. Every write
+// to an account locks it first, so within an account transaction ids follow commit order and a
+// reader that saw a transaction id has seen every earlier one. Returns entity.ErrNoRows if the
+// account doesn't exist.
+func (d *AccountDomain) GetAccountUpdates(ctx context.Context, accountID, afterTransactionID uint64) (entity.AccountUpdates, error) {
+	tx, err := d.store.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	exists, err := tx.CheckAccountExists(ctx, int64(accountID))
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to check account exists: %w", err)
+	}
+
+	if !exists {
+		return entity.AccountUpdates{}, entity.ErrNoRows
+	}
+
+	rawBalance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{
+		FilterAccountID: int64(accountID),
+	})
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to get account balance: %w", err)
+	}
+
+	balance, err := decimal.NewFromString(rawBalance)
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to parse account balance: %w", err)
+	}
+
+	lastID, err := tx.GetLastAccountTransactionID(ctx, int64(accountID))
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to get last transaction id: %w", err)
+	}
+
+	updates := entity.AccountUpdates{
+		AccountID:         accountID,
+		Transactions:      []entity.StatementEntry{},
+		Balance:           balance,
+		LastTransactionID: uint64(lastID),
+	}
+	if afterTransactionID >= uint64(lastID) {
+		return updates, nil
+	}
+
+	transactions, err := tx.ListAccountTransactionsAfterID(ctx, sqlc.ListAccountTransactionsAfterIDParams{
+		AccountID: int64(accountID),
+		AfterID:   int64(afterTransactionID),
+		PageSize:  maxAccountUpdates,
+	})
+	if err != nil {
+		return entity.AccountUpdates{}, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	if len(transactions) == 0 {
+		return updates, nil
+	}
+
+	// Past the last transaction read, the balance is the current one less what was posted since
+	last := transactions[len(transactions)-1].ID
+	if last < lastID {
+		rawSince, err := tx.SumAccountTransactionsAfterID(ctx, sqlc.SumAccountTransactionsAfterIDParams{
+			AccountID: int64(accountID),
+			AfterID:   last,
+		})
+		if err != nil {
+			return entity.AccountUpdates{}, fmt.Errorf("failed to sum transactions: %w", err)
+		}
+
+		since, err := decimal.NewFromString(rawSince)
+		if err != nil {
+			return entity.AccountUpdates{}, fmt.Errorf("failed to parse transactions sum: %w", err)
+		}
+
+		updates.Balance = balance.Sub(since)
+		updates.LastTransactionID = uint64(last)
+		updates.More = true
+	}
+
+	// Running backwards from the balance after the last transaction gives the others
+	running := updates.Balance
+	updates.Transactions = make([]entity.StatementEntry, len(transactions))
+	for i, t := range slices.Backward(transactions) {
+		updates.Transactions[i] = entity.StatementEntry{
+			Transaction: entity.Transaction{
+				Model: entity.Model{
+					ID:        uint64(t.ID),
+					CreatedAt: t.CreatedAt.Time,
+				},
+				AccountID:  uint64(t.AccountID),
+				TransferID: t.TransferID,
+				Amount:     t.Amount,
+				TrxType:    entity.TrxType(t.TrxType),
+			},
+			CounterpartyAccountID: counterparty(t.CounterpartyAccountID),
+			Balance:               running,
+		}
+
+		switch entity.TrxType(t.TrxType) {
+		case entity.TrxTypeCredit:
+			running = running.Sub(t.Amount)
+		case entity.TrxTypeDebit:
+			running = running.Add(t.Amount)
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,336 @@
+package ach
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/nacha"
+	"bank/transaction"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ACHDomain struct {
+	db                  *sql.DB
+	queries             *sqlc.Queries
+	transactionDomain   *transaction.TransactionDomain
+	logger              *logger.Logger
+	settlementAccountID uint64
+}
+
+func NewACHDomain(db *sql.DB, sqlc *sqlc.Queries, transactionDomain *transaction.TransactionDomain, cfg *config.Config, logger *logger.Logger) (*ACHDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.ACHSettlementAccountID == 0 {
+		return nil, errors.New("ach settlement account id is required")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "ach")
+	return &ACHDomain{
+		db:                  db,
+		queries:             sqlc,
+		transactionDomain:   transactionDomain,
+		logger:              log,
+		settlementAccountID: cfg.ACHSettlementAccountID,
+	}, nil
+}
+
+// entryOutcome is what happened to one entry, now or on an earlier import
+type entryOutcome struct {
+	status           entity.ACHEntryStatus
+	returnReasonCode string
+	alreadyProcessed bool
+}
+
+// ImportFile applies the entries of a NACHA file: credits are paid out of the settlement
+// account, debits into it. Every entry commits on its own and is recorded by trace number, so
+// importing the same file again, or resuming an interrupted import, never posts an entry twice.
+// Entries that can't be applied are written to returns as a NACHA returns file, nothing is
+// written when there are none. Entries returned by an earlier import of the file were already
+// sent back with its returns file and are left out.
+func (d *ACHDomain) ImportFile(ctx context.Context, r io.Reader, returns io.Writer) (entity.ImportACHFileResult, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return entity.ImportACHFileResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	file, err := nacha.Parse(bytes.NewReader(content))
+	if err != nil {
+		return entity.ImportACHFileResult{}, fmt.Errorf("%w: %v", entity.ErrValidation, err)
+	}
+
+	routing := strings.TrimSpace(file.Header.ImmediateDestination)
+	if len(routing) != 9 {
+		return entity.ImportACHFileResult{}, fmt.Errorf("%w: immediate destination must be a routing number", entity.ErrValidation)
+	}
+
+	if err := d.queries.EnsureAccount(ctx, int64(d.settlementAccountID)); err != nil {
+		return entity.ImportACHFileResult{}, fmt.Errorf("failed to ensure settlement account: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	achFile, err := d.queries.UpsertACHFile(ctx, sqlc.UpsertACHFileParams{
+		ImmediateOrigin:      strings.TrimSpace(file.Header.ImmediateOrigin),
+		ImmediateDestination: routing,
+		FileCreatedAt:        file.Header.CreatedAt,
+		FileIDModifier:       file.Header.FileIDModifier,
+		FileHash:             hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return entity.ImportACHFileResult{}, fmt.Errorf("failed to record ach file: %w", err)
+	}
+
+	if achFile.FileHash != hex.EncodeToString(sum[:]) {
+		return entity.ImportACHFileResult{}, fmt.Errorf("%w: a different file with the same header was imported as file %d", entity.ErrInvalidState, achFile.ID)
+	}
+
+	result := entity.ImportACHFileResult{FileID: uint64(achFile.ID)}
+	returned := make([][]nacha.Entry, len(file.Batches))
+	toReturn := 0
+	for i, batch := range file.Batches {
+		for _, e := range batch.Entries {
+			outcome, err := d.applyEntry(ctx, achFile.ID, batch.Header, e)
+			if err != nil {
+				d.logger.Error(ctx, "failed to apply ach entry file_id=%d trace_number=%s: %v", achFile.ID, e.TraceNumber, err)
+				return result, fmt.Errorf("failed to apply entry %s: %w", e.TraceNumber, err)
+			}
+
+			if outcome.alreadyProcessed {
+				result.AlreadyProcessed++
+			}
+
+			switch outcome.status {
+			case entity.ACHEntryStatusPosted:
+				result.Posted++
+			case entity.ACHEntryStatusSkipped:
+				result.Skipped++
+			case entity.ACHEntryStatusReturned:
+				result.Returned++
+				if outcome.alreadyProcessed {
+					continue
+				}
+				e.Return = &nacha.ReturnAddenda{ReturnReasonCode: outcome.returnReasonCode}
+				returned[i] = append(returned[i], e)
+				toReturn++
+			}
+		}
+	}
+
+	if toReturn > 0 {
+		if err := nacha.Write(returns, returnsFile(file, returned, routing[:8], time.Now())); err != nil {
+			return result, fmt.Errorf("failed to write returns file: %w", err)
+		}
+	}
+
+	d.logger.Info(ctx, "ach file_id=%d: posted %d, returned %d, skipped %d entries, %d already processed", achFile.ID, result.Posted, result.Returned, result.Skipped, result.AlreadyProcessed)
+	return result, nil
+}
+
+// applyEntry posts one entry and records it in the same transaction. A concurrent import of the
+// same entry waits on the trace number and then finds it recorded, its posting is rolled back.
+func (d *ACHDomain) applyEntry(ctx context.Context, fileID int64, batch nacha.BatchHeader, e nacha.Entry) (entryOutcome, error) {
+	existing, err := d.queries.GetACHEntryByTraceNumber(ctx, sqlc.GetACHEntryByTraceNumberParams{AchFileID: fileID, TraceNumber: e.TraceNumber})
+	if err == nil {
+		return recordedOutcome(existing), nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return entryOutcome{}, fmt.Errorf("failed to get ach entry: %w", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entryOutcome{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := d.queries.WithTx(tx)
+	amount := decimal.New(e.Amount, -2)
+	outcome, transferID, err := d.post(ctx, tx, qtx, e, amount)
+	if err != nil {
+		return entryOutcome{}, err
+	}
+
+	_, err = qtx.CreateACHEntry(ctx, sqlc.CreateACHEntryParams{
+		AchFileID:        fileID,
+		BatchNumber:      int32(batch.BatchNumber),
+		TraceNumber:      e.TraceNumber,
+		TransactionCode:  int32(e.TransactionCode),
+		AccountNumber:    e.DFIAccountNumber,
+		Amount:           amount,
+		Status:           string(outcome.status),
+		ReturnReasonCode: sql.NullString{String: outcome.returnReasonCode, Valid: outcome.returnReasonCode != ""},
+		TransferID:       sql.NullInt64{Int64: int64(transferID), Valid: transferID != 0},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		existing, err := d.queries.GetACHEntryByTraceNumber(ctx, sqlc.GetACHEntryByTraceNumberParams{AchFileID: fileID, TraceNumber: e.TraceNumber})
+		if err != nil {
+			return entryOutcome{}, fmt.Errorf("failed to get ach entry: %w", err)
+		}
+		return recordedOutcome(existing), nil
+	}
+
+	if err != nil {
+		return entryOutcome{}, fmt.Errorf("failed to record ach entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entryOutcome{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return outcome, nil
+}
+
+// post moves the money of an entry, or decides why it is returned
+func (d *ACHDomain) post(ctx context.Context, tx *sql.Tx, qtx *sqlc.Queries, e nacha.Entry, amount decimal.Decimal) (entryOutcome, uint64, error) {
+	returned := func(code string) (entryOutcome, uint64, error) {
+		return entryOutcome{status: entity.ACHEntryStatusReturned, returnReasonCode: code}, 0, nil
+	}
+
+	// Returns of entries we originated are settled with the originated entries
+	if e.IsReturn() {
+		return entryOutcome{status: entity.ACHEntryStatusSkipped}, 0, nil
+	}
+
+	accountID, err := strconv.ParseUint(e.DFIAccountNumber, 10, 64)
+	if err != nil || accountID == 0 {
+		return returned(entity.ACHReturnInvalidAccountNumber)
+	}
+
+	account, err := qtx.GetAccountByID(ctx, int64(accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return returned(entity.ACHReturnNoAccount)
+	}
+
+	if err != nil {
+		return entryOutcome{}, 0, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if entity.AccountType(account.AccountType) == entity.AccountTypeSystem {
+		return returned(entity.ACHReturnNonTransactionAccount)
+	}
+
+	// Prenotes only validate the account
+	if e.IsPrenote() || e.Amount == 0 {
+		return entryOutcome{status: entity.ACHEntryStatusSkipped}, 0, nil
+	}
+
+	var transfer entity.CreateTransferFundsResult
+	if e.IsCredit() {
+		transfer, err = d.transactionDomain.PostTransferTx(ctx, tx, entity.CreateTransferFundsParams{
+			SourceAccountID:      d.settlementAccountID,
+			DestinationAccountID: accountID,
+			Amount:               amount,
+		})
+	} else {
+		transfer, err = d.transactionDomain.CreateTransferFundsTx(ctx, tx, entity.CreateTransferFundsParams{
+			SourceAccountID:      accountID,
+			DestinationAccountID: d.settlementAccountID,
+			Amount:               amount,
+		})
+	}
+
+	if errors.Is(err, entity.ErrInsufficientFunds) {
+		return returned(entity.ACHReturnInsufficientFunds)
+	}
+
+	if errors.Is(err, entity.ErrDataNotFound) {
+		return returned(entity.ACHReturnNoAccount)
+	}
+
+	if err != nil {
+		return entryOutcome{}, 0, fmt.Errorf("failed to post entry: %w", err)
+	}
+
+	return entryOutcome{status: entity.ACHEntryStatusPosted}, transfer.TransferID, nil
+}
+
+func recordedOutcome(entry sqlc.AchEntry) entryOutcome {
+	return entryOutcome{
+		status:           entity.ACHEntryStatus(entry.Status),
+		returnReasonCode: entry.ReturnReasonCode.String,
+		alreadyProcessed: true,
+	}
+}
+
+// returnsFile sends the returned entries back to the originating bank: the file goes from us to
+// the origin of the received file, and every entry is addressed to the bank that originated it
+func returnsFile(received *nacha.File, returned [][]nacha.Entry, routing string, now time.Time) *nacha.File {
+	f := &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination:     received.Header.ImmediateOrigin,
+			ImmediateOrigin:          received.Header.ImmediateDestination,
+			CreatedAt:                now.UTC().Truncate(time.Minute),
+			FileIDModifier:           "A",
+			ImmediateDestinationName: received.Header.ImmediateOriginName,
+			ImmediateOriginName:      received.Header.ImmediateDestinationName,
+			ReferenceCode:            "RETURNS",
+		},
+		Batches: []nacha.Batch{},
+	}
+
+	sequence := 0
+	for i, entries := range returned {
+		if len(entries) == 0 {
+			continue
+		}
+
+		header := received.Batches[i].Header
+		header.OriginatingDFI = routing
+		header.BatchNumber = len(f.Batches) + 1
+		batch := nacha.Batch{Header: header, Entries: []nacha.Entry{}}
+		for _, e := range entries {
+			sequence++
+			// Returns are never returned, every other code has a return code
+			code, _ := nacha.ReturnTransactionCode(e.TransactionCode)
+			batch.Entries = append(batch.Entries, nacha.Entry{
+				TransactionCode:    code,
+				RDFIRoutingNumber:  received.Batches[i].Header.OriginatingDFI,
+				DFIAccountNumber:   e.DFIAccountNumber,
+				Amount:             e.Amount,
+				IndividualIDNumber: e.IndividualIDNumber,
+				IndividualName:     e.IndividualName,
+				DiscretionaryData:  e.DiscretionaryData,
+				TraceNumber:        fmt.Sprintf("%s%07d", routing, sequence),
+				Addenda:            []nacha.Addenda{},
+				Return: &nacha.ReturnAddenda{
+					ReturnReasonCode:    e.Return.ReturnReasonCode,
+					OriginalTraceNumber: e.TraceNumber,
+					OriginalRDFI:        e.RDFIRoutingNumber,
+				},
+			})
+		}
+		f.Batches = append(f.Batches, batch)
+	}
+	return f
+}
@@ -0,0 +1,182 @@
+package ach_test
+
+import (
+	"bank/ach"
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/nacha"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const settlementAccountID = 900000005
+
+func inboundFile(t *testing.T) []byte {
+	t.Helper()
+	entry := func(code int, account string, amount int64, sequence int) nacha.Entry {
+		return nacha.Entry{
+			TransactionCode:   code,
+			RDFIRoutingNumber: "09100001",
+			DFIAccountNumber:  account,
+			Amount:            amount,
+			IndividualName:    "CUSTOMER",
+			TraceNumber:       fmt.Sprintf("07100001%07d", sequence),
+			Addenda:           []nacha.Addenda{},
+		}
+	}
+
+	f := &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination:     " 091000019",
+			ImmediateOrigin:          " 071000013",
+			CreatedAt:                time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC),
+			FileIDModifier:           "A",
+			ImmediateDestinationName: "BANK",
+			ImmediateOriginName:      "SPONSOR BANK",
+		},
+		Batches: []nacha.Batch{
+			{
+				Header: nacha.BatchHeader{
+					ServiceClassCode:        nacha.ServiceClassMixed,
+					CompanyName:             "ACME",
+					CompanyIdentification:   "1234567890",
+					StandardEntryClassCode:  "PPD",
+					CompanyEntryDescription: "PAYMENTS",
+					EffectiveEntryDate:      "261019",
+					OriginatorStatusCode:    "1",
+					OriginatingDFI:          "07100001",
+					BatchNumber:             1,
+				},
+				Entries: []nacha.Entry{
+					entry(nacha.CheckingCredit, "600", 5000, 1),
+					entry(nacha.CheckingDebit, "600", 3000, 2),
+					entry(nacha.SavingsDebit, "601", 1000, 3),
+					entry(nacha.CheckingCredit, "999", 2500, 4),
+					entry(nacha.CheckingCredit, "ABC", 100, 5),
+					entry(nacha.CheckingCreditPrenote, "600", 0, 6),
+					entry(nacha.CheckingCredit, "900000005", 100, 7),
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := nacha.Write(&buf, f); err != nil {
+		t.Fatalf("failed to write inbound file: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportFile(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		testLogger := logger.NewLogger("debug")
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create transaction domain: %v", err)
+		}
+
+		domain, err := ach.NewACHDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+			ACHSettlementAccountID: settlementAccountID,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create ach domain: %v", err)
+		}
+
+		statements := []string{
+			"INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES (600, 'SAVINGS', now(), now()), (601, 'SAVINGS', now(), now())",
+			"INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES (600, 100, 'CREDIT', now())",
+		}
+		for _, stmt := range statements {
+			if _, err := testDB.DB.Exec(stmt); err != nil {
+				t.Fatalf("failed to seed data: %v", err)
+			}
+		}
+
+		content := inboundFile(t)
+		expectedReturns := map[string]struct {
+			code   int
+			reason string
+		}{
+			"071000010000003": {nacha.SavingsReturnDebit, entity.ACHReturnInsufficientFunds},
+			"071000010000004": {nacha.CheckingReturnCredit, entity.ACHReturnNoAccount},
+			"071000010000005": {nacha.CheckingReturnCredit, entity.ACHReturnInvalidAccountNumber},
+			"071000010000007": {nacha.CheckingReturnCredit, entity.ACHReturnNonTransactionAccount},
+		}
+
+		// The second import must not post anything, and must not send the returns again
+		for run, alreadyProcessed := range []int{0, 7} {
+			var returns bytes.Buffer
+			result, err := domain.ImportFile(ctx, bytes.NewReader(content), &returns)
+			if err != nil {
+				t.Fatalf("run %d: failed to import file: %v", run, err)
+			}
+
+			if result.Posted != 2 || result.Returned != 4 || result.Skipped != 1 || result.AlreadyProcessed != alreadyProcessed {
+				t.Errorf("run %d: expected 2 posted, 4 returned, 1 skipped and %d already processed, got %+v", run, alreadyProcessed, result)
+			}
+
+			if run > 0 {
+				if returns.Len() != 0 {
+					t.Errorf("run %d: expected no returns file, got %q", run, returns.String())
+				}
+				continue
+			}
+
+			returnsFile, err := nacha.Parse(&returns)
+			if err != nil {
+				t.Fatalf("run %d: failed to parse returns file: %v", run, err)
+			}
+
+			if returnsFile.Header.ImmediateDestination != " 071000013" || len(returnsFile.Batches) != 1 {
+				t.Fatalf("run %d: expected one batch addressed to the origin, got %+v", run, returnsFile)
+			}
+
+			entries := returnsFile.Batches[0].Entries
+			if len(entries) != len(expectedReturns) {
+				t.Fatalf("run %d: expected %d returns, got %d", run, len(expectedReturns), len(entries))
+			}
+
+			for _, e := range entries {
+				expected, ok := expectedReturns[e.Return.OriginalTraceNumber]
+				if !ok {
+					t.Errorf("run %d: unexpected return of %s", run, e.Return.OriginalTraceNumber)
+					continue
+				}
+				if e.TransactionCode != expected.code || e.Return.ReturnReasonCode != expected.reason {
+					t.Errorf("run %d: expected return of %s with code %d and reason %s, got %d and %s",
+						run, e.Return.OriginalTraceNumber, expected.code, expected.reason, e.TransactionCode, e.Return.ReturnReasonCode)
+				}
+				if e.RDFIRoutingNumber != "07100001" {
+					t.Errorf("run %d: expected return to be addressed to 07100001, got %s", run, e.RDFIRoutingNumber)
+				}
+			}
+		}
+
+		expectedBalances := map[uint64]string{600: "120", 601: "0", settlementAccountID: "-20"}
+		for accountID, expected := range expectedBalances {
+			var balance string
+			if err := testDB.DB.QueryRow("SELECT get_account_balance($1, false)", accountID).Scan(&balance); err != nil {
+				t.Fatalf("failed to get balance of account %d: %v", accountID, err)
+			}
+			if !decimal.RequireFromString(balance).Equal(decimal.RequireFromString(expected)) {
+				t.Errorf("expected balance of account %d to be %s, got %s", accountID, expected, balance)
+			}
+		}
+	})
+}
@@ -0,0 +1,516 @@
+package approval
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/transaction"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const listLimit = 100
+
+type ApprovalDomain struct {
+	db                  *sql.DB
+	queries             *sqlc.Queries
+	transactionDomain   *transaction.TransactionDomain
+	logger              *logger.Logger
+	transferThreshold   decimal.Decimal
+	ttl                 time.Duration
+	adjustmentAccountID uint64
+}
+
+func NewApprovalDomain(db *sql.DB, sqlc *sqlc.Queries, transactionDomain *transaction.TransactionDomain, cfg *config.Config, logger *logger.Logger) (*ApprovalDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.ApprovalTTL <= 0 {
+		return nil, errors.New("approval ttl must be positive")
+	}
+
+	if cfg.AdjustmentAccountID == 0 {
+		return nil, errors.New("adjustment account id is required")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "approval")
+	return &ApprovalDomain{
+		db:                  db,
+		queries:             sqlc,
+		transactionDomain:   transactionDomain,
+		logger:              log,
+		transferThreshold:   cfg.ApprovalTransferThreshold,
+		ttl:                 cfg.ApprovalTTL,
+		adjustmentAccountID: cfg.AdjustmentAccountID,
+	}, nil
+}
+
+// RequiresApproval reports whether a transfer of the given amount must go through
+// maker-checker approval. A zero threshold disables the workflow.
+func (d *ApprovalDomain) RequiresApproval(amount decimal.Decimal) bool {
+	return d.transferThreshold.IsPositive() && amount.GreaterThan(d.transferThreshold)
+}
+
+// RequestTransfer records a transfer as PENDING_APPROVAL instead of executing it.
+// The funds only move once another person approves the request.
+func (d *ApprovalDomain) RequestTransfer(ctx context.Context, param entity.CreateTransferApproval) (entity.ApprovalRequest, error) {
+	logger.AddAccountIDs(ctx, param.SourceAccountID, param.DestinationAccountID)
+	if err := param.Validate(); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	if param.SourceAccountID == param.DestinationAccountID {
+		return entity.ApprovalRequest{}, fmt.Errorf("%w: Cannot transfer to the same account", entity.ErrValidation)
+	}
+
+	return d.createRequest(ctx, sqlc.CreateApprovalRequestParams{
+		Kind:                 string(entity.ApprovalKindTransfer),
+		SourceAccountID:      int64(param.SourceAccountID),
+		DestinationAccountID: int64(param.DestinationAccountID),
+		Amount:               param.Amount,
+		RequestedBy:          param.RequestedBy,
+	})
+}
+
+// RequestAdjustment records an admin adjustment as PENDING_APPROVAL. Adjustments are
+// always approved by a second person and post against the adjustment system account.
+func (d *ApprovalDomain) RequestAdjustment(ctx context.Context, param entity.CreateAdjustment) (entity.ApprovalRequest, error) {
+	logger.AddAccountIDs(ctx, param.AccountID)
+	if err := param.Validate(); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	if param.AccountID == d.adjustmentAccountID {
+		return entity.ApprovalRequest{}, fmt.Errorf("%w: cannot adjust the adjustment account", entity.ErrValidation)
+	}
+
+	source, destination := d.adjustmentAccountID, param.AccountID
+	if param.TrxType == entity.TrxTypeDebit {
+		source, destination = param.AccountID, d.adjustmentAccountID
+	}
+
+	return d.createRequest(ctx, sqlc.CreateApprovalRequestParams{
+		Kind:                 string(entity.ApprovalKindAdjustment),
+		SourceAccountID:      int64(source),
+		DestinationAccountID: int64(destination),
+		Amount:               param.Amount,
+		RequestedBy:          param.RequestedBy,
+		Reason:               param.Reason,
+	})
+}
+
+// RequestPaymentTx records the transfer of an external payment to the clearing account as
+// PENDING_APPROVAL within tx, where the payment domain creates the payment waiting for it. The
+// funds only move once another person approves the request.
+func (d *ApprovalDomain) RequestPaymentTx(ctx context.Context, tx *sql.Tx, param entity.CreateTransferApproval) (entity.ApprovalRequest, error) {
+	if err := param.Validate(); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	request, err := d.insertRequest(ctx, d.queries.WithTx(tx), sqlc.CreateApprovalRequestParams{
+		Kind:                 string(entity.ApprovalKindPayment),
+		SourceAccountID:      int64(param.SourceAccountID),
+		DestinationAccountID: int64(param.DestinationAccountID),
+		Amount:               param.Amount,
+		RequestedBy:          param.RequestedBy,
+	})
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	return toApprovalRequest(request), nil
+}
+
+func (d *ApprovalDomain) createRequest(ctx context.Context, param sqlc.CreateApprovalRequestParams) (entity.ApprovalRequest, error) {
+	exists, err := d.queries.CheckAccountExists(ctx, d.customerAccountID(param))
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to check account exists: %w", err)
+	}
+
+	if !exists {
+		return entity.ApprovalRequest{}, entity.ErrDataNotFound
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		d.logger.Error(ctx, "failed to begin transaction for approval request: %v", err)
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	request, err := d.insertRequest(ctx, d.queries.WithTx(tx), param)
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error(ctx, "failed to commit approval request: %v", err)
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return toApprovalRequest(request), nil
+}
+
+// insertRequest stores a new pending request with its REQUESTED step
+func (d *ApprovalDomain) insertRequest(ctx context.Context, qtx *sqlc.Queries, param sqlc.CreateApprovalRequestParams) (sqlc.ApprovalRequest, error) {
+	param.ExpiresAt = time.Now().Add(d.ttl)
+	request, err := qtx.CreateApprovalRequest(ctx, param)
+	if err != nil {
+		d.logger.Error(ctx, "param=%+v, failed to create approval request: %v", param, err)
+		return sqlc.ApprovalRequest{}, fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	if err := d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventRequested, param.RequestedBy, param.Reason); err != nil {
+		return sqlc.ApprovalRequest{}, err
+	}
+
+	return request, nil
+}
+
+// customerAccountID returns the non-system side of a request, which must already exist
+func (d *ApprovalDomain) customerAccountID(param sqlc.CreateApprovalRequestParams) int64 {
+	if param.Kind == string(entity.ApprovalKindAdjustment) && uint64(param.SourceAccountID) == d.adjustmentAccountID {
+		return param.DestinationAccountID
+	}
+	return param.SourceAccountID
+}
+
+// GetApprovalRequest returns an approval request together with its recorded steps.
+func (d *ApprovalDomain) GetApprovalRequest(ctx context.Context, id uint64) (entity.ApprovalRequest, error) {
+	request, err := d.queries.GetApprovalRequestByID(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ApprovalRequest{}, entity.ErrDataNotFound
+		}
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to get approval request: %w", err)
+	}
+
+	events, err := d.queries.ListApprovalEventsByApprovalRequestID(ctx, request.ID)
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to list approval events: %w", err)
+	}
+
+	result := toApprovalRequest(request)
+	result.Events = make([]entity.ApprovalEvent, 0, len(events))
+	for _, event := range events {
+		result.Events = append(result.Events, toApprovalEvent(event))
+	}
+	return result, nil
+}
+
+// ListApprovalRequests returns the oldest requests in the given status.
+func (d *ApprovalDomain) ListApprovalRequests(ctx context.Context, status entity.ApprovalStatus) ([]entity.ApprovalRequest, error) {
+	requests, err := d.queries.ListApprovalRequestsByStatus(ctx, sqlc.ListApprovalRequestsByStatusParams{
+		Status: string(status),
+		Limit:  listLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+
+	result := make([]entity.ApprovalRequest, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, toApprovalRequest(request))
+	}
+	return result, nil
+}
+
+// Approve approves a pending request and executes it in the same database transaction.
+// The approver must differ from the requester. If execution fails for a business reason
+// (insufficient funds, unknown account) the request is marked FAILED and the error returned.
+func (d *ApprovalDomain) Approve(ctx context.Context, param entity.DecideApproval) (entity.ApprovalRequest, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		d.logger.Error(ctx, "failed to begin transaction for approval_id=%d: %v", param.ApprovalRequestID, err)
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	request, err := d.lockPending(ctx, tx, qtx, param)
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	if err := d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventApproved, param.Actor, param.Note); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	transfer := entity.CreateTransferFundsParams{
+		SourceAccountID:      uint64(request.SourceAccountID),
+		DestinationAccountID: uint64(request.DestinationAccountID),
+		Amount:               request.Amount,
+	}
+
+	adjustment := entity.ApprovalKind(request.Kind) == entity.ApprovalKindAdjustment
+	if adjustment {
+		if err = qtx.EnsureAccount(ctx, int64(d.adjustmentAccountID)); err != nil {
+			return entity.ApprovalRequest{}, fmt.Errorf("failed to ensure adjustment account: %w", err)
+		}
+	}
+
+	// Only a credit from the adjustment account skips the balance check, a debit adjustment can't
+	// overdraw the customer account any more than a transfer can
+	var result entity.CreateTransferFundsResult
+	if adjustment && uint64(request.SourceAccountID) == d.adjustmentAccountID {
+		result, err = d.transactionDomain.PostTransferTx(ctx, tx, transfer)
+	} else {
+		result, err = d.transactionDomain.CreateTransferFundsTx(ctx, tx, transfer)
+	}
+
+	var execErr error
+	switch {
+	case err == nil:
+		request.Status = string(entity.ApprovalStatusExecuted)
+		request.TransferID = sql.NullInt64{Int64: int64(result.TransferID), Valid: true}
+		err = d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventExecuted, entity.ApprovalActorSystem, "")
+	case errors.Is(err, entity.ErrInsufficientFunds),
+		errors.Is(err, entity.ErrDataNotFound),
+		errors.Is(err, entity.ErrValidation):
+		execErr = err
+		request.Status = string(entity.ApprovalStatusFailed)
+		err = d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventFailed, entity.ApprovalActorSystem, err.Error())
+	default:
+		d.logger.Error(ctx, "failed to execute approval_id=%d: %v", request.ID, err)
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to execute approval request: %w", err)
+	}
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	request.DecidedBy = sql.NullString{String: param.Actor, Valid: true}
+	if err := d.finish(ctx, tx, qtx, request); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	return toApprovalRequest(request), execErr
+}
+
+// Reject closes a pending request without moving any funds.
+func (d *ApprovalDomain) Reject(ctx context.Context, param entity.DecideApproval) (entity.ApprovalRequest, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		d.logger.Error(ctx, "failed to begin transaction for approval_id=%d: %v", param.ApprovalRequestID, err)
+		return entity.ApprovalRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	request, err := d.lockPending(ctx, tx, qtx, param)
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	if err := d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventRejected, param.Actor, param.Note); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	request.Status = string(entity.ApprovalStatusRejected)
+	request.DecidedBy = sql.NullString{String: param.Actor, Valid: true}
+	if err := d.finish(ctx, tx, qtx, request); err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	return toApprovalRequest(request), nil
+}
+
+// ExpireApprovalRequests marks every pending request past its deadline as EXPIRED.
+// It is run periodically by the worker and returns the number of expired requests.
+func (d *ApprovalDomain) ExpireApprovalRequests(ctx context.Context) (int, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	ids, err := qtx.ExpireApprovalRequests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire approval requests: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := d.recordEvent(ctx, qtx, id, entity.ApprovalEventExpired, entity.ApprovalActorSystem, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	err = qtx.RejectUnapprovedExternalPayments(ctx, sqlc.RejectUnapprovedExternalPaymentsParams{
+		StatusReason:       paymentReason(entity.ApprovalStatusExpired),
+		ApprovalRequestIds: ids,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reject unapproved external payments: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// lockPending locks the request row and checks it can still be decided by the actor.
+// A request found past its deadline is expired on the spot.
+func (d *ApprovalDomain) lockPending(ctx context.Context, tx *sql.Tx, qtx *sqlc.Queries, param entity.DecideApproval) (sqlc.ApprovalRequest, error) {
+	if param.Actor == "" {
+		return sqlc.ApprovalRequest{}, fmt.Errorf("%w: approver is required", entity.ErrValidation)
+	}
+
+	request, err := qtx.GetApprovalRequestByIDForUpdate(ctx, int64(param.ApprovalRequestID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.ApprovalRequest{}, entity.ErrDataNotFound
+		}
+		return sqlc.ApprovalRequest{}, fmt.Errorf("failed to get approval request: %w", err)
+	}
+
+	if request.Status != string(entity.ApprovalStatusPending) {
+		return sqlc.ApprovalRequest{}, fmt.Errorf("%w: approval request is %s", entity.ErrInvalidState, request.Status)
+	}
+
+	if !time.Now().Before(request.ExpiresAt) {
+		if err := d.recordEvent(ctx, qtx, request.ID, entity.ApprovalEventExpired, entity.ApprovalActorSystem, ""); err != nil {
+			return sqlc.ApprovalRequest{}, err
+		}
+		request.Status = string(entity.ApprovalStatusExpired)
+		if err := d.finish(ctx, tx, qtx, request); err != nil {
+			return sqlc.ApprovalRequest{}, err
+		}
+		return sqlc.ApprovalRequest{}, entity.ErrExpired
+	}
+
+	if request.RequestedBy == param.Actor {
+		return sqlc.ApprovalRequest{}, fmt.Errorf("%w: requester cannot decide their own request", entity.ErrForbidden)
+	}
+
+	return request, nil
+}
+
+// finish stores the final state of a request, and of the payment waiting for it, and commits the
+// transaction
+func (d *ApprovalDomain) finish(ctx context.Context, tx *sql.Tx, qtx *sqlc.Queries, request sqlc.ApprovalRequest) error {
+	err := qtx.UpdateApprovalRequestDecision(ctx, sqlc.UpdateApprovalRequestDecisionParams{
+		ID:         request.ID,
+		Status:     request.Status,
+		DecidedBy:  request.DecidedBy,
+		TransferID: request.TransferID,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "failed to update approval_id=%d: %v", request.ID, err)
+		return fmt.Errorf("failed to update approval request: %w", err)
+	}
+
+	if entity.ApprovalKind(request.Kind) == entity.ApprovalKindPayment {
+		if err := d.finishPayment(ctx, qtx, request); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error(ctx, "failed to commit approval_id=%d: %v", request.ID, err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// finishPayment releases the payment of an executed request to the next payment file, with the
+// transfer that moved its amount to the clearing account. The payment of any other request is
+// rejected without having left the account.
+func (d *ApprovalDomain) finishPayment(ctx context.Context, qtx *sqlc.Queries, request sqlc.ApprovalRequest) error {
+	var err error
+	if entity.ApprovalStatus(request.Status) == entity.ApprovalStatusExecuted {
+		err = qtx.ReleaseApprovedExternalPayment(ctx, sqlc.ReleaseApprovedExternalPaymentParams{
+			TransferID:        request.TransferID,
+			ApprovalRequestID: sql.NullInt64{Int64: request.ID, Valid: true},
+		})
+	} else {
+		err = qtx.RejectUnapprovedExternalPayments(ctx, sqlc.RejectUnapprovedExternalPaymentsParams{
+			StatusReason:       paymentReason(entity.ApprovalStatus(request.Status)),
+			ApprovalRequestIds: []int64{request.ID},
+		})
+	}
+	if err != nil {
+		d.logger.Error(ctx, "failed to update the payment of approval_id=%d: %v", request.ID, err)
+		return fmt.Errorf("failed to update external payment: %w", err)
+	}
+	return nil
+}
+
+// paymentReason is the status reason of a payment whose approval request ended in status
+func paymentReason(status entity.ApprovalStatus) string {
+	return "approval request " + string(status)
+}
+
+func (d *ApprovalDomain) recordEvent(ctx context.Context, qtx *sqlc.Queries, approvalRequestID int64, eventType entity.ApprovalEventType, actor, note string) error {
+	err := qtx.CreateApprovalEvent(ctx, sqlc.CreateApprovalEventParams{
+		ApprovalRequestID: approvalRequestID,
+		EventType:         string(eventType),
+		Actor:             actor,
+		Note:              note,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "failed to record %s event for approval_id=%d: %v", eventType, approvalRequestID, err)
+		return fmt.Errorf("failed to record approval event: %w", err)
+	}
+	return nil
+}
+
+func toApprovalRequest(r sqlc.ApprovalRequest) entity.ApprovalRequest {
+	return entity.ApprovalRequest{
+		ModelWithUpdatedAt: entity.ModelWithUpdatedAt{
+			Model: entity.Model{
+				ID:        uint64(r.ID),
+				CreatedAt: r.CreatedAt.Time,
+			},
+			UpdatedAt: r.UpdatedAt.Time,
+		},
+		Kind:                 entity.ApprovalKind(r.Kind),
+		Status:               entity.ApprovalStatus(r.Status),
+		SourceAccountID:      uint64(r.SourceAccountID),
+		DestinationAccountID: uint64(r.DestinationAccountID),
+		Amount:               r.Amount,
+		RequestedBy:          r.RequestedBy,
+		DecidedBy:            r.DecidedBy.String,
+		Reason:               r.Reason,
+		TransferID:           uint64(r.TransferID.Int64),
+		ExpiresAt:            r.ExpiresAt,
+	}
+}
+
+func toApprovalEvent(e sqlc.ApprovalEvent) entity.ApprovalEvent {
+	return entity.ApprovalEvent{
+		Model: entity.Model{
+			ID:        uint64(e.ID),
+			CreatedAt: e.CreatedAt.Time,
+		},
+		ApprovalRequestID: uint64(e.ApprovalRequestID),
+		EventType:         entity.ApprovalEventType(e.EventType),
+		Actor:             e.Actor,
+		Note:              e.Note,
+	}
+}
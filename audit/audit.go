@@ -0,0 +1,285 @@
+// Package audit keeps the audit trail: a record of every mutating call of the APIs, and of every
+// run of the worker jobs and commands that change the ledger, with its caller, payload and
+// outcome. Records are hash-chained, each holds the hash of the one before it, so Verify detects
+// a record that was changed or deleted.
+package audit
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/requestid"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+)
+
+// chainLockKey is the advisory lock that serializes the writers of the chain
+const chainLockKey = 0x61756469740000
+
+// verifyBatchSize is the number of records Verify reads at once
+const verifyBatchSize = 1000
+
+// redactedFields are the top level fields of JSON payloads whose values aren't kept, the
+// signing secrets of webhook subscriptions
+var redactedFields = []string{"secret"}
+
+type AuditDomain struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+	logger  *logger.Logger
+}
+
+func NewAuditDomain(db *sql.DB, sqlc *sqlc.Queries, logger *logger.Logger) (*AuditDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "audit")
+	return &AuditDomain{db: db, queries: sqlc, logger: log}, nil
+}
+
+// Record appends a record to the chain. Writers take turns, so each record chains to the one
+// committed before it.
+func (d *AuditDomain) Record(ctx context.Context, param entity.CreateAuditRecord) (entity.AuditRecord, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.AuditRecord{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := d.queries.WithTx(tx)
+	if err := queries.LockAuditChain(ctx, chainLockKey); err != nil {
+		return entity.AuditRecord{}, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	head, err := queries.GetAuditChainHead(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return entity.AuditRecord{}, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	record := entity.AuditRecord{
+		CreateAuditRecord: param,
+		Seq:               uint64(head.Seq) + 1,
+		PrevHash:          head.Hash,
+	}
+	// Postgres keeps microseconds, the hash must cover the time as it is read back
+	record.OccurredAt = record.OccurredAt.UTC().Truncate(time.Microsecond)
+	record.Payload = redact(record.Payload)
+	record.Hash = Hash(record)
+
+	err = queries.CreateAuditRecord(ctx, sqlc.CreateAuditRecordParams{
+		Seq:        int64(record.Seq),
+		OccurredAt: record.OccurredAt,
+		Actor:      record.Actor,
+		Action:     record.Action,
+		Resource:   record.Resource,
+		RequestID:  record.RequestID,
+		Ip:         record.IP,
+		Payload:    record.Payload,
+		Status:     record.Status,
+		Outcome:    string(record.Outcome),
+		PrevHash:   record.PrevHash,
+		Hash:       record.Hash,
+	})
+	if err != nil {
+		return entity.AuditRecord{}, fmt.Errorf("failed to create audit record: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.AuditRecord{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return record, nil
+}
+
+// Received records a call before it runs, with the RECEIVED outcome and no status. A call whose
+// record fails to be written must not run, it would take effect without a trace: the record of
+// its outcome is written once its changes are committed and can't undo them.
+func (d *AuditDomain) Received(ctx context.Context, param entity.CreateAuditRecord) error {
+	param.Status = ""
+	param.Outcome = entity.AuditOutcomeReceived
+	_, err := d.Record(ctx, param)
+	return err
+}
+
+// Run records a run of a worker job or a command: fn only runs once its RECEIVED record is
+// written, and the record of its outcome holds the JSON of the result fn returns. Both records
+// share a request id.
+func (d *AuditDomain) Run(ctx context.Context, param entity.CreateAuditRecord, fn func(ctx context.Context) (any, error)) error {
+	param.OccurredAt = time.Now()
+	param.RequestID = requestid.New()
+	if err := d.Received(ctx, param); err != nil {
+		return fmt.Errorf("failed to record %s: %w", param.Action, err)
+	}
+
+	result, err := fn(ctx)
+
+	param.Payload, _ = json.Marshal(result)
+	param.Status = "OK"
+	param.Outcome = entity.AuditOutcomeSucceeded
+	if err != nil {
+		param.Status = "ERROR"
+		param.Outcome = entity.AuditOutcomeFailed
+	}
+	if _, recordErr := d.Record(context.WithoutCancel(ctx), param); recordErr != nil {
+		d.logger.Error(ctx, "failed to record the outcome of %s: %v", param.Action, recordErr)
+	}
+	return err
+}
+
+// LocalUser is the login name of the user running the process, the actor of the commands
+func LocalUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Verify walks the chain from its first record and stops at the first one that doesn't chain: a
+// missing record, one whose hash doesn't match its fields, or one that doesn't hold the hash of
+// the record before it. Records deleted from the end of the chain leave no gap, they are only
+// detected against an anchor, a head returned by an earlier verification.
+func (d *AuditDomain) Verify(ctx context.Context, anchor *entity.AuditChainHead) (entity.AuditVerification, error) {
+	var verification entity.AuditVerification
+	for {
+		rows, err := d.queries.ListAuditRecordsAfterSeq(ctx, sqlc.ListAuditRecordsAfterSeqParams{
+			AfterSeq:  int64(verification.Head.Seq),
+			BatchSize: verifyBatchSize,
+		})
+		if err != nil {
+			return entity.AuditVerification{}, fmt.Errorf("failed to list audit records: %w", err)
+		}
+
+		for _, row := range rows {
+			record := toAuditRecord(row)
+			if problem := checkLink(verification.Head, record); problem != "" {
+				verification.BrokenSeq = verification.Head.Seq + 1
+				verification.Problem = problem
+				return verification, nil
+			}
+			if anchor != nil && record.Seq == anchor.Seq && record.Hash != anchor.Hash {
+				verification.BrokenSeq = record.Seq
+				verification.Problem = "the chain was rewritten, the record doesn't match the anchor"
+				return verification, nil
+			}
+
+			verification.Records++
+			verification.Head = entity.AuditChainHead{Seq: record.Seq, Hash: record.Hash}
+		}
+
+		if len(rows) < verifyBatchSize {
+			break
+		}
+	}
+
+	if anchor != nil && verification.Head.Seq < anchor.Seq {
+		verification.BrokenSeq = verification.Head.Seq + 1
+		verification.Problem = fmt.Sprintf("the records up to the anchor %d are missing", anchor.Seq)
+	}
+	return verification, nil
+}
+
+// checkLink tells why record doesn't follow head, or returns an empty string
+func checkLink(head entity.AuditChainHead, record entity.AuditRecord) string {
+	switch {
+	case record.Seq == head.Seq+2:
+		return fmt.Sprintf("the record %d is missing", head.Seq+1)
+	case record.Seq != head.Seq+1:
+		return fmt.Sprintf("the records %d to %d are missing", head.Seq+1, record.Seq-1)
+	case record.PrevHash != head.Hash:
+		return "the record doesn't chain to the one before it"
+	case Hash(record) != record.Hash:
+		return "the record was changed, its hash doesn't match its fields"
+	}
+	return ""
+}
+
+// Hash returns the hex SHA-256 of the hash of the record before and the fields of the record.
+// Each field is prefixed with its length, so different records never hash the same bytes.
+func Hash(record entity.AuditRecord) string {
+	h := sha256.New()
+	for _, field := range [][]byte{
+		[]byte(record.PrevHash),
+		[]byte(strconv.FormatUint(record.Seq, 10)),
+		[]byte(record.OccurredAt.UTC().Format(time.RFC3339Nano)),
+		[]byte(record.Actor),
+		[]byte(record.Action),
+		[]byte(record.Resource),
+		[]byte(record.RequestID),
+		[]byte(record.IP),
+		record.Payload,
+		[]byte(record.Status),
+		[]byte(record.Outcome),
+	} {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(field))))
+		h.Write(field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redact replaces the values of the redacted fields of a JSON object payload. Other payloads are
+// kept as they are, never nil.
+func redact(payload []byte) []byte {
+	if payload == nil {
+		return []byte{}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload
+	}
+
+	redacted := false
+	for _, field := range redactedFields {
+		if _, ok := fields[field]; ok {
+			fields[field] = json.RawMessage(`"REDACTED"`)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return payload
+	}
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return out
+}
+
+func toAuditRecord(row sqlc.AuditRecord) entity.AuditRecord {
+	return entity.AuditRecord{
+		CreateAuditRecord: entity.CreateAuditRecord{
+			OccurredAt: row.OccurredAt,
+			Actor:      row.Actor,
+			Action:     row.Action,
+			Resource:   row.Resource,
+			RequestID:  row.RequestID,
+			IP:         row.Ip,
+			Payload:    row.Payload,
+			Status:     row.Status,
+			Outcome:    entity.AuditOutcome(row.Outcome),
+		},
+		Seq:      uint64(row.Seq),
+		PrevHash: row.PrevHash,
+		Hash:     row.Hash,
+	}
+}
@@ -0,0 +1,221 @@
+package audit_test
+
+import (
+	"bank/audit"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/test"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAuditChain(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		domain, err := audit.NewAuditDomain(testDB.DB, sqlc.New(testDB.DB), logger.NewLogger("debug"))
+		if err != nil {
+			t.Fatalf("failed to create audit domain: %v", err)
+		}
+
+		verify := func(anchor *entity.AuditChainHead) entity.AuditVerification {
+			t.Helper()
+			verification, err := domain.Verify(ctx, anchor)
+			if err != nil {
+				t.Fatalf("failed to verify audit chain: %v", err)
+			}
+			return verification
+		}
+
+		if verification := verify(nil); !verification.Intact() || verification.Records != 0 {
+			t.Fatalf("expected an empty intact chain, got %+v", verification)
+		}
+
+		// Concurrent writers each chain to the record committed before theirs
+		var wg sync.WaitGroup
+		for i := range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := domain.Record(ctx, entity.CreateAuditRecord{
+					OccurredAt: time.Now(),
+					Actor:      fmt.Sprintf("user-%d", i),
+					Action:     "POST /transactions",
+					Resource:   "/transactions",
+					IP:         "192.0.2.1",
+					Payload:    []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"10"}`),
+					Status:     "200",
+					Outcome:    entity.AuditOutcomeSucceeded,
+				})
+				if err != nil {
+					t.Errorf("failed to record: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		record, err := domain.Record(ctx, entity.CreateAuditRecord{
+			OccurredAt: time.Now(),
+			Actor:      "admin",
+			Action:     "POST /admin/webhooks",
+			Payload:    []byte(`{"url":"https://example.com","secret":"0123456789abcdef"}`),
+			Status:     "201",
+			Outcome:    entity.AuditOutcomeSucceeded,
+		})
+		if err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+		if strings.Contains(string(record.Payload), "0123456789abcdef") {
+			t.Errorf("expected the secret to be redacted, got %s", record.Payload)
+		}
+
+		verification := verify(nil)
+		if !verification.Intact() || verification.Records != 6 || verification.Head.Seq != 6 || verification.Head.Hash != record.Hash {
+			t.Fatalf("expected an intact chain of 6 records ending with the last one, got %+v", verification)
+		}
+		anchor := verification.Head
+
+		if _, err := testDB.DB.Exec("DELETE FROM audit_records WHERE seq = 6"); err == nil {
+			t.Fatal("expected audit records to be append only")
+		}
+
+		// The owner of the table can lift the trigger that keeps it append only, the chain still tells
+		tamper := func(query string) {
+			t.Helper()
+			tx, err := testDB.DB.Begin()
+			if err != nil {
+				t.Fatalf("failed to begin transaction: %v", err)
+			}
+			defer tx.Rollback()
+			for _, statement := range []string{
+				"ALTER TABLE audit_records DISABLE TRIGGER audit_records_append_only",
+				query,
+				"ALTER TABLE audit_records ENABLE TRIGGER audit_records_append_only",
+			} {
+				if _, err := tx.Exec(statement); err != nil {
+					t.Fatalf("failed to tamper with the chain: %v", err)
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				t.Fatalf("failed to commit: %v", err)
+			}
+		}
+
+		tamper("UPDATE audit_records SET actor = 'someone-else' WHERE seq = 3")
+		if verification := verify(nil); verification.BrokenSeq != 3 || !strings.Contains(verification.Problem, "changed") {
+			t.Errorf("expected the changed record to break the chain, got %+v", verification)
+		}
+
+		// Recomputing the hash of the changed record doesn't hide it, the next one still holds the former
+		var changed sqlc.AuditRecord
+		err = testDB.DB.QueryRow("SELECT seq, occurred_at, actor, action, resource, request_id, ip, payload, status, outcome, prev_hash FROM audit_records WHERE seq = 3").
+			Scan(&changed.Seq, &changed.OccurredAt, &changed.Actor, &changed.Action, &changed.Resource, &changed.RequestID, &changed.Ip, &changed.Payload, &changed.Status, &changed.Outcome, &changed.PrevHash)
+		if err != nil {
+			t.Fatalf("failed to read record: %v", err)
+		}
+		rehashed := audit.Hash(entity.AuditRecord{
+			CreateAuditRecord: entity.CreateAuditRecord{
+				OccurredAt: changed.OccurredAt,
+				Actor:      changed.Actor,
+				Action:     changed.Action,
+				Resource:   changed.Resource,
+				RequestID:  changed.RequestID,
+				IP:         changed.Ip,
+				Payload:    changed.Payload,
+				Status:     changed.Status,
+				Outcome:    entity.AuditOutcome(changed.Outcome),
+			},
+			Seq:      uint64(changed.Seq),
+			PrevHash: changed.PrevHash,
+		})
+		tamper("UPDATE audit_records SET hash = '" + rehashed + "' WHERE seq = 3")
+		if verification := verify(nil); verification.BrokenSeq != 4 || verification.Records != 3 {
+			t.Errorf("expected the record after the changed one to break the chain, got %+v", verification)
+		}
+
+		tamper("DELETE FROM audit_records WHERE seq = 3")
+		if verification := verify(nil); verification.BrokenSeq != 3 || !strings.Contains(verification.Problem, "missing") {
+			t.Errorf("expected the deleted record to break the chain, got %+v", verification)
+		}
+
+		// Deleting the end of the chain leaves no gap, only the anchor tells
+		tamper("DELETE FROM audit_records WHERE seq >= 3")
+		if verification := verify(nil); !verification.Intact() || verification.Records != 2 {
+			t.Errorf("expected the shortened chain to verify without an anchor, got %+v", verification)
+		}
+		if verification := verify(&anchor); verification.BrokenSeq != 3 {
+			t.Errorf("expected the anchor to detect the deleted records, got %+v", verification)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		queries := sqlc.New(testDB.DB)
+		domain, err := audit.NewAuditDomain(testDB.DB, queries, logger.NewLogger("debug"))
+		if err != nil {
+			t.Fatalf("failed to create audit domain: %v", err)
+		}
+
+		ran := false
+		err = domain.Run(ctx, entity.CreateAuditRecord{Actor: "worker", Action: "job interest"}, func(ctx context.Context) (any, error) {
+			// The run is recorded before it starts
+			records, err := queries.ListAuditRecordsAfterSeq(ctx, sqlc.ListAuditRecordsAfterSeqParams{AfterSeq: 0, BatchSize: 10})
+			if err != nil {
+				t.Fatalf("failed to list audit records: %v", err)
+			}
+			if len(records) != 1 || records[0].Outcome != string(entity.AuditOutcomeReceived) {
+				t.Errorf("expected the run to be received before it starts, got %+v", records)
+			}
+			ran = true
+			return map[string]int{"capitalized": 2}, nil
+		})
+		if err != nil || !ran {
+			t.Fatalf("expected the job to run, got %v", err)
+		}
+
+		records, err := queries.ListAuditRecordsAfterSeq(ctx, sqlc.ListAuditRecordsAfterSeqParams{AfterSeq: 0, BatchSize: 10})
+		if err != nil {
+			t.Fatalf("failed to list audit records: %v", err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("expected the run to be recorded twice, got %d records", len(records))
+		}
+		outcome := records[1]
+		if outcome.Actor != "worker" || outcome.Action != "job interest" || outcome.Status != "OK" || outcome.Outcome != string(entity.AuditOutcomeSucceeded) {
+			t.Errorf("expected the outcome of the run, got %+v", outcome)
+		}
+		if string(outcome.Payload) != `{"capitalized":2}` || outcome.RequestID == "" || outcome.RequestID != records[0].RequestID {
+			t.Errorf("expected the result and request id of the run, got %+v", outcome)
+		}
+	})
+}
+
+func TestRun_WriteFailure(t *testing.T) {
+	// A closed database fails every write of the audit trail
+	db, err := sql.Open("postgres", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	domain, err := audit.NewAuditDomain(db, sqlc.New(db), logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create audit domain: %v", err)
+	}
+
+	ran := false
+	err = domain.Run(context.Background(), entity.CreateAuditRecord{Actor: "worker", Action: "job interest"}, func(ctx context.Context) (any, error) {
+		ran = true
+		return nil, nil
+	})
+	if err == nil || ran {
+		t.Errorf("expected a run that can't be recorded not to start, got %v, ran %v", err, ran)
+	}
+}
@@ -0,0 +1,385 @@
+package billing
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/transaction"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ledgerPrecision is the number of decimal places of a posted amount
+const ledgerPrecision = 6
+
+var monthsInYear = decimal.NewFromInt(12)
+
+type BillingDomain struct {
+	db                    *sql.DB
+	queries               *sqlc.Queries
+	transactionDomain     *transaction.TransactionDomain
+	logger                *logger.Logger
+	incomeAccountID       uint64
+	dueDays               int
+	minimumPaymentPercent decimal.Decimal
+	minimumPaymentFloor   decimal.Decimal
+	lateFee               decimal.Decimal
+}
+
+func NewBillingDomain(db *sql.DB, sqlc *sqlc.Queries, transactionDomain *transaction.TransactionDomain, cfg *config.Config, logger *logger.Logger) (*BillingDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.CreditIncomeAccountID == 0 {
+		return nil, errors.New("credit income account id is required")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "billing")
+	return &BillingDomain{
+		db:                    db,
+		queries:               sqlc,
+		transactionDomain:     transactionDomain,
+		logger:                log,
+		incomeAccountID:       cfg.CreditIncomeAccountID,
+		dueDays:               max(cfg.CreditStatementDueDays, 1),
+		minimumPaymentPercent: cfg.CreditMinimumPaymentPercent,
+		minimumPaymentFloor:   cfg.CreditMinimumPaymentFloor,
+		lateFee:               cfg.CreditLateFee,
+	}, nil
+}
+
+// MinimumPayment returns the least amount due on a statement balance: a percentage of the
+// balance but at least the floor, and never more than the balance itself.
+func MinimumPayment(balance, percent, floor decimal.Decimal) decimal.Decimal {
+	if !balance.IsPositive() {
+		return decimal.Zero
+	}
+	minimum := decimal.Max(balance.Mul(percent).RoundBank(ledgerPrecision), floor)
+	return decimal.Min(minimum, balance)
+}
+
+// MonthlyInterest returns one month of interest on the balance carried past the due date,
+// a twelfth of the annual rate rounded half to even to ledger precision.
+func MonthlyInterest(carried, annualRate decimal.Decimal) decimal.Decimal {
+	if !carried.IsPositive() || !annualRate.IsPositive() {
+		return decimal.Zero
+	}
+	return carried.Mul(annualRate).Div(monthsInYear).RoundBank(ledgerPrecision)
+}
+
+// ListStatements returns the statements of an account, newest first
+func (d *BillingDomain) ListStatements(ctx context.Context, accountID uint64) ([]entity.CreditStatement, error) {
+	exists, err := d.queries.CheckAccountExists(ctx, int64(accountID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check account exists: %w", err)
+	}
+
+	if !exists {
+		return nil, entity.ErrNoRows
+	}
+
+	statements, err := d.queries.ListCreditStatementsByAccountID(ctx, int64(accountID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credit statements: %w", err)
+	}
+
+	result := make([]entity.CreditStatement, 0, len(statements))
+	for _, s := range statements {
+		statement, err := toCreditStatement(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, statement)
+	}
+	return result, nil
+}
+
+// GenerateStatements closes every completed calendar month (UTC) of every CREDIT account
+// that has no statement yet. Interest on the unpaid previous balance and the late fee for
+// a missed minimum payment are posted to the credit income account in the same transaction
+// as the statement, so a month is never billed twice. Returns the number of statements.
+func (d *BillingDomain) GenerateStatements(ctx context.Context, now time.Time) (int, error) {
+	accounts, err := d.queries.ListAccountsByType(ctx, string(entity.AccountTypeCredit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list credit accounts: %w", err)
+	}
+
+	if len(accounts) > 0 {
+		if err := d.queries.EnsureAccount(ctx, int64(d.incomeAccountID)); err != nil {
+			return 0, fmt.Errorf("failed to ensure credit income account: %w", err)
+		}
+	}
+
+	currentMonth := truncateToMonth(now)
+	generated := 0
+	for _, account := range accounts {
+		n, err := d.generateAccountStatements(ctx, account, currentMonth)
+		generated += n
+		if err != nil {
+			d.logger.Error(ctx, "failed to generate statements for account_id=%d: %v", account.ID, err)
+			return generated, err
+		}
+	}
+	return generated, nil
+}
+
+func (d *BillingDomain) generateAccountStatements(ctx context.Context, account sqlc.Account, before time.Time) (int, error) {
+	var prev *sqlc.CreditStatement
+	periodStart := truncateToMonth(account.CreatedAt.Time)
+	latest, err := d.queries.GetLatestCreditStatementByAccountID(ctx, account.ID)
+	switch {
+	case err == nil:
+		prev = &latest
+		periodStart = latest.PeriodEnd
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("failed to get latest credit statement: %w", err)
+	}
+
+	// Charges of this run are posted now, after every period end being closed here,
+	// so they are missing from the ledger balance of the later periods
+	pending := decimal.Zero
+	generated := 0
+	for periodEnd := periodStart.AddDate(0, 1, 0); !periodEnd.After(before); periodEnd = periodEnd.AddDate(0, 1, 0) {
+		statement, err := d.generateStatement(ctx, account.ID, periodStart, periodEnd, prev, pending)
+		if err != nil {
+			return generated, fmt.Errorf("failed to generate statement for %s: %w", periodStart.Format("2006-01"), err)
+		}
+		charged, err := sumCharges(statement)
+		if err != nil {
+			return generated, err
+		}
+		pending = pending.Add(charged)
+		prev = &statement
+		periodStart = periodEnd
+		generated++
+	}
+	return generated, nil
+}
+
+func (d *BillingDomain) generateStatement(ctx context.Context, accountID int64, periodStart, periodEnd time.Time, prev *sqlc.CreditStatement, pending decimal.Decimal) (sqlc.CreditStatement, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	if _, err := qtx.LockAccount(ctx, accountID); err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	interest, lateFee := decimal.Zero, decimal.Zero
+	if prev != nil {
+		interest, lateFee, err = d.previousStatementCharges(ctx, qtx, *prev, periodStart, periodEnd)
+		if err != nil {
+			return sqlc.CreditStatement{}, err
+		}
+	}
+
+	interestTransferID, err := d.postCharge(ctx, tx, accountID, interest)
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to post interest: %w", err)
+	}
+
+	lateFeeTransferID, err := d.postCharge(ctx, tx, accountID, lateFee)
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to post late fee: %w", err)
+	}
+
+	ledgerBalance, err := qtx.GetAccountBalanceAt(ctx, sqlc.GetAccountBalanceAtParams{
+		AccountID: accountID,
+		Cutoff:    periodEnd,
+	})
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to get balance at period end: %w", err)
+	}
+
+	balance, err := decimal.NewFromString(ledgerBalance)
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("invalid balance %q: %w", ledgerBalance, err)
+	}
+
+	closingBalance := balance.Sub(pending).Sub(interest).Sub(lateFee)
+	statementBalance := decimal.Max(closingBalance.Neg(), decimal.Zero)
+	statement, err := qtx.CreateCreditStatement(ctx, sqlc.CreateCreditStatementParams{
+		AccountID:          accountID,
+		PeriodStart:        periodStart,
+		PeriodEnd:          periodEnd,
+		ClosingBalance:     closingBalance.String(),
+		StatementBalance:   statementBalance.String(),
+		MinimumPayment:     MinimumPayment(statementBalance, d.minimumPaymentPercent, d.minimumPaymentFloor).String(),
+		DueDate:            periodEnd.AddDate(0, 0, d.dueDays),
+		InterestCharged:    interest.String(),
+		LateFeeCharged:     lateFee.String(),
+		InterestTransferID: interestTransferID,
+		LateFeeTransferID:  lateFeeTransferID,
+	})
+	if err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to create credit statement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return sqlc.CreditStatement{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return statement, nil
+}
+
+// previousStatementCharges returns the interest and late fee owed for the previous statement.
+// Payments made up to the end of the due date count against it: a balance paid in full earns
+// no interest, and paying less than the minimum payment incurs the late fee.
+func (d *BillingDomain) previousStatementCharges(ctx context.Context, qtx *sqlc.Queries, prev sqlc.CreditStatement, periodStart, periodEnd time.Time) (decimal.Decimal, decimal.Decimal, error) {
+	owed, err := decimal.NewFromString(prev.StatementBalance)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("invalid statement balance %q: %w", prev.StatementBalance, err)
+	}
+
+	if !owed.IsPositive() {
+		return decimal.Zero, decimal.Zero, nil
+	}
+
+	minimum, err := decimal.NewFromString(prev.MinimumPayment)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("invalid minimum payment %q: %w", prev.MinimumPayment, err)
+	}
+
+	paymentsEnd := prev.DueDate.AddDate(0, 0, 1)
+	if paymentsEnd.After(periodEnd) {
+		paymentsEnd = periodEnd
+	}
+	total, err := qtx.SumAccountCreditsBetween(ctx, sqlc.SumAccountCreditsBetweenParams{
+		AccountID: prev.AccountID,
+		FromTime:  periodStart,
+		ToTime:    paymentsEnd,
+	})
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("failed to sum payments: %w", err)
+	}
+
+	payments, err := decimal.NewFromString(total)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("invalid payments total %q: %w", total, err)
+	}
+
+	rate, err := d.creditRateAt(ctx, qtx, periodStart)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	interest := MonthlyInterest(owed.Sub(payments), rate)
+	lateFee := decimal.Zero
+	if payments.LessThan(minimum) {
+		lateFee = d.lateFee
+	}
+	return interest, lateFee, nil
+}
+
+func (d *BillingDomain) creditRateAt(ctx context.Context, qtx *sqlc.Queries, day time.Time) (decimal.Decimal, error) {
+	rate, err := qtx.GetInterestRateAt(ctx, sqlc.GetInterestRateAtParams{
+		AccountType:   string(entity.AccountTypeCredit),
+		EffectiveFrom: day,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return decimal.Zero, nil
+		}
+		return decimal.Zero, fmt.Errorf("failed to get interest rate: %w", err)
+	}
+
+	annualRate, err := decimal.NewFromString(rate.AnnualRate)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid annual rate %q: %w", rate.AnnualRate, err)
+	}
+	return annualRate, nil
+}
+
+func (d *BillingDomain) postCharge(ctx context.Context, tx *sql.Tx, accountID int64, amount decimal.Decimal) (sql.NullInt64, error) {
+	if !amount.IsPositive() {
+		return sql.NullInt64{}, nil
+	}
+
+	result, err := d.transactionDomain.PostTransferTx(ctx, tx, entity.CreateTransferFundsParams{
+		SourceAccountID:      uint64(accountID),
+		DestinationAccountID: d.incomeAccountID,
+		Amount:               amount,
+	})
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	return sql.NullInt64{Int64: int64(result.TransferID), Valid: true}, nil
+}
+
+func sumCharges(s sqlc.CreditStatement) (decimal.Decimal, error) {
+	interest, err := decimal.NewFromString(s.InterestCharged)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid interest charged %q: %w", s.InterestCharged, err)
+	}
+
+	lateFee, err := decimal.NewFromString(s.LateFeeCharged)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid late fee charged %q: %w", s.LateFeeCharged, err)
+	}
+	return interest.Add(lateFee), nil
+}
+
+func truncateToMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func toCreditStatement(s sqlc.CreditStatement) (entity.CreditStatement, error) {
+	amounts := map[string]string{
+		"closing balance":   s.ClosingBalance,
+		"statement balance": s.StatementBalance,
+		"minimum payment":   s.MinimumPayment,
+		"interest charged":  s.InterestCharged,
+		"late fee charged":  s.LateFeeCharged,
+	}
+	parsed := make(map[string]decimal.Decimal, len(amounts))
+	for name, value := range amounts {
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return entity.CreditStatement{}, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+		parsed[name] = d
+	}
+
+	return entity.CreditStatement{
+		Model: entity.Model{
+			ID:        uint64(s.ID),
+			CreatedAt: s.CreatedAt.Time,
+		},
+		AccountID:        uint64(s.AccountID),
+		PeriodStart:      s.PeriodStart,
+		PeriodEnd:        s.PeriodEnd,
+		ClosingBalance:   parsed["closing balance"],
+		StatementBalance: parsed["statement balance"],
+		MinimumPayment:   parsed["minimum payment"],
+		DueDate:          s.DueDate,
+		InterestCharged:  parsed["interest charged"],
+		LateFeeCharged:   parsed["late fee charged"],
+	}, nil
+}
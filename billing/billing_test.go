@@ -0,0 +1,154 @@
+package billing_test
+
+import (
+	"bank/billing"
+	"bank/config"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const incomeAccountID = 900000003
+
+func TestMinimumPayment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		balance  string
+		expected string
+	}{
+		{name: "percentage of balance", balance: "5000", expected: "100"},
+		{name: "floor", balance: "500", expected: "25"},
+		{name: "balance below floor", balance: "10", expected: "10"},
+		{name: "nothing owed", balance: "0", expected: "0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := billing.MinimumPayment(decimal.RequireFromString(tc.balance), decimal.RequireFromString("0.02"), decimal.NewFromInt(25))
+			if !got.Equal(decimal.RequireFromString(tc.expected)) {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMonthlyInterest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		carried    string
+		annualRate string
+		expected   string
+	}{
+		{name: "whole result", carried: "1200", annualRate: "0.24", expected: "24"},
+		{name: "rounded half to even", carried: "0.000025", annualRate: "0.24", expected: "0"},
+		{name: "nothing carried", carried: "0", annualRate: "0.24", expected: "0"},
+		{name: "overpaid", carried: "-50", annualRate: "0.24", expected: "0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := billing.MonthlyInterest(decimal.RequireFromString(tc.carried), decimal.RequireFromString(tc.annualRate))
+			if !got.Equal(decimal.RequireFromString(tc.expected)) {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerateStatements(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		testLogger := logger.NewLogger("debug")
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create transaction domain: %v", err)
+		}
+
+		domain, err := billing.NewBillingDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+			CreditIncomeAccountID:       incomeAccountID,
+			CreditStatementDueDays:      25,
+			CreditMinimumPaymentPercent: decimal.RequireFromString("0.02"),
+			CreditMinimumPaymentFloor:   decimal.NewFromInt(25),
+			CreditLateFee:               decimal.NewFromInt(25),
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create billing domain: %v", err)
+		}
+
+		// Account 300 spends 1000 on credit in August and pays nothing in September
+		start := time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC)
+		statements := []string{
+			"INSERT INTO accounts (id, account_type, credit_limit, created_at, updated_at) VALUES (300, 'CREDIT', 5000, $1, $1)",
+			"INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES (300, 1000, 'DEBIT', $1::timestamptz + interval '10 days')",
+		}
+		for _, stmt := range statements {
+			if _, err := testDB.DB.Exec(stmt, start); err != nil {
+				t.Fatalf("failed to seed data: %v", err)
+			}
+		}
+		_, err = testDB.DB.Exec("INSERT INTO interest_rates (account_type, annual_rate, effective_from) VALUES ('CREDIT', 0.24, '2026-01-01')")
+		if err != nil {
+			t.Fatalf("failed to create interest rate: %v", err)
+		}
+
+		// Close August and September twice, the second run must be a no-op
+		now := time.Date(2026, time.October, 1, 1, 0, 0, 0, time.UTC)
+		for run, expected := range []int{2, 0} {
+			generated, err := domain.GenerateStatements(ctx, now)
+			if err != nil {
+				t.Fatalf("failed to generate statements: %v", err)
+			}
+			if generated != expected {
+				t.Errorf("run %d: expected %d statements, got %d", run, expected, generated)
+			}
+		}
+
+		result, err := domain.ListStatements(ctx, 300)
+		if err != nil {
+			t.Fatalf("failed to list statements: %v", err)
+		}
+		if len(result) != 2 {
+			t.Fatalf("expected 2 statements, got %d", len(result))
+		}
+
+		august, september := result[1], result[0]
+		if !august.StatementBalance.Equal(decimal.NewFromInt(1000)) || !august.MinimumPayment.Equal(decimal.NewFromInt(25)) {
+			t.Errorf("unexpected august statement: balance=%s minimum=%s", august.StatementBalance, august.MinimumPayment)
+		}
+		if !august.DueDate.Equal(time.Date(2026, time.September, 25, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected august due date %s", august.DueDate)
+		}
+
+		// 1000 * 0.24 / 12 = 20 interest plus the late fee of 25
+		if !september.InterestCharged.Equal(decimal.NewFromInt(20)) || !september.LateFeeCharged.Equal(decimal.NewFromInt(25)) {
+			t.Errorf("unexpected september charges: interest=%s late fee=%s", september.InterestCharged, september.LateFeeCharged)
+		}
+		if !september.StatementBalance.Equal(decimal.NewFromInt(1045)) {
+			t.Errorf("expected september statement balance 1045, got %s", september.StatementBalance)
+		}
+
+		var balance, income decimal.Decimal
+		err = testDB.DB.QueryRow("SELECT get_account_balance(300), get_account_balance($1)", incomeAccountID).Scan(&balance, &income)
+		if err != nil {
+			t.Fatalf("failed to query balances: %v", err)
+		}
+		if !balance.Equal(decimal.NewFromInt(-1045)) {
+			t.Errorf("expected balance -1045, got %s", balance)
+		}
+		if !income.Equal(decimal.NewFromInt(45)) {
+			t.Errorf("expected credit income balance 45, got %s", income)
+		}
+	})
+}
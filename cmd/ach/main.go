@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bank/ach"
+	"bank/audit"
+	"bank/config"
+	"bank/entity"
+	dbPkg "bank/internal/db"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/store"
+	"bank/transaction"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const usage = `Usage: ach <command> [flags]
+
+Commands:
+  import    apply the entries of a NACHA file received from the sponsor bank
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "import":
+		os.Exit(importFile(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// importFile returns the exit code. Returned entries don't fail the import, they are written to
+// the returns file for the sponsor bank.
+func importFile(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "NACHA file to import (required)")
+	returns := fs.String("returns", "", "returns file to write when entries can't be applied, defaults to <file>.returns.ach")
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "file is required")
+		return 2
+	}
+
+	if *returns == "" {
+		*returns = strings.TrimSuffix(*file, filepath.Ext(*file)) + ".returns.ach"
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
+		return 2
+	}
+	defer in.Close()
+
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	sqlc := sqlc.New(db)
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		log.Fatal(ctx, "failed to create store: %v", err)
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, cfg, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create transaction domain: %v", err)
+	}
+
+	achDomain, err := ach.NewACHDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create ach domain: %v", err)
+	}
+
+	auditDomain, err := audit.NewAuditDomain(db, sqlc, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create audit domain: %v", err)
+	}
+
+	// The returns file is only created when there are returns
+	var buf bytes.Buffer
+	var result entity.ImportACHFileResult
+	err = auditDomain.Run(ctx, entity.CreateAuditRecord{
+		Actor:    audit.LocalUser(),
+		Action:   "ach import",
+		Resource: *file,
+	}, func(ctx context.Context) (any, error) {
+		result, err = achDomain.ImportFile(ctx, in, &buf)
+		return result, err
+	})
+	if err != nil {
+		log.Error(ctx, "failed to import %s: %v", *file, err)
+		return 1
+	}
+
+	if buf.Len() > 0 {
+		if err := os.WriteFile(*returns, buf.Bytes(), 0o644); err != nil {
+			log.Error(ctx, "failed to write returns file: %v", err)
+			return 1
+		}
+		log.Info(ctx, "wrote the returns of this run to %s", *returns)
+	}
+
+	fmt.Fprintf(os.Stderr, "posted %d, returned %d, skipped %d entries (%d already processed)\n", result.Posted, result.Returned, result.Skipped, result.AlreadyProcessed)
+	return 0
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bank/audit"
+	"bank/config"
+	"bank/entity"
+	dbPkg "bank/internal/db"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const usage = `Usage: audit <command> [flags]
+
+Commands:
+  verify    check that no record of the audit trail was changed or deleted
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "verify":
+		os.Exit(verify(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// verify walks the audit chain and returns the exit code: 0 when it is intact, 1 when it is
+// broken. The head it prints is the anchor of the next verification.
+func verify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	anchorFlag := fs.String("anchor", "", "head printed by an earlier verification, as seq:hash, to detect records deleted from the end of the chain")
+	fs.Parse(args)
+
+	var anchor *entity.AuditChainHead
+	if *anchorFlag != "" {
+		head, err := parseAnchor(*anchorFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid anchor: %v\n", err)
+			return 2
+		}
+		anchor = &head
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	auditDomain, err := audit.NewAuditDomain(db, sqlc.New(db), log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create audit domain: %v", err)
+	}
+
+	verification, err := auditDomain.Verify(ctx, anchor)
+	if err != nil {
+		log.Error(ctx, "failed to verify audit chain: %v", err)
+		return 1
+	}
+
+	if !verification.Intact() {
+		fmt.Printf("audit chain broken at record %d: %s\n", verification.BrokenSeq, verification.Problem)
+		fmt.Printf("%d records verified before it\n", verification.Records)
+		return 1
+	}
+
+	fmt.Printf("audit chain intact: %d records\n", verification.Records)
+	if verification.Records > 0 {
+		fmt.Printf("head %d:%s\n", verification.Head.Seq, verification.Head.Hash)
+	}
+	return 0
+}
+
+func parseAnchor(value string) (entity.AuditChainHead, error) {
+	seq, hash, ok := strings.Cut(value, ":")
+	if !ok || hash == "" {
+		return entity.AuditChainHead{}, errors.New("must be formatted as seq:hash")
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n == 0 {
+		return entity.AuditChainHead{}, fmt.Errorf("seq %q must be a positive number", seq)
+	}
+	return entity.AuditChainHead{Seq: n, Hash: hash}, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bank/account"
+	"bank/audit"
+	"bank/config"
+	"bank/entity"
+	dbPkg "bank/internal/db"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/store"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+const usage = `Usage: import <command> [flags]
+
+Commands:
+  accounts    load accounts with their opening balances from a CSV file
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "accounts":
+		os.Exit(importAccounts(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// importAccounts returns the exit code, 1 when any row was rejected
+func importAccounts(args []string) int {
+	fs := flag.NewFlagSet("accounts", flag.ExitOnError)
+	file := fs.String("file", "-", "CSV file with the columns account_id, initial_balance and optionally account_type and credit_limit, - reads stdin")
+	dryRun := fs.Bool("dry-run", false, "validate the file without loading it")
+	chunkSize := fs.Int("chunk-size", 1000, "rows per COPY")
+	report := fs.String("report", "", "write the rejected rows as CSV to this file instead of stderr")
+	fs.Parse(args)
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		log.Fatal(ctx, "failed to create store: %v", err)
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, cfg, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create account domain: %v", err)
+	}
+
+	auditDomain, err := audit.NewAuditDomain(db, sqlc.New(db), log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create audit domain: %v", err)
+	}
+
+	importAccounts := func(ctx context.Context) (entity.ImportAccountsResult, error) {
+		return accountDomain.ImportAccounts(ctx, in, entity.ImportAccountsOptions{
+			OpeningBalanceAccountID: cfg.OpeningBalanceAccountID,
+			ChunkSize:               *chunkSize,
+			DryRun:                  *dryRun,
+		})
+	}
+
+	// A dry run changes nothing, it isn't audited
+	var result entity.ImportAccountsResult
+	if *dryRun {
+		result, err = importAccounts(ctx)
+	} else {
+		err = auditDomain.Run(ctx, entity.CreateAuditRecord{
+			Actor:    audit.LocalUser(),
+			Action:   "import accounts",
+			Resource: *file,
+		}, func(ctx context.Context) (any, error) {
+			result, err = importAccounts(ctx)
+			return map[string]int{"imported": result.Imported, "rejected": len(result.Rejected)}, err
+		})
+	}
+	if err != nil {
+		log.Error(ctx, "failed to import accounts: %v", err)
+		return 1
+	}
+
+	if len(result.Rejected) > 0 {
+		if err := writeReport(*report, result.Rejected); err != nil {
+			log.Error(ctx, "failed to write report: %v", err)
+		}
+	}
+
+	mode := "imported"
+	if *dryRun {
+		mode = "would import"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d accounts, rejected %d rows\n", mode, result.Imported, len(result.Rejected))
+
+	if len(result.Rejected) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeReport writes one line per rejected row to path, or to stderr when path is empty
+func writeReport(path string, rejected []entity.RejectedAccountRow) (err error) {
+	var out io.Writer = os.Stderr
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"line", "account_id", "error"}); err != nil {
+		return err
+	}
+	for _, row := range rejected {
+		if err := w.Write([]string{strconv.Itoa(row.Line), row.AccountID, row.Reason}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
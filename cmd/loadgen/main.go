@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/http/handler/customer"
+	"bank/http/middleware"
+	dbPkg "bank/internal/db"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/response"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const usage = `Usage: loadgen <command> [flags]
+
+Commands:
+  transfers    send transfers between a set of accounts to POST /transactions and report latencies
+`
+
+// Outcomes of a request that has no error code in its body
+const (
+	outcomePendingApproval = "PENDING_APPROVAL"
+	outcomeTimeout         = "TIMEOUT"
+	outcomeTransport       = "TRANSPORT_ERROR"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "transfers":
+		os.Exit(transfers(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+type options struct {
+	URL            string          `json:"url"`
+	UserID         string          `json:"user_id"`
+	Accounts       int             `json:"accounts"`
+	FirstAccountID uint64          `json:"first_account_id"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+	Skew           float64         `json:"skew"`
+	Rate           int             `json:"rate"`
+	Workers        int             `json:"workers"`
+	Duration       time.Duration   `json:"duration"`
+	MaxAmount      decimal.Decimal `json:"max_amount"`
+	Timeout        time.Duration   `json:"timeout"`
+	Seed           uint64          `json:"seed"`
+}
+
+// transfers creates the accounts that are missing, sends transfers for the duration and writes
+// the result. Returns the exit code.
+func transfers(args []string) int {
+	fs := flag.NewFlagSet("transfers", flag.ExitOnError)
+	url := fs.String("url", "http://localhost:8080", "base URL of the API")
+	userID := fs.String("user", "loadgen", "user id sent in the "+middleware.UserIDHeader+" header")
+	accounts := fs.Int("accounts", 100, "number of accounts transfers are made between")
+	firstAccountID := fs.Uint64("first-account", 700000000, "id of the first account, the others follow it")
+	initialBalance := fs.String("initial-balance", "10000", "initial balance of the accounts created by the setup")
+	skew := fs.Float64("skew", 1.2, "Zipf exponent of the account picks, above 1; the lower ids are the hot accounts. 0 picks uniformly")
+	rate := fs.Int("rate", 100, "transfers per second, 0 sends as fast as the workers get answers")
+	workers := fs.Int("workers", 16, "number of concurrent requests")
+	duration := fs.Duration("duration", 30*time.Second, "how long transfers are sent")
+	maxAmount := fs.String("max-amount", "10", "upper bound of the random transfer amounts")
+	timeout := fs.Duration("timeout", 5*time.Second, "timeout of each request")
+	seed := fs.Uint64("seed", 0, "seed of the account and amount picks, random when 0")
+	setup := fs.Bool("setup", true, "create the accounts that don't exist before sending transfers")
+	lockInterval := fs.Duration("lock-interval", 250*time.Millisecond, "how often lock waits are sampled from pg_stat_activity")
+	label := fs.String("label", "", "name of the run in the result, e.g. the transfer engine")
+	out := fs.String("out", "", "result file, defaults to loadgen-<start time>.json")
+	fs.Parse(args)
+
+	opts := options{
+		URL:            strings.TrimRight(*url, "/"),
+		UserID:         *userID,
+		Accounts:       *accounts,
+		FirstAccountID: *firstAccountID,
+		Skew:           *skew,
+		Rate:           *rate,
+		Workers:        *workers,
+		Duration:       *duration,
+		Timeout:        *timeout,
+		Seed:           *seed,
+	}
+	if opts.Seed == 0 {
+		opts.Seed = rand.Uint64()
+	}
+
+	var err error
+	if opts.InitialBalance, err = decimal.NewFromString(*initialBalance); err != nil || !opts.InitialBalance.IsPositive() {
+		fmt.Fprintln(os.Stderr, "initial-balance must be a positive number")
+		return 2
+	}
+	if opts.MaxAmount, err = decimal.NewFromString(*maxAmount); err != nil || opts.MaxAmount.LessThan(decimal.New(1, -2)) {
+		fmt.Fprintln(os.Stderr, "max-amount must be at least 0.01")
+		return 2
+	}
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	queries := sqlc.New(db)
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: &http.Transport{MaxIdleConnsPerHost: opts.Workers},
+	}
+	gen := &generator{opts: opts, client: client}
+
+	if *setup {
+		created, err := gen.createAccounts(ctx, queries)
+		if err != nil {
+			log.Error(ctx, "failed to create accounts: %v", err)
+			return 1
+		}
+		log.Info(ctx, "created %d of %d accounts", created, opts.Accounts)
+	}
+
+	log.Info(ctx, "sending transfers between %d accounts for %s", opts.Accounts, opts.Duration)
+	result := gen.run(ctx, queries, *lockInterval, log)
+	result.Label = *label
+
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("loadgen-%s.json", result.StartedAt.UTC().Format("20060102T150405Z"))
+	}
+	if err := writeResult(path, result); err != nil {
+		log.Error(ctx, "failed to write result: %v", err)
+		return 1
+	}
+
+	result.print(os.Stdout)
+	log.Info(ctx, "wrote %s", path)
+	return 0
+}
+
+// MarshalJSON writes the durations like the flags take them
+func (o options) MarshalJSON() ([]byte, error) {
+	type plain options
+	return json.Marshal(struct {
+		plain
+		Duration string `json:"duration"`
+		Timeout  string `json:"timeout"`
+	}{plain(o), o.Duration.String(), o.Timeout.String()})
+}
+
+func (o options) validate() error {
+	switch {
+	case o.Accounts < 2:
+		return errors.New("accounts must be at least 2")
+	case o.FirstAccountID == 0:
+		return errors.New("first-account must be positive")
+	case o.Skew != 0 && o.Skew <= 1:
+		return errors.New("skew must be above 1, or 0 for uniform picks")
+	case o.Rate < 0:
+		return errors.New("rate can't be negative")
+	case o.Workers < 1:
+		return errors.New("workers must be at least 1")
+	case o.Duration <= 0:
+		return errors.New("duration must be positive")
+	}
+	return nil
+}
+
+func (o options) accountIDs() []int64 {
+	ids := make([]int64, 0, o.Accounts)
+	for i := range o.Accounts {
+		ids = append(ids, int64(o.FirstAccountID)+int64(i))
+	}
+	return ids
+}
+
+type generator struct {
+	opts   options
+	client *http.Client
+}
+
+// createAccounts creates the accounts that don't exist through POST /accounts and returns how
+// many it created
+func (g *generator) createAccounts(ctx context.Context, queries *sqlc.Queries) (int, error) {
+	existing, err := queries.ListExistingAccountIDs(ctx, g.opts.accountIDs())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list existing accounts: %w", err)
+	}
+
+	exists := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+
+	// The first failure stops the setup
+	setupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ids := make(chan int64)
+	errs := make(chan error, g.opts.Workers)
+	var wg sync.WaitGroup
+	for range g.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				status, code, err := g.post(setupCtx, "/accounts", customer.CreateAccountRequest{
+					AccountID:      uint64(id),
+					AccountType:    entity.AccountTypeSavings,
+					InitialBalance: g.opts.InitialBalance,
+				})
+				if err == nil && status != http.StatusCreated {
+					err = fmt.Errorf("status %d %s", status, code)
+				}
+				if err != nil {
+					errs <- fmt.Errorf("account %d: %w", id, err)
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	created := 0
+	go func() {
+		defer close(ids)
+		for _, id := range g.opts.accountIDs() {
+			if exists[id] {
+				continue
+			}
+			select {
+			case ids <- id:
+				created++
+			case <-setupCtx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return 0, err
+	}
+	return created, ctx.Err()
+}
+
+// transfer is a request the dispatcher hands to a worker
+type transfer struct {
+	body customer.CreateTransferFundsRequest
+}
+
+// run sends transfers for the duration, at the rate when there is one. A tick that finds every
+// worker busy is dropped, so a saturated server shows as dropped transfers instead of a lower
+// rate with flattering latencies.
+func (g *generator) run(ctx context.Context, queries *sqlc.Queries, lockInterval time.Duration, log *logger.Logger) result {
+	runCtx, cancel := context.WithTimeout(ctx, g.opts.Duration)
+	defer cancel()
+
+	res := result{StartedAt: time.Now(), Options: g.opts}
+	jobs := make(chan transfer, g.opts.Workers)
+	stats := make([]*workerStats, g.opts.Workers)
+
+	var wg sync.WaitGroup
+	for i := range g.opts.Workers {
+		stats[i] = newWorkerStats()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				// In-flight requests finish after the duration, only an interrupt cancels them
+				stats[i].record(g.send(ctx, job))
+			}
+		}()
+	}
+
+	sampler := newLockSampler(queries)
+	samplerDone := make(chan struct{})
+	go func() {
+		defer close(samplerDone)
+		sampler.run(runCtx, lockInterval, log)
+	}()
+
+	res.Sent, res.Dropped = g.dispatch(runCtx, jobs)
+	close(jobs)
+	wg.Wait()
+	<-samplerDone
+
+	res.Elapsed = time.Since(res.StartedAt)
+	res.summarize(stats)
+	res.LockWaits = sampler.summary()
+	return res
+}
+
+// dispatch queues transfers until ctx is done and returns how many were sent and dropped
+func (g *generator) dispatch(ctx context.Context, jobs chan<- transfer) (sent, dropped int) {
+	p := newPicker(g.opts)
+
+	if g.opts.Rate == 0 {
+		for {
+			select {
+			case jobs <- p.next():
+				sent++
+			case <-ctx.Done():
+				return sent, dropped
+			}
+		}
+	}
+
+	interval := time.Second / time.Duration(g.opts.Rate)
+	next := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return sent, dropped
+		}
+
+		select {
+		case jobs <- p.next():
+			sent++
+		default:
+			dropped++
+		}
+
+		// Ticks are scheduled from the start, a late one is caught up right away
+		next = next.Add(interval)
+		timer.Reset(time.Until(next))
+	}
+}
+
+// send posts the transfer and returns its latency and outcome, "" when it succeeded
+func (g *generator) send(ctx context.Context, job transfer) sample {
+	start := time.Now()
+	status, code, err := g.post(ctx, "/transactions", job.body)
+	s := sample{latency: time.Since(start)}
+
+	var timeoutErr interface{ Timeout() bool }
+	switch {
+	case errors.As(err, &timeoutErr) && timeoutErr.Timeout():
+		s.outcome = outcomeTimeout
+	case err != nil:
+		s.outcome = outcomeTransport
+	case status == http.StatusOK:
+	case status == http.StatusAccepted:
+		s.outcome = outcomePendingApproval
+	case code != "":
+		s.outcome = string(code)
+	default:
+		s.outcome = fmt.Sprintf("HTTP_%d", status)
+	}
+	return s
+}
+
+// post sends body as JSON and returns the status with the error code of the response, if any
+func (g *generator) post(ctx context.Context, path string, body any) (int, response.ErrorCode, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.opts.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.UserIDHeader, g.opts.UserID)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	var errResp response.ErrorResponse
+	if resp.StatusCode >= http.StatusBadRequest {
+		json.NewDecoder(resp.Body).Decode(&errResp)
+	}
+	// Drained bodies let the connection be reused
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, errResp.Error.Code, nil
+}
+
+// picker picks the accounts and amounts of the transfers, it is only used by the dispatcher
+type picker struct {
+	opts options
+	rng  *rand.Rand
+	zipf *rand.Zipf
+	// maxCents is the max amount in cents
+	maxCents int64
+}
+
+func newPicker(opts options) *picker {
+	rng := rand.New(rand.NewPCG(opts.Seed, 0))
+	p := &picker{opts: opts, rng: rng, maxCents: opts.MaxAmount.Shift(2).IntPart()}
+	if opts.Skew > 0 {
+		p.zipf = rand.NewZipf(rng, opts.Skew, 1, uint64(opts.Accounts-1))
+	}
+	return p
+}
+
+func (p *picker) account() uint64 {
+	if p.zipf == nil {
+		return p.opts.FirstAccountID + p.rng.Uint64N(uint64(p.opts.Accounts))
+	}
+	return p.opts.FirstAccountID + p.zipf.Uint64()
+}
+
+func (p *picker) next() transfer {
+	source, destination := p.account(), p.account()
+	for destination == source {
+		destination = p.account()
+	}
+	return transfer{body: customer.CreateTransferFundsRequest{
+		SourceAccountID:      source,
+		DestinationAccountID: destination,
+		Amount:               decimal.New(p.rng.Int64N(p.maxCents)+1, -2),
+	}}
+}
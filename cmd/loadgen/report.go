@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// sample is the latency and outcome of one transfer, the outcome is "" when it succeeded
+type sample struct {
+	latency time.Duration
+	outcome string
+}
+
+// workerStats holds the samples of one worker, merged once the run is over
+type workerStats struct {
+	latencies []time.Duration
+	outcomes  map[string]int
+}
+
+func newWorkerStats() *workerStats {
+	return &workerStats{outcomes: map[string]int{}}
+}
+
+func (w *workerStats) record(s sample) {
+	w.latencies = append(w.latencies, s.latency)
+	w.outcomes[s.outcome]++
+}
+
+// result is written as JSON, results of runs with the same options compare
+type result struct {
+	Label     string        `json:"label,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Options   options       `json:"options"`
+	Elapsed   time.Duration `json:"-"`
+	// ElapsedSeconds runs until the last in-flight transfer got its answer
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	// Sent transfers were handed to a worker, dropped ones found every worker busy
+	Sent       int     `json:"sent"`
+	Dropped    int     `json:"dropped"`
+	Completed  int     `json:"completed"`
+	Succeeded  int     `json:"succeeded"`
+	Throughput float64 `json:"throughput_per_second"`
+	// Latency covers every completed request, refused or not
+	Latency latency `json:"latency_ms"`
+	// Errors counts the refused and failed transfers by error code
+	Errors    map[string]int `json:"errors"`
+	LockWaits lockWaits      `json:"lock_waits"`
+}
+
+type latency struct {
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+func (r *result) summarize(stats []*workerStats) {
+	latencies := []time.Duration{}
+	r.Errors = map[string]int{}
+	for _, s := range stats {
+		latencies = append(latencies, s.latencies...)
+		for outcome, n := range s.outcomes {
+			if outcome == "" {
+				r.Succeeded += n
+				continue
+			}
+			r.Errors[outcome] += n
+		}
+	}
+
+	r.Completed = len(latencies)
+	r.ElapsedSeconds = r.Elapsed.Seconds()
+	if r.ElapsedSeconds > 0 {
+		r.Throughput = float64(r.Completed) / r.ElapsedSeconds
+	}
+	if len(latencies) == 0 {
+		return
+	}
+
+	slices.Sort(latencies)
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	r.Latency = latency{
+		P50:  milliseconds(percentile(latencies, 50)),
+		P95:  milliseconds(percentile(latencies, 95)),
+		P99:  milliseconds(percentile(latencies, 99)),
+		Max:  milliseconds(latencies[len(latencies)-1]),
+		Mean: milliseconds(total / time.Duration(len(latencies))),
+	}
+}
+
+// percentile returns the nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (r result) print(w io.Writer) {
+	fmt.Fprintf(w, "sent %d transfers in %s, %d dropped, %.1f/s\n", r.Sent, r.Elapsed.Round(time.Millisecond), r.Dropped, r.Throughput)
+	fmt.Fprintf(w, "latency ms: p50 %.1f  p95 %.1f  p99 %.1f  max %.1f\n", r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Latency.Max)
+	fmt.Fprintf(w, "succeeded: %d\n", r.Succeeded)
+	for _, code := range slices.Sorted(maps.Keys(r.Errors)) {
+		fmt.Fprintf(w, "  %s: %d\n", code, r.Errors[code])
+	}
+	fmt.Fprintf(w, "lock waits: %d of %d samples, max %d waiting, longest %.3fs\n",
+		r.LockWaits.SamplesWaiting, r.LockWaits.Samples, r.LockWaits.MaxWaiting, r.LockWaits.LongestSeconds)
+	for _, event := range slices.Sorted(maps.Keys(r.LockWaits.ByEvent)) {
+		fmt.Fprintf(w, "  %s: %d\n", event, r.LockWaits.ByEvent[event])
+	}
+}
+
+func writeResult(path string, r result) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// lockWaits sums up the backends of the database waiting on a lock, sampled from
+// pg_stat_activity. Waits shorter than the sampling interval are mostly missed, the counts
+// compare between runs with the same interval.
+type lockWaits struct {
+	Samples int `json:"samples"`
+	// SamplesWaiting had at least one backend waiting
+	SamplesWaiting int     `json:"samples_waiting"`
+	MaxWaiting     int     `json:"max_waiting"`
+	MeanWaiting    float64 `json:"mean_waiting"`
+	// LongestSeconds is the longest a waiting statement had been running when sampled
+	LongestSeconds float64 `json:"longest_seconds"`
+	// ByEvent counts the waiting backends of all samples by lock type, e.g. transactionid
+	ByEvent map[string]int `json:"by_event"`
+	Failed  int            `json:"failed_samples,omitempty"`
+}
+
+type lockSampler struct {
+	queries *sqlc.Queries
+	waits   lockWaits
+	total   int
+}
+
+func newLockSampler(queries *sqlc.Queries) *lockSampler {
+	return &lockSampler{queries: queries, waits: lockWaits{ByEvent: map[string]int{}}}
+}
+
+func (s *lockSampler) run(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		rows, err := s.queries.ListLockWaits(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.waits.Failed++
+				log.Warn(ctx, "failed to sample lock waits: %v", err)
+			}
+			continue
+		}
+		s.add(rows)
+	}
+}
+
+func (s *lockSampler) add(rows []sqlc.ListLockWaitsRow) {
+	waiting := 0
+	for _, row := range rows {
+		waiting += int(row.Waiting)
+		s.waits.ByEvent[row.WaitEvent] += int(row.Waiting)
+		s.waits.LongestSeconds = max(s.waits.LongestSeconds, row.LongestSeconds)
+	}
+
+	s.waits.Samples++
+	s.total += waiting
+	if waiting > 0 {
+		s.waits.SamplesWaiting++
+	}
+	s.waits.MaxWaiting = max(s.waits.MaxWaiting, waiting)
+}
+
+// summary is called once run returned
+func (s *lockSampler) summary() lockWaits {
+	if s.waits.Samples > 0 {
+		s.waits.MeanWaiting = float64(s.total) / float64(s.waits.Samples)
+	}
+	return s.waits
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bank/account"
+	"bank/config"
+	"bank/entity"
+	dbPkg "bank/internal/db"
+	"bank/internal/logger"
+	"bank/internal/statementfile"
+	"bank/store"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const usage = `Usage: statement <command> [flags]
+
+Commands:
+  export    write the MT940 or BAI2 statement files of a list of accounts for a day
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		os.Exit(export(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// export writes one file per account and returns the exit code. A failed account doesn't stop
+// the others, its partial file is removed.
+func export(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	accounts := fs.String("accounts", "", "comma separated account ids (required)")
+	date := fs.String("date", time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly), "statement day (UTC), formatted as YYYY-MM-DD")
+	format := fs.String("format", string(statementfile.FormatMT940), "file format: mt940 or bai2")
+	out := fs.String("out", ".", "output directory")
+	sender := fs.String("sender", "BANK", "BAI2 sender identification")
+	receiver := fs.String("receiver", "", "BAI2 receiver identification, defaults to the account id")
+	fs.Parse(args)
+
+	accountIDs, err := parseAccountIDs(*accounts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid accounts: %v\n", err)
+		return 2
+	}
+
+	day, err := time.Parse(time.DateOnly, *date)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "date must be formatted as YYYY-MM-DD")
+		return 2
+	}
+
+	fileFormat := statementfile.Format(*format)
+	if fileFormat != statementfile.FormatMT940 && fileFormat != statementfile.FormatBAI2 {
+		fmt.Fprintln(os.Stderr, "format must be mt940 or bai2")
+		return 2
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		log.Fatal(ctx, "failed to create store: %v", err)
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, cfg, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create account domain: %v", err)
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatal(ctx, "failed to create output directory: %v", err)
+	}
+
+	failed := 0
+	for _, accountID := range accountIDs {
+		path := filepath.Join(*out, fmt.Sprintf("%d-%s.%s", accountID, day.Format("20060102"), fileFormat.Extension()))
+		opts := statementfile.Options{
+			Reference:  fmt.Sprintf("%d-%s", accountID, day.Format("060102")),
+			SenderID:   *sender,
+			ReceiverID: *receiver,
+			CreatedAt:  time.Now(),
+			// Accounts are held in a single currency
+			Currency: entity.CurrencyCodeUSD,
+		}
+		if opts.ReceiverID == "" {
+			opts.ReceiverID = strconv.FormatUint(accountID, 10)
+		}
+
+		if err := writeStatement(ctx, accountDomain, path, fileFormat, opts, accountID, day); err != nil {
+			log.Error(ctx, "failed to export statement of account_id=%d: %v", accountID, err)
+			failed++
+			continue
+		}
+		log.Info(ctx, "wrote %s", path)
+	}
+
+	if failed > 0 {
+		log.Error(ctx, "%d of %d statements failed", failed, len(accountIDs))
+		return 1
+	}
+	return 0
+}
+
+func writeStatement(ctx context.Context, accountDomain *account.AccountDomain, path string, format statementfile.Format, opts statementfile.Options, accountID uint64, day time.Time) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	w, err := statementfile.NewWriter(f, format, opts)
+	if err != nil {
+		return err
+	}
+
+	err = accountDomain.StreamStatement(ctx, entity.GetAccountStatement{
+		AccountID: accountID,
+		From:      day,
+		To:        day.AddDate(0, 0, 1),
+	}, w)
+	if errors.Is(err, entity.ErrNoRows) {
+		return errors.New("account not found")
+	}
+	return err
+}
+
+func parseAccountIDs(s string) ([]uint64, error) {
+	ids := []uint64{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an account id", part)
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("at least one account id is required")
+	}
+	return ids, nil
+}
@@ -2,13 +2,28 @@ package main
 
 import (
 	"bank/account"
+	"bank/approval"
+	"bank/audit"
+	"bank/billing"
 	"bank/config"
+	"bank/http/handler/admin"
 	"bank/http/handler/customer"
+	"bank/http/middleware"
+	"bank/http/openapi"
+	"bank/interest"
 	dbPkg "bank/internal/db"
 	"bank/internal/db/sqlc"
+	"bank/internal/gateway"
 	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/pgnotify"
 	"bank/internal/server"
+	"bank/internal/tracing"
+	"bank/payment"
+	"bank/rpc"
+	"bank/store"
 	"bank/transaction"
+	"bank/webhook"
 	"context"
 	"database/sql"
 	"errors"
@@ -20,10 +35,14 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
 )
 
 const gracefulShutdownTimeout = time.Minute
 
+// accountTransactionsChannel is notified with the account id of every new transaction
+const accountTransactionsChannel = "account_transactions"
+
 func main() {
 	cfg, err := config.Get()
 	if err != nil {
@@ -33,59 +52,212 @@ func main() {
 	log := logger.NewLogger(cfg.LogLevel)
 	ctx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(ctx, tracingOptions(cfg))
+	if err != nil {
+		log.Fatal(ctx, "failed to set up tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error(ctx, "failed to flush traces: %v", err)
+		}
+	}()
+
 	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
 	if err != nil {
 		log.Fatal(ctx, "failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
+	listener, err := pgnotify.NewListener(dbPkg.DSN(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName), accountTransactionsChannel, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create listener: %v", err)
+	}
+	listenerCtx, stopListener := context.WithCancel(ctx)
+	defer stopListener()
+	go listener.Run(listenerCtx)
+
 	r := chi.NewRouter()
-	srv := server.NewServer(net.JoinHostPort("", cfg.Port), r)
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		if err := metrics.RegisterDB(db, cfg.DBName); err != nil {
+			log.Fatal(ctx, "failed to register database metrics: %v", err)
+		}
+		// Outermost, so the latency covers the other middlewares too
+		r.Use(middleware.Metrics)
+		metricsServer = metrics.NewServer(net.JoinHostPort("", cfg.MetricsPort), cfg.MetricsPath)
+	}
+	r.Use(middleware.Tracing)
+
+	gatewayNetworks, err := gateway.ParseNetworks(cfg.GatewayNetworks)
+	if err != nil {
+		log.Fatal(ctx, "failed to parse gateway networks: %v", err)
+	}
+	r.Use(middleware.TrustGateway(gatewayNetworks))
+
+	auditDomain, err := audit.NewAuditDomain(db, sqlc.New(db), log)
+	if err != nil {
+		log.Fatal(ctx, "failed to create audit domain: %v", err)
+	}
+	// The audit records carry the request id, RequestID runs again in NewServer and keeps it
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Audit(auditDomain, log))
+	srv := server.NewServer(net.JoinHostPort("", cfg.Port), r, log)
+	// Shutdown doesn't wait for event streams to end on their own, stopping the listener ends them
+	srv.RegisterOnShutdown(stopListener)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			rpc.GatewayInterceptor(gatewayNetworks),
+			rpc.AuditInterceptor(auditDomain, log),
+		),
+		grpc.ChainStreamInterceptor(rpc.GatewayStreamInterceptor(gatewayNetworks)),
+	)
 
-	if err := registerDependencies(r, db, cfg, log); err != nil {
+	if err := registerDependencies(ctx, r, grpcServer, db, listener, cfg, log); err != nil {
 		log.Fatal(ctx, "failed to register dependencies: %v", err)
 	}
 
+	grpcListener, err := net.Listen("tcp", net.JoinHostPort("", cfg.GRPCPort))
+	if err != nil {
+		log.Fatal(ctx, "failed to listen on grpc port: %v", err)
+	}
+
+	log.Info(ctx, "starting grpc server on port %s", cfg.GRPCPort)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Error(ctx, "serving grpc server: %v", err)
+		}
+	}()
+
+	if metricsServer != nil {
+		log.Info(ctx, "starting metrics server on port %s", cfg.MetricsPort)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error(ctx, "serving metrics server: %v", err)
+			}
+		}()
+	}
+
 	log.Info(ctx, "starting server on port %s", cfg.Port)
 
-	// Listen for OS interrupt signal
+	// Listen for OS interrupt signal, both servers drain within the same timeout
+	shutdownDone := make(chan struct{})
 	exitSig := make(chan os.Signal, 1)
 	signal.Notify(exitSig, os.Interrupt, syscall.SIGTERM)
 	go func() {
+		defer close(shutdownDone)
 		<-exitSig
 		log.Info(ctx, "received shutdown signal, gracefully shutting down...")
 		ctx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
 		defer cancel()
 
+		grpcStopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Error(ctx, "failed to shutdown server: %v", err)
 		}
+
+		// Scraped until the API is drained
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				log.Error(ctx, "failed to shutdown metrics server: %v", err)
+			}
+		}
+
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			log.Error(ctx, "failed to shutdown grpc server: %v", ctx.Err())
+			grpcServer.Stop()
+		}
 	}()
 
 	err = srv.ListenAndServe()
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Error(ctx, "serving http server: %v", err)
+		return
+	}
+	<-shutdownDone
+}
+
+func tracingOptions(cfg *config.Config) tracing.Options {
+	return tracing.Options{
+		ServiceName:  "bank-web",
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		File:         cfg.TracingFile,
+		SampleRatio:  cfg.TracingSampleRatio,
 	}
 }
 
-func registerDependencies(r *chi.Mux, db *sql.DB, cfg *config.Config, log *logger.Logger) error {
+func registerDependencies(ctx context.Context, r *chi.Mux, grpcServer *grpc.Server, db *sql.DB, listener *pgnotify.Listener, cfg *config.Config, log *logger.Logger) error {
 	sqlc := sqlc.New(db)
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		return err
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	if err := accountDomain.EnsureSystemAccounts(ctx); err != nil {
+		return err
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	approvalDomain, err := approval.NewApprovalDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	interestDomain, err := interest.NewInterestDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	billingDomain, err := billing.NewBillingDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	paymentDomain, err := payment.NewPaymentDomain(db, sqlc, transactionDomain, approvalDomain, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	webhookDomain, err := webhook.NewWebhookDomain(db, sqlc, cfg, log)
+	if err != nil {
+		return err
+	}
 
-	accountDomain, err := account.NewAccountDomain(db, sqlc, log)
+	customerHandler, err := customer.NewHandler(accountDomain, transactionDomain, approvalDomain, interestDomain, billingDomain, paymentDomain, listener, log)
 	if err != nil {
 		return err
 	}
 
-	transactionDomain, err := transaction.NewTransactionDomain(db, sqlc, log)
+	adminHandler, err := admin.NewHandler(approvalDomain, interestDomain, paymentDomain, webhookDomain, log)
 	if err != nil {
 		return err
 	}
 
-	customerHandler, err := customer.NewHandler(accountDomain, transactionDomain, log)
+	rpcServer, err := rpc.NewServer(accountDomain, transactionDomain, approvalDomain, log)
 	if err != nil {
 		return err
 	}
 
 	customerHandler.RegisterRoutes(r)
+	adminHandler.RegisterRoutes(r)
+	r.Get("/openapi.json", openapi.Handler())
+	rpcServer.Register(grpcServer)
 	return nil
 }
@@ -1,5 +1,289 @@
 package main
 
+import (
+	"bank/account"
+	"bank/approval"
+	"bank/audit"
+	"bank/billing"
+	"bank/config"
+	"bank/entity"
+	"bank/interest"
+	dbPkg "bank/internal/db"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/tracing"
+	"bank/internal/worker"
+	"bank/outbox"
+	"bank/payment"
+	"bank/store"
+	"bank/transaction"
+	"bank/webhook"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
 func main() {
+	cfg, err := config.Get()
+	if err != nil {
+		panic("failed to get config: " + err.Error())
+	}
+
+	log := logger.NewLogger(cfg.LogLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:  "bank-worker",
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		File:         cfg.TracingFile,
+		SampleRatio:  cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal(ctx, "failed to set up tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error(context.Background(), "failed to flush traces: %v", err)
+		}
+	}()
+
+	db, err := dbPkg.New(cfg.DBHost, cfg.DBPort, cfg.DBCustomer, cfg.DBPassword, cfg.DBName)
+	if err != nil {
+		log.Fatal(ctx, "failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	jobs, err := registerJobs(ctx, db, cfg, log)
+	if err != nil {
+		log.Fatal(ctx, "failed to register jobs: %v", err)
+	}
+
+	w, err := worker.NewWorker(log, jobs...)
+	if err != nil {
+		log.Fatal(ctx, "failed to create worker: %v", err)
+	}
+
+	if cfg.MetricsEnabled {
+		if err := metrics.RegisterDB(db, cfg.DBName); err != nil {
+			log.Fatal(ctx, "failed to register database metrics: %v", err)
+		}
+		if err := metrics.RegisterBalanceSnapshots(); err != nil {
+			log.Fatal(ctx, "failed to register balance snapshot metrics: %v", err)
+		}
+
+		metricsServer := metrics.NewServer(net.JoinHostPort("", cfg.WorkerMetricsPort), cfg.MetricsPath)
+		log.Info(ctx, "starting metrics server on port %s", cfg.WorkerMetricsPort)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error(ctx, "serving metrics server: %v", err)
+			}
+		}()
+		defer metricsServer.Close()
+	}
+
+	log.Info(ctx, "starting worker with %d jobs", len(jobs))
+	w.Start(ctx)
+	log.Info(context.Background(), "worker stopped")
+}
+
+func registerJobs(ctx context.Context, db *sql.DB, cfg *config.Config, log *logger.Logger) ([]worker.Job, error) {
+	sqlc := sqlc.New(db)
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		return nil, err
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := accountDomain.EnsureSystemAccounts(ctx); err != nil {
+		return nil, err
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalDomain, err := approval.NewApprovalDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	interestDomain, err := interest.NewInterestDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	billingDomain, err := billing.NewBillingDomain(db, sqlc, transactionDomain, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentDomain, err := payment.NewPaymentDomain(db, sqlc, transactionDomain, approvalDomain, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookDomain, err := webhook.NewWebhookDomain(db, sqlc, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	auditDomain, err := audit.NewAuditDomain(db, sqlc, log)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relayed events become webhook deliveries
+	relay, err := outbox.NewRelay(db, sqlc, webhookDomain, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return []worker.Job{
+		{
+			Name:     "expire_approval_requests",
+			Interval: cfg.ApprovalExpiryInterval,
+			Run: audited(auditDomain, "expire_approval_requests", func(ctx context.Context) (any, error) {
+				expired, err := approvalDomain.ExpireApprovalRequests(ctx)
+				if expired > 0 {
+					log.Info(ctx, "expired %d approval requests", expired)
+				}
+				return map[string]int{"expired": expired}, err
+			}),
+		},
+		{
+			// Accrual runs first so the last day of a month is accrued before it is capitalized
+			Name:     "interest",
+			Interval: cfg.InterestAccrualInterval,
+			Run: audited(auditDomain, "interest", func(ctx context.Context) (any, error) {
+				now := time.Now()
+				accrued, err := interestDomain.AccrueInterest(ctx, now)
+				if accrued > 0 {
+					log.Info(ctx, "recorded %d interest accruals", accrued)
+				}
+				if err != nil {
+					return map[string]int{"accrued": accrued}, err
+				}
+
+				capitalized, err := interestDomain.CapitalizeInterest(ctx, now)
+				if capitalized > 0 {
+					log.Info(ctx, "capitalized interest for %d account months", capitalized)
+				}
+				return map[string]int{"accrued": accrued, "capitalized": capitalized}, err
+			}),
+		},
+		{
+			Name:     "credit_statements",
+			Interval: cfg.CreditBillingInterval,
+			Run: audited(auditDomain, "credit_statements", func(ctx context.Context) (any, error) {
+				generated, err := billingDomain.GenerateStatements(ctx, time.Now())
+				if generated > 0 {
+					log.Info(ctx, "generated %d credit statements", generated)
+				}
+				return map[string]int{"generated": generated}, err
+			}),
+		},
+		{
+			// Every run closes a payment window, pending payments beyond the file limit go into
+			// further files of the same run
+			Name:     "payment_files",
+			Interval: cfg.PaymentFileInterval,
+			Run: audited(auditDomain, "payment_files", func(ctx context.Context) (any, error) {
+				files := []string{}
+				for {
+					file, err := paymentDomain.EmitPaymentFile(ctx, time.Now())
+					if errors.Is(err, entity.ErrNoRows) {
+						return map[string][]string{"files": files}, nil
+					}
+					if err != nil {
+						return map[string][]string{"files": files}, err
+					}
+					files = append(files, file.MessageID)
+
+					if err := writePaymentFile(cfg.PaymentFileDir, file); err != nil {
+						// The payments are already SENT, the stored file can be fetched from the admin API
+						return map[string][]string{"files": files}, fmt.Errorf("failed to write payment file %s: %w", file.MessageID, err)
+					}
+					log.Info(ctx, "wrote payment file %s with %d payments", file.MessageID, file.PaymentCount)
+				}
+			}),
+		},
+		{
+			Name:     "balance_snapshots",
+			Interval: cfg.BalanceSnapshotInterval,
+			Run: func(ctx context.Context) error {
+				snapshotted, err := accountDomain.SnapshotBalances(ctx)
+				if snapshotted > 0 {
+					log.Info(ctx, "snapshotted the balances of %d accounts", snapshotted)
+				}
+				if err != nil {
+					return err
+				}
+				metrics.BalanceSnapshotsTaken(snapshotted)
+				return nil
+			},
+		},
+		{
+			Name:     "outbox_relay",
+			Interval: cfg.OutboxRelayInterval,
+			Run:      relay.Run,
+		},
+		{
+			Name:     "webhook_deliveries",
+			Interval: cfg.WebhookDeliveryInterval,
+			Run: func(ctx context.Context) error {
+				for {
+					result, err := webhookDomain.DeliverWebhooks(ctx)
+					if err != nil {
+						return err
+					}
+
+					sent := result.Delivered + result.Retrying + result.Dead
+					if sent > 0 {
+						log.Info(ctx, "sent %d webhooks: %d delivered, %d retrying, %d dead", sent, result.Delivered, result.Retrying, result.Dead)
+					}
+					if sent < webhookDomain.BatchSize() {
+						return nil
+					}
+				}
+			},
+		},
+	}, nil
+}
+
+// audited records the runs of a job that changes the ledger in the audit trail, the job doesn't
+// run when they can't be recorded
+func audited(auditDomain *audit.AuditDomain, job string, run func(ctx context.Context) (any, error)) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return auditDomain.Run(ctx, entity.CreateAuditRecord{Actor: "worker", Action: "job " + job}, run)
+	}
+}
+
+// writePaymentFile writes the file under a temporary name first, so whatever picks up files from
+// the directory never sees a partial one
+func writePaymentFile(dir string, file entity.PaymentFile) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
 
+	path := filepath.Join(dir, file.MessageID+".xml")
+	if err := os.WriteFile(path+".tmp", []byte(file.Content), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
 }
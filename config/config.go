@@ -1,10 +1,17 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/shopspring/decimal"
+)
 
 type Config struct {
 	Env  string `envconfig:"ENV" default:"dev"`
 	Port string `envconfig:"PORT" default:"80"`
+	// GRPCPort serves the gRPC API next to the HTTP one
+	GRPCPort string `envconfig:"GRPC_PORT" default:"9090"`
 
 	DBHost     string `envconfig:"DB_HOST" default:"localhost"`
 	DBPort     string `envconfig:"DB_PORT" default:"5432"`
@@ -13,6 +20,86 @@ type Config struct {
 	DBName     string `envconfig:"DB_NAME" default:"postgres"`
 
 	LogLevel string `envconfig:"LOG_LEVEL" default:"debug"`
+
+	// The caller identity (X-User-ID, x-user-id) is only trusted on connections from the gateway
+	// in front of the service, addresses or CIDRs separated by commas
+	GatewayNetworks []string `envconfig:"GATEWAY_NETWORKS" default:"127.0.0.1,::1"`
+
+	// Prometheus metrics are served on their own port, apart from the API. The worker has its
+	// own port so both can run on one host.
+	MetricsEnabled    bool   `envconfig:"METRICS_ENABLED" default:"true"`
+	MetricsPath       string `envconfig:"METRICS_PATH" default:"/metrics"`
+	MetricsPort       string `envconfig:"METRICS_PORT" default:"9100"`
+	WorkerMetricsPort string `envconfig:"WORKER_METRICS_PORT" default:"9101"`
+
+	// Traces are sent to an OTLP collector over HTTP ("otlp"), written to stdout ("stdout") or
+	// appended to TracingFile ("file"), empty disables tracing
+	TracingExporter     string  `envconfig:"TRACING_EXPORTER" default:""`
+	TracingOTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT" default:"http://localhost:4318"`
+	TracingFile         string  `envconfig:"TRACING_FILE" default:"traces.json"`
+	TracingSampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+
+	// Transfers run in the transfer_funds stored procedure ("procedure") or in Go ("go")
+	TransferEngine string `envconfig:"TRANSFER_ENGINE" default:"procedure"`
+
+	// Balances are snapshotted so get_account_balance only sums the transactions since
+	BalanceSnapshotInterval time.Duration `envconfig:"BALANCE_SNAPSHOT_INTERVAL" default:"1h"`
+
+	// Transfers above the threshold need a second person's approval, zero disables it
+	ApprovalTransferThreshold decimal.Decimal `envconfig:"APPROVAL_TRANSFER_THRESHOLD" default:"0"`
+	ApprovalTTL               time.Duration   `envconfig:"APPROVAL_TTL" default:"24h"`
+	ApprovalExpiryInterval    time.Duration   `envconfig:"APPROVAL_EXPIRY_INTERVAL" default:"1m"`
+
+	// System account that balances admin adjustments. The system accounts are created when the
+	// applications start, their ids are reserved
+	AdjustmentAccountID uint64 `envconfig:"ADJUSTMENT_ACCOUNT_ID" default:"900000001"`
+
+	// Savings interest is paid out of the interest expense system account
+	InterestExpenseAccountID    uint64        `envconfig:"INTEREST_EXPENSE_ACCOUNT_ID" default:"900000002"`
+	InterestAccrualInterval     time.Duration `envconfig:"INTEREST_ACCRUAL_INTERVAL" default:"1h"`
+	InterestAccrualLookbackDays int           `envconfig:"INTEREST_ACCRUAL_LOOKBACK_DAYS" default:"7"`
+
+	// Credit interest and late fees are paid into the credit income system account
+	CreditIncomeAccountID       uint64          `envconfig:"CREDIT_INCOME_ACCOUNT_ID" default:"900000003"`
+	CreditStatementDueDays      int             `envconfig:"CREDIT_STATEMENT_DUE_DAYS" default:"25"`
+	CreditMinimumPaymentPercent decimal.Decimal `envconfig:"CREDIT_MINIMUM_PAYMENT_PERCENT" default:"0.02"`
+	CreditMinimumPaymentFloor   decimal.Decimal `envconfig:"CREDIT_MINIMUM_PAYMENT_FLOOR" default:"25"`
+	CreditLateFee               decimal.Decimal `envconfig:"CREDIT_LATE_FEE" default:"25"`
+	CreditBillingInterval       time.Duration   `envconfig:"CREDIT_BILLING_INTERVAL" default:"1h"`
+
+	// Opening balances of bulk imported accounts are balanced against this system account
+	OpeningBalanceAccountID uint64 `envconfig:"OPENING_BALANCE_ACCOUNT_ID" default:"900000004"`
+
+	// Inbound ACH credits are paid out of, and debits into, the ACH settlement system account
+	ACHSettlementAccountID uint64 `envconfig:"ACH_SETTLEMENT_ACCOUNT_ID" default:"900000005"`
+
+	// External payments move into the clearing system account when requested and are sent to the
+	// clearing partner in pain.001 files, debiting the bank's account with the partner
+	ClearingAccountID      uint64        `envconfig:"CLEARING_ACCOUNT_ID" default:"900000006"`
+	PaymentDebtorName      string        `envconfig:"PAYMENT_DEBTOR_NAME" default:"BANK"`
+	PaymentDebtorAccount   string        `envconfig:"PAYMENT_DEBTOR_ACCOUNT" default:"900000006"`
+	PaymentDebtorAgentBIC  string        `envconfig:"PAYMENT_DEBTOR_AGENT_BIC" default:"BANKUS33XXX"`
+	PaymentFileInterval    time.Duration `envconfig:"PAYMENT_FILE_INTERVAL" default:"1h"`
+	PaymentFileMaxPayments int           `envconfig:"PAYMENT_FILE_MAX_PAYMENTS" default:"1000"`
+	PaymentFileDir         string        `envconfig:"PAYMENT_FILE_DIR" default:"payment-files"`
+
+	// Domain events are relayed from the outbox table to the publisher in batches. Failed events
+	// are retried with exponential backoff from OutboxBackoffBase up to OutboxBackoffMax, and dead
+	// after OutboxMaxAttempts
+	OutboxRelayInterval time.Duration `envconfig:"OUTBOX_RELAY_INTERVAL" default:"1s"`
+	OutboxBatchSize     int           `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxMaxAttempts   int           `envconfig:"OUTBOX_MAX_ATTEMPTS" default:"10"`
+	OutboxBackoffBase   time.Duration `envconfig:"OUTBOX_BACKOFF_BASE" default:"1s"`
+	OutboxBackoffMax    time.Duration `envconfig:"OUTBOX_BACKOFF_MAX" default:"10m"`
+
+	// Webhook deliveries are retried with exponential backoff from WebhookBackoffBase up to
+	// WebhookBackoffMax, and dead-lettered after WebhookMaxAttempts
+	WebhookDeliveryInterval time.Duration `envconfig:"WEBHOOK_DELIVERY_INTERVAL" default:"5s"`
+	WebhookBatchSize        int           `envconfig:"WEBHOOK_BATCH_SIZE" default:"20"`
+	WebhookTimeout          time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookMaxAttempts      int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookBackoffBase      time.Duration `envconfig:"WEBHOOK_BACKOFF_BASE" default:"30s"`
+	WebhookBackoffMax       time.Duration `envconfig:"WEBHOOK_BACKOFF_MAX" default:"6h"`
 }
 
 func Get() (*Config, error) {
@@ -24,3 +111,15 @@ func Get() (*Config, error) {
 
 	return c, nil
 }
+
+// SystemAccountIDs are the ids of the bank's own accounts, no customer account may take them
+func (c *Config) SystemAccountIDs() []uint64 {
+	return []uint64{
+		c.AdjustmentAccountID,
+		c.InterestExpenseAccountID,
+		c.CreditIncomeAccountID,
+		c.OpeningBalanceAccountID,
+		c.ACHSettlementAccountID,
+		c.ClearingAccountID,
+	}
+}
@@ -12,6 +12,8 @@ type AccountType string
 const (
 	AccountTypeSavings AccountType = "SAVINGS"
 	AccountTypeCredit  AccountType = "CREDIT"
+	// AccountTypeSystem marks internal accounts (adjustments, interest expense) that may go negative
+	AccountTypeSystem AccountType = "SYSTEM"
 )
 
 type CurrencyCode string
@@ -23,11 +25,16 @@ const (
 
 type Account struct {
 	ModelWithUpdatedAt
+	AccountType AccountType
+	CreditLimit decimal.Decimal
 }
 
 type CreateAccount struct {
 	AccountID      uint64
+	AccountType    AccountType
 	InitialBalance decimal.Decimal
+	// CreditLimit is how far below zero a CREDIT account may go
+	CreditLimit decimal.Decimal
 }
 
 func (a *CreateAccount) Validate() error {
@@ -41,8 +48,30 @@ func (a *CreateAccount) Validate() error {
 	if a.InitialBalance.LessThan(decimal.Zero) {
 		msgs = append(msgs, "initial balance must be greater than 0")
 	}
+	if a.AccountType != AccountTypeSavings && a.AccountType != AccountTypeCredit {
+		msgs = append(msgs, "account type must be SAVINGS or CREDIT")
+	}
+	if a.CreditLimit.IsNegative() {
+		msgs = append(msgs, "credit limit must not be negative")
+	}
+	if !a.CreditLimit.IsZero() && a.AccountType != AccountTypeCredit {
+		msgs = append(msgs, "credit limit is only allowed on CREDIT accounts")
+	}
 	if len(msgs) > 0 {
 		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
 	}
 	return nil
 }
+
+// AccountUpdates is what was committed on an account after a transaction, read from one snapshot
+type AccountUpdates struct {
+	AccountID uint64
+	// Transactions are in id order, each with the balance right after it
+	Transactions []StatementEntry
+	Balance      decimal.Decimal
+	// LastTransactionID is the last transaction the updates cover, the latest of the account
+	// unless More is set, 0 without any. Balance is the balance right after it.
+	LastTransactionID uint64
+	// More tells that later transactions were left out, they are read on from LastTransactionID
+	More bool
+}
@@ -0,0 +1,28 @@
+package entity
+
+type ACHEntryStatus string
+
+const (
+	ACHEntryStatusPosted   ACHEntryStatus = "POSTED"
+	ACHEntryStatusReturned ACHEntryStatus = "RETURNED"
+	// ACHEntryStatusSkipped is a prenote or an entry that moves no money
+	ACHEntryStatusSkipped ACHEntryStatus = "SKIPPED"
+)
+
+// ACH return reason codes of the entries we can't apply
+const (
+	ACHReturnInsufficientFunds     = "R01"
+	ACHReturnNoAccount             = "R03"
+	ACHReturnInvalidAccountNumber  = "R04"
+	ACHReturnNonTransactionAccount = "R20"
+)
+
+type ImportACHFileResult struct {
+	FileID   uint64
+	Posted   int
+	Returned int
+	Skipped  int
+	// AlreadyProcessed counts the entries applied by an earlier import of the same file, they
+	// are included in the counts above with their first outcome
+	AlreadyProcessed int
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ApprovalKind string
+
+const (
+	ApprovalKindTransfer   ApprovalKind = "TRANSFER"
+	ApprovalKindAdjustment ApprovalKind = "ADJUSTMENT"
+	// ApprovalKindPayment moves the amount of an external payment to the clearing account
+	ApprovalKindPayment ApprovalKind = "PAYMENT"
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "PENDING_APPROVAL"
+	ApprovalStatusExecuted ApprovalStatus = "EXECUTED"
+	ApprovalStatusFailed   ApprovalStatus = "FAILED"
+	ApprovalStatusRejected ApprovalStatus = "REJECTED"
+	ApprovalStatusExpired  ApprovalStatus = "EXPIRED"
+)
+
+type ApprovalEventType string
+
+const (
+	ApprovalEventRequested ApprovalEventType = "REQUESTED"
+	ApprovalEventApproved  ApprovalEventType = "APPROVED"
+	ApprovalEventExecuted  ApprovalEventType = "EXECUTED"
+	ApprovalEventFailed    ApprovalEventType = "EXECUTION_FAILED"
+	ApprovalEventRejected  ApprovalEventType = "REJECTED"
+	ApprovalEventExpired   ApprovalEventType = "EXPIRED"
+)
+
+// ApprovalActorSystem is recorded as the actor of steps taken by the worker
+const ApprovalActorSystem = "system"
+
+type ApprovalRequest struct {
+	ModelWithUpdatedAt
+	Kind                 ApprovalKind
+	Status               ApprovalStatus
+	SourceAccountID      uint64
+	DestinationAccountID uint64
+	Amount               decimal.Decimal
+	RequestedBy          string
+	DecidedBy            string
+	Reason               string
+	TransferID           uint64
+	ExpiresAt            time.Time
+	Events               []ApprovalEvent
+}
+
+type ApprovalEvent struct {
+	Model
+	ApprovalRequestID uint64
+	EventType         ApprovalEventType
+	Actor             string
+	Note              string
+}
+
+type CreateTransferApproval struct {
+	CreateTransferFundsParams
+	RequestedBy string
+}
+
+func (a *CreateTransferApproval) Validate() error {
+	msgs := []string{}
+	if a.SourceAccountID == 0 {
+		msgs = append(msgs, "source account id is required")
+	}
+	if a.DestinationAccountID == 0 {
+		msgs = append(msgs, "destination account id is required")
+	}
+	if !a.Amount.IsPositive() {
+		msgs = append(msgs, "amount must be greater than 0")
+	}
+	if a.RequestedBy == "" {
+		msgs = append(msgs, "requester is required")
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+type CreateAdjustment struct {
+	AccountID   uint64
+	Amount      decimal.Decimal
+	TrxType     TrxType
+	Reason      string
+	RequestedBy string
+}
+
+func (a *CreateAdjustment) Validate() error {
+	msgs := []string{}
+	if a.AccountID == 0 {
+		msgs = append(msgs, "account id is required")
+	}
+	if !a.Amount.IsPositive() {
+		msgs = append(msgs, "amount must be greater than 0")
+	}
+	if a.TrxType != TrxTypeCredit && a.TrxType != TrxTypeDebit {
+		msgs = append(msgs, "trx type must be CREDIT or DEBIT")
+	}
+	if a.Reason == "" {
+		msgs = append(msgs, "reason is required")
+	}
+	if a.RequestedBy == "" {
+		msgs = append(msgs, "requester is required")
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+type DecideApproval struct {
+	ApprovalRequestID uint64
+	Actor             string
+	Note              string
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+type AuditOutcome string
+
+const (
+	// AuditOutcomeReceived is a call recorded before it runs, its outcome follows in another record
+	AuditOutcomeReceived  AuditOutcome = "RECEIVED"
+	AuditOutcomeSucceeded AuditOutcome = "SUCCEEDED"
+	// AuditOutcomeRejected is a call refused for the caller's mistake, a 4xx status
+	AuditOutcomeRejected AuditOutcome = "REJECTED"
+	// AuditOutcomeFailed is a call that failed on our side, a 5xx status
+	AuditOutcomeFailed AuditOutcome = "FAILED"
+)
+
+// CreateAuditRecord is a mutating call of one of the APIs, or a run of a worker job or a command
+type CreateAuditRecord struct {
+	OccurredAt time.Time
+	Actor      string
+	// Action is the HTTP method and route pattern, e.g. "POST /transactions", the gRPC method, or
+	// the job or command, e.g. "job interest"
+	Action    string
+	Resource  string
+	RequestID string
+	IP        string
+	Payload   []byte
+	Status    string
+	Outcome   AuditOutcome
+}
+
+// AuditRecord is a link of the audit chain: Hash covers PrevHash, the hash of the record before
+// it, and every other field
+type AuditRecord struct {
+	CreateAuditRecord
+	Seq      uint64
+	PrevHash string
+	Hash     string
+}
+
+// AuditChainHead is the last record of the chain. Kept outside the database, it lets a later
+// verification tell that no record was deleted from the end of the chain.
+type AuditChainHead struct {
+	Seq  uint64
+	Hash string
+}
+
+// AuditVerification is the result of checking the audit chain
+type AuditVerification struct {
+	Records uint64
+	Head    AuditChainHead
+	// BrokenSeq is the first record that doesn't chain, 0 when the chain is intact
+	BrokenSeq uint64
+	Problem   string
+}
+
+func (v AuditVerification) Intact() bool {
+	return v.BrokenSeq == 0
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreditStatement is the bill of a CREDIT account for one calendar month
+type CreditStatement struct {
+	Model
+	AccountID uint64
+	// PeriodStart is inclusive, PeriodEnd is exclusive and is also the statement date
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+	ClosingBalance   decimal.Decimal
+	StatementBalance decimal.Decimal
+	MinimumPayment   decimal.Decimal
+	DueDate          time.Time
+	InterestCharged  decimal.Decimal
+	LateFeeCharged   decimal.Decimal
+}
@@ -7,4 +7,7 @@ var (
 	ErrValidation        = errors.New("validation error")
 	ErrNoRows            = errors.New("no rows found")
 	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInvalidState      = errors.New("invalid state")
+	ErrExpired           = errors.New("expired")
 )
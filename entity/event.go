@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type EventType string
+
+const (
+	EventTypeAccountCreated    EventType = "AccountCreated"
+	EventTypeTransferCompleted EventType = "TransferCompleted"
+	EventTypeTransferFailed    EventType = "TransferFailed"
+)
+
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventTypeAccountCreated, EventTypeTransferCompleted, EventTypeTransferFailed:
+		return true
+	}
+	return false
+}
+
+// EventPayload is the typed body of a domain event
+type EventPayload interface {
+	EventType() EventType
+	// AccountIDs are the accounts the event is ordered by: events sharing an account are
+	// published in the order they were written
+	AccountIDs() []uint64
+}
+
+// Event is a domain event as stored in the outbox and handed to publishers. ID increases in
+// write order and identifies the event, consumers use it to drop redeliveries.
+type Event struct {
+	ID         uint64
+	Type       EventType
+	AccountIDs []uint64
+	Payload    json.RawMessage
+	CreatedAt  time.Time
+}
+
+type AccountCreated struct {
+	AccountID      uint64          `json:"account_id"`
+	AccountType    AccountType     `json:"account_type"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+	CreditLimit    decimal.Decimal `json:"credit_limit"`
+}
+
+func (e AccountCreated) EventType() EventType { return EventTypeAccountCreated }
+func (e AccountCreated) AccountIDs() []uint64 { return []uint64{e.AccountID} }
+
+type TransferCompleted struct {
+	TransferID           uint64          `json:"transfer_id"`
+	SourceAccountID      uint64          `json:"source_account_id"`
+	DestinationAccountID uint64          `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+}
+
+func (e TransferCompleted) EventType() EventType { return EventTypeTransferCompleted }
+func (e TransferCompleted) AccountIDs() []uint64 {
+	return []uint64{e.SourceAccountID, e.DestinationAccountID}
+}
+
+// TransferFailed is a transfer refused for a business reason, nothing was posted
+type TransferFailed struct {
+	SourceAccountID      uint64          `json:"source_account_id"`
+	DestinationAccountID uint64          `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Reason               string          `json:"reason"`
+}
+
+func (e TransferFailed) EventType() EventType { return EventTypeTransferFailed }
+func (e TransferFailed) AccountIDs() []uint64 {
+	return []uint64{e.SourceAccountID, e.DestinationAccountID}
+}
+
+type RelayOutboxResult struct {
+	Published int
+	Failed    int
+	// Deferred counts events held back because an earlier event of one of their accounts failed
+	Deferred int
+	// Dead counts the failed events that used their last attempt
+	Dead int
+}
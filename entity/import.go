@@ -0,0 +1,23 @@
+package entity
+
+type ImportAccountsOptions struct {
+	// OpeningBalanceAccountID is the system account that balances the opening entries
+	OpeningBalanceAccountID uint64
+	// ChunkSize is the number of rows loaded per COPY
+	ChunkSize int
+	// DryRun validates every row, including against existing accounts, without loading anything
+	DryRun bool
+}
+
+// RejectedAccountRow is a CSV row that was not imported
+type RejectedAccountRow struct {
+	Line      int
+	AccountID string
+	Reason    string
+}
+
+type ImportAccountsResult struct {
+	// Imported is the number of accounts loaded, or that would be loaded on a dry run
+	Imported int
+	Rejected []RejectedAccountRow
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type InterestRate struct {
+	Model
+	AccountType   AccountType
+	AnnualRate    decimal.Decimal
+	EffectiveFrom time.Time
+}
+
+type CreateInterestRate struct {
+	AccountType   AccountType
+	AnnualRate    decimal.Decimal
+	EffectiveFrom time.Time
+}
+
+func (r *CreateInterestRate) Validate() error {
+	msgs := []string{}
+	if r.AccountType != AccountTypeSavings && r.AccountType != AccountTypeCredit {
+		msgs = append(msgs, "account type must be SAVINGS or CREDIT")
+	}
+	if r.AnnualRate.IsNegative() || r.AnnualRate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		msgs = append(msgs, "annual rate must be between 0 and 1")
+	}
+	if r.EffectiveFrom.IsZero() {
+		msgs = append(msgs, "effective from is required")
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+type AccruedInterest struct {
+	AccountID uint64
+	Amount    decimal.Decimal
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type ExternalPaymentStatus string
+
+const (
+	// ExternalPaymentStatusPendingApproval is above the approval threshold, nothing is debited until
+	// a second person approves it
+	ExternalPaymentStatusPendingApproval ExternalPaymentStatus = "PENDING_APPROVAL"
+	// ExternalPaymentStatusPending is debited from the customer and waits for the next payment file
+	ExternalPaymentStatusPending ExternalPaymentStatus = "PENDING"
+	// ExternalPaymentStatusSent is in a payment file sent to the clearing partner
+	ExternalPaymentStatusSent     ExternalPaymentStatus = "SENT"
+	ExternalPaymentStatusAccepted ExternalPaymentStatus = "ACCEPTED"
+	// ExternalPaymentStatusRejected is refunded to the customer, or was never approved
+	ExternalPaymentStatusRejected ExternalPaymentStatus = "REJECTED"
+)
+
+type ExternalPayment struct {
+	ModelWithUpdatedAt
+	AccountID             uint64
+	Amount                decimal.Decimal
+	CreditorName          string
+	CreditorAccount       string
+	CreditorAgent         string
+	RemittanceInformation string
+	Status                ExternalPaymentStatus
+	StatusReason          string
+	TransferID            uint64
+	ReversalTransferID    uint64
+	PaymentFileID         uint64
+	ApprovalRequestID     uint64
+}
+
+// EndToEndID identifies the payment in payment files and status reports
+func (p ExternalPayment) EndToEndID() string {
+	return fmt.Sprintf("PMT-%d", p.ID)
+}
+
+type CreateExternalPayment struct {
+	AccountID             uint64
+	Amount                decimal.Decimal
+	CreditorName          string
+	CreditorAccount       string
+	CreditorAgent         string
+	RemittanceInformation string
+	// RequestedBy is the caller, required for payments that need approval
+	RequestedBy string
+}
+
+func (p *CreateExternalPayment) Validate() error {
+	msgs := []string{}
+	if p.AccountID == 0 {
+		msgs = append(msgs, "account id is required")
+	}
+	if !p.Amount.IsPositive() {
+		msgs = append(msgs, "amount must be greater than 0")
+	}
+	if p.Amount.Exponent() < -2 {
+		msgs = append(msgs, "amount must have at most 2 decimal places")
+	}
+	if p.CreditorName == "" {
+		msgs = append(msgs, "creditor name is required")
+	}
+	if p.CreditorAccount == "" {
+		msgs = append(msgs, "creditor account is required")
+	}
+	if p.CreditorAgent == "" {
+		msgs = append(msgs, "creditor agent is required")
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+type PaymentFile struct {
+	Model
+	MessageID    string
+	PaymentCount int
+	ControlSum   decimal.Decimal
+	Content      string
+}
+
+type ApplyPaymentStatusReportResult struct {
+	PaymentFileID uint64
+	Accepted      int
+	Rejected      int
+	// Unchanged counts payments the report leaves pending and payments already accepted or
+	// rejected by an earlier report
+	Unchanged int
+}
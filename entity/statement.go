@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GetAccountStatement struct {
+	AccountID uint64
+	// From is inclusive and To is exclusive
+	From time.Time
+	To   time.Time
+}
+
+func (s *GetAccountStatement) Validate() error {
+	msgs := []string{}
+	if s.AccountID == 0 {
+		msgs = append(msgs, "account id is required")
+	}
+	if s.From.IsZero() {
+		msgs = append(msgs, "from is required")
+	}
+	if s.To.IsZero() {
+		msgs = append(msgs, "to is required")
+	}
+	if !s.From.IsZero() && !s.To.IsZero() && !s.To.After(s.From) {
+		msgs = append(msgs, "to must be after from")
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// AccountStatement holds the balances of an account at both ends of a period
+type AccountStatement struct {
+	GetAccountStatement
+	OpeningBalance decimal.Decimal
+	ClosingBalance decimal.Decimal
+}
+
+// StatementEntry is a transaction of a statement with the balance right after it
+type StatementEntry struct {
+	Transaction
+	// CounterpartyAccountID is the other account of the transfer, if any
+	CounterpartyAccountID sql.NullInt64
+	Balance               decimal.Decimal
+}
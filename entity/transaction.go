@@ -28,7 +28,6 @@ type CreateTransferFundsParams struct {
 }
 
 type CreateTransferFundsResult struct {
-	TransferID   uint64
-	Success      bool
-	ErrorMessage string
+	TransferID uint64
+	Success    bool
 }
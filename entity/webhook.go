@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending waits for its first attempt or a retry
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "DELIVERED"
+	// WebhookDeliveryStatusDead failed every attempt, it is only sent again when redelivered
+	WebhookDeliveryStatusDead WebhookDeliveryStatus = "DEAD"
+)
+
+// webhookSecretMinLength keeps signing secrets from being guessable
+const webhookSecretMinLength = 16
+
+// WebhookSubscription pushes the events of its types to URL. The signing secret is write only.
+type WebhookSubscription struct {
+	ModelWithUpdatedAt
+	URL        string
+	EventTypes []EventType
+	Active     bool
+}
+
+type CreateWebhookSubscription struct {
+	URL        string
+	EventTypes []EventType
+	Secret     string
+}
+
+func (s *CreateWebhookSubscription) Validate() error {
+	msgs := validateWebhookTarget(s.URL, s.EventTypes)
+	if len(s.Secret) < webhookSecretMinLength {
+		msgs = append(msgs, fmt.Sprintf("secret must be at least %d characters", webhookSecretMinLength))
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// UpdateWebhookSubscription replaces the subscription, an empty Secret keeps the current one
+type UpdateWebhookSubscription struct {
+	ID         uint64
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	Active     bool
+}
+
+func (s *UpdateWebhookSubscription) Validate() error {
+	msgs := validateWebhookTarget(s.URL, s.EventTypes)
+	if s.Secret != "" && len(s.Secret) < webhookSecretMinLength {
+		msgs = append(msgs, fmt.Sprintf("secret must be at least %d characters", webhookSecretMinLength))
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+func validateWebhookTarget(rawURL string, eventTypes []EventType) []string {
+	msgs := []string{}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		msgs = append(msgs, "url must be an absolute http or https url")
+	}
+
+	if len(eventTypes) == 0 {
+		msgs = append(msgs, "at least one event type is required")
+	}
+	seen := map[EventType]bool{}
+	for _, t := range eventTypes {
+		if !t.IsValid() {
+			msgs = append(msgs, fmt.Sprintf("unknown event type %q", t))
+		} else if seen[t] {
+			msgs = append(msgs, fmt.Sprintf("duplicate event type %q", t))
+		}
+		seen[t] = true
+	}
+	return msgs
+}
+
+// WebhookDelivery is one event for one subscription. Payload is the request body, the same on
+// every attempt.
+type WebhookDelivery struct {
+	ModelWithUpdatedAt
+	SubscriptionID     uint64
+	EventID            uint64
+	EventType          EventType
+	Payload            json.RawMessage
+	Status             WebhookDeliveryStatus
+	Attempts           int
+	NextAttemptAt      time.Time
+	LastResponseStatus int
+	LastError          string
+	DeliveredAt        time.Time
+	// AttemptLog is only loaded for a single delivery
+	AttemptLog []WebhookDeliveryAttempt
+}
+
+type WebhookDeliveryAttempt struct {
+	Model
+	Attempt int
+	// ResponseStatus is 0 when no response was received
+	ResponseStatus int
+	Error          string
+	Duration       time.Duration
+}
+
+type DeliverWebhooksResult struct {
+	Delivered int
+	// Retrying failed and were scheduled for another attempt
+	Retrying int
+	Dead     int
+}
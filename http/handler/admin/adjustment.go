@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"bank/entity"
+	"bank/http/middleware"
+	"bank/internal/request"
+	"bank/internal/response"
+	"errors"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+type CreateAdjustmentRequest struct {
+	AccountID uint64          `json:"account_id" validate:"required,number"`
+	Amount    decimal.Decimal `json:"amount" validate:"decimal_required,decimal_positive,decimal_precision=6"`
+	TrxType   entity.TrxType  `json:"trx_type" validate:"required,oneof=CREDIT DEBIT"`
+	Reason    string          `json:"reason" validate:"required,max=500"`
+}
+
+// CreateAdjustment records a manual credit or debit on an account. It never posts
+// directly: the adjustment waits for a second person's approval.
+func (h *Handler) CreateAdjustment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateAdjustmentRequest
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return
+		}
+
+		approval, err := h.approvalDomain.RequestAdjustment(r.Context(), entity.CreateAdjustment{
+			AccountID:   req.AccountID,
+			Amount:      req.Amount,
+			TrxType:     req.TrxType,
+			Reason:      req.Reason,
+			RequestedBy: middleware.UserID(r.Context()),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+			case errors.Is(err, entity.ErrValidation):
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to create adjustment: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusAccepted, newApprovalResponse(approval))
+	}
+}
@@ -0,0 +1,202 @@
+package admin
+
+import (
+	"bank/entity"
+	"bank/http/middleware"
+	"bank/internal/request"
+	"bank/internal/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ApprovalEventResponse struct {
+	EventType entity.ApprovalEventType `json:"event_type"`
+	Actor     string                   `json:"actor"`
+	Note      string                   `json:"note,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+type ApprovalResponse struct {
+	ApprovalID           uint64                  `json:"approval_id"`
+	Kind                 entity.ApprovalKind     `json:"kind"`
+	Status               entity.ApprovalStatus   `json:"status"`
+	SourceAccountID      uint64                  `json:"source_account_id"`
+	DestinationAccountID uint64                  `json:"destination_account_id"`
+	Amount               decimal.Decimal         `json:"amount"`
+	RequestedBy          string                  `json:"requested_by"`
+	DecidedBy            string                  `json:"decided_by,omitempty"`
+	Reason               string                  `json:"reason,omitempty"`
+	TransferID           uint64                  `json:"transfer_id,omitempty"`
+	ExpiresAt            time.Time               `json:"expires_at"`
+	CreatedAt            time.Time               `json:"created_at"`
+	Events               []ApprovalEventResponse `json:"events,omitempty"`
+}
+
+func newApprovalResponse(a entity.ApprovalRequest) ApprovalResponse {
+	resp := ApprovalResponse{
+		ApprovalID:           a.ID,
+		Kind:                 a.Kind,
+		Status:               a.Status,
+		SourceAccountID:      a.SourceAccountID,
+		DestinationAccountID: a.DestinationAccountID,
+		Amount:               a.Amount,
+		RequestedBy:          a.RequestedBy,
+		DecidedBy:            a.DecidedBy,
+		Reason:               a.Reason,
+		TransferID:           a.TransferID,
+		ExpiresAt:            a.ExpiresAt,
+		CreatedAt:            a.CreatedAt,
+	}
+	for _, event := range a.Events {
+		resp.Events = append(resp.Events, ApprovalEventResponse{
+			EventType: event.EventType,
+			Actor:     event.Actor,
+			Note:      event.Note,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func (h *Handler) ListApprovals() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := entity.ApprovalStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "":
+			status = entity.ApprovalStatusPending
+		case entity.ApprovalStatusPending, entity.ApprovalStatusExecuted, entity.ApprovalStatusFailed,
+			entity.ApprovalStatusRejected, entity.ApprovalStatusExpired:
+		default:
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid status")
+			return
+		}
+
+		approvals, err := h.approvalDomain.ListApprovalRequests(r.Context(), status)
+		if err != nil {
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to list approvals: %v", err)
+			return
+		}
+
+		resp := make([]ApprovalResponse, 0, len(approvals))
+		for _, approval := range approvals {
+			resp = append(resp, newApprovalResponse(approval))
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
+
+func (h *Handler) GetApproval() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		approvalID, err := request.GetParamUint64(r, "approval_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid approval id")
+			return
+		}
+
+		approval, err := h.approvalDomain.GetApprovalRequest(r.Context(), approvalID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusNotFound, response.CodeApprovalNotFound, "approval not found")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to get approval: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusOK, newApprovalResponse(approval))
+	}
+}
+
+type DecideApprovalRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+func (h *Handler) Approve() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param, ok := h.bindDecision(w, r)
+		if !ok {
+			return
+		}
+
+		approval, err := h.approvalDomain.Approve(r.Context(), param)
+		if err != nil {
+			// A returned approval means it was executed and failed, and is now closed as FAILED
+			switch {
+			case errors.Is(err, entity.ErrInsufficientFunds):
+				response.JsonError(w, http.StatusUnprocessableEntity, response.CodeInsufficientFunds, "source account has insufficient funds, approval request failed")
+			case errors.Is(err, entity.ErrDataNotFound) && approval.ID != 0:
+				response.JsonError(w, http.StatusUnprocessableEntity, response.CodeAccountNotFound, "invalid account, approval request failed")
+			case errors.Is(err, entity.ErrValidation) && approval.ID != 0:
+				response.JsonError(w, http.StatusUnprocessableEntity, response.CodeValidationFailed, err.Error())
+			default:
+				h.decisionError(w, r, err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusOK, newApprovalResponse(approval))
+	}
+}
+
+func (h *Handler) Reject() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param, ok := h.bindDecision(w, r)
+		if !ok {
+			return
+		}
+
+		approval, err := h.approvalDomain.Reject(r.Context(), param)
+		if err != nil {
+			h.decisionError(w, r, err)
+			return
+		}
+
+		response.Json(w, http.StatusOK, newApprovalResponse(approval))
+	}
+}
+
+func (h *Handler) bindDecision(w http.ResponseWriter, r *http.Request) (entity.DecideApproval, bool) {
+	approvalID, err := request.GetParamUint64(r, "approval_id")
+	if err != nil {
+		response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid approval id")
+		return entity.DecideApproval{}, false
+	}
+
+	var req DecideApprovalRequest
+	if r.ContentLength != 0 {
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return entity.DecideApproval{}, false
+		}
+	}
+
+	return entity.DecideApproval{
+		ApprovalRequestID: approvalID,
+		Actor:             middleware.UserID(r.Context()),
+		Note:              req.Note,
+	}, true
+}
+
+func (h *Handler) decisionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, entity.ErrDataNotFound):
+		response.JsonError(w, http.StatusNotFound, response.CodeApprovalNotFound, "approval not found")
+	case errors.Is(err, entity.ErrForbidden):
+		response.JsonError(w, http.StatusForbidden, response.CodeSelfApproval, "requester cannot approve or reject their own request")
+	case errors.Is(err, entity.ErrExpired):
+		response.JsonError(w, http.StatusConflict, response.CodeApprovalExpired, "approval request has expired")
+	case errors.Is(err, entity.ErrInvalidState):
+		response.JsonError(w, http.StatusConflict, response.CodeApprovalNotPending, "approval request is no longer pending")
+	case errors.Is(err, entity.ErrValidation):
+		response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+	default:
+		response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+		h.logger.Error(r.Context(), "failed to decide approval: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestApprove(t *testing.T) {
+	testCases := []struct {
+		name             string
+		approver         string
+		amount           string
+		expiresIn        time.Duration
+		expectedStatus   int
+		expectedBody     string
+		expectedDBStatus string
+	}{
+		{
+			name:             "success - transfer executed",
+			approver:         "bob",
+			amount:           "150",
+			expiresIn:        time.Hour,
+			expectedStatus:   http.StatusOK,
+			expectedDBStatus: "EXECUTED",
+		},
+		{
+			name:             "requester cannot approve",
+			approver:         "alice",
+			amount:           "150",
+			expiresIn:        time.Hour,
+			expectedStatus:   http.StatusForbidden,
+			expectedBody:     `{"error":{"code":"SELF_APPROVAL","message":"requester cannot approve or reject their own request"}}`,
+			expectedDBStatus: "PENDING_APPROVAL",
+		},
+		{
+			name:             "expired",
+			approver:         "bob",
+			amount:           "150",
+			expiresIn:        -time.Minute,
+			expectedStatus:   http.StatusConflict,
+			expectedBody:     `{"error":{"code":"APPROVAL_EXPIRED","message":"approval request has expired"}}`,
+			expectedDBStatus: "EXPIRED",
+		},
+		{
+			name:             "insufficient funds - marked failed",
+			approver:         "bob",
+			amount:           "5000",
+			expiresIn:        time.Hour,
+			expectedStatus:   http.StatusUnprocessableEntity,
+			expectedBody:     `{"error":{"code":"INSUFFICIENT_FUNDS","message":"source account has insufficient funds, approval request failed"}}`,
+			expectedDBStatus: "FAILED",
+		},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				createAccount(t, handler.db, 100, "1000")
+				createAccount(t, handler.db, 200, "10")
+				approvalID := createPendingTransfer(t, handler.db, "alice", tc.amount, tc.expiresIn)
+
+				req := createRequest(t, "POST", "/admin/approvals/{approval_id}/approve", tc.approver, "",
+					requestParam{key: "approval_id", value: strconv.FormatUint(approvalID, 10)})
+				rr := httptest.NewRecorder()
+				handler.handler.Approve()(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d: %s", tc.expectedStatus, rr.Code, rr.Body.String())
+				}
+
+				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+				}
+
+				var status string
+				if err := handler.db.QueryRow("SELECT status FROM approval_requests WHERE id = $1", approvalID).Scan(&status); err != nil {
+					t.Fatalf("failed to query approval request: %v", err)
+				}
+				if status != tc.expectedDBStatus {
+					t.Errorf("expected approval status %s, got %s", tc.expectedDBStatus, status)
+				}
+
+				var transfers int
+				if err := handler.db.QueryRow("SELECT COUNT(*) FROM transfers").Scan(&transfers); err != nil {
+					t.Fatalf("failed to count transfers: %v", err)
+				}
+				if executed := tc.expectedDBStatus == "EXECUTED"; executed != (transfers == 1) {
+					t.Errorf("expected transfer executed=%t, got %d transfers", executed, transfers)
+				}
+			})
+		})
+	}
+}
+
+func TestReject(t *testing.T) {
+	testHandler(t, func(t *testing.T, handler *handlerFixture) {
+		t.Run("reject then approve", func(t *testing.T) {
+			createAccount(t, handler.db, 100, "1000")
+			createAccount(t, handler.db, 200, "10")
+			approvalID := createPendingTransfer(t, handler.db, "alice", "150", time.Hour)
+			param := requestParam{key: "approval_id", value: strconv.FormatUint(approvalID, 10)}
+
+			rr := httptest.NewRecorder()
+			handler.handler.Reject()(rr, createRequest(t, "POST", "/admin/approvals/{approval_id}/reject", "bob", `{"note":"not expected"}`, param))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+
+			rr = httptest.NewRecorder()
+			handler.handler.Approve()(rr, createRequest(t, "POST", "/admin/approvals/{approval_id}/approve", "carol", "", param))
+			if rr.Code != http.StatusConflict {
+				t.Errorf("expected status %d, got %d", http.StatusConflict, rr.Code)
+			}
+
+			var events []string
+			rows, err := handler.db.Query("SELECT event_type || ':' || actor FROM approval_events WHERE approval_request_id = $1 ORDER BY id", approvalID)
+			if err != nil {
+				t.Fatalf("failed to query approval events: %v", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var event string
+				if err := rows.Scan(&event); err != nil {
+					t.Fatalf("failed to scan approval event: %v", err)
+				}
+				events = append(events, event)
+			}
+			if len(events) != 1 || events[0] != "REJECTED:bob" {
+				t.Errorf("expected only REJECTED:bob event, got %v", events)
+			}
+		})
+	})
+}
+
+func TestCreateAdjustment(t *testing.T) {
+	testHandler(t, func(t *testing.T, handler *handlerFixture) {
+		t.Run("credit adjustment posts after approval", func(t *testing.T) {
+			createAccount(t, handler.db, 100, "10")
+
+			rr := httptest.NewRecorder()
+			handler.handler.CreateAdjustment()(rr, createRequest(t, "POST", "/admin/adjustments", "alice", `{
+				"account_id": 100,
+				"amount": "25.5",
+				"trx_type": "CREDIT",
+				"reason": "fee refund"
+			}`))
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body.String())
+			}
+
+			var approvalID uint64
+			if err := handler.db.QueryRow("SELECT id FROM approval_requests WHERE kind = 'ADJUSTMENT'").Scan(&approvalID); err != nil {
+				t.Fatalf("failed to query approval request: %v", err)
+			}
+
+			rr = httptest.NewRecorder()
+			handler.handler.Approve()(rr, createRequest(t, "POST", "/admin/approvals/{approval_id}/approve", "bob", "",
+				requestParam{key: "approval_id", value: strconv.FormatUint(approvalID, 10)}))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+
+			var balance, systemBalance decimal.Decimal
+			if err := handler.db.QueryRow("SELECT get_account_balance(100), get_account_balance($1)", adjustmentAccountID).Scan(&balance, &systemBalance); err != nil {
+				t.Fatalf("failed to query balances: %v", err)
+			}
+			if !balance.Equal(decimal.RequireFromString("35.5")) {
+				t.Errorf("expected balance 35.5, got %s", balance)
+			}
+			if !systemBalance.Equal(decimal.RequireFromString("-25.5")) {
+				t.Errorf("expected adjustment account balance -25.5, got %s", systemBalance)
+			}
+		})
+	})
+}
+
+func TestCreateAdjustment_DebitChecksBalance(t *testing.T) {
+	testHandler(t, func(t *testing.T, handler *handlerFixture) {
+		t.Run("debit adjustment over the balance fails", func(t *testing.T) {
+			createAccount(t, handler.db, 100, "10")
+
+			rr := httptest.NewRecorder()
+			handler.handler.CreateAdjustment()(rr, createRequest(t, "POST", "/admin/adjustments", "alice", `{
+				"account_id": 100,
+				"amount": "25",
+				"trx_type": "DEBIT",
+				"reason": "chargeback"
+			}`))
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body.String())
+			}
+
+			var approvalID uint64
+			if err := handler.db.QueryRow("SELECT id FROM approval_requests WHERE kind = 'ADJUSTMENT'").Scan(&approvalID); err != nil {
+				t.Fatalf("failed to query approval request: %v", err)
+			}
+
+			rr = httptest.NewRecorder()
+			handler.handler.Approve()(rr, createRequest(t, "POST", "/admin/approvals/{approval_id}/approve", "bob", "",
+				requestParam{key: "approval_id", value: strconv.FormatUint(approvalID, 10)}))
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+			}
+
+			var balance decimal.Decimal
+			if err := handler.db.QueryRow("SELECT get_account_balance(100)").Scan(&balance); err != nil {
+				t.Fatalf("failed to query balance: %v", err)
+			}
+			if !balance.Equal(decimal.RequireFromString("10")) {
+				t.Errorf("expected balance 10, got %s", balance)
+			}
+		})
+	})
+}
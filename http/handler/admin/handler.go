@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"bank/approval"
+	"bank/interest"
+	"bank/internal/logger"
+	"bank/payment"
+	"bank/webhook"
+	"errors"
+)
+
+type Handler struct {
+	approvalDomain *approval.ApprovalDomain
+	interestDomain *interest.InterestDomain
+	logger         *logger.Logger
+	paymentDomain  *payment.PaymentDomain
+	webhookDomain  *webhook.WebhookDomain
+}
+
+func NewHandler(approvalDomain *approval.ApprovalDomain, interestDomain *interest.InterestDomain, paymentDomain *payment.PaymentDomain, webhookDomain *webhook.WebhookDomain, logger *logger.Logger) (*Handler, error) {
+	if approvalDomain == nil {
+		return nil, errors.New("approval domain is nil")
+	}
+
+	if interestDomain == nil {
+		return nil, errors.New("interest domain is nil")
+	}
+
+	if paymentDomain == nil {
+		return nil, errors.New("payment domain is nil")
+	}
+
+	if webhookDomain == nil {
+		return nil, errors.New("webhook domain is nil")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("handler", "admin")
+	return &Handler{
+		approvalDomain: approvalDomain,
+		interestDomain: interestDomain,
+		logger:         log,
+		paymentDomain:  paymentDomain,
+		webhookDomain:  webhookDomain,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package admin_test
+
+import (
+	"bank/approval"
+	"bank/config"
+	"bank/http/handler/admin"
+	"bank/http/middleware"
+	"bank/interest"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/payment"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"bank/webhook"
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	adjustmentAccountID = 900000001
+	clearingAccountID   = 900000006
+)
+
+type handlerFixture struct {
+	db      *sql.DB
+	handler *admin.Handler
+}
+
+type testHandlerFunc func(t *testing.T, handler *handlerFixture)
+
+func testHandler(t *testing.T, testFunc testHandlerFunc) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		testLogger := logger.NewLogger("debug")
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create transaction domain: %v", err)
+		}
+
+		approvalDomain, err := approval.NewApprovalDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+			ApprovalTTL:         time.Hour,
+			AdjustmentAccountID: adjustmentAccountID,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create approval domain: %v", err)
+		}
+
+		interestDomain, err := interest.NewInterestDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+			InterestExpenseAccountID: 900000002,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create interest domain: %v", err)
+		}
+
+		paymentDomain, err := payment.NewPaymentDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, approvalDomain, &config.Config{
+			ClearingAccountID:      clearingAccountID,
+			PaymentDebtorName:      "BANK",
+			PaymentDebtorAccount:   "900000006",
+			PaymentDebtorAgentBIC:  "BANKUS33XXX",
+			PaymentFileMaxPayments: 1000,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create payment domain: %v", err)
+		}
+
+		webhookDomain, err := webhook.NewWebhookDomain(testDB.DB, sqlc.New(testDB.DB), &config.Config{
+			WebhookTimeout:     time.Second,
+			WebhookMaxAttempts: 3,
+			WebhookBackoffBase: time.Second,
+			WebhookBackoffMax:  time.Minute,
+			WebhookBatchSize:   10,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create webhook domain: %v", err)
+		}
+
+		handler, err := admin.NewHandler(approvalDomain, interestDomain, paymentDomain, webhookDomain, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create handler: %v", err)
+		}
+
+		testFunc(t, &handlerFixture{
+			db:      testDB.DB,
+			handler: handler,
+		})
+	})
+}
+
+type requestParam struct {
+	key   string
+	value string
+}
+
+func createRequest(t *testing.T, method, path, userID, body string, params ...requestParam) *http.Request {
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	paramCtx := chi.NewRouteContext()
+	for _, param := range params {
+		paramCtx.URLParams.Add(param.key, param.value)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, paramCtx))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req = req.WithContext(middleware.WithUserID(req.Context(), userID))
+	}
+	return req
+}
+
+// createAccount inserts an account funded with a single credit transaction
+func createAccount(t *testing.T, db *sql.DB, accountID uint64, balance string) {
+	_, err := db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", accountID)
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+		VALUES ($1, $2, 'CREDIT', NOW())
+	`, accountID, balance)
+	if err != nil {
+		t.Fatalf("failed to create initial transaction: %v", err)
+	}
+}
+
+// createPendingTransfer inserts a transfer approval request and returns its id
+func createPendingTransfer(t *testing.T, db *sql.DB, requestedBy string, amount string, expiresIn time.Duration) uint64 {
+	var approvalID uint64
+	err := db.QueryRow(`
+		INSERT INTO approval_requests (kind, status, source_account_id, destination_account_id, amount, requested_by, expires_at)
+		VALUES ('TRANSFER', 'PENDING_APPROVAL', 100, 200, $1, $2, $3) RETURNING id
+	`, amount, requestedBy, time.Now().Add(expiresIn)).Scan(&approvalID)
+	if err != nil {
+		t.Fatalf("failed to create approval request: %v", err)
+	}
+	return approvalID
+}
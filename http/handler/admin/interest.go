@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"bank/entity"
+	"bank/internal/request"
+	"bank/internal/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type InterestRateResponse struct {
+	ID            uint64             `json:"id"`
+	AccountType   entity.AccountType `json:"account_type"`
+	AnnualRate    decimal.Decimal    `json:"annual_rate"`
+	EffectiveFrom string             `json:"effective_from"`
+}
+
+func newInterestRateResponse(r entity.InterestRate) InterestRateResponse {
+	return InterestRateResponse{
+		ID:            r.ID,
+		AccountType:   r.AccountType,
+		AnnualRate:    r.AnnualRate,
+		EffectiveFrom: r.EffectiveFrom.Format(time.DateOnly),
+	}
+}
+
+type CreateInterestRateRequest struct {
+	AccountType   entity.AccountType `json:"account_type" validate:"required,oneof=SAVINGS CREDIT"`
+	AnnualRate    decimal.Decimal    `json:"annual_rate" validate:"decimal_non_negative,decimal_precision=6"`
+	EffectiveFrom string             `json:"effective_from" validate:"required,datetime=2006-01-02"`
+}
+
+func (h *Handler) CreateInterestRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateInterestRateRequest
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return
+		}
+
+		effectiveFrom, err := time.Parse(time.DateOnly, req.EffectiveFrom)
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "effective from is invalid")
+			return
+		}
+
+		rate, err := h.interestDomain.CreateInterestRate(r.Context(), entity.CreateInterestRate{
+			AccountType:   req.AccountType,
+			AnnualRate:    req.AnnualRate,
+			EffectiveFrom: effectiveFrom,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrValidation):
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to create interest rate: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusCreated, newInterestRateResponse(rate))
+	}
+}
+
+func (h *Handler) ListInterestRates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rates, err := h.interestDomain.ListInterestRates(r.Context())
+		if err != nil {
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to list interest rates: %v", err)
+			return
+		}
+
+		resp := make([]InterestRateResponse, 0, len(rates))
+		for _, rate := range rates {
+			resp = append(resp, newInterestRateResponse(rate))
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
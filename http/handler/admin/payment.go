@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"bank/entity"
+	"bank/internal/response"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxStatusReportSize = 10 << 20 // 10MB
+
+type PaymentStatusReportResponse struct {
+	PaymentFileID uint64 `json:"payment_file_id"`
+	Accepted      int    `json:"accepted"`
+	Rejected      int    `json:"rejected"`
+	Unchanged     int    `json:"unchanged"`
+}
+
+// ApplyPaymentStatusReport applies a pain.002 status report received from the clearing partner,
+// the request body is the XML message
+func (h *Handler) ApplyPaymentStatusReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := http.MaxBytesReader(w, r.Body, maxStatusReportSize)
+		result, err := h.paymentDomain.ApplyStatusReport(r.Context(), body)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrValidation):
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusNotFound, response.CodePaymentFileNotFound, "payment file not found")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to apply payment status report: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusOK, PaymentStatusReportResponse{
+			PaymentFileID: result.PaymentFileID,
+			Accepted:      result.Accepted,
+			Rejected:      result.Rejected,
+			Unchanged:     result.Unchanged,
+		})
+	}
+}
+
+// GetPaymentFile returns a stored pain.001 file as it was sent, to deliver it again by hand
+func (h *Handler) GetPaymentFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		messageID := chi.URLParam(r, "message_id")
+		file, err := h.paymentDomain.GetPaymentFile(r.Context(), messageID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusNotFound, response.CodePaymentFileNotFound, "payment file not found")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to get payment file: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.MessageID+".xml"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(file.Content))
+	}
+}
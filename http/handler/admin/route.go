@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"bank/http/middleware"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) RegisterRoutes(r *chi.Mux) http.Handler {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authenticate)
+		r.Use(middleware.RequireUser)
+
+		r.With(middleware.RequireRole(middleware.RoleApprover)).Post("/admin/adjustments", h.CreateAdjustment())
+
+		r.Get("/admin/approvals", h.ListApprovals())
+		r.Get("/admin/approvals/{approval_id}", h.GetApproval())
+		r.With(middleware.RequireRole(middleware.RoleApprover)).Post("/admin/approvals/{approval_id}/approve", h.Approve())
+		r.With(middleware.RequireRole(middleware.RoleApprover)).Post("/admin/approvals/{approval_id}/reject", h.Reject())
+
+		r.Get("/admin/interest-rates", h.ListInterestRates())
+		r.Post("/admin/interest-rates", h.CreateInterestRate())
+
+		r.Post("/admin/payment-status-reports", h.ApplyPaymentStatusReport())
+		r.Get("/admin/payment-files/{message_id}", h.GetPaymentFile())
+
+		r.Get("/admin/webhooks", h.ListWebhookSubscriptions())
+		r.Post("/admin/webhooks", h.CreateWebhookSubscription())
+		r.Get("/admin/webhooks/{webhook_id}", h.GetWebhookSubscription())
+		r.Put("/admin/webhooks/{webhook_id}", h.UpdateWebhookSubscription())
+		r.Delete("/admin/webhooks/{webhook_id}", h.DeleteWebhookSubscription())
+		r.Get("/admin/webhooks/{webhook_id}/deliveries", h.ListWebhookDeliveries())
+		r.Get("/admin/webhook-deliveries/{delivery_id}", h.GetWebhookDelivery())
+		r.Post("/admin/webhook-deliveries/{delivery_id}/redeliver", h.RedeliverWebhook())
+	})
+
+	return r
+}
@@ -0,0 +1,293 @@
+package admin
+
+import (
+	"bank/entity"
+	"bank/internal/request"
+	"bank/internal/response"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type WebhookSubscriptionResponse struct {
+	ID         uint64             `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []entity.EventType `json:"event_types"`
+	Active     bool               `json:"active"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+func newWebhookSubscriptionResponse(s entity.WebhookSubscription) WebhookSubscriptionResponse {
+	return WebhookSubscriptionResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	Attempt        int       `json:"attempt"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID                 uint64                           `json:"id"`
+	SubscriptionID     uint64                           `json:"subscription_id"`
+	EventID            uint64                           `json:"event_id"`
+	EventType          entity.EventType                 `json:"event_type"`
+	Status             entity.WebhookDeliveryStatus     `json:"status"`
+	Attempts           int                              `json:"attempts"`
+	NextAttemptAt      *time.Time                       `json:"next_attempt_at,omitempty"`
+	LastResponseStatus int                              `json:"last_response_status,omitempty"`
+	LastError          string                           `json:"last_error,omitempty"`
+	DeliveredAt        *time.Time                       `json:"delivered_at,omitempty"`
+	CreatedAt          time.Time                        `json:"created_at"`
+	Payload            json.RawMessage                  `json:"payload,omitempty"`
+	AttemptLog         []WebhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+func newWebhookDeliveryResponse(d entity.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:                 d.ID,
+		SubscriptionID:     d.SubscriptionID,
+		EventID:            d.EventID,
+		EventType:          d.EventType,
+		Status:             d.Status,
+		Attempts:           d.Attempts,
+		LastResponseStatus: d.LastResponseStatus,
+		LastError:          d.LastError,
+		CreatedAt:          d.CreatedAt,
+	}
+	if d.Status == entity.WebhookDeliveryStatusPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	if !d.DeliveredAt.IsZero() {
+		resp.DeliveredAt = &d.DeliveredAt
+	}
+	return resp
+}
+
+// newWebhookDeliveryDetailResponse adds the payload and the attempt log
+func newWebhookDeliveryDetailResponse(d entity.WebhookDelivery) WebhookDeliveryResponse {
+	resp := newWebhookDeliveryResponse(d)
+	resp.Payload = d.Payload
+	resp.AttemptLog = make([]WebhookDeliveryAttemptResponse, 0, len(d.AttemptLog))
+	for _, a := range d.AttemptLog {
+		resp.AttemptLog = append(resp.AttemptLog, WebhookDeliveryAttemptResponse{
+			Attempt:        a.Attempt,
+			ResponseStatus: a.ResponseStatus,
+			Error:          a.Error,
+			DurationMs:     a.Duration.Milliseconds(),
+			CreatedAt:      a.CreatedAt,
+		})
+	}
+	return resp
+}
+
+type CreateWebhookSubscriptionRequest struct {
+	URL        string             `json:"url" validate:"required,max=2048"`
+	EventTypes []entity.EventType `json:"event_types" validate:"required"`
+	Secret     string             `json:"secret" validate:"required,max=255"`
+}
+
+func (h *Handler) CreateWebhookSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateWebhookSubscriptionRequest
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return
+		}
+
+		subscription, err := h.webhookDomain.CreateSubscription(r.Context(), entity.CreateWebhookSubscription{
+			URL:        req.URL,
+			EventTypes: req.EventTypes,
+			Secret:     req.Secret,
+		})
+		if err != nil {
+			h.webhookError(w, r, err, "failed to create webhook subscription")
+			return
+		}
+
+		response.Json(w, http.StatusCreated, newWebhookSubscriptionResponse(subscription))
+	}
+}
+
+func (h *Handler) ListWebhookSubscriptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscriptions, err := h.webhookDomain.ListSubscriptions(r.Context())
+		if err != nil {
+			h.webhookError(w, r, err, "failed to list webhook subscriptions")
+			return
+		}
+
+		resp := make([]WebhookSubscriptionResponse, 0, len(subscriptions))
+		for _, s := range subscriptions {
+			resp = append(resp, newWebhookSubscriptionResponse(s))
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
+
+func (h *Handler) GetWebhookSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := request.GetParamUint64(r, "webhook_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		subscription, err := h.webhookDomain.GetSubscription(r.Context(), webhookID)
+		if err != nil {
+			h.webhookError(w, r, err, "failed to get webhook subscription")
+			return
+		}
+
+		response.Json(w, http.StatusOK, newWebhookSubscriptionResponse(subscription))
+	}
+}
+
+type UpdateWebhookSubscriptionRequest struct {
+	URL        string             `json:"url" validate:"required,max=2048"`
+	EventTypes []entity.EventType `json:"event_types" validate:"required"`
+	// Secret is optional, the current secret is kept without one
+	Secret string `json:"secret" validate:"max=255"`
+	Active *bool  `json:"active" validate:"required"`
+}
+
+func (h *Handler) UpdateWebhookSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := request.GetParamUint64(r, "webhook_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		var req UpdateWebhookSubscriptionRequest
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return
+		}
+
+		subscription, err := h.webhookDomain.UpdateSubscription(r.Context(), entity.UpdateWebhookSubscription{
+			ID:         webhookID,
+			URL:        req.URL,
+			EventTypes: req.EventTypes,
+			Secret:     req.Secret,
+			Active:     *req.Active,
+		})
+		if err != nil {
+			h.webhookError(w, r, err, "failed to update webhook subscription")
+			return
+		}
+
+		response.Json(w, http.StatusOK, newWebhookSubscriptionResponse(subscription))
+	}
+}
+
+func (h *Handler) DeleteWebhookSubscription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := request.GetParamUint64(r, "webhook_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		if err := h.webhookDomain.DeleteSubscription(r.Context(), webhookID); err != nil {
+			h.webhookError(w, r, err, "failed to delete webhook subscription")
+			return
+		}
+
+		response.StatusOnly(w, http.StatusNoContent)
+	}
+}
+
+// ListWebhookDeliveries is the delivery log of a subscription, filtered by the optional status
+// query parameter
+func (h *Handler) ListWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := request.GetParamUint64(r, "webhook_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		status := entity.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "", entity.WebhookDeliveryStatusPending, entity.WebhookDeliveryStatusDelivered, entity.WebhookDeliveryStatusDead:
+		default:
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid status")
+			return
+		}
+
+		deliveries, err := h.webhookDomain.ListDeliveries(r.Context(), webhookID, status)
+		if err != nil {
+			h.webhookError(w, r, err, "failed to list webhook deliveries")
+			return
+		}
+
+		resp := make([]WebhookDeliveryResponse, 0, len(deliveries))
+		for _, d := range deliveries {
+			resp = append(resp, newWebhookDeliveryResponse(d))
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
+
+func (h *Handler) GetWebhookDelivery() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryID, err := request.GetParamUint64(r, "delivery_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid delivery id")
+			return
+		}
+
+		delivery, err := h.webhookDomain.GetDelivery(r.Context(), deliveryID)
+		if err != nil {
+			h.webhookError(w, r, err, "failed to get webhook delivery")
+			return
+		}
+
+		response.Json(w, http.StatusOK, newWebhookDeliveryDetailResponse(delivery))
+	}
+}
+
+// RedeliverWebhook queues a delivered or dead delivery to be sent again
+func (h *Handler) RedeliverWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryID, err := request.GetParamUint64(r, "delivery_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid delivery id")
+			return
+		}
+
+		delivery, err := h.webhookDomain.Redeliver(r.Context(), deliveryID)
+		if err != nil {
+			h.webhookError(w, r, err, "failed to redeliver webhook")
+			return
+		}
+
+		response.Json(w, http.StatusAccepted, newWebhookDeliveryDetailResponse(delivery))
+	}
+}
+
+func (h *Handler) webhookError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, entity.ErrValidation):
+		response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+	case errors.Is(err, entity.ErrDataNotFound):
+		response.JsonError(w, http.StatusNotFound, response.CodeWebhookNotFound, "webhook not found")
+	case errors.Is(err, entity.ErrInvalidState):
+		response.JsonError(w, http.StatusConflict, response.CodeWebhookDeliveryPending, "webhook delivery is already pending")
+	default:
+		response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+		h.logger.Error(r.Context(), "%s: %v", msg, err)
+	}
+}
@@ -0,0 +1,120 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			body:           `{"url":"https://example.com/hooks","event_types":["TransferCompleted","TransferFailed"],"secret":"0123456789abcdef"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing secret",
+			body:           `{"url":"https://example.com/hooks","event_types":["TransferCompleted"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"secret is required","details":[{"field":"secret","rule":"required","message":"secret is required"}]}}`,
+		},
+		{
+			name:           "invalid url and event type",
+			body:           `{"url":"ftp://example.com","event_types":["AccountClosed"],"secret":"0123456789abcdef"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: url must be an absolute http or https url, unknown event type \"AccountClosed\""}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				req := createRequest(t, "POST", "/admin/webhooks", "alice", tc.body)
+				rr := httptest.NewRecorder()
+				handler.handler.CreateWebhookSubscription()(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d: %s", tc.expectedStatus, rr.Code, rr.Body.String())
+				}
+
+				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+				}
+
+				if tc.expectedStatus != http.StatusCreated {
+					return
+				}
+
+				var resp map[string]any
+				if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if _, ok := resp["secret"]; ok {
+					t.Errorf("expected the secret not to be returned, got %s", rr.Body.String())
+				}
+				if resp["active"] != true {
+					t.Errorf("expected an active subscription, got %s", rr.Body.String())
+				}
+			})
+		})
+	}
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	testCases := []struct {
+		name             string
+		status           string
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{name: "dead delivery", status: "DEAD", expectedStatus: http.StatusAccepted, expectedAttempts: 0},
+		{name: "delivered delivery", status: "DELIVERED", expectedStatus: http.StatusAccepted, expectedAttempts: 0},
+		{name: "pending delivery", status: "PENDING", expectedStatus: http.StatusConflict, expectedAttempts: 2},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				var deliveryID uint64
+				err := handler.db.QueryRow(`
+					WITH s AS (
+						INSERT INTO webhook_subscriptions (url, event_types, secret) VALUES ('https://example.com', '{AccountCreated}', '0123456789abcdef') RETURNING id
+					), e AS (
+						INSERT INTO outbox (event_type, account_ids, payload) VALUES ('AccountCreated', '{1}', '{}') RETURNING id
+					)
+					INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at)
+					SELECT s.id, e.id, 'AccountCreated', '{}', $1, 2, NOW() FROM s, e RETURNING id
+				`, tc.status).Scan(&deliveryID)
+				if err != nil {
+					t.Fatalf("failed to create delivery: %v", err)
+				}
+
+				req := createRequest(t, "POST", "/admin/webhook-deliveries/{delivery_id}/redeliver", "alice", "",
+					requestParam{key: "delivery_id", value: strconv.FormatUint(deliveryID, 10)})
+				rr := httptest.NewRecorder()
+				handler.handler.RedeliverWebhook()(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d: %s", tc.expectedStatus, rr.Code, rr.Body.String())
+				}
+
+				var status string
+				var attempts int
+				if err := handler.db.QueryRow("SELECT status, attempts FROM webhook_deliveries WHERE id = $1", deliveryID).Scan(&status, &attempts); err != nil {
+					t.Fatalf("failed to get delivery: %v", err)
+				}
+				if status != "PENDING" || attempts != tc.expectedAttempts {
+					t.Errorf("expected a pending delivery with %d attempts, got %s with %d", tc.expectedAttempts, status, attempts)
+				}
+			})
+		})
+	}
+}
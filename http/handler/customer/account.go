@@ -6,34 +6,39 @@ import (
 	"bank/internal/response"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
 
 type CreateAccountRequest struct {
-	AccountID      uint64          `json:"account_id" validate:"required,number"`
-	InitialBalance decimal.Decimal `json:"initial_balance" validate:"decimal_required,decimal_positive,decimal_precision=6"`
+	AccountID      uint64             `json:"account_id" validate:"required,number"`
+	AccountType    entity.AccountType `json:"account_type" validate:"omitempty,oneof=SAVINGS CREDIT"`
+	InitialBalance decimal.Decimal    `json:"initial_balance" validate:"decimal_required,decimal_positive,decimal_precision=6"`
+	CreditLimit    decimal.Decimal    `json:"credit_limit" validate:"decimal_non_negative,decimal_precision=6"`
 }
 
 func (h *Handler) CreateAccount() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req CreateAccountRequest
 		if err := request.BindJSON(r, &req); err != nil {
-			response.JsonError(w, http.StatusBadRequest, err.Error())
+			response.JsonBindError(w, err)
 			return
 		}
 
 		account := &entity.CreateAccount{
 			AccountID:      req.AccountID,
+			AccountType:    req.AccountType,
 			InitialBalance: req.InitialBalance,
+			CreditLimit:    req.CreditLimit,
 		}
 
 		if err := h.accountDomain.CreateAccount(r.Context(), account); err != nil {
 			switch {
 			case errors.Is(err, entity.ErrValidation):
-				response.JsonError(w, http.StatusBadRequest, err.Error())
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
 			default:
-				response.JsonError(w, http.StatusInternalServerError, "it's not you, it's us. please contact support")
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
 				h.logger.Error(r.Context(), "failed to create account: %v", err)
 			}
 			return
@@ -52,7 +57,7 @@ func (h *Handler) GetAccountBalance() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID, err := request.GetParamUint64(r, "account_id")
 		if err != nil {
-			response.JsonError(w, http.StatusBadRequest, "invalid account id")
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
 			return
 		}
 
@@ -60,9 +65,9 @@ func (h *Handler) GetAccountBalance() http.HandlerFunc {
 		if err != nil {
 			switch {
 			case errors.Is(err, entity.ErrNoRows):
-				response.JsonError(w, http.StatusBadRequest, "invalid account")
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
 			default:
-				response.JsonError(w, http.StatusInternalServerError, "it's not you, it's us. please contact support")
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
 				h.logger.Error(r.Context(), "failed to get account balance: %v", err)
 			}
 			return
@@ -74,3 +79,93 @@ func (h *Handler) GetAccountBalance() http.HandlerFunc {
 		})
 	}
 }
+
+type GetAccruedInterestResponse struct {
+	AccountID       uint64          `json:"account_id"`
+	AccruedInterest decimal.Decimal `json:"accrued_interest"`
+}
+
+// GetAccruedInterest returns the interest accrued on the account since its last capitalization
+func (h *Handler) GetAccruedInterest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		accrued, err := h.interestDomain.GetAccruedInterest(r.Context(), accountID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrNoRows):
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to get accrued interest: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusOK, GetAccruedInterestResponse{
+			AccountID:       accountID,
+			AccruedInterest: accrued.Amount,
+		})
+	}
+}
+
+type CreditStatementResponse struct {
+	PeriodStart      string          `json:"period_start"`
+	PeriodEnd        string          `json:"period_end"`
+	ClosingBalance   decimal.Decimal `json:"closing_balance"`
+	StatementBalance decimal.Decimal `json:"statement_balance"`
+	MinimumPayment   decimal.Decimal `json:"minimum_payment"`
+	DueDate          string          `json:"due_date"`
+	InterestCharged  decimal.Decimal `json:"interest_charged"`
+	LateFeeCharged   decimal.Decimal `json:"late_fee_charged"`
+}
+
+type ListStatementsResponse struct {
+	AccountID  uint64                    `json:"account_id"`
+	Statements []CreditStatementResponse `json:"statements"`
+}
+
+// ListStatements returns the credit statements of the account, newest first
+func (h *Handler) ListStatements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		statements, err := h.billingDomain.ListStatements(r.Context(), accountID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrNoRows):
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to list statements: %v", err)
+			}
+			return
+		}
+
+		resp := ListStatementsResponse{
+			AccountID:  accountID,
+			Statements: make([]CreditStatementResponse, 0, len(statements)),
+		}
+		for _, s := range statements {
+			resp.Statements = append(resp.Statements, CreditStatementResponse{
+				PeriodStart:      s.PeriodStart.Format(time.DateOnly),
+				PeriodEnd:        s.PeriodEnd.Format(time.DateOnly),
+				ClosingBalance:   s.ClosingBalance,
+				StatementBalance: s.StatementBalance,
+				MinimumPayment:   s.MinimumPayment,
+				DueDate:          s.DueDate.Format(time.DateOnly),
+				InterestCharged:  s.InterestCharged,
+				LateFeeCharged:   s.LateFeeCharged,
+			})
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
@@ -1,7 +1,8 @@
 package customer_test
 
 import (
-	"database/sql"
+	"bank/store/memory"
+	"bank/transaction"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -38,7 +39,7 @@ func TestCreateAccount(t *testing.T) {
 				"initial_balance": "100.12345"
 			}`,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"account id is required"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"account id is required","details":[{"field":"account_id","rule":"required","message":"account id is required"}]}}`,
 		},
 		{
 			name: "initial_balance empty",
@@ -46,7 +47,7 @@ func TestCreateAccount(t *testing.T) {
 				"account_id": 123
 			}`,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"initial balance is required"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"initial balance is required","details":[{"field":"initial_balance","rule":"decimal_required","message":"initial balance is required"}]}}`,
 		},
 		{
 			name: "initial_balance negative",
@@ -55,101 +56,72 @@ func TestCreateAccount(t *testing.T) {
 				"initial_balance": "-100"
 			}`,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"initial balance must be greater than 0"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"initial balance must be greater than 0","details":[{"field":"initial_balance","rule":"decimal_positive","message":"initial balance must be greater than 0"}]}}`,
+		},
+		{
+			name: "every failing field is reported",
+			request: `{
+				"account_type": "CHECKING",
+				"initial_balance": "1.1234567"
+			}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"account id is required, account type must be one of SAVINGS, CREDIT, initial balance has too many decimal places (max 6)","details":[{"field":"account_id","rule":"required","message":"account id is required"},{"field":"account_type","rule":"oneof","message":"account type must be one of SAVINGS, CREDIT"},{"field":"initial_balance","rule":"decimal_precision","message":"initial balance has too many decimal places (max 6)"}]}}`,
 		},
 	}
 
 	for _, tc := range testCases {
-		testHandler(t, func(t *testing.T, handler *handlerFixture) {
-			t.Run(tc.name, func(t *testing.T) {
-				req := createRequest(t, "POST", "/accounts", tc.request)
-
-				rr := httptest.NewRecorder()
-				handler.handler.CreateAccount()(rr, req)
-
-				if tc.expectedStatus != 0 && rr.Code != tc.expectedStatus {
-					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
-				}
-
-				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
-					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
-				}
-
-				if tc.expectedStatus != http.StatusCreated {
-					return
-				}
-
-				// Check if the account and transaction are created
-				var accountID int64
-				err := handler.db.QueryRow("SELECT id FROM accounts WHERE id = $1", 123).Scan(&accountID)
-				if err != nil {
-					t.Fatalf("failed to query account: %v", err)
-				}
-
-				if accountID != 123 {
-					t.Errorf("expected account id %d, got %d", 123, accountID)
-				}
-
-				var transactionAmount decimal.Decimal
-				err = handler.db.QueryRow("SELECT amount FROM transactions WHERE account_id = $1", 123).Scan(&transactionAmount)
-				if err != nil {
-					t.Fatalf("failed to query transaction: %v", err)
-				}
-
-				if transactionAmount.String() != "100" {
-					t.Errorf("expected transaction amount %s, got %s", "100", transactionAmount.String())
-				}
-			})
+		t.Run(tc.name, func(t *testing.T) {
+			handler, ledger := testMemoryHandler(t, transaction.EngineProcedure)
+			req := createRequest(t, "POST", "/accounts", tc.request)
+
+			rr := httptest.NewRecorder()
+			handler.CreateAccount()(rr, req)
+
+			if tc.expectedStatus != 0 && rr.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+			}
+
+			if tc.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			// Check that the account is created with its initial transaction
+			transactions := listMemoryTransactions(t, ledger, 123)
+			if len(transactions) != 1 {
+				t.Fatalf("expected the initial transaction, got %d transactions", len(transactions))
+			}
+
+			if transactions[0].TrxType != "CREDIT" || transactions[0].Amount.String() != "100" {
+				t.Errorf("expected a CREDIT of %s, got a %s of %s", "100", transactions[0].TrxType, transactions[0].Amount.String())
+			}
 		})
 	}
 }
 
 func TestGetAccountBalance(t *testing.T) {
 	testCases := []struct {
-		name              string
-		accountID         string
-		setupDB           func(t *testing.T, db *sql.DB)
-		expectedStatus    int
-		expectedBody      string
-		expectedErrorLogs bool
+		name           string
+		accountID      string
+		setup          func(t *testing.T, ledger *memory.Store)
+		expectedStatus int
+		expectedBody   string
 	}{
 		{
 			name:      "success - account with balance",
 			accountID: "123",
-			setupDB: func(t *testing.T, db *sql.DB) {
-				// Create account
-				_, err := db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 123)
-				if err != nil {
-					t.Fatalf("failed to create account: %v", err)
-				}
-
-				// Create initial transaction (to match the last_transaction_id)
-				var transactionID uint64
-				err = db.QueryRow(`
-					INSERT INTO transactions (id, account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, $3, 'CREDIT', NOW()) RETURNING id
-				`, 1, 123, "150.500000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create transaction: %v", err)
-				}
+			setup: func(t *testing.T, ledger *memory.Store) {
+				createMemoryAccount(t, ledger, 123)
 
-				// Create balance snapshot
-				_, err = db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 123, "150.51234", transactionID)
-				if err != nil {
+				// The snapshot covers the first transaction, the balance adds the ones after it
+				transactionID := creditMemoryAccount(t, ledger, 123, "150.5")
+				if err := ledger.CreateBalanceSnapshot(123, decimal.RequireFromString("150.51234"), transactionID); err != nil {
 					t.Fatalf("failed to create balance snapshot: %v", err)
 				}
-
-				// Create another transaction
-				_, err = db.Exec(`
-					INSERT INTO transactions (id, account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, $3, 'CREDIT', NOW())
-				`, 2, 123, "100.000000")
-				if err != nil {
-					t.Fatalf("failed to create transaction: %v", err)
-				}
+				creditMemoryAccount(t, ledger, 123, "100")
 			},
 			expectedStatus: http.StatusOK,
 			// This should be calculated as 150.51234 + 100.000000 = 250.51234
@@ -158,30 +130,9 @@ func TestGetAccountBalance(t *testing.T) {
 		{
 			name:      "success - account with zero balance",
 			accountID: "456",
-			setupDB: func(t *testing.T, db *sql.DB) {
-				// Create account
-				_, err := db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 456)
-				if err != nil {
-					t.Fatalf("failed to create account: %v", err)
-				}
-
-				var transactionID uint64
-				err = db.QueryRow(`
-					INSERT INTO transactions (id, account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, $3, 'CREDIT', NOW()) RETURNING id
-				`, 1, 456, "0.000000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create transaction: %v", err)
-				}
-
-				// Create balance snapshot with zero balance
-				_, err = db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 456, "0.000000", transactionID)
-				if err != nil {
-					t.Fatalf("failed to create balance snapshot: %v", err)
-				}
+			setup: func(t *testing.T, ledger *memory.Store) {
+				createMemoryAccount(t, ledger, 456)
+				fundMemoryAccount(t, ledger, 456, "0")
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"account_id":456,"balance":"0"}`,
@@ -189,59 +140,51 @@ func TestGetAccountBalance(t *testing.T) {
 		{
 			name:           "invalid account_id - not a number",
 			accountID:      "abc",
-			setupDB:        func(t *testing.T, db *sql.DB) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid account id"}`,
+			expectedBody:   `{"error":{"code":"INVALID_PARAMETER","message":"invalid account id"}}`,
 		},
 		{
 			name:           "invalid account_id - negative number",
 			accountID:      "-123",
-			setupDB:        func(t *testing.T, db *sql.DB) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid account id"}`,
+			expectedBody:   `{"error":{"code":"INVALID_PARAMETER","message":"invalid account id"}}`,
 		},
 		{
 			name:           "invalid account_id - empty",
 			accountID:      "",
-			setupDB:        func(t *testing.T, db *sql.DB) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid account id"}`,
+			expectedBody:   `{"error":{"code":"INVALID_PARAMETER","message":"invalid account id"}}`,
 		},
 		{
-			name:              "account not found",
-			accountID:         "999",
-			setupDB:           func(t *testing.T, db *sql.DB) {},
-			expectedStatus:    http.StatusBadRequest,
-			expectedBody:      `{"error":"invalid account"}`,
-			expectedErrorLogs: true,
+			name:           "account not found",
+			accountID:      "999",
+			setup:          func(t *testing.T, ledger *memory.Store) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
 		},
 	}
 
 	for _, tc := range testCases {
-		testHandler(t, func(t *testing.T, handler *handlerFixture) {
-			t.Run(tc.name, func(t *testing.T) {
-				tc.setupDB(t, handler.db)
+		t.Run(tc.name, func(t *testing.T) {
+			handler, ledger := testMemoryHandler(t, transaction.EngineProcedure)
+			tc.setup(t, ledger)
 
-				// Create request with account_id parameter
-				req := createRequest(t, "GET", "/accounts/{account_id}", "", requestParam{key: "account_id", value: tc.accountID})
+			// Create request with account_id parameter
+			req := createRequest(t, "GET", "/accounts/{account_id}", "", requestParam{key: "account_id", value: tc.accountID})
 
-				rr := httptest.NewRecorder()
-				handler.handler.GetAccountBalance()(rr, req)
+			rr := httptest.NewRecorder()
+			handler.GetAccountBalance()(rr, req)
 
-				if rr.Code != tc.expectedStatus {
-					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
-				}
-
-				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
-					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
-				}
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+			}
 
-				// Verify that error logs are generated when expected
-				if tc.expectedErrorLogs && rr.Code == http.StatusInternalServerError {
-					// This is a placeholder for verifying error logs
-					// In a real scenario, you might want to capture and verify log output
-				}
-			})
+			if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+			}
 		})
 	}
 }
@@ -0,0 +1,197 @@
+package customer
+
+import (
+	"bank/account"
+	"bank/entity"
+	"bank/internal/request"
+	"bank/internal/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// eventsRetry is how long clients wait before reconnecting, in milliseconds
+	eventsRetry = 3000
+	// eventsKeepAlive keeps proxies from closing a quiet stream
+	eventsKeepAlive = 15 * time.Second
+)
+
+type transactionEventResponse struct {
+	TransactionID         uint64          `json:"transaction_id"`
+	TransferID            *int64          `json:"transfer_id"`
+	CounterpartyAccountID *int64          `json:"counterparty_account_id"`
+	Type                  entity.TrxType  `json:"type"`
+	Amount                decimal.Decimal `json:"amount"`
+	Balance               decimal.Decimal `json:"balance"`
+	CreatedAt             time.Time       `json:"created_at"`
+}
+
+type balanceEventResponse struct {
+	AccountID uint64          `json:"account_id"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// AccountEvents streams the balance changes of the account as Server-Sent Events: a transaction
+// event per posted transaction followed by a balance event, both with the transaction id as the
+// event id. A client reconnecting with Last-Event-ID gets every transaction it missed, a new
+// client starts from the current balance.
+func (h *Handler) AccountEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		lastEventID := uint64(account.LatestTransactionID)
+		if header := strings.TrimSpace(r.Header.Get("Last-Event-ID")); header != "" {
+			lastEventID, err = strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid Last-Event-ID")
+				return
+			}
+		}
+
+		// Subscribe before the first read so no transaction falls between the read and the signal
+		signals, cancel := h.listener.Subscribe(strconv.FormatUint(accountID, 10))
+		defer cancel()
+
+		updates, err := h.accountDomain.GetAccountUpdates(r.Context(), accountID, lastEventID)
+		if err != nil {
+			if errors.Is(err, entity.ErrNoRows) {
+				response.JsonError(w, http.StatusNotFound, response.CodeAccountNotFound, "account not found")
+				return
+			}
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to get account updates: %v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		rc := http.NewResponseController(w)
+		// The stream outlives any write deadline of the server
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", eventsRetry); err != nil {
+			return
+		}
+
+		sentID, err := h.sendAccountUpdates(r.Context(), w, rc, updates)
+		if err != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case _, ok := <-signals:
+				// Closed when the server shuts down
+				if !ok {
+					return
+				}
+
+				updates, err := h.accountDomain.GetAccountUpdates(r.Context(), accountID, sentID)
+				if err != nil {
+					if r.Context().Err() == nil {
+						h.logger.Error(r.Context(), "failed to get account updates: %v", err)
+					}
+					return
+				}
+
+				// Signals also follow a reconnect of the listener, with nothing new for the account
+				if updates.LastTransactionID == sentID {
+					continue
+				}
+				if sentID, err = h.sendAccountUpdates(r.Context(), w, rc, updates); err != nil {
+					return
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendAccountUpdates writes and flushes the updates, then reads on and sends the transactions they
+// left out until it caught up. Returns the id of the last transaction sent.
+func (h *Handler) sendAccountUpdates(ctx context.Context, w http.ResponseWriter, rc *http.ResponseController, updates entity.AccountUpdates) (uint64, error) {
+	for {
+		if err := writeAccountUpdates(w, updates); err != nil {
+			return 0, err
+		}
+		if err := rc.Flush(); err != nil {
+			return 0, err
+		}
+		if !updates.More {
+			return updates.LastTransactionID, nil
+		}
+
+		var err error
+		updates, err = h.accountDomain.GetAccountUpdates(ctx, updates.AccountID, updates.LastTransactionID)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Error(ctx, "failed to get account updates: %v", err)
+			}
+			return 0, err
+		}
+	}
+}
+
+// writeAccountUpdates writes the transactions of the updates and the balance after them
+func writeAccountUpdates(w http.ResponseWriter, updates entity.AccountUpdates) error {
+	for _, t := range updates.Transactions {
+		event := transactionEventResponse{
+			TransactionID: t.ID,
+			Type:          t.TrxType,
+			Amount:        t.Amount,
+			Balance:       t.Balance,
+			CreatedAt:     t.CreatedAt.UTC(),
+		}
+		if t.TransferID.Valid {
+			event.TransferID = &t.TransferID.Int64
+		}
+		if t.CounterpartyAccountID.Valid {
+			event.CounterpartyAccountID = &t.CounterpartyAccountID.Int64
+		}
+
+		if err := writeEvent(w, t.ID, "transaction", event); err != nil {
+			return err
+		}
+	}
+
+	return writeEvent(w, updates.LastTransactionID, "balance", balanceEventResponse{
+		AccountID: updates.AccountID,
+		Balance:   updates.Balance,
+	})
+}
+
+func writeEvent(w http.ResponseWriter, id uint64, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+	return err
+}
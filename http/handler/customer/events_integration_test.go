@@ -0,0 +1,138 @@
+//go:build integration
+
+package customer_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type serverSentEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads the next event of the stream, skipping comments and the retry field
+func readEvent(t *testing.T, scanner *bufio.Scanner) serverSentEvent {
+	var e serverSentEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if e.event != "" {
+				return e
+			}
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", scanner.Err())
+	return e
+}
+
+func openEventStream(t *testing.T, ctx context.Context, url, lastEventID string) (*http.Response, *bufio.Scanner) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	return resp, bufio.NewScanner(resp.Body)
+}
+
+func TestAccountEvents(t *testing.T) {
+	testHandler(t, func(t *testing.T, handler *handlerFixture) {
+		r := chi.NewRouter()
+		handler.handler.RegisterRoutes(r)
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		if _, err := handler.db.Exec("INSERT INTO accounts (id) VALUES (600)"); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+		var firstID int64
+		if err := handler.db.QueryRow("INSERT INTO transactions (account_id, amount, trx_type) VALUES (600, 1000, 'CREDIT') RETURNING id").Scan(&firstID); err != nil {
+			t.Fatalf("failed to create transaction: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		t.Run("unknown account", func(t *testing.T) {
+			resp, _ := openEventStream(t, ctx, server.URL+"/accounts/601/events", "")
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+			}
+		})
+
+		t.Run("invalid last event id", func(t *testing.T) {
+			resp, _ := openEventStream(t, ctx, server.URL+"/accounts/600/events", "abc")
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+
+		resp, scanner := openEventStream(t, ctx, server.URL+"/accounts/600/events", "")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("expected an event stream, got status %d and %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+
+		// A new client starts from the current balance
+		first := readEvent(t, scanner)
+		expected := serverSentEvent{id: strconv.FormatInt(firstID, 10), event: "balance", data: `{"account_id":600,"balance":"1000"}`}
+		if first != expected {
+			t.Fatalf("expected %+v, got %+v", expected, first)
+		}
+
+		var secondID int64
+		if err := handler.db.QueryRow("INSERT INTO transactions (account_id, amount, trx_type) VALUES (600, 25, 'DEBIT') RETURNING id").Scan(&secondID); err != nil {
+			t.Fatalf("failed to create transaction: %v", err)
+		}
+
+		transaction := readEvent(t, scanner)
+		if transaction.event != "transaction" || transaction.id != strconv.FormatInt(secondID, 10) ||
+			!strings.Contains(transaction.data, `"type":"DEBIT","amount":"25","balance":"975"`) {
+			t.Errorf("unexpected transaction event %+v", transaction)
+		}
+
+		balance := readEvent(t, scanner)
+		expected = serverSentEvent{id: strconv.FormatInt(secondID, 10), event: "balance", data: `{"account_id":600,"balance":"975"}`}
+		if balance != expected {
+			t.Errorf("expected %+v, got %+v", expected, balance)
+		}
+
+		// A reconnecting client gets what it missed since its last event
+		replay, replayScanner := openEventStream(t, ctx, server.URL+"/accounts/600/events", first.id)
+		defer replay.Body.Close()
+
+		missed := readEvent(t, replayScanner)
+		if missed != transaction {
+			t.Errorf("expected the missed transaction %+v, got %+v", transaction, missed)
+		}
+		if e := readEvent(t, replayScanner); e != balance {
+			t.Errorf("expected %+v, got %+v", balance, e)
+		}
+	})
+}
@@ -2,18 +2,38 @@ package customer
 
 import (
 	"bank/account"
+	"bank/approval"
+	"bank/billing"
+	"bank/entity"
+	"bank/interest"
 	"bank/internal/logger"
+	"bank/internal/pgnotify"
+	"bank/payment"
 	"bank/transaction"
+	"context"
 	"errors"
+
+	"github.com/shopspring/decimal"
 )
 
+// transferApprover parks the transfers above the approval threshold, it is the approval domain
+type transferApprover interface {
+	RequiresApproval(amount decimal.Decimal) bool
+	RequestTransfer(ctx context.Context, param entity.CreateTransferApproval) (entity.ApprovalRequest, error)
+}
+
 type Handler struct {
 	accountDomain     *account.AccountDomain
+	approvalDomain    transferApprover
+	billingDomain     *billing.BillingDomain
+	interestDomain    *interest.InterestDomain
+	listener          *pgnotify.Listener
 	logger            *logger.Logger
+	paymentDomain     *payment.PaymentDomain
 	transactionDomain *transaction.TransactionDomain
 }
 
-func NewHandler(accountDomain *account.AccountDomain, transactionDomain *transaction.TransactionDomain, logger *logger.Logger) (*Handler, error) {
+func NewHandler(accountDomain *account.AccountDomain, transactionDomain *transaction.TransactionDomain, approvalDomain *approval.ApprovalDomain, interestDomain *interest.InterestDomain, billingDomain *billing.BillingDomain, paymentDomain *payment.PaymentDomain, listener *pgnotify.Listener, logger *logger.Logger) (*Handler, error) {
 	if accountDomain == nil {
 		return nil, errors.New("account domain is nil")
 	}
@@ -22,6 +42,26 @@ func NewHandler(accountDomain *account.AccountDomain, transactionDomain *transac
 		return nil, errors.New("transaction domain is nil")
 	}
 
+	if approvalDomain == nil {
+		return nil, errors.New("approval domain is nil")
+	}
+
+	if interestDomain == nil {
+		return nil, errors.New("interest domain is nil")
+	}
+
+	if billingDomain == nil {
+		return nil, errors.New("billing domain is nil")
+	}
+
+	if paymentDomain == nil {
+		return nil, errors.New("payment domain is nil")
+	}
+
+	if listener == nil {
+		return nil, errors.New("listener is nil")
+	}
+
 	if logger == nil {
 		return nil, errors.New("logger is nil")
 	}
@@ -29,7 +69,12 @@ func NewHandler(accountDomain *account.AccountDomain, transactionDomain *transac
 	log := logger.WithField("handler", "customer")
 	return &Handler{
 		accountDomain:     accountDomain,
+		approvalDomain:    approvalDomain,
+		billingDomain:     billingDomain,
+		interestDomain:    interestDomain,
+		listener:          listener,
 		logger:            log,
+		paymentDomain:     paymentDomain,
 		transactionDomain: transactionDomain,
 	}, nil
 }
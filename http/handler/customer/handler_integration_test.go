@@ -0,0 +1,109 @@
+//go:build integration
+
+package customer_test
+
+import (
+	"bank/approval"
+	"bank/billing"
+	"bank/config"
+	"bank/http/handler/customer"
+	"bank/interest"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/pgnotify"
+	"bank/payment"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// approvalThreshold is the transfer amount above which the fixture requires approval
+var approvalThreshold = decimal.NewFromInt(100000)
+
+const clearingAccountID = 900000006
+
+type handlerFixture struct {
+	db      *sql.DB
+	handler *customer.Handler
+}
+
+type testHandlerFunc func(t *testing.T, handler *handlerFixture)
+
+func testHandler(t *testing.T, testFunc testHandlerFunc) {
+	testHandlerWithTransferEngine(t, transaction.EngineProcedure, testFunc)
+}
+
+func testHandlerWithTransferEngine(t *testing.T, transferEngine string, testFunc testHandlerFunc) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		testLogger := logger.NewLogger("debug")
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		listener, err := pgnotify.NewListener(testDB.DSN, "account_transactions", testLogger)
+		if err != nil {
+			t.Fatalf("failed to create listener: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go listener.Run(ctx)
+
+		testFunc(t, &handlerFixture{
+			db:      testDB.DB,
+			handler: newTestHandler(t, testDB.DB, ledger, transferEngine, listener, testLogger),
+		})
+	})
+}
+
+// newTestHandler wires the domains of the handler, the account and transaction domains on ledger
+func newTestHandler(t *testing.T, db *sql.DB, ledger store.Store, transferEngine string, listener *pgnotify.Listener, testLogger *logger.Logger) *customer.Handler {
+	t.Helper()
+	accountDomain, transactionDomain := newLedgerDomains(t, ledger, transferEngine, testLogger)
+
+	approvalDomain, err := approval.NewApprovalDomain(db, sqlc.New(db), transactionDomain, &config.Config{
+		ApprovalTransferThreshold: approvalThreshold,
+		ApprovalTTL:               time.Hour,
+		AdjustmentAccountID:       900000001,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create approval domain: %v", err)
+	}
+
+	interestDomain, err := interest.NewInterestDomain(db, sqlc.New(db), transactionDomain, &config.Config{
+		InterestExpenseAccountID: 900000002,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create interest domain: %v", err)
+	}
+
+	billingDomain, err := billing.NewBillingDomain(db, sqlc.New(db), transactionDomain, &config.Config{
+		CreditIncomeAccountID: 900000003,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create billing domain: %v", err)
+	}
+
+	paymentDomain, err := payment.NewPaymentDomain(db, sqlc.New(db), transactionDomain, approvalDomain, &config.Config{
+		ClearingAccountID:      clearingAccountID,
+		PaymentDebtorName:      "BANK",
+		PaymentDebtorAccount:   "900000006",
+		PaymentDebtorAgentBIC:  "BANKUS33XXX",
+		PaymentFileMaxPayments: 1000,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create payment domain: %v", err)
+	}
+
+	handler, err := customer.NewHandler(accountDomain, transactionDomain, approvalDomain, interestDomain, billingDomain, paymentDomain, listener, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	return handler
+}
@@ -2,50 +2,36 @@ package customer_test
 
 import (
 	"bank/account"
-	"bank/http/handler/customer"
-	"bank/internal/db/sqlc"
+	"bank/config"
 	"bank/internal/logger"
-	"bank/test"
+	"bank/store"
 	"bank/transaction"
 	"bytes"
 	"context"
-	"database/sql"
 	"net/http"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 )
 
-type handlerFixture struct {
-	db      *sql.DB
-	handler *customer.Handler
-}
-
-type testHandlerFunc func(t *testing.T, handler *handlerFixture)
-
-func testHandler(t *testing.T, testFunc testHandlerFunc) {
-	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
-		testLogger := logger.NewLogger("debug")
-		accountDomain, err := account.NewAccountDomain(testDB.DB, sqlc.New(testDB.DB), testLogger)
-		if err != nil {
-			t.Fatalf("failed to create account domain: %v", err)
-		}
-
-		transactionDomain, err := transaction.NewTransactionDomain(testDB.DB, sqlc.New(testDB.DB), testLogger)
-		if err != nil {
-			t.Fatalf("failed to create transaction domain: %v", err)
-		}
+// transferEngines are the transfer engines the transfer tests run against
+var transferEngines = []string{transaction.EngineProcedure, transaction.EngineGo}
 
-		handler, err := customer.NewHandler(accountDomain, transactionDomain, testLogger)
-		if err != nil {
-			t.Fatalf("failed to create handler: %v", err)
-		}
+// newLedgerDomains wires the account and transaction domains on ledger
+func newLedgerDomains(t *testing.T, ledger store.Store, transferEngine string, testLogger *logger.Logger) (*account.AccountDomain, *transaction.TransactionDomain) {
+	t.Helper()
+	accountDomain, err := account.NewAccountDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
 
-		testFunc(t, &handlerFixture{
-			db:      testDB.DB,
-			handler: handler,
-		})
-	})
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{
+		TransferEngine: transferEngine,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+	return accountDomain, transactionDomain
 }
 
 type requestParam struct {
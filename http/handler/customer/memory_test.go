@@ -0,0 +1,191 @@
+package customer_test
+
+import (
+	"bank/http/handler/customer"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/pgnotify"
+	"bank/store/memory"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// testMemoryHandler runs the handler on the in-memory ledger, no database is needed. Only the
+// account and transaction routes can be used, the domains that need the database are left out.
+func testMemoryHandler(t *testing.T, transferEngine string) (*customer.Handler, *memory.Store) {
+	t.Helper()
+	ledger := memory.New()
+	testLogger := logger.NewLogger("debug")
+	accountDomain, transactionDomain := newLedgerDomains(t, ledger, transferEngine, testLogger)
+	return customer.NewMemoryHandler(accountDomain, transactionDomain, &pgnotify.Listener{}, testLogger), ledger
+}
+
+// createMemoryAccount creates SAVINGS accounts without a balance on the in-memory ledger
+func createMemoryAccount(t *testing.T, ledger *memory.Store, ids ...int64) {
+	t.Helper()
+	tx, err := ledger.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.CreateAccount(context.Background(), sqlc.CreateAccountParams{ID: id, AccountType: "SAVINGS", CreditLimit: "0"}); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+}
+
+// creditMemoryAccount credits the account on the in-memory ledger and returns the transaction id
+func creditMemoryAccount(t *testing.T, ledger *memory.Store, id int64, amount string) int64 {
+	t.Helper()
+	tx, err := ledger.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	transaction, err := tx.CreateCreditTransaction(context.Background(), sqlc.CreateCreditTransactionParams{AccountID: id, Amount: decimal.RequireFromString(amount)})
+	if err != nil {
+		t.Fatalf("failed to create transaction: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return transaction.ID
+}
+
+// fundMemoryAccount credits the balance to the account and snapshots it, the way the database
+// tests seed the source of a transfer
+func fundMemoryAccount(t *testing.T, ledger *memory.Store, id int64, balance string) {
+	t.Helper()
+	transactionID := creditMemoryAccount(t, ledger, id, balance)
+	if err := ledger.CreateBalanceSnapshot(id, decimal.RequireFromString(balance), transactionID); err != nil {
+		t.Fatalf("failed to create balance snapshot: %v", err)
+	}
+}
+
+// listMemoryTransactions returns the transactions of the account on the in-memory ledger
+func listMemoryTransactions(t *testing.T, ledger *memory.Store, id int64) []sqlc.ListAccountTransactionsAfterIDRow {
+	t.Helper()
+	tx, err := ledger.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	transactions, err := tx.ListAccountTransactionsAfterID(context.Background(), sqlc.ListAccountTransactionsAfterIDParams{AccountID: id, PageSize: 1000})
+	if err != nil {
+		t.Fatalf("failed to list transactions: %v", err)
+	}
+	return transactions
+}
+
+// verifyMemoryTransfers checks that count transfers of amount moved from the source to the
+// destination account, each a DEBIT of the source and a CREDIT of the destination
+func verifyMemoryTransfers(t *testing.T, ledger *memory.Store, sourceAccountID, destAccountID int64, amount decimal.Decimal, count int) {
+	t.Helper()
+	credits := map[int64]decimal.Decimal{}
+	for _, transaction := range listMemoryTransactions(t, ledger, destAccountID) {
+		if transaction.TrxType == "CREDIT" && transaction.CounterpartyAccountID == sourceAccountID {
+			credits[transaction.TransferID.Int64] = transaction.Amount
+		}
+	}
+
+	transfers := 0
+	for _, transaction := range listMemoryTransactions(t, ledger, sourceAccountID) {
+		if transaction.TrxType != "DEBIT" || transaction.CounterpartyAccountID != destAccountID {
+			continue
+		}
+		transfers++
+		if !transaction.Amount.Equal(amount) {
+			t.Errorf("expected debit amount %s, got %s", amount, transaction.Amount)
+		}
+		if credit, ok := credits[transaction.TransferID.Int64]; !ok || !credit.Equal(amount) {
+			t.Errorf("expected a credit of %s in transfer %d, got %s", amount, transaction.TransferID.Int64, credit)
+		}
+	}
+	if transfers != count {
+		t.Errorf("expected %d transfers, got %d", count, transfers)
+	}
+}
+
+func TestInMemoryTransfer(t *testing.T) {
+	type step struct {
+		name           string
+		method         string
+		request        string
+		accountID      string
+		expectedStatus int
+		expectedBody   string
+	}
+
+	steps := []step{
+		{name: "create source", method: "POST", request: `{"account_id": 100, "initial_balance": "100"}`, expectedStatus: http.StatusCreated},
+		{name: "create destination", method: "POST", request: `{"account_id": 200, "initial_balance": "0.5"}`, expectedStatus: http.StatusCreated},
+		{name: "create credit", method: "POST", request: `{"account_id": 300, "account_type": "CREDIT", "initial_balance": "1", "credit_limit": "50"}`, expectedStatus: http.StatusCreated},
+		{name: "transfer", request: `{"source_account_id": 100, "destination_account_id": 200, "amount": "40.25"}`, expectedStatus: http.StatusOK},
+		{
+			name:           "insufficient funds",
+			request:        `{"source_account_id": 100, "destination_account_id": 200, "amount": "60"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INSUFFICIENT_FUNDS","message":"your account has insufficient funds"}}`,
+		},
+		{name: "spend the credit limit", request: `{"source_account_id": 300, "destination_account_id": 200, "amount": "51"}`, expectedStatus: http.StatusOK},
+		{
+			name:           "unknown account",
+			request:        `{"source_account_id": 100, "destination_account_id": 999, "amount": "1"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+		},
+		{
+			name:           "same account",
+			request:        `{"source_account_id": 100, "destination_account_id": 100, "amount": "1"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: Cannot transfer to the same account"}}`,
+		},
+		{name: "source balance", method: "GET", accountID: "100", expectedStatus: http.StatusOK, expectedBody: `{"account_id":100,"balance":"59.75"}`},
+		{name: "destination balance", method: "GET", accountID: "200", expectedStatus: http.StatusOK, expectedBody: `{"account_id":200,"balance":"91.75"}`},
+		{name: "credit balance", method: "GET", accountID: "300", expectedStatus: http.StatusOK, expectedBody: `{"account_id":300,"balance":"-50"}`},
+		{
+			name:           "unknown account balance",
+			method:         "GET",
+			accountID:      "999",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+		},
+	}
+
+	for _, engine := range transferEngines {
+		t.Run(engine, func(t *testing.T) {
+			handler, _ := testMemoryHandler(t, engine)
+
+			for _, s := range steps {
+				rr := httptest.NewRecorder()
+				switch s.method {
+				case "POST":
+					handler.CreateAccount()(rr, createRequest(t, "POST", "/accounts", s.request))
+				case "GET":
+					handler.GetAccountBalance()(rr, createRequest(t, "GET", "/accounts/{account_id}", "", requestParam{key: "account_id", value: s.accountID}))
+				default:
+					handler.CreateTransferFunds()(rr, createRequest(t, "POST", "/transactions", s.request))
+				}
+
+				if rr.Code != s.expectedStatus {
+					t.Errorf("%s: expected status %d, got %d: %s", s.name, s.expectedStatus, rr.Code, rr.Body.String())
+				}
+				if s.expectedBody != "" && rr.Body.String() != s.expectedBody {
+					t.Errorf("%s: expected body %s, got %s", s.name, s.expectedBody, rr.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package customer
+
+import (
+	"bank/entity"
+	"bank/http/middleware"
+	"bank/internal/request"
+	"bank/internal/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CreatePaymentRequest struct {
+	Amount                decimal.Decimal `json:"amount" validate:"required,decimal_required,decimal_positive,decimal_precision=2"`
+	CreditorName          string          `json:"creditor_name" validate:"required,max=140"`
+	CreditorAccount       string          `json:"creditor_account" validate:"required,max=34"`
+	CreditorAgent         string          `json:"creditor_agent" validate:"required,bic"`
+	RemittanceInformation string          `json:"remittance_information" validate:"max=140"`
+}
+
+type PaymentResponse struct {
+	PaymentID             uint64                       `json:"payment_id"`
+	AccountID             uint64                       `json:"account_id"`
+	Amount                decimal.Decimal              `json:"amount"`
+	CreditorName          string                       `json:"creditor_name"`
+	CreditorAccount       string                       `json:"creditor_account"`
+	CreditorAgent         string                       `json:"creditor_agent"`
+	RemittanceInformation string                       `json:"remittance_information,omitempty"`
+	Status                entity.ExternalPaymentStatus `json:"status"`
+	StatusReason          string                       `json:"status_reason,omitempty"`
+	ApprovalID            uint64                       `json:"approval_id,omitempty"`
+	CreatedAt             time.Time                    `json:"created_at"`
+	UpdatedAt             time.Time                    `json:"updated_at"`
+}
+
+func newPaymentResponse(p entity.ExternalPayment) PaymentResponse {
+	return PaymentResponse{
+		PaymentID:             p.ID,
+		AccountID:             p.AccountID,
+		Amount:                p.Amount,
+		CreditorName:          p.CreditorName,
+		CreditorAccount:       p.CreditorAccount,
+		CreditorAgent:         p.CreditorAgent,
+		RemittanceInformation: p.RemittanceInformation,
+		Status:                p.Status,
+		StatusReason:          p.StatusReason,
+		ApprovalID:            p.ApprovalRequestID,
+		CreatedAt:             p.CreatedAt,
+		UpdatedAt:             p.UpdatedAt,
+	}
+}
+
+// CreatePayment pays an external beneficiary. The amount leaves the account right away, the
+// payment is sent to the clearing partner with the next payment file. A payment above the approval
+// threshold is accepted as PENDING_APPROVAL and only leaves the account once it is approved.
+func (h *Handler) CreatePayment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		var req CreatePaymentRequest
+		if err := request.BindJSON(r, &req); err != nil {
+			response.JsonBindError(w, err)
+			return
+		}
+
+		payment, err := h.paymentDomain.CreatePayment(r.Context(), entity.CreateExternalPayment{
+			AccountID:             accountID,
+			Amount:                req.Amount,
+			CreditorName:          req.CreditorName,
+			CreditorAccount:       req.CreditorAccount,
+			CreditorAgent:         req.CreditorAgent,
+			RemittanceInformation: req.RemittanceInformation,
+			RequestedBy:           middleware.UserID(r.Context()),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrInsufficientFunds):
+				response.JsonError(w, http.StatusBadRequest, response.CodeInsufficientFunds, "your account has insufficient funds")
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+			case errors.Is(err, entity.ErrValidation):
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to create payment: %v", err)
+			}
+			return
+		}
+
+		if payment.Status == entity.ExternalPaymentStatusPendingApproval {
+			response.Json(w, http.StatusAccepted, newPaymentResponse(payment))
+			return
+		}
+		response.Json(w, http.StatusCreated, newPaymentResponse(payment))
+	}
+}
+
+func (h *Handler) ListPayments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		payments, err := h.paymentDomain.ListPayments(r.Context(), accountID)
+		if err != nil {
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to list payments: %v", err)
+			return
+		}
+
+		resp := make([]PaymentResponse, 0, len(payments))
+		for _, payment := range payments {
+			resp = append(resp, newPaymentResponse(payment))
+		}
+		response.Json(w, http.StatusOK, resp)
+	}
+}
+
+func (h *Handler) GetPayment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		paymentID, err := request.GetParamUint64(r, "payment_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid payment id")
+			return
+		}
+
+		payment, err := h.paymentDomain.GetPayment(r.Context(), accountID, paymentID)
+		if err != nil {
+			switch {
+			case errors.Is(err, entity.ErrDataNotFound):
+				response.JsonError(w, http.StatusNotFound, response.CodePaymentNotFound, "payment not found")
+			default:
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+				h.logger.Error(r.Context(), "failed to get payment: %v", err)
+			}
+			return
+		}
+
+		response.Json(w, http.StatusOK, newPaymentResponse(payment))
+	}
+}
@@ -0,0 +1,173 @@
+//go:build integration
+
+package customer_test
+
+import (
+	"bank/http/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCreatePayment(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accountID       string
+		userID          string
+		request         string // json
+		expectedStatus  int
+		expectedBody    string
+		expectedBalance string
+		waiting         int // payments waiting for approval
+	}{
+		{
+			name:      "success - funds moved to clearing",
+			accountID: "100",
+			request: `{
+				"amount": "40.50",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX",
+				"remittance_information": "invoice 42"
+			}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBalance: "59.5",
+		},
+		{
+			name:      "insufficient funds",
+			accountID: "100",
+			request: `{
+				"amount": "150",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX"
+			}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"error":{"code":"INSUFFICIENT_FUNDS","message":"your account has insufficient funds"}}`,
+			expectedBalance: "100",
+		},
+		{
+			name:      "validation error - invalid creditor agent",
+			accountID: "100",
+			request: `{
+				"amount": "10",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "not a bic"
+			}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"error":{"code":"VALIDATION_FAILED","message":"creditor agent must be a valid BIC","details":[{"field":"creditor_agent","rule":"bic","message":"creditor agent must be a valid BIC"}]}}`,
+			expectedBalance: "100",
+		},
+		{
+			name:      "validation error - too many decimal places",
+			accountID: "100",
+			request: `{
+				"amount": "10.001",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX"
+			}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"error":{"code":"VALIDATION_FAILED","message":"amount has too many decimal places (max 2)","details":[{"field":"amount","rule":"decimal_precision","message":"amount has too many decimal places (max 2)"}]}}`,
+			expectedBalance: "100",
+		},
+		{
+			name:      "above threshold - parked for approval",
+			accountID: "100",
+			userID:    "alice",
+			request: `{
+				"amount": "200000",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX"
+			}`,
+			expectedStatus:  http.StatusAccepted,
+			expectedBalance: "100",
+			waiting:         1,
+		},
+		{
+			name:      "above threshold - requester unknown",
+			accountID: "100",
+			request: `{
+				"amount": "200000",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX"
+			}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"error":{"code":"VALIDATION_FAILED","message":"validation error: requester is required"}}`,
+			expectedBalance: "100",
+		},
+		{
+			name:      "unknown account",
+			accountID: "999",
+			request: `{
+				"amount": "10",
+				"creditor_name": "Jane Doe",
+				"creditor_account": "DE89370400440532013000",
+				"creditor_agent": "COBADEFFXXX"
+			}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+			expectedBalance: "100",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testHandler(t, func(t *testing.T, handler *handlerFixture) {
+				statements := []string{
+					"INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES (100, 'SAVINGS', NOW(), NOW())",
+					"INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES (100, 100, 'CREDIT', NOW())",
+				}
+				for _, stmt := range statements {
+					if _, err := handler.db.Exec(stmt); err != nil {
+						t.Fatalf("failed to seed data: %v", err)
+					}
+				}
+
+				req := createRequest(t, http.MethodPost, "/accounts/"+tc.accountID+"/payments", tc.request, requestParam{key: "account_id", value: tc.accountID})
+				if tc.userID != "" {
+					req.Header.Set(middleware.UserIDHeader, tc.userID)
+				}
+				rec := httptest.NewRecorder()
+				middleware.Authenticate(handler.handler.CreatePayment()).ServeHTTP(rec, req)
+
+				if rec.Code != tc.expectedStatus {
+					t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+				}
+
+				if tc.expectedBody != "" && rec.Body.String() != tc.expectedBody {
+					t.Errorf("expected body %s, got %s", tc.expectedBody, rec.Body.String())
+				}
+
+				var balance string
+				if err := handler.db.QueryRow("SELECT get_account_balance(100, false)").Scan(&balance); err != nil {
+					t.Fatalf("failed to get balance: %v", err)
+				}
+				if !decimal.RequireFromString(balance).Equal(decimal.RequireFromString(tc.expectedBalance)) {
+					t.Errorf("expected balance %s, got %s", tc.expectedBalance, balance)
+				}
+
+				var pending int
+				if err := handler.db.QueryRow("SELECT count(*) FROM external_payments WHERE status = 'PENDING'").Scan(&pending); err != nil {
+					t.Fatalf("failed to count payments: %v", err)
+				}
+				if expected := map[bool]int{true: 1, false: 0}[tc.expectedStatus == http.StatusCreated]; pending != expected {
+					t.Errorf("expected %d pending payments, got %d", expected, pending)
+				}
+
+				var waiting int
+				if err := handler.db.QueryRow("SELECT count(*) FROM external_payments WHERE status = 'PENDING_APPROVAL' AND approval_request_id IS NOT NULL").Scan(&waiting); err != nil {
+					t.Fatalf("failed to count payments: %v", err)
+				}
+				if waiting != tc.waiting {
+					t.Errorf("expected %d payments waiting for approval, got %d", tc.waiting, waiting)
+				}
+			})
+		})
+	}
+}
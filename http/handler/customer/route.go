@@ -1,6 +1,7 @@
 package customer
 
 import (
+	"bank/http/middleware"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -8,8 +9,19 @@ import (
 
 func (h *Handler) RegisterRoutes(r *chi.Mux) http.Handler {
 	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authenticate)
+
 		r.Post("/accounts", h.CreateAccount())
 		r.Get("/accounts/{account_id}", h.GetAccountBalance())
+		r.Get("/accounts/{account_id}/events", h.AccountEvents())
+		r.Get("/accounts/{account_id}/interest", h.GetAccruedInterest())
+		r.Get("/accounts/{account_id}/statements", h.ListStatements())
+		r.Get("/accounts/{account_id}/statement", h.GetAccountStatement())
+		r.Get("/accounts/{account_id}/statement/{message}", h.GetISO20022Statement())
+
+		r.Post("/accounts/{account_id}/payments", h.CreatePayment())
+		r.Get("/accounts/{account_id}/payments", h.ListPayments())
+		r.Get("/accounts/{account_id}/payments/{payment_id}", h.GetPayment())
 
 		r.Post("/transactions", h.CreateTransferFunds())
 	})
@@ -0,0 +1,301 @@
+package customer
+
+import (
+	"bank/account"
+	"bank/entity"
+	"bank/internal/camt"
+	"bank/internal/request"
+	"bank/internal/response"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	statementFormatCSV  = "csv"
+	statementFormatJSON = "json"
+)
+
+// GetAccountStatement streams the statement of the account between the from and to dates
+// (inclusive, UTC) as CSV or JSON
+func (h *Handler) GetAccountStatement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		param, err := bindStatementPeriod(r, accountID)
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, err.Error())
+			return
+		}
+
+		var writer statementWriter
+		switch format := r.URL.Query().Get("format"); format {
+		case "", statementFormatJSON:
+			writer = &jsonStatementWriter{w: w}
+		case statementFormatCSV:
+			writer = &csvStatementWriter{w: w, csv: csv.NewWriter(w)}
+		default:
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "format must be csv or json")
+			return
+		}
+
+		h.streamStatement(w, r, param, writer)
+	}
+}
+
+// GetISO20022Statement streams the statement of the account between the from and to dates
+// (inclusive, UTC) as a camt.053 statement or a camt.052 intraday report. A report covering
+// today ends now.
+func (h *Handler) GetISO20022Statement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := request.GetParamUint64(r, "account_id")
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "invalid account id")
+			return
+		}
+
+		var messageType camt.MessageType
+		switch chi.URLParam(r, "message") {
+		case "camt.053":
+			messageType = camt.MessageTypeStatement
+		case "camt.052":
+			messageType = camt.MessageTypeReport
+		default:
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, "message must be camt.053 or camt.052")
+			return
+		}
+
+		param, err := bindStatementPeriod(r, accountID)
+		if err != nil {
+			response.JsonError(w, http.StatusBadRequest, response.CodeInvalidParameter, err.Error())
+			return
+		}
+
+		now := time.Now().UTC()
+		if messageType == camt.MessageTypeReport && param.To.After(now) {
+			param.To = now
+		}
+
+		xw := &xmlStatementWriter{w: w, filename: fmt.Sprintf("%s-%d-%s-%s.xml", chi.URLParam(r, "message"), accountID, param.From.Format(time.DateOnly), lastDay(param.To))}
+		xw.Writer, err = camt.NewWriter(w, messageType, camt.Options{
+			MessageID: fmt.Sprintf("%d-%s", accountID, now.Format("20060102T150405.000000")),
+			CreatedAt: now,
+			// Accounts are held in a single currency
+			Currency: entity.CurrencyCodeUSD,
+		})
+		if err != nil {
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to create camt writer: %v", err)
+			return
+		}
+
+		h.streamStatement(w, r, param, xw)
+	}
+}
+
+func (h *Handler) streamStatement(w http.ResponseWriter, r *http.Request, param entity.GetAccountStatement, writer statementWriter) {
+	err := h.accountDomain.StreamStatement(r.Context(), param, writer)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrValidation):
+			response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+		case errors.Is(err, entity.ErrNoRows):
+			response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+		default:
+			// Once the statement started the status is already sent, the body is cut short
+			h.logger.Error(r.Context(), "failed to stream statement: %v", err)
+			if !writer.Started() {
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			}
+		}
+	}
+}
+
+// bindStatementPeriod reads the inclusive from and to dates of the query into a period
+// with an exclusive end
+func bindStatementPeriod(r *http.Request, accountID uint64) (entity.GetAccountStatement, error) {
+	query := r.URL.Query()
+	from, err := time.Parse(time.DateOnly, query.Get("from"))
+	if err != nil {
+		return entity.GetAccountStatement{}, errors.New("from must be a date formatted as YYYY-MM-DD")
+	}
+
+	to, err := time.Parse(time.DateOnly, query.Get("to"))
+	if err != nil {
+		return entity.GetAccountStatement{}, errors.New("to must be a date formatted as YYYY-MM-DD")
+	}
+
+	return entity.GetAccountStatement{
+		AccountID: accountID,
+		From:      from,
+		To:        to.AddDate(0, 0, 1),
+	}, nil
+}
+
+// lastDay turns the exclusive end of a period back into the inclusive date the client asked for
+func lastDay(to time.Time) string {
+	return to.Add(-time.Nanosecond).Format(time.DateOnly)
+}
+
+func statementFilename(s entity.AccountStatement, format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s", s.AccountID, s.From.Format(time.DateOnly), lastDay(s.To), format)
+}
+
+// statementWriter tells whether the response status has been sent already
+type statementWriter interface {
+	account.StatementWriter
+	Started() bool
+}
+
+type jsonStatementWriter struct {
+	w       http.ResponseWriter
+	started bool
+	entries int
+}
+
+func (j *jsonStatementWriter) Started() bool {
+	return j.started
+}
+
+// StatementResponse is the JSON statement, jsonStatementWriter writes it piece by piece
+type StatementResponse struct {
+	AccountID      uint64                   `json:"account_id"`
+	From           string                   `json:"from"`
+	To             string                   `json:"to"`
+	OpeningBalance decimal.Decimal          `json:"opening_balance"`
+	Transactions   []StatementEntryResponse `json:"transactions"`
+	ClosingBalance decimal.Decimal          `json:"closing_balance"`
+}
+
+type StatementEntryResponse struct {
+	TransactionID uint64          `json:"transaction_id"`
+	TransferID    *int64          `json:"transfer_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Type          entity.TrxType  `json:"type"`
+	Amount        decimal.Decimal `json:"amount"`
+	Balance       decimal.Decimal `json:"balance"`
+}
+
+func (j *jsonStatementWriter) Begin(s entity.AccountStatement) error {
+	j.w.Header().Set("Content-Type", "application/json")
+	j.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statementFilename(s, statementFormatJSON)))
+	j.w.WriteHeader(http.StatusOK)
+	j.started = true
+
+	from, _ := json.Marshal(s.From.Format(time.DateOnly))
+	to, _ := json.Marshal(lastDay(s.To))
+	opening, _ := json.Marshal(s.OpeningBalance)
+	_, err := fmt.Fprintf(j.w, `{"account_id":%d,"from":%s,"to":%s,"opening_balance":%s,"transactions":[`, s.AccountID, from, to, opening)
+	return err
+}
+
+func (j *jsonStatementWriter) WriteEntry(e entity.StatementEntry) error {
+	entry := StatementEntryResponse{
+		TransactionID: e.ID,
+		CreatedAt:     e.CreatedAt.UTC(),
+		Type:          e.TrxType,
+		Amount:        e.Amount,
+		Balance:       e.Balance,
+	}
+	if e.TransferID.Valid {
+		entry.TransferID = &e.TransferID.Int64
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if j.entries > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.entries++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonStatementWriter) End(s entity.AccountStatement) error {
+	closing, _ := json.Marshal(s.ClosingBalance)
+	_, err := fmt.Fprintf(j.w, `],"closing_balance":%s}`, closing)
+	return err
+}
+
+// csvStatementWriter writes one row per transaction between an opening and a closing balance row
+type csvStatementWriter struct {
+	w       http.ResponseWriter
+	csv     *csv.Writer
+	started bool
+}
+
+func (c *csvStatementWriter) Started() bool {
+	return c.started
+}
+
+func (c *csvStatementWriter) Begin(s entity.AccountStatement) error {
+	c.w.Header().Set("Content-Type", "text/csv")
+	c.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statementFilename(s, statementFormatCSV)))
+	c.w.WriteHeader(http.StatusOK)
+	c.started = true
+
+	if err := c.csv.Write([]string{"date", "transaction_id", "transfer_id", "type", "amount", "balance"}); err != nil {
+		return err
+	}
+	return c.csv.Write([]string{s.From.Format(time.DateOnly), "", "", "OPENING_BALANCE", "", s.OpeningBalance.String()})
+}
+
+func (c *csvStatementWriter) WriteEntry(e entity.StatementEntry) error {
+	transferID := ""
+	if e.TransferID.Valid {
+		transferID = strconv.FormatInt(e.TransferID.Int64, 10)
+	}
+	return c.csv.Write([]string{
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		strconv.FormatUint(e.ID, 10),
+		transferID,
+		string(e.TrxType),
+		e.Amount.String(),
+		e.Balance.String(),
+	})
+}
+
+func (c *csvStatementWriter) End(s entity.AccountStatement) error {
+	if err := c.csv.Write([]string{lastDay(s.To), "", "", "CLOSING_BALANCE", "", s.ClosingBalance.String()}); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// xmlStatementWriter sends the response headers before the camt document starts
+type xmlStatementWriter struct {
+	*camt.Writer
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (x *xmlStatementWriter) Started() bool {
+	return x.started
+}
+
+func (x *xmlStatementWriter) Begin(s entity.AccountStatement) error {
+	x.w.Header().Set("Content-Type", "application/xml")
+	x.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", x.filename))
+	x.w.WriteHeader(http.StatusOK)
+	x.started = true
+	return x.Writer.Begin(s)
+}
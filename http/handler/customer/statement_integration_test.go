@@ -0,0 +1,204 @@
+//go:build integration
+
+package customer_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// seedStatementData opens account 500 with 1000 in August, it receives 250.5 and pays 100
+// in September, and receives 50 in October after a balance snapshot
+func seedStatementData(t *testing.T, db *sql.DB) {
+	statements := []string{
+		"INSERT INTO accounts (id, created_at, updated_at) VALUES (500, '2026-08-01', '2026-08-01')",
+		"INSERT INTO accounts (id, created_at, updated_at) VALUES (501, '2026-08-01', '2026-08-01')",
+		"INSERT INTO transfers (id, from_account_id, to_account_id, created_at) VALUES (70, 501, 500, '2026-09-03T10:00:00Z'), (71, 500, 501, '2026-09-20T08:30:00Z')",
+		`INSERT INTO transactions (id, account_id, transfer_id, amount, trx_type, created_at) VALUES
+			(1, 500, NULL, 1000, 'CREDIT', '2026-08-15T00:00:00Z'),
+			(2, 500, 70, 250.5, 'CREDIT', '2026-09-03T10:00:00Z'),
+			(3, 500, 71, 100, 'DEBIT', '2026-09-20T08:30:00Z'),
+			(4, 500, NULL, 50, 'CREDIT', '2026-10-02T00:00:00Z')`,
+		"INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) VALUES (500, 1150.5, 3, '2026-10-01')",
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to seed data: %v", err)
+		}
+	}
+}
+
+func TestGetAccountStatement(t *testing.T) {
+	testCases := []struct {
+		name           string
+		accountID      string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success - json",
+			accountID:      "500",
+			query:          "?from=2026-09-01&to=2026-09-30",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"account_id":500,"from":"2026-09-01","to":"2026-09-30","opening_balance":"1000","transactions":[` +
+				`{"transaction_id":2,"transfer_id":70,"created_at":"2026-09-03T10:00:00Z","type":"CREDIT","amount":"250.5","balance":"1250.5"},` +
+				`{"transaction_id":3,"transfer_id":71,"created_at":"2026-09-20T08:30:00Z","type":"DEBIT","amount":"100","balance":"1150.5"}` +
+				`],"closing_balance":"1150.5"}`,
+		},
+		{
+			name:           "success - csv",
+			accountID:      "500",
+			query:          "?from=2026-09-01&to=2026-10-31&format=csv",
+			expectedStatus: http.StatusOK,
+			expectedBody: "date,transaction_id,transfer_id,type,amount,balance\n" +
+				"2026-09-01,,,OPENING_BALANCE,,1000\n" +
+				"2026-09-03T10:00:00Z,2,70,CREDIT,250.5,1250.5\n" +
+				"2026-09-20T08:30:00Z,3,71,DEBIT,100,1150.5\n" +
+				"2026-10-02T00:00:00Z,4,,CREDIT,50,1200.5\n" +
+				"2026-10-31,,,CLOSING_BALANCE,,1200.5\n",
+		},
+		{
+			name:           "success - no transactions in period",
+			accountID:      "500",
+			query:          "?from=2026-07-01&to=2026-07-31",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"account_id":500,"from":"2026-07-01","to":"2026-07-31","opening_balance":"0","transactions":[],"closing_balance":"0"}`,
+		},
+		{
+			name:           "invalid date",
+			accountID:      "500",
+			query:          "?from=2026-09&to=2026-09-30",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_PARAMETER","message":"from must be a date formatted as YYYY-MM-DD"}}`,
+		},
+		{
+			name:           "to before from",
+			accountID:      "500",
+			query:          "?from=2026-09-30&to=2026-09-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: to must be after from"}}`,
+		},
+		{
+			name:           "unknown format",
+			accountID:      "500",
+			query:          "?from=2026-09-01&to=2026-09-30&format=pdf",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_PARAMETER","message":"format must be csv or json"}}`,
+		},
+		{
+			name:           "account not found",
+			accountID:      "999",
+			query:          "?from=2026-09-01&to=2026-09-30",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				seedStatementData(t, handler.db)
+
+				req := createRequest(t, "GET", "/accounts/{account_id}/statement"+tc.query, "", requestParam{key: "account_id", value: tc.accountID})
+
+				rr := httptest.NewRecorder()
+				handler.handler.GetAccountStatement()(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+				}
+
+				if rr.Body.String() != tc.expectedBody {
+					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+				}
+			})
+		})
+	}
+
+	// The closing balance of a period ending today ties out with get_account_balance
+	testHandler(t, func(t *testing.T, handler *handlerFixture) {
+		seedStatementData(t, handler.db)
+
+		var balance decimal.Decimal
+		if err := handler.db.QueryRow("SELECT get_account_balance(500)").Scan(&balance); err != nil {
+			t.Fatalf("failed to get account balance: %v", err)
+		}
+		if !balance.Equal(decimal.RequireFromString("1200.5")) {
+			t.Errorf("expected get_account_balance 1200.5, got %s", balance)
+		}
+	})
+}
+
+func TestGetISO20022Statement(t *testing.T) {
+	testCases := []struct {
+		name           string
+		message        string
+		query          string
+		expectedStatus int
+		expectedParts  []string
+	}{
+		{
+			name:           "success - camt.053",
+			message:        "camt.053",
+			query:          "?from=2026-09-01&to=2026-09-30",
+			expectedStatus: http.StatusOK,
+			expectedParts: []string{
+				`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"><BkToCstmrStmt>`,
+				`<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">1000</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+				`<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">1150.5</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+				`<NtryRef>2</NtryRef><Amt Ccy="USD">250.5</Amt><CdtDbtInd>CRDT</CdtDbtInd>`,
+				`<RltdPties><DbtrAcct><Id><Othr><Id>501</Id></Othr></Id></DbtrAcct></RltdPties>`,
+				`<NtryRef>3</NtryRef><Amt Ccy="USD">100</Amt><CdtDbtInd>DBIT</CdtDbtInd>`,
+				`</Stmt></BkToCstmrStmt></Document>`,
+			},
+		},
+		{
+			name:           "success - camt.052",
+			message:        "camt.052",
+			query:          "?from=2026-10-01&to=2026-10-02",
+			expectedStatus: http.StatusOK,
+			expectedParts: []string{
+				`<BkToCstmrAcctRpt>`,
+				`<Cd>ITBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">1200.5</Amt>`,
+				`</Rpt></BkToCstmrAcctRpt></Document>`,
+			},
+		},
+		{
+			name:           "unknown message",
+			message:        "camt.054",
+			query:          "?from=2026-09-01&to=2026-09-30",
+			expectedStatus: http.StatusBadRequest,
+			expectedParts:  []string{`{"error":{"code":"INVALID_PARAMETER","message":"message must be camt.053 or camt.052"}}`},
+		},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				seedStatementData(t, handler.db)
+
+				req := createRequest(t, "GET", "/accounts/{account_id}/statement/{message}"+tc.query, "",
+					requestParam{key: "account_id", value: "500"}, requestParam{key: "message", value: tc.message})
+
+				rr := httptest.NewRecorder()
+				handler.handler.GetISO20022Statement()(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+				}
+
+				for _, part := range tc.expectedParts {
+					if !strings.Contains(rr.Body.String(), part) {
+						t.Errorf("expected body to contain %s, got %s", part, rr.Body.String())
+					}
+				}
+			})
+		})
+	}
+}
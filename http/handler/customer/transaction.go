@@ -2,10 +2,12 @@ package customer
 
 import (
 	"bank/entity"
+	"bank/http/middleware"
 	"bank/internal/request"
 	"bank/internal/response"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -20,25 +22,32 @@ func (h *Handler) CreateTransferFunds() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req CreateTransferFundsRequest
 		if err := request.BindJSON(r, &req); err != nil {
-			response.JsonError(w, http.StatusBadRequest, "invalid request")
+			response.JsonBindError(w, err)
 			return
 		}
 
-		_, err := h.transactionDomain.CreateTransferFunds(r.Context(), entity.CreateTransferFundsParams{
+		param := entity.CreateTransferFundsParams{
 			SourceAccountID:      req.SourceAccountID,
 			DestinationAccountID: req.DestinationAccountID,
 			Amount:               req.Amount,
-		})
+		}
+
+		if h.approvalDomain.RequiresApproval(req.Amount) {
+			h.requestTransferApproval(w, r, param)
+			return
+		}
+
+		_, err := h.transactionDomain.CreateTransferFunds(r.Context(), param)
 		if err != nil {
 			switch {
 			case errors.Is(err, entity.ErrInsufficientFunds):
-				response.JsonError(w, http.StatusBadRequest, "your account has insufficient funds")
+				response.JsonError(w, http.StatusBadRequest, response.CodeInsufficientFunds, "your account has insufficient funds")
 			case errors.Is(err, entity.ErrDataNotFound):
-				response.JsonError(w, http.StatusBadRequest, "invalid account")
+				response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
 			case errors.Is(err, entity.ErrValidation):
-				response.JsonError(w, http.StatusBadRequest, err.Error())
+				response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
 			default:
-				response.JsonError(w, http.StatusInternalServerError, "it's not you, it's us. please contact support")
+				response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
 				h.logger.Error(r.Context(), "failed to create transfer funds: %v", err)
 			}
 			return
@@ -47,3 +56,35 @@ func (h *Handler) CreateTransferFunds() http.HandlerFunc {
 		response.StatusOnly(w, http.StatusOK)
 	}
 }
+
+type TransferApprovalResponse struct {
+	ApprovalID uint64                `json:"approval_id"`
+	Status     entity.ApprovalStatus `json:"status"`
+	ExpiresAt  time.Time             `json:"expires_at"`
+}
+
+// requestTransferApproval parks a transfer above the approval threshold until a second person approves it
+func (h *Handler) requestTransferApproval(w http.ResponseWriter, r *http.Request, param entity.CreateTransferFundsParams) {
+	approval, err := h.approvalDomain.RequestTransfer(r.Context(), entity.CreateTransferApproval{
+		CreateTransferFundsParams: param,
+		RequestedBy:               middleware.UserID(r.Context()),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrDataNotFound):
+			response.JsonError(w, http.StatusBadRequest, response.CodeAccountNotFound, "invalid account")
+		case errors.Is(err, entity.ErrValidation):
+			response.JsonError(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+		default:
+			response.JsonError(w, http.StatusInternalServerError, response.CodeInternal, "it's not you, it's us. please contact support")
+			h.logger.Error(r.Context(), "failed to request transfer approval: %v", err)
+		}
+		return
+	}
+
+	response.Json(w, http.StatusAccepted, TransferApprovalResponse{
+		ApprovalID: approval.ID,
+		Status:     approval.Status,
+		ExpiresAt:  approval.ExpiresAt,
+	})
+}
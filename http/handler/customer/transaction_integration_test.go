@@ -0,0 +1,547 @@
+//go:build integration
+
+package customer_test
+
+import (
+	"bank/http/middleware"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCreateTransferFunds_Postgres(t *testing.T) {
+	testCases := []struct {
+		name           string
+		request        string // json
+		setupDB        func(t *testing.T, handler *handlerFixture)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success - valid transfer",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "50.123456"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				// Create source account with sufficient balance
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
+				if err != nil {
+					t.Fatalf("failed to create source account: %v", err)
+				}
+
+				// Create destination account
+				_, err = handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
+				if err != nil {
+					t.Fatalf("failed to create destination account: %v", err)
+				}
+
+				// Create initial transaction for source account
+				var transactionID uint64
+				err = handler.db.QueryRow(`
+					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
+				`, 100, "100.000000").Scan(&transactionID)
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				// Create balance snapshot for source account
+				_, err = handler.db.Exec(`
+					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
+					VALUES ($1, $2, $3, NOW())
+				`, 100, "100.000000", transactionID)
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "invalid request - malformed JSON",
+			request: `{
+				"source_account_id": "invalid",
+				"destination_account_id": 200,
+				"amount": "50.123456"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST","message":"invalid JSON: json: cannot unmarshal string into Go struct field CreateTransferFundsRequest.source_account_id of type uint64"}}`,
+		},
+		{
+			name: "validation error - missing source_account_id",
+			request: `{
+				"destination_account_id": 200,
+				"amount": "50.123456"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"source account id is required","details":[{"field":"source_account_id","rule":"required","message":"source account id is required"}]}}`,
+		},
+		{
+			name: "validation error - missing destination_account_id",
+			request: `{
+				"source_account_id": 100,
+				"amount": "50.123456"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"destination account id is required","details":[{"field":"destination_account_id","rule":"required","message":"destination account id is required"}]}}`,
+		},
+		{
+			name: "validation error - missing amount",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount is required","details":[{"field":"amount","rule":"decimal_required","message":"amount is required"}]}}`,
+		},
+		{
+			name: "validation error - negative amount",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "-50.123456"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount must be greater than 0","details":[{"field":"amount","rule":"decimal_positive","message":"amount must be greater than 0"}]}}`,
+		},
+		{
+			name: "validation error - zero amount",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "0"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount is required","details":[{"field":"amount","rule":"decimal_required","message":"amount is required"}]}}`,
+		},
+		{
+			name: "validation error - too many decimal places",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "50.1234567"
+			}`,
+			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount has too many decimal places (max 6)","details":[{"field":"amount","rule":"decimal_precision","message":"amount has too many decimal places (max 6)"}]}}`,
+		},
+		{
+			name: "insufficient funds",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "10000.000000"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				// Create source account with insufficient balance
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
+				if err != nil {
+					t.Fatalf("failed to create source account: %v", err)
+				}
+
+				// Create destination account
+				_, err = handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
+				if err != nil {
+					t.Fatalf("failed to create destination account: %v", err)
+				}
+
+				// Create initial transaction for source account with low balance
+				var transactionID uint64
+				err = handler.db.QueryRow(`
+					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
+				`, 100, "100.000000").Scan(&transactionID)
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				// Create balance snapshot for source account
+				_, err = handler.db.Exec(`
+					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
+					VALUES ($1, $2, $3, NOW())
+				`, 100, "100.000000", transactionID)
+				if err != nil {
+					t.Fatalf("failed to create balance snapshot: %v", err)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INSUFFICIENT_FUNDS","message":"your account has insufficient funds"}}`,
+		},
+		{
+			name: "invalid account - source account does not exist",
+			request: `{
+				"source_account_id": 999,
+				"destination_account_id": 200,
+				"amount": "50.123456"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				// Create destination account only
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
+				if err != nil {
+					t.Fatalf("failed to create destination account: %v", err)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+		},
+		{
+			name: "invalid account - destination account does not exist",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 999,
+				"amount": "50.123456"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				// Create source account only
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
+				if err != nil {
+					t.Fatalf("failed to create source account: %v", err)
+				}
+
+				// Create initial transaction for source account
+				var transactionID uint64
+				err = handler.db.QueryRow(`
+					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
+				`, 100, "100.000000").Scan(&transactionID)
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				// Create balance snapshot for source account
+				_, err = handler.db.Exec(`
+					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
+					VALUES ($1, $2, $3, NOW())
+				`, 100, "100.000000", transactionID)
+				if err != nil {
+					t.Fatalf("failed to create balance snapshot: %v", err)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
+		},
+		{
+			name: "same account transfer",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 100,
+				"amount": "50.123456"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				// Create account
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
+				if err != nil {
+					t.Fatalf("failed to create account: %v", err)
+				}
+
+				// Create initial transaction
+				var transactionID uint64
+				err = handler.db.QueryRow(`
+					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
+				`, 100, "100.000000").Scan(&transactionID)
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				// Create balance snapshot
+				_, err = handler.db.Exec(`
+					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
+					VALUES ($1, $2, $3, NOW())
+				`, 100, "100.000000", transactionID)
+				if err != nil {
+					t.Fatalf("failed to create balance snapshot: %v", err)
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: Cannot transfer to the same account"}}`,
+		},
+		{
+			name: "unexpected database error with a comma in its message",
+			request: `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "50"
+			}`,
+			setupDB: func(t *testing.T, handler *handlerFixture) {
+				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW()), ($2, NOW(), NOW())", 100, 200)
+				if err != nil {
+					t.Fatalf("failed to create accounts: %v", err)
+				}
+
+				_, err = handler.db.Exec("INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES ($1, $2, 'CREDIT', NOW())", 100, "100.000000")
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				// transfer_funds catches the error and returns it as SQLERRM
+				_, err = handler.db.Exec(`
+					CREATE FUNCTION reject_transfer() RETURNS TRIGGER LANGUAGE plpgsql AS $$
+					BEGIN
+						RAISE EXCEPTION 'transfers are frozen, try again later';
+					END;
+					$$;
+					CREATE TRIGGER reject_transfer BEFORE INSERT ON transfers FOR EACH ROW EXECUTE FUNCTION reject_transfer();
+				`)
+				if err != nil {
+					t.Fatalf("failed to create trigger: %v", err)
+				}
+				t.Cleanup(func() {
+					handler.db.Exec("DROP TRIGGER IF EXISTS reject_transfer ON transfers; DROP FUNCTION IF EXISTS reject_transfer;")
+				})
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"it's not you, it's us. please contact support"}}`,
+		},
+	}
+
+	for _, engine := range transferEngines {
+		t.Run(engine, func(t *testing.T) {
+			for _, tc := range testCases {
+				testHandlerWithTransferEngine(t, engine, func(t *testing.T, handler *handlerFixture) {
+					t.Run(tc.name, func(t *testing.T) {
+						// Setup database state
+						tc.setupDB(t, handler)
+
+						req := createRequest(t, "POST", "/transactions", tc.request)
+						rr := httptest.NewRecorder()
+						handler.handler.CreateTransferFunds()(rr, req)
+
+						if tc.expectedStatus != 0 && rr.Code != tc.expectedStatus {
+							t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+						}
+
+						if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+							t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+						}
+
+						if tc.expectedStatus != http.StatusOK {
+							return
+						}
+
+						// For successful transfers, verify the database state
+						verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("50.123456"))
+					})
+				})
+			}
+		})
+	}
+}
+
+// verifySuccessfulTransfer checks that a successful transfer created the correct database records
+func verifySuccessfulTransfer(t *testing.T, handler *handlerFixture, sourceAccountID, destAccountID uint64, amount decimal.Decimal) {
+	// Check that a transfer record was created
+	var transferID int64
+	err := handler.db.QueryRow(`
+		SELECT id FROM transfers 
+		WHERE from_account_id = $1 AND to_account_id = $2
+	`, sourceAccountID, destAccountID).Scan(&transferID)
+	if err != nil {
+		t.Fatalf("failed to query transfer record: %v", err)
+	}
+
+	if transferID == 0 {
+		t.Error("expected transfer record to be created")
+	}
+
+	// Check that debit transaction was created for source account
+	var debitAmount decimal.Decimal
+	var debitTrxType string
+	err = handler.db.QueryRow(`
+		SELECT amount, trx_type FROM transactions 
+		WHERE account_id = $1 AND transfer_id = $2 AND trx_type = 'DEBIT'
+	`, sourceAccountID, transferID).Scan(&debitAmount, &debitTrxType)
+	if err != nil {
+		t.Fatalf("failed to query debit transaction: %v", err)
+	}
+
+	if !debitAmount.Equal(amount) {
+		t.Errorf("expected debit amount %s, got %s", amount.String(), debitAmount.String())
+	}
+
+	// Check that credit transaction was created for destination account
+	var creditAmount decimal.Decimal
+	var creditTrxType string
+	err = handler.db.QueryRow(`
+		SELECT amount, trx_type FROM transactions 
+		WHERE account_id = $1 AND transfer_id = $2 AND trx_type = 'CREDIT'
+	`, destAccountID, transferID).Scan(&creditAmount, &creditTrxType)
+	if err != nil {
+		t.Fatalf("failed to query credit transaction: %v", err)
+	}
+
+	if !creditAmount.Equal(amount) {
+		t.Errorf("expected credit amount %s, got %s", amount.String(), creditAmount.String())
+	}
+}
+
+func TestCreateTransferFunds_Concurrent_Postgres(t *testing.T) {
+	for _, engine := range transferEngines {
+		testHandlerWithTransferEngine(t, engine, func(t *testing.T, handler *handlerFixture) {
+			t.Run(engine+"/concurrent transfer", func(t *testing.T) {
+				type account struct {
+					id      uint64
+					balance decimal.Decimal
+				}
+				accounts := []account{
+					{id: 100, balance: decimal.NewFromInt(500)},
+					{id: 200, balance: decimal.NewFromInt(200)},
+				}
+
+				// Create accounts
+				for _, acc := range accounts {
+					handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", acc.id)
+
+					// Create initial transaction for source account
+					var transactionID uint64
+					err := handler.db.QueryRow(`
+				INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+				VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
+			`, acc.id, acc.balance.String()).Scan(&transactionID)
+					if err != nil {
+						t.Fatalf("failed to create initial transaction: %v", err)
+					}
+
+					// Create balance snapshot for source account
+					_, err = handler.db.Exec(`
+				INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
+				VALUES ($1, $2, $3, NOW())
+			`, acc.id, acc.balance.String(), transactionID)
+					if err != nil {
+						t.Fatalf("failed to create balance snapshot: %v", err)
+					}
+				}
+
+				// Create 3 concurrent transfers
+				// 1. Transfer from account 100 to account 200, amount 250
+				// 2. Transfer from account 100 to account 200, amount 250
+				// 3. Transfer from account 100 to account 200, amount 250
+				// Two transfers should success, one should fail
+				wg := sync.WaitGroup{}
+				wg.Add(3)
+
+				request := `{
+				"source_account_id": 100,
+				"destination_account_id": 200,
+				"amount": "250.000000"
+			}`
+
+				success, failed := 0, 0
+				for i := 0; i < 3; i++ {
+					go func() {
+						defer wg.Done()
+						req := createRequest(t, "POST", "/transactions", request)
+						rr := httptest.NewRecorder()
+						handler.handler.CreateTransferFunds()(rr, req)
+						if rr.Code == http.StatusOK {
+							success++
+						} else {
+							failed++
+						}
+					}()
+				}
+
+				wg.Wait()
+
+				if success != 2 || failed != 1 {
+					t.Errorf("expected 2 success and 1 failed, got %d success and %d failed", success, failed)
+				}
+
+				// Check that the database state is correct
+				verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("250.000000"))
+				verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("250.000000"))
+			})
+		})
+	}
+}
+
+func TestCreateTransferFunds_RequiresApproval(t *testing.T) {
+	testCases := []struct {
+		name           string
+		userID         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "above threshold - parked for approval",
+			userID:         "alice",
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "above threshold - requester unknown",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: requester is required"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		testHandler(t, func(t *testing.T, handler *handlerFixture) {
+			t.Run(tc.name, func(t *testing.T) {
+				for _, accountID := range []uint64{100, 200} {
+					_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", accountID)
+					if err != nil {
+						t.Fatalf("failed to create account: %v", err)
+					}
+				}
+				_, err := handler.db.Exec(`
+					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
+					VALUES ($1, $2, 'CREDIT', NOW())
+				`, 100, "500000.000000")
+				if err != nil {
+					t.Fatalf("failed to create initial transaction: %v", err)
+				}
+
+				req := createRequest(t, "POST", "/transactions", `{
+					"source_account_id": 100,
+					"destination_account_id": 200,
+					"amount": "200000"
+				}`)
+				if tc.userID != "" {
+					req.Header.Set(middleware.UserIDHeader, tc.userID)
+				}
+				rr := httptest.NewRecorder()
+				middleware.Authenticate(handler.handler.CreateTransferFunds()).ServeHTTP(rr, req)
+
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+				}
+
+				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+				}
+
+				// No funds may move before the approval
+				var transfers int
+				if err := handler.db.QueryRow("SELECT COUNT(*) FROM transfers").Scan(&transfers); err != nil {
+					t.Fatalf("failed to count transfers: %v", err)
+				}
+				if transfers != 0 {
+					t.Errorf("expected no transfers, got %d", transfers)
+				}
+
+				if tc.expectedStatus != http.StatusAccepted {
+					return
+				}
+
+				var status, requestedBy string
+				err = handler.db.QueryRow("SELECT status, requested_by FROM approval_requests WHERE source_account_id = $1", 100).Scan(&status, &requestedBy)
+				if err != nil {
+					t.Fatalf("failed to query approval request: %v", err)
+				}
+				if status != "PENDING_APPROVAL" || requestedBy != tc.userID {
+					t.Errorf("expected PENDING_APPROVAL requested by %s, got %s requested by %s", tc.userID, status, requestedBy)
+				}
+			})
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package customer_test
 
 import (
+	"bank/store/memory"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -13,7 +14,7 @@ func TestCreateTransferFunds(t *testing.T) {
 	testCases := []struct {
 		name           string
 		request        string // json
-		setupDB        func(t *testing.T, handler *handlerFixture)
+		setup          func(t *testing.T, ledger *memory.Store)
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -24,37 +25,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "50.123456"
 			}`,
-			setupDB: func(t *testing.T, handler *handlerFixture) {
-				// Create source account with sufficient balance
-				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
-				if err != nil {
-					t.Fatalf("failed to create source account: %v", err)
-				}
-
-				// Create destination account
-				_, err = handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
-				if err != nil {
-					t.Fatalf("failed to create destination account: %v", err)
-				}
-
-				// Create initial transaction for source account
-				var transactionID uint64
-				err = handler.db.QueryRow(`
-					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
-				`, 100, "100.000000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
-
-				// Create balance snapshot for source account
-				_, err = handler.db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 100, "100.000000", transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
+			setup: func(t *testing.T, ledger *memory.Store) {
+				createMemoryAccount(t, ledger, 100, 200)
+				fundMemoryAccount(t, ledger, 100, "100")
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -65,9 +38,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "50.123456"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST","message":"invalid JSON: json: cannot unmarshal string into Go struct field CreateTransferFundsRequest.source_account_id of type uint64"}}`,
 		},
 		{
 			name: "validation error - missing source_account_id",
@@ -75,9 +48,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "50.123456"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"source account id is required","details":[{"field":"source_account_id","rule":"required","message":"source account id is required"}]}}`,
 		},
 		{
 			name: "validation error - missing destination_account_id",
@@ -85,9 +58,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"source_account_id": 100,
 				"amount": "50.123456"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"destination account id is required","details":[{"field":"destination_account_id","rule":"required","message":"destination account id is required"}]}}`,
 		},
 		{
 			name: "validation error - missing amount",
@@ -95,9 +68,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"source_account_id": 100,
 				"destination_account_id": 200
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount is required","details":[{"field":"amount","rule":"decimal_required","message":"amount is required"}]}}`,
 		},
 		{
 			name: "validation error - negative amount",
@@ -106,9 +79,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "-50.123456"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount must be greater than 0","details":[{"field":"amount","rule":"decimal_positive","message":"amount must be greater than 0"}]}}`,
 		},
 		{
 			name: "validation error - zero amount",
@@ -117,9 +90,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "0"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount is required","details":[{"field":"amount","rule":"decimal_required","message":"amount is required"}]}}`,
 		},
 		{
 			name: "validation error - too many decimal places",
@@ -128,9 +101,9 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "50.1234567"
 			}`,
-			setupDB:        func(t *testing.T, handler *handlerFixture) {},
+			setup:          func(t *testing.T, ledger *memory.Store) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid request"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"amount has too many decimal places (max 6)","details":[{"field":"amount","rule":"decimal_precision","message":"amount has too many decimal places (max 6)"}]}}`,
 		},
 		{
 			name: "insufficient funds",
@@ -139,40 +112,12 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "10000.000000"
 			}`,
-			setupDB: func(t *testing.T, handler *handlerFixture) {
-				// Create source account with insufficient balance
-				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
-				if err != nil {
-					t.Fatalf("failed to create source account: %v", err)
-				}
-
-				// Create destination account
-				_, err = handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
-				if err != nil {
-					t.Fatalf("failed to create destination account: %v", err)
-				}
-
-				// Create initial transaction for source account with low balance
-				var transactionID uint64
-				err = handler.db.QueryRow(`
-					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
-				`, 100, "100.000000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
-
-				// Create balance snapshot for source account
-				_, err = handler.db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 100, "100.000000", transactionID)
-				if err != nil {
-					t.Fatalf("failed to create balance snapshot: %v", err)
-				}
+			setup: func(t *testing.T, ledger *memory.Store) {
+				createMemoryAccount(t, ledger, 100, 200)
+				fundMemoryAccount(t, ledger, 100, "100")
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"your account has insufficient funds"}`,
+			expectedBody:   `{"error":{"code":"INSUFFICIENT_FUNDS","message":"your account has insufficient funds"}}`,
 		},
 		{
 			name: "invalid account - source account does not exist",
@@ -181,15 +126,12 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 200,
 				"amount": "50.123456"
 			}`,
-			setupDB: func(t *testing.T, handler *handlerFixture) {
+			setup: func(t *testing.T, ledger *memory.Store) {
 				// Create destination account only
-				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 200)
-				if err != nil {
-					t.Fatalf("failed to create destination account: %v", err)
-				}
+				createMemoryAccount(t, ledger, 200)
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid account"}`,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
 		},
 		{
 			name: "invalid account - destination account does not exist",
@@ -198,34 +140,13 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 999,
 				"amount": "50.123456"
 			}`,
-			setupDB: func(t *testing.T, handler *handlerFixture) {
+			setup: func(t *testing.T, ledger *memory.Store) {
 				// Create source account only
-				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
-				if err != nil {
-					t.Fatalf("failed to create source account: %v", err)
-				}
-
-				// Create initial transaction for source account
-				var transactionID uint64
-				err = handler.db.QueryRow(`
-					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
-				`, 100, "100.000000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
-
-				// Create balance snapshot for source account
-				_, err = handler.db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 100, "100.000000", transactionID)
-				if err != nil {
-					t.Fatalf("failed to create balance snapshot: %v", err)
-				}
+				createMemoryAccount(t, ledger, 100)
+				fundMemoryAccount(t, ledger, 100, "100")
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"invalid account"}`,
+			expectedBody:   `{"error":{"code":"ACCOUNT_NOT_FOUND","message":"invalid account"}}`,
 		},
 		{
 			name: "same account transfer",
@@ -234,170 +155,73 @@ func TestCreateTransferFunds(t *testing.T) {
 				"destination_account_id": 100,
 				"amount": "50.123456"
 			}`,
-			setupDB: func(t *testing.T, handler *handlerFixture) {
-				// Create account
-				_, err := handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", 100)
-				if err != nil {
-					t.Fatalf("failed to create account: %v", err)
-				}
-
-				// Create initial transaction
-				var transactionID uint64
-				err = handler.db.QueryRow(`
-					INSERT INTO transactions (account_id, amount, trx_type, created_at) 
-					VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
-				`, 100, "100.000000").Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
-
-				// Create balance snapshot
-				_, err = handler.db.Exec(`
-					INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-					VALUES ($1, $2, $3, NOW())
-				`, 100, "100.000000", transactionID)
-				if err != nil {
-					t.Fatalf("failed to create balance snapshot: %v", err)
-				}
+			setup: func(t *testing.T, ledger *memory.Store) {
+				createMemoryAccount(t, ledger, 100)
+				fundMemoryAccount(t, ledger, 100, "100")
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"validation error: Cannot transfer to the same account"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"validation error: Cannot transfer to the same account"}}`,
 		},
 	}
 
-	for _, tc := range testCases {
-		testHandler(t, func(t *testing.T, handler *handlerFixture) {
-			t.Run(tc.name, func(t *testing.T) {
-				// Setup database state
-				tc.setupDB(t, handler)
+	for _, engine := range transferEngines {
+		t.Run(engine, func(t *testing.T) {
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					handler, ledger := testMemoryHandler(t, engine)
+					tc.setup(t, ledger)
 
-				req := createRequest(t, "POST", "/transactions", tc.request)
-				rr := httptest.NewRecorder()
-				handler.handler.CreateTransferFunds()(rr, req)
+					req := createRequest(t, "POST", "/transactions", tc.request)
+					rr := httptest.NewRecorder()
+					handler.CreateTransferFunds()(rr, req)
 
-				if tc.expectedStatus != 0 && rr.Code != tc.expectedStatus {
-					t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
-				}
+					if tc.expectedStatus != 0 && rr.Code != tc.expectedStatus {
+						t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+					}
 
-				if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
-					t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
-				}
+					if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+						t.Errorf("expected body %s, got %s", tc.expectedBody, rr.Body.String())
+					}
 
-				if tc.expectedStatus != http.StatusOK {
-					return
-				}
+					if tc.expectedStatus != http.StatusOK {
+						return
+					}
 
-				// For successful transfers, verify the database state
-				verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("50.123456"))
-			})
+					verifyMemoryTransfers(t, ledger, 100, 200, decimal.RequireFromString("50.123456"), 1)
+				})
+			}
 		})
 	}
 }
 
-// verifySuccessfulTransfer checks that a successful transfer created the correct database records
-func verifySuccessfulTransfer(t *testing.T, handler *handlerFixture, sourceAccountID, destAccountID uint64, amount decimal.Decimal) {
-	// Check that a transfer record was created
-	var transferID int64
-	err := handler.db.QueryRow(`
-		SELECT id FROM transfers 
-		WHERE from_account_id = $1 AND to_account_id = $2
-	`, sourceAccountID, destAccountID).Scan(&transferID)
-	if err != nil {
-		t.Fatalf("failed to query transfer record: %v", err)
-	}
-
-	if transferID == 0 {
-		t.Error("expected transfer record to be created")
-	}
-
-	// Check that debit transaction was created for source account
-	var debitAmount decimal.Decimal
-	var debitTrxType string
-	err = handler.db.QueryRow(`
-		SELECT amount, trx_type FROM transactions 
-		WHERE account_id = $1 AND transfer_id = $2 AND trx_type = 'DEBIT'
-	`, sourceAccountID, transferID).Scan(&debitAmount, &debitTrxType)
-	if err != nil {
-		t.Fatalf("failed to query debit transaction: %v", err)
-	}
-
-	if !debitAmount.Equal(amount) {
-		t.Errorf("expected debit amount %s, got %s", amount.String(), debitAmount.String())
-	}
-
-	// Check that credit transaction was created for destination account
-	var creditAmount decimal.Decimal
-	var creditTrxType string
-	err = handler.db.QueryRow(`
-		SELECT amount, trx_type FROM transactions 
-		WHERE account_id = $1 AND transfer_id = $2 AND trx_type = 'CREDIT'
-	`, destAccountID, transferID).Scan(&creditAmount, &creditTrxType)
-	if err != nil {
-		t.Fatalf("failed to query credit transaction: %v", err)
-	}
-
-	if !creditAmount.Equal(amount) {
-		t.Errorf("expected credit amount %s, got %s", amount.String(), creditAmount.String())
-	}
-}
-
 func TestCreateTransferFunds_Concurrent(t *testing.T) {
-	testHandler(t, func(t *testing.T, handler *handlerFixture) {
-		t.Run("concurrent transfer", func(t *testing.T) {
-			type account struct {
-				id      uint64
-				balance decimal.Decimal
-			}
-			accounts := []account{
-				{id: 100, balance: decimal.NewFromInt(500)},
-				{id: 200, balance: decimal.NewFromInt(200)},
-			}
-
-			// Create accounts
-			for _, acc := range accounts {
-				handler.db.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", acc.id)
-
-				// Create initial transaction for source account
-				var transactionID uint64
-				err := handler.db.QueryRow(`
-				INSERT INTO transactions (account_id, amount, trx_type, created_at) 
-				VALUES ($1, $2, 'CREDIT', NOW()) RETURNING id
-			`, acc.id, acc.balance.String()).Scan(&transactionID)
-				if err != nil {
-					t.Fatalf("failed to create initial transaction: %v", err)
-				}
-
-				// Create balance snapshot for source account
-				_, err = handler.db.Exec(`
-				INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at) 
-				VALUES ($1, $2, $3, NOW())
-			`, acc.id, acc.balance.String(), transactionID)
-				if err != nil {
-					t.Fatalf("failed to create balance snapshot: %v", err)
-				}
-			}
-
-			// Create 3 concurrent transfers
-			// 1. Transfer from account 100 to account 200, amount 250
-			// 2. Transfer from account 100 to account 200, amount 250
-			// 3. Transfer from account 100 to account 200, amount 250
-			// Two transfers should success, one should fail
-			wg := sync.WaitGroup{}
-			wg.Add(3)
-
+	for _, engine := range transferEngines {
+		t.Run(engine+"/concurrent transfer", func(t *testing.T) {
+			handler, ledger := testMemoryHandler(t, engine)
+			createMemoryAccount(t, ledger, 100, 200)
+			fundMemoryAccount(t, ledger, 100, "500")
+			fundMemoryAccount(t, ledger, 200, "200")
+
+			// 3 concurrent transfers of 250 from account 100 to account 200, two should succeed
+			// and one should fail
 			request := `{
 				"source_account_id": 100,
 				"destination_account_id": 200,
 				"amount": "250.000000"
 			}`
 
+			var mu sync.Mutex
+			var wg sync.WaitGroup
 			success, failed := 0, 0
-			for i := 0; i < 3; i++ {
+			for range 3 {
+				wg.Add(1)
 				go func() {
 					defer wg.Done()
-					req := createRequest(t, "POST", "/transactions", request)
 					rr := httptest.NewRecorder()
-					handler.handler.CreateTransferFunds()(rr, req)
+					handler.CreateTransferFunds()(rr, createRequest(t, "POST", "/transactions", request))
+
+					mu.Lock()
+					defer mu.Unlock()
 					if rr.Code == http.StatusOK {
 						success++
 					} else {
@@ -405,16 +229,13 @@ func TestCreateTransferFunds_Concurrent(t *testing.T) {
 					}
 				}()
 			}
-
 			wg.Wait()
 
 			if success != 2 || failed != 1 {
 				t.Errorf("expected 2 success and 1 failed, got %d success and %d failed", success, failed)
 			}
 
-			// Check that the database state is correct
-			verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("250.000000"))
-			verifySuccessfulTransfer(t, handler, 100, 200, decimal.RequireFromString("250.000000"))
+			verifyMemoryTransfers(t, ledger, 100, 200, decimal.RequireFromString("250"), 2)
 		})
-	})
+	}
 }
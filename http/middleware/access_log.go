@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bank/internal/logger"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// AccessLog logs every request once it is served, with its status, size and latency. It makes
+// the request context collect the request-scoped log fields, so its lines and those logged while
+// serving carry the principal and the accounts of the request. It must run after RequestID.
+func AccessLog(log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := logger.WithRequestFields(r.Context())
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			entry := log.WithFields(map[string]interface{}{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      status,
+				"bytes":       ww.BytesWritten(),
+				"latency_ms":  float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr": r.RemoteAddr,
+			})
+			if status >= http.StatusInternalServerError {
+				entry.Error(ctx, "%s %s %d", r.Method, r.URL.Path, status)
+				return
+			}
+			entry.Info(ctx, "%s %s %d", r.Method, r.URL.Path, status)
+		})
+	}
+}
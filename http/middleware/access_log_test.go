@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"bank/http/middleware"
+	"bank/internal/logger"
+	"bank/internal/requestid"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewLoggerWithWriter(&out, "info")
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(middleware.AccessLog(log))
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Authenticate)
+		r.Post("/accounts/{account_id}/transfers", func(w http.ResponseWriter, r *http.Request) {
+			logger.AddAccountIDs(r.Context(), 2, 1)
+			log.Info(r.Context(), "transferring")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts/1/transfers", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	req.Header.Set(middleware.UserIDHeader, "alice")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var lines []map[string]interface{}
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var line map[string]interface{}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("failed to decode log line: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected the handler line and the access log line, got %v", lines)
+	}
+
+	// Both lines carry the fields of the request, the access log one those set while serving it
+	for _, line := range lines {
+		if line["request_id"] != "req-1" {
+			t.Errorf("expected request_id req-1, got %v", line["request_id"])
+		}
+		if line["route"] != "/accounts/{account_id}/transfers" {
+			t.Errorf("expected the route pattern, got %v", line["route"])
+		}
+		if line["principal"] != "alice" {
+			t.Errorf("expected principal alice, got %v", line["principal"])
+		}
+		accountIDs, _ := line["account_ids"].([]interface{})
+		if !slices.Equal(accountIDs, []interface{}{1.0, 2.0}) {
+			t.Errorf("expected account_ids [1 2], got %v", line["account_ids"])
+		}
+	}
+
+	access := lines[1]
+	if access["status"] != 201.0 || access["method"] != http.MethodPost || access["bytes"] != 2.0 {
+		t.Errorf("expected status, method and size in the access log, got %v", access)
+	}
+	if _, ok := access["latency_ms"].(float64); !ok {
+		t.Errorf("expected latency_ms in the access log, got %v", access)
+	}
+}
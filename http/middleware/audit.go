@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bank/audit"
+	"bank/entity"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/requestid"
+	"bank/internal/response"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// auditPayloadLimit bounds the part of the request body kept in an audit record
+const auditPayloadLimit = 64 << 10
+
+// Audit records every mutating request to a route in the audit trail, failed and refused ones
+// included: its caller, route, body, status and the address it came from. The caller is the
+// X-User-ID header, which TrustGateway only lets through from the gateway. A RECEIVED record is
+// written before the request is served, and a request it can't be written for is refused with
+// 503, so no change is made without a record. The record of the outcome follows once the request
+// is served and its changes are committed, a failure to write it is logged and counted in
+// bank_audit_write_failures_total. It must run after RequestID.
+func Audit(auditDomain *audit.AuditDomain, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// No route, no handler runs
+			pattern := routePattern(r)
+			if pattern == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			occurredAt := time.Now()
+			// A body that fails to read fails the handler as well, the record keeps what was read
+			payload, _ := io.ReadAll(io.LimitReader(r.Body, auditPayloadLimit))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(payload), r.Body), r.Body}
+
+			record := entity.CreateAuditRecord{
+				OccurredAt: occurredAt,
+				Actor:      userIDFromHeader(r),
+				Action:     r.Method + " " + pattern,
+				Resource:   r.URL.Path,
+				RequestID:  requestid.FromContext(r.Context()),
+				IP:         remoteIP(r),
+				Payload:    payload,
+			}
+			if err := auditDomain.Received(r.Context(), record); err != nil {
+				log.Error(r.Context(), "failed to record audit of %s %s: %v", record.Action, r.URL.Path, err)
+				metrics.AuditWriteFailed(metrics.APIHTTP)
+				response.JsonError(w, http.StatusServiceUnavailable, response.CodeAuditUnavailable, "the request can't be recorded, please retry later")
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			record.Status = strconv.Itoa(status)
+			record.Outcome = auditOutcome(status)
+			if _, err := auditDomain.Record(context.WithoutCancel(r.Context()), record); err != nil {
+				log.Error(r.Context(), "failed to record audit of %s %s: %v", record.Action, r.URL.Path, err)
+				metrics.AuditWriteFailed(metrics.APIHTTP)
+			}
+		})
+	}
+}
+
+// routePattern is the pattern of the route the request is going to, or an empty string when it
+// matches none
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, path)
+}
+
+func auditOutcome(status int) entity.AuditOutcome {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return entity.AuditOutcomeFailed
+	case status >= http.StatusBadRequest:
+		return entity.AuditOutcomeRejected
+	default:
+		return entity.AuditOutcomeSucceeded
+	}
+}
+
+// remoteIP is the address of the peer, the service isn't behind a proxy that would forward it
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
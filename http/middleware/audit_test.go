@@ -0,0 +1,123 @@
+package middleware_test
+
+import (
+	"bank/audit"
+	"bank/entity"
+	"bank/http/middleware"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/test"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAudit(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		testLogger := logger.NewLogger("debug")
+		queries := sqlc.New(testDB.DB)
+		auditDomain, err := audit.NewAuditDomain(testDB.DB, queries, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create audit domain: %v", err)
+		}
+
+		r := chi.NewRouter()
+		r.Use(middleware.RequestID)
+		r.Use(middleware.Audit(auditDomain, testLogger))
+		r.Get("/accounts/{account_id}", func(w http.ResponseWriter, r *http.Request) {})
+		r.Post("/transactions", func(w http.ResponseWriter, r *http.Request) {
+			// The handler reads the whole body, the audited part included
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"amount":"10"}` {
+				t.Errorf("expected the handler to read the body, got %q", body)
+			}
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		})
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/1", nil))
+		req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{"amount":"10"}`))
+		req.Header.Set(middleware.UserIDHeader, "alice")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/unknown", nil))
+
+		records, err := queries.ListAuditRecordsAfterSeq(context.Background(), sqlc.ListAuditRecordsAfterSeqParams{AfterSeq: 0, BatchSize: 10})
+		if err != nil {
+			t.Fatalf("failed to list audit records: %v", err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("expected only the transfer to be audited, got %d records", len(records))
+		}
+
+		// The transfer is recorded before it runs, then with its outcome
+		for i, expected := range []struct {
+			status  string
+			outcome entity.AuditOutcome
+		}{
+			{"", entity.AuditOutcomeReceived},
+			{"422", entity.AuditOutcomeRejected},
+		} {
+			record := records[i]
+			if record.Actor != "alice" || record.Action != "POST /transactions" || record.Resource != "/transactions" {
+				t.Errorf("expected the caller and route of the transfer, got %+v", record)
+			}
+			if string(record.Payload) != `{"amount":"10"}` || record.Status != expected.status || record.Outcome != string(expected.outcome) {
+				t.Errorf("expected the payload and %s outcome of the transfer, got %+v", expected.outcome, record)
+			}
+			if record.Ip != "192.0.2.1" || record.RequestID == "" || record.RequestID != rr.Header().Get("X-Request-ID") {
+				t.Errorf("expected the address and request id of the transfer, got %+v", record)
+			}
+		}
+	})
+}
+
+func TestAudit_WriteFailure(t *testing.T) {
+	// A closed database fails every write of the audit trail
+	db, err := sql.Open("postgres", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	testLogger := logger.NewLogger("debug")
+	auditDomain, err := audit.NewAuditDomain(db, sqlc.New(db), testLogger)
+	if err != nil {
+		t.Fatalf("failed to create audit domain: %v", err)
+	}
+
+	served := false
+	r := chi.NewRouter()
+	r.Use(middleware.Audit(auditDomain, testLogger))
+	r.Post("/transactions", func(w http.ResponseWriter, r *http.Request) {
+		served = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	// A request that can't be recorded doesn't run
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{"amount":"10"}`)))
+	if rr.Code != http.StatusServiceUnavailable || served {
+		t.Errorf("expected the request to be refused with 503 before it is served, got %d, served %v", rr.Code, served)
+	}
+	if !strings.Contains(rr.Body.String(), `"code":"AUDIT_UNAVAILABLE"`) {
+		t.Errorf("expected an AUDIT_UNAVAILABLE error, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`bank_audit_write_failures_total{api="http"} 1`,
+		`bank_audit_write_failures_total{api="grpc"} 0`,
+	} {
+		if !strings.Contains(rr.Body.String(), line+"\n") {
+			t.Errorf("expected %s in:\n%s", line, rr.Body.String())
+		}
+	}
+}
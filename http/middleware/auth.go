@@ -1 +1,122 @@
 package middleware
+
+import (
+	"bank/internal/gateway"
+	"bank/internal/logger"
+	"bank/internal/response"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// UserIDHeader carries the identity of the caller, set by the gateway in front of the service. It
+// is only trusted on connections from the gateway, see TrustGateway.
+const UserIDHeader = "X-User-ID"
+
+// UserRolesHeader carries the roles of the caller separated by commas, set by the gateway along
+// with X-User-ID and trusted the same way.
+const UserRolesHeader = "X-User-Roles"
+
+// RoleApprover may request adjustments and approve or reject the requests of others
+const RoleApprover = "approver"
+
+type contextKey string
+
+const (
+	userIDKey    contextKey = "user_id"
+	userRolesKey contextKey = "user_roles"
+)
+
+// TrustGateway drops the X-User-ID and X-User-Roles headers of requests that don't come from the
+// gateway networks, so a client reaching the service directly can't pick its identity. It must
+// run before every middleware and handler that reads the headers.
+func TrustGateway(networks gateway.Networks) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if (r.Header.Get(UserIDHeader) != "" || r.Header.Get(UserRolesHeader) != "") && !networks.Contains(r.RemoteAddr) {
+				r.Header.Del(UserIDHeader)
+				r.Header.Del(UserRolesHeader)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authenticate stores the caller identity from the X-User-ID header and its roles from the
+// X-User-Roles header in the request context, and records the identity as the principal of the
+// request logs. Requests without the identity pass through anonymously, without roles. The headers
+// are only as trustworthy as TrustGateway makes them.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := userIDFromHeader(r)
+		if userID != "" {
+			logger.SetPrincipal(r.Context(), userID)
+			ctx := WithUserID(r.Context(), userID)
+			r = r.WithContext(WithUserRoles(ctx, userRolesFromHeader(r)...))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireUser rejects requests that carry no caller identity. It must run after Authenticate.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserID(r.Context()) == "" {
+			response.JsonError(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole rejects requests whose caller doesn't have role. It must run after RequireUser.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(UserRoles(r.Context()), role) {
+				response.JsonError(w, http.StatusForbidden, response.CodeForbidden, "caller doesn't have the "+role+" role")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// userIDFromHeader returns the caller identity of the X-User-ID header, or an empty string
+func userIDFromHeader(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(UserIDHeader))
+}
+
+// userRolesFromHeader returns the roles of the X-User-Roles header
+func userRolesFromHeader(r *http.Request) []string {
+	roles := []string{}
+	for _, role := range strings.Split(r.Header.Get(UserRolesHeader), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// WithUserID returns a copy of ctx carrying the caller identity
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the caller identity stored in ctx, or an empty string
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
+
+// WithUserRoles returns a copy of ctx carrying the roles of the caller
+func WithUserRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, userRolesKey, roles)
+}
+
+// UserRoles returns the roles of the caller stored in ctx
+func UserRoles(ctx context.Context) []string {
+	roles, _ := ctx.Value(userRolesKey).([]string)
+	return roles
+}
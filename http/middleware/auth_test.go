@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"bank/http/middleware"
+	"bank/internal/gateway"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestTrustGateway(t *testing.T) {
+	networks, err := gateway.ParseNetworks([]string{"10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatalf("failed to parse networks: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		expected   string
+		roles      []string
+	}{
+		{name: "gateway network", remoteAddr: "10.1.2.3:5000", expected: "alice", roles: []string{"approver", "auditor"}},
+		{name: "gateway address", remoteAddr: "[::1]:5000", expected: "alice", roles: []string{"approver", "auditor"}},
+		{name: "direct client", remoteAddr: "192.0.2.1:5000", expected: ""},
+		{name: "unparsable address", remoteAddr: "pipe", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var userID string
+			var roles []string
+			handler := middleware.TrustGateway(networks)(middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userID = middleware.UserID(r.Context())
+				roles = middleware.UserRoles(r.Context())
+			})))
+
+			req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set(middleware.UserIDHeader, "alice")
+			req.Header.Set(middleware.UserRolesHeader, "approver, auditor")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if userID != tc.expected {
+				t.Errorf("expected user %q, got %q", tc.expected, userID)
+			}
+			if !slices.Equal(roles, tc.roles) {
+				t.Errorf("expected roles %v, got %v", tc.roles, roles)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	testCases := []struct {
+		name     string
+		roles    string
+		expected int
+	}{
+		{name: "approver", roles: "approver", expected: http.StatusOK},
+		{name: "one of several roles", roles: "auditor,approver", expected: http.StatusOK},
+		{name: "other role", roles: "auditor", expected: http.StatusForbidden},
+		{name: "no role", roles: "", expected: http.StatusForbidden},
+	}
+
+	handler := middleware.Authenticate(middleware.RequireUser(middleware.RequireRole(middleware.RoleApprover)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/approvals/1/approve", nil)
+			req.Header.Set(middleware.UserIDHeader, "alice")
+			req.Header.Set(middleware.UserRolesHeader, tc.roles)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expected {
+				t.Errorf("expected status %d, got %d: %s", tc.expected, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
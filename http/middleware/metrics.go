@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"bank/internal/metrics"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute labels the requests no route matched, their paths would each make a series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and latency of requests by chi route pattern and status. The
+// pattern is only complete once the routing is done, so it is read after the request is served.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.ObserveRequest(r.Method, route, status, time.Since(start))
+	})
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"bank/http/middleware"
+	"bank/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(middleware.Metrics)
+	r.Group(func(r chi.Router) {
+		r.Get("/accounts/{account_id}", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("{}"))
+		})
+		r.Post("/transactions", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+	})
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/accounts/1"},
+		{http.MethodGet, "/accounts/2"},
+		{http.MethodPost, "/transactions"},
+		{http.MethodGet, "/unknown/3"},
+	}
+	for _, req := range requests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+
+	expected := []string{
+		`bank_http_requests_total{method="GET",route="/accounts/{account_id}",status="200"} 2`,
+		`bank_http_requests_total{method="POST",route="/transactions",status="400"} 1`,
+		`bank_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`bank_http_request_duration_seconds_count{method="GET",route="/accounts/{account_id}",status="200"} 2`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %s in:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"bank/internal/requestid"
+	"net/http"
+)
+
+// RequestID keeps the X-Request-ID of the request, or generates one, stores it in the request
+// context and echoes it on the response. A request that already has its id keeps it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestid.FromContext(r.Context()) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -0,0 +1,196 @@
+// Package openapi describes the HTTP API as an OpenAPI 3.0 document. The schemas are generated
+// from the request and response types of the handlers, so the document follows them, and
+// ValidateResponse checks actual responses against it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeXML  = "application/xml"
+	contentTypeCSV  = "text/csv"
+	contentTypeSSE  = "text/event-stream"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem maps lower case HTTP methods to their operation
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []*Parameter               `json:"parameters,omitempty"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]*ResponseObject `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type ResponseObject struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var pathParam = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Build generates the document from the operations
+func Build() *Document {
+	g := newSchemaGenerator()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Bank API",
+			Description: "Amounts and balances are decimal strings.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]*PathItem{},
+	}
+
+	for _, op := range operations {
+		item, ok := doc.Paths[op.Path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[op.Path] = item
+		}
+
+		o := &OperationObject{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Tags:        []string{op.Tag},
+			Responses:   map[string]*ResponseObject{},
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+			o.Parameters = append(o.Parameters, &Parameter{Name: match[1], In: "path", Required: true, Schema: pathParamSchema(match[1])})
+		}
+		o.Parameters = append(o.Parameters, op.Parameters...)
+		if op.Tag == tagAdmin {
+			o.Parameters = append(o.Parameters, &Parameter{
+				Name:        "X-User-ID",
+				In:          "header",
+				Description: "Identity of the caller, set by the gateway",
+				Required:    true,
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+
+		if op.Request != nil {
+			o.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{op.requestContentType(): {Schema: g.schemaOf(reflect.TypeOf(op.Request))}},
+			}
+		}
+
+		responses := op.Responses
+		if op.Method != http.MethodGet {
+			responses = append(slices.Clip(responses), auditUnavailable)
+		}
+		for _, r := range responses {
+			resp := &ResponseObject{Description: r.Description}
+			if r.Body != nil {
+				resp.Content = map[string]*MediaType{r.contentType(): {Schema: g.schemaOf(reflect.TypeOf(r.Body))}}
+			}
+			for contentType, body := range r.Alternatives {
+				resp.Content[contentType] = &MediaType{Schema: g.schemaOf(reflect.TypeOf(body))}
+			}
+			o.Responses[strconv.Itoa(r.Status)] = resp
+		}
+
+		(*item)[strings.ToLower(op.Method)] = o
+	}
+
+	doc.Components.Schemas = g.components
+	return doc
+}
+
+// pathParamSchema types the path parameters, ids are positive integers
+func pathParamSchema(name string) *Schema {
+	switch name {
+	case "message":
+		return &Schema{Type: "string", Enum: []string{"camt.053", "camt.052"}}
+	case "message_id":
+		return &Schema{Type: "string"}
+	}
+	one := 1
+	return &Schema{Type: "integer", Format: "int64", Minimum: &one}
+}
+
+// Operation finds the operation of a route pattern as registered on the router
+func (d *Document) Operation(method, pattern string) (*OperationObject, bool) {
+	item, ok := d.Paths[pattern]
+	if !ok {
+		return nil, false
+	}
+	op, ok := (*item)[strings.ToLower(method)]
+	return op, ok
+}
+
+// Routes lists the documented routes as "METHOD /pattern"
+func (d *Document) Routes() []string {
+	routes := []string{}
+	for path, item := range d.Paths {
+		for method := range *item {
+			routes = append(routes, strings.ToUpper(method)+" "+path)
+		}
+	}
+	slices.Sort(routes)
+	return routes
+}
+
+var (
+	specOnce sync.Once
+	spec     []byte
+)
+
+// Handler serves the document as JSON
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		specOnce.Do(func() {
+			spec, _ = json.Marshal(Build())
+		})
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		w.Write(spec)
+	}
+}
@@ -0,0 +1,522 @@
+package openapi_test
+
+import (
+	"bank/account"
+	"bank/approval"
+	"bank/billing"
+	"bank/config"
+	"bank/http/handler/admin"
+	"bank/http/handler/customer"
+	"bank/http/openapi"
+	"bank/interest"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/pgnotify"
+	"bank/internal/server"
+	"bank/outbox"
+	"bank/payment"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"bank/webhook"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	adjustmentAccountID = 900000001
+	clearingAccountID   = 900000006
+)
+
+// newRouter registers every handler the way cmd/web does, with a recorder in front
+func newRouter(t *testing.T, db *sql.DB, listener *pgnotify.Listener, recorder *exchangeRecorder) (*chi.Mux, *payment.PaymentDomain, *webhook.WebhookDomain) {
+	testLogger := logger.NewLogger("error")
+	queries := sqlc.New(db)
+	cfg := &config.Config{
+		ApprovalTransferThreshold: decimal.NewFromInt(100000),
+		ApprovalTTL:               time.Hour,
+		AdjustmentAccountID:       adjustmentAccountID,
+		InterestExpenseAccountID:  900000002,
+		CreditIncomeAccountID:     900000003,
+		ClearingAccountID:         clearingAccountID,
+		PaymentDebtorName:         "BANK",
+		PaymentDebtorAccount:      "900000006",
+		PaymentDebtorAgentBIC:     "BANKUS33XXX",
+		PaymentFileMaxPayments:    1000,
+		WebhookTimeout:            time.Second,
+		WebhookMaxAttempts:        3,
+		WebhookBackoffBase:        time.Second,
+		WebhookBackoffMax:         time.Minute,
+		WebhookBatchSize:          10,
+		OutboxBatchSize:           100,
+		OutboxMaxAttempts:         3,
+		OutboxBackoffBase:         time.Second,
+		OutboxBackoffMax:          time.Minute,
+	}
+
+	ledger, err := store.NewPostgres(db)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+	approvalDomain, err := approval.NewApprovalDomain(db, queries, transactionDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create approval domain: %v", err)
+	}
+	interestDomain, err := interest.NewInterestDomain(db, queries, transactionDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create interest domain: %v", err)
+	}
+	billingDomain, err := billing.NewBillingDomain(db, queries, transactionDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create billing domain: %v", err)
+	}
+	paymentDomain, err := payment.NewPaymentDomain(db, queries, transactionDomain, approvalDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create payment domain: %v", err)
+	}
+	webhookDomain, err := webhook.NewWebhookDomain(db, queries, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create webhook domain: %v", err)
+	}
+
+	customerHandler, err := customer.NewHandler(accountDomain, transactionDomain, approvalDomain, interestDomain, billingDomain, paymentDomain, listener, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create customer handler: %v", err)
+	}
+	adminHandler, err := admin.NewHandler(approvalDomain, interestDomain, paymentDomain, webhookDomain, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create admin handler: %v", err)
+	}
+
+	r := chi.NewRouter()
+	if recorder != nil {
+		r.Use(recorder.middleware)
+	}
+	server.NewServer(":0", r, testLogger)
+	customerHandler.RegisterRoutes(r)
+	adminHandler.RegisterRoutes(r)
+	r.Get("/openapi.json", openapi.Handler())
+	return r, paymentDomain, webhookDomain
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	db, err := sql.Open("postgres", "host=localhost")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Walking the routes needs neither a database nor a running listener
+	r, _, _ := newRouter(t, db, &pgnotify.Listener{}, nil)
+
+	routes := []string{}
+	err = chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+	slices.Sort(routes)
+
+	documented := openapi.Build().Routes()
+	for _, route := range routes {
+		if !slices.Contains(documented, route) {
+			t.Errorf("route %s is not documented", route)
+		}
+	}
+	for _, route := range documented {
+		if !slices.Contains(routes, route) {
+			t.Errorf("documented route %s is not registered", route)
+		}
+	}
+}
+
+func TestDocumentIsValidJSON(t *testing.T) {
+	rr := httptest.NewRecorder()
+	openapi.Handler()(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var doc map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("expected JSON, got %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("expected openapi 3.0.3, got %v", doc["openapi"])
+	}
+
+	// Every reference must resolve
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, ref := range strings.Split(rr.Body.String(), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("unknown schema %s", name)
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := openapi.Build()
+	testCases := []struct {
+		name          string
+		pattern       string
+		status        int
+		contentType   string
+		body          string
+		expectedError string
+	}{
+		{
+			name:        "valid",
+			pattern:     "/accounts/{account_id}",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"account_id":1,"balance":"10.5"}`,
+		},
+		{
+			name:          "undocumented status",
+			pattern:       "/accounts/{account_id}",
+			status:        http.StatusTeapot,
+			expectedError: "status 418 is not documented",
+		},
+		{
+			name:          "missing property",
+			pattern:       "/accounts/{account_id}",
+			status:        http.StatusOK,
+			contentType:   "application/json",
+			body:          `{"account_id":1}`,
+			expectedError: "balance is required",
+		},
+		{
+			name:          "undocumented property",
+			pattern:       "/accounts/{account_id}",
+			status:        http.StatusOK,
+			contentType:   "application/json",
+			body:          `{"account_id":1,"balance":"1","currency":"USD"}`,
+			expectedError: "currency is not documented",
+		},
+		{
+			name:          "wrong format",
+			pattern:       "/accounts/{account_id}",
+			status:        http.StatusOK,
+			contentType:   "application/json",
+			body:          `{"account_id":1,"balance":"ten"}`,
+			expectedError: `"ten" is not a valid decimal`,
+		},
+		{
+			name:          "wrong content type",
+			pattern:       "/accounts/{account_id}",
+			status:        http.StatusOK,
+			contentType:   "text/plain",
+			body:          "10",
+			expectedError: `content type "text/plain"`,
+		},
+		{
+			name:          "unexpected body",
+			pattern:       "/accounts",
+			status:        http.StatusCreated,
+			body:          "{}",
+			expectedError: "none is documented",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tc.pattern == "/accounts" {
+				method = http.MethodPost
+			}
+			err := doc.ValidateResponse(method, tc.pattern, tc.status, tc.contentType, []byte(tc.body))
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+type exchange struct {
+	method      string
+	pattern     string
+	status      int
+	contentType string
+	body        []byte
+}
+
+// exchangeRecorder keeps every response the router sends with the route pattern it matched
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []exchange
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (rec *exchangeRecorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recordingWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		status := rw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		rec.exchanges = append(rec.exchanges, exchange{
+			method:      r.Method,
+			pattern:     chi.RouteContext(r.Context()).RoutePattern(),
+			status:      status,
+			contentType: rw.Header().Get("Content-Type"),
+			body:        rw.body.Bytes(),
+		})
+	})
+}
+
+type apiClient struct {
+	t   *testing.T
+	url string
+}
+
+func (c apiClient) do(method, path, userID, contentType, body string) (int, []byte) {
+	req, err := http.NewRequest(method, c.url+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("failed to create request: %v", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+		req.Header.Set("X-User-Roles", "approver")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("failed to read response of %s %s: %v", method, path, err)
+	}
+	return resp.StatusCode, data
+}
+
+func (c apiClient) json(method, path, userID, body string, expectedStatus int, v any) {
+	status, data := c.do(method, path, userID, "application/json", body)
+	if status != expectedStatus {
+		c.t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, expectedStatus, status, data)
+	}
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			c.t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+		}
+	}
+}
+
+// TestContract drives every route through the router and checks each response against the document
+func TestContract(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		recorder := &exchangeRecorder{}
+		listener, err := pgnotify.NewListener(testDB.DSN, "account_transactions", logger.NewLogger("error"))
+		if err != nil {
+			t.Fatalf("failed to create listener: %v", err)
+		}
+		listenerCtx, stopListener := context.WithCancel(ctx)
+		defer stopListener()
+		go listener.Run(listenerCtx)
+
+		r, paymentDomain, webhookDomain := newRouter(t, testDB.DB, listener, recorder)
+		srv := httptest.NewServer(r)
+		c := apiClient{t: t, url: srv.URL}
+
+		for _, id := range []int{adjustmentAccountID, clearingAccountID} {
+			if _, err := testDB.DB.Exec("INSERT INTO accounts (id, account_type) VALUES ($1, 'SYSTEM') ON CONFLICT DO NOTHING", id); err != nil {
+				t.Fatalf("failed to create system account: %v", err)
+			}
+		}
+
+		c.do(http.MethodGet, "/_health", "", "", "")
+		c.do(http.MethodGet, "/openapi.json", "", "", "")
+
+		// Webhooks first, so the account events get deliveries
+		var subscription admin.WebhookSubscriptionResponse
+		c.json(http.MethodPost, "/admin/webhooks", "ops", `{"url":"https://example.com/hook","event_types":["AccountCreated"],"secret":"0123456789abcdef"}`, http.StatusCreated, &subscription)
+		c.do(http.MethodPost, "/admin/webhooks", "ops", "application/json", `{"url":"ftp://example.com"}`)
+		c.do(http.MethodGet, "/admin/webhooks", "", "", "")
+		c.do(http.MethodGet, "/admin/webhooks", "ops", "", "")
+		c.do(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d", subscription.ID), "ops", "", "")
+		c.do(http.MethodGet, "/admin/webhooks/999999", "ops", "", "")
+		c.do(http.MethodPut, fmt.Sprintf("/admin/webhooks/%d", subscription.ID), "ops", "application/json", `{"url":"https://example.com/hook2","event_types":["AccountCreated","TransferCompleted"],"active":true}`)
+
+		// Accounts
+		c.json(http.MethodPost, "/accounts", "", `{"account_id":100,"initial_balance":"1000"}`, http.StatusCreated, nil)
+		c.json(http.MethodPost, "/accounts", "", `{"account_id":200,"account_type":"CREDIT","initial_balance":"10","credit_limit":"500"}`, http.StatusCreated, nil)
+		c.do(http.MethodPost, "/accounts", "", "application/json", `{"account_id":300}`)
+		c.do(http.MethodGet, "/accounts/100", "", "", "")
+		c.do(http.MethodGet, "/accounts/abc", "", "", "")
+		c.do(http.MethodGet, "/accounts/100/interest", "", "", "")
+		c.do(http.MethodGet, "/accounts/200/statements", "", "", "")
+
+		// Transfers, the large one waits for approval
+		c.do(http.MethodPost, "/transactions", "", "application/json", `{"source_account_id":100,"destination_account_id":200,"amount":"25.5"}`)
+		c.do(http.MethodPost, "/transactions", "", "application/json", `{"source_account_id":100,"destination_account_id":200,"amount":"999"}`)
+		var pending customer.TransferApprovalResponse
+		c.json(http.MethodPost, "/transactions", "maker", `{"source_account_id":100,"destination_account_id":200,"amount":"200000"}`, http.StatusAccepted, &pending)
+
+		// Statements
+		today := time.Now().UTC().Format(time.DateOnly)
+		c.do(http.MethodGet, "/accounts/100/statement?from="+today+"&to="+today, "", "", "")
+		c.do(http.MethodGet, "/accounts/100/statement?from="+today+"&to="+today+"&format=csv", "", "", "")
+		c.do(http.MethodGet, "/accounts/100/statement?from=yesterday&to="+today, "", "", "")
+		c.do(http.MethodGet, "/accounts/100/statement/camt.053?from="+today+"&to="+today, "", "", "")
+		c.do(http.MethodGet, "/accounts/100/statement/camt.054?from="+today+"&to="+today, "", "", "")
+
+		// Events, the stream is left as soon as it started
+		c.do(http.MethodGet, "/accounts/999/events", "", "", "")
+		streamCtx, stopStream := context.WithCancel(ctx)
+		req, _ := http.NewRequestWithContext(streamCtx, http.MethodGet, srv.URL+"/accounts/100/events", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to open event stream: %v", err)
+		}
+		bufio.NewReader(resp.Body).ReadString('\n')
+		stopStream()
+		resp.Body.Close()
+
+		// Approvals
+		c.do(http.MethodGet, "/admin/approvals", "checker", "", "")
+		c.do(http.MethodGet, "/admin/approvals?status=UNKNOWN", "checker", "", "")
+		c.do(http.MethodGet, fmt.Sprintf("/admin/approvals/%d", pending.ApprovalID), "checker", "", "")
+		c.do(http.MethodGet, "/admin/approvals/999999", "checker", "", "")
+		c.do(http.MethodPost, fmt.Sprintf("/admin/approvals/%d/approve", pending.ApprovalID), "maker", "application/json", `{}`)
+		// Approved but the source account can't cover it
+		c.do(http.MethodPost, fmt.Sprintf("/admin/approvals/%d/approve", pending.ApprovalID), "checker", "application/json", `{"note":"ok"}`)
+		c.do(http.MethodPost, fmt.Sprintf("/admin/approvals/%d/reject", pending.ApprovalID), "checker", "application/json", `{}`)
+
+		var adjustment admin.ApprovalResponse
+		c.json(http.MethodPost, "/admin/adjustments", "maker", `{"account_id":100,"amount":"5","trx_type":"CREDIT","reason":"goodwill"}`, http.StatusAccepted, &adjustment)
+		c.do(http.MethodPost, "/admin/adjustments", "maker", "application/json", `{"account_id":100}`)
+		c.do(http.MethodPost, fmt.Sprintf("/admin/approvals/%d/reject", adjustment.ApprovalID), "checker", "application/json", `{"note":"no"}`)
+
+		// Interest rates
+		c.do(http.MethodPost, "/admin/interest-rates", "ops", "application/json", `{"account_type":"SAVINGS","annual_rate":"0.05","effective_from":"2026-01-01"}`)
+		c.do(http.MethodPost, "/admin/interest-rates", "ops", "application/json", `{"account_type":"SAVINGS"}`)
+		c.do(http.MethodGet, "/admin/interest-rates", "ops", "", "")
+
+		// External payments
+		var created customer.PaymentResponse
+		c.json(http.MethodPost, "/accounts/100/payments", "", `{"amount":"10","creditor_name":"Jane Doe","creditor_account":"DE89370400440532013000","creditor_agent":"COBADEFFXXX","remittance_information":"invoice 42"}`, http.StatusCreated, &created)
+		c.do(http.MethodPost, "/accounts/100/payments", "", "application/json", `{"amount":"10"}`)
+		c.do(http.MethodGet, "/accounts/100/payments", "", "", "")
+		c.do(http.MethodGet, fmt.Sprintf("/accounts/100/payments/%d", created.PaymentID), "", "", "")
+		c.do(http.MethodGet, "/accounts/100/payments/999999", "", "", "")
+
+		file, err := paymentDomain.EmitPaymentFile(ctx, time.Now())
+		if err != nil {
+			t.Fatalf("failed to emit payment file: %v", err)
+		}
+		c.do(http.MethodGet, "/admin/payment-files/"+file.MessageID, "ops", "", "")
+		c.do(http.MethodGet, "/admin/payment-files/unknown", "ops", "", "")
+		report := fmt.Sprintf(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10"><CstmrPmtStsRpt><GrpHdr><MsgId>STS-1</MsgId></GrpHdr><OrgnlGrpInfAndSts><OrgnlMsgId>%s</OrgnlMsgId><GrpSts>ACSC</GrpSts></OrgnlGrpInfAndSts></CstmrPmtStsRpt></Document>`, file.MessageID)
+		c.do(http.MethodPost, "/admin/payment-status-reports", "ops", "application/xml", report)
+		c.do(http.MethodPost, "/admin/payment-status-reports", "ops", "application/xml", strings.ReplaceAll(report, file.MessageID, "unknown"))
+		c.do(http.MethodPost, "/admin/payment-status-reports", "ops", "application/xml", "not xml")
+
+		// Webhook deliveries of the account events
+		relay, err := outbox.NewRelay(testDB.DB, sqlc.New(testDB.DB), webhookDomain, &config.Config{
+			OutboxBatchSize:   100,
+			OutboxMaxAttempts: 3,
+			OutboxBackoffBase: time.Second,
+			OutboxBackoffMax:  time.Minute,
+		}, logger.NewLogger("error"))
+		if err != nil {
+			t.Fatalf("failed to create relay: %v", err)
+		}
+		if _, err := relay.RelayBatch(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+
+		var deliveries []admin.WebhookDeliveryResponse
+		c.json(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries", subscription.ID), "ops", "", http.StatusOK, &deliveries)
+		if len(deliveries) == 0 {
+			t.Fatal("expected webhook deliveries")
+		}
+		c.do(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries?status=LOST", subscription.ID), "ops", "", "")
+		c.do(http.MethodGet, fmt.Sprintf("/admin/webhook-deliveries/%d", deliveries[0].ID), "ops", "", "")
+		c.do(http.MethodGet, "/admin/webhook-deliveries/999999", "ops", "", "")
+		c.do(http.MethodPost, fmt.Sprintf("/admin/webhook-deliveries/%d/redeliver", deliveries[0].ID), "ops", "", "")
+		c.do(http.MethodDelete, fmt.Sprintf("/admin/webhooks/%d", subscription.ID), "ops", "", "")
+		c.do(http.MethodDelete, fmt.Sprintf("/admin/webhooks/%d", subscription.ID), "ops", "", "")
+
+		// Waits for the event stream handler to return
+		srv.Close()
+
+		doc := openapi.Build()
+		exercised := map[string]bool{}
+		for _, e := range recorder.exchanges {
+			exercised[e.method+" "+e.pattern] = true
+			if err := doc.ValidateResponse(e.method, e.pattern, e.status, e.contentType, e.body); err != nil {
+				t.Errorf("%v\n%s", err, e.body)
+			}
+		}
+
+		for _, route := range doc.Routes() {
+			if !exercised[route] {
+				t.Errorf("route %s was not exercised", route)
+			}
+		}
+	})
+}
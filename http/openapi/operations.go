@@ -0,0 +1,380 @@
+package openapi
+
+import (
+	"bank/http/handler/admin"
+	"bank/http/handler/customer"
+	"bank/internal/response"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	tagAccounts     = "accounts"
+	tagTransactions = "transactions"
+	tagPayments     = "payments"
+	tagAdmin        = "admin"
+	tagService      = "service"
+)
+
+// Operation is a route of the API with its request and the responses its handler sends.
+// Bodies are zero values of the types the handler encodes.
+type Operation struct {
+	Method  string
+	Path    string
+	ID      string
+	Summary string
+	Tag     string
+	// Parameters are the query and header parameters, path parameters come from the path
+	Parameters []*Parameter
+	Request    any
+	Responses  []Response
+}
+
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	// ContentType defaults to JSON when there is a body
+	ContentType string
+	// Alternatives are other content types of the response with their body
+	Alternatives map[string]any
+}
+
+func (o Operation) requestContentType() string {
+	if _, ok := o.Request.(xmlBody); ok {
+		return contentTypeXML
+	}
+	return contentTypeJSON
+}
+
+func (r Response) contentType() string {
+	if r.ContentType != "" {
+		return r.ContentType
+	}
+	return contentTypeJSON
+}
+
+// xmlBody and textBody stand for documents that aren't JSON
+type (
+	xmlBody  string
+	textBody string
+)
+
+func errorResponse(status int, description string) Response {
+	return Response{Status: status, Description: description, Body: response.ErrorResponse{}}
+}
+
+var (
+	badRequest    = errorResponse(http.StatusBadRequest, "Invalid request")
+	unauthorized  = errorResponse(http.StatusUnauthorized, "Missing caller identity")
+	notFound      = errorResponse(http.StatusNotFound, "Not found")
+	conflict      = errorResponse(http.StatusConflict, "Conflicts with the current state")
+	notApprover   = errorResponse(http.StatusForbidden, "Caller doesn't have the approver role")
+	internalError = errorResponse(http.StatusInternalServerError, "Unexpected error")
+	// auditUnavailable is added to every mutating operation, the Audit middleware refuses the
+	// requests it can't record
+	auditUnavailable = errorResponse(http.StatusServiceUnavailable, "The request can't be recorded in the audit trail")
+)
+
+// userRoles is the header of the routes that need a role
+var userRoles = &Parameter{
+	Name:        "X-User-Roles",
+	In:          "header",
+	Description: "Roles of the caller separated by commas, set by the gateway. Needs approver",
+	Required:    true,
+	Schema:      &Schema{Type: "string"},
+}
+
+func query(name, description string, enum ...string) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: enum}}
+}
+
+func requiredQuery(name, description, format string) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Required: true, Schema: &Schema{Type: "string", Format: format}}
+}
+
+var approvalStatuses = []string{"PENDING_APPROVAL", "EXECUTED", "FAILED", "REJECTED", "EXPIRED"}
+
+var operations = []Operation{
+	{
+		Method: http.MethodGet, Path: "/_health", ID: "health", Tag: tagService,
+		Summary:   "Liveness check",
+		Responses: []Response{{Status: http.StatusOK, Description: "Serving"}},
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Tag: tagService,
+		Summary:   "This document",
+		Responses: []Response{{Status: http.StatusOK, Description: "OpenAPI 3.0 document", Body: json.RawMessage{}}},
+	},
+
+	// Customer
+	{
+		Method: http.MethodPost, Path: "/accounts", ID: "createAccount", Tag: tagAccounts,
+		Summary: "Open an account with its initial balance, SAVINGS by default",
+		Request: customer.CreateAccountRequest{},
+		Responses: []Response{
+			{Status: http.StatusCreated, Description: "Account opened"},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}", ID: "getAccountBalance", Tag: tagAccounts,
+		Summary: "Current balance of the account",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Balance", Body: customer.GetAccountBalanceResponse{}},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/events", ID: "streamAccountEvents", Tag: tagAccounts,
+		Summary: "Server-Sent Events of the balance changes, resumed with the Last-Event-ID header",
+		Parameters: []*Parameter{{
+			Name: "Last-Event-ID", In: "header", Description: "Id of the last event received, the stream replays what followed",
+			Schema: &Schema{Type: "string"},
+		}},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "transaction and balance events", Body: textBody(""), ContentType: contentTypeSSE},
+			badRequest, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/interest", ID: "getAccruedInterest", Tag: tagAccounts,
+		Summary: "Interest accrued since the last capitalization",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Accrued interest", Body: customer.GetAccruedInterestResponse{}},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/statements", ID: "listCreditStatements", Tag: tagAccounts,
+		Summary: "Credit statements of the account, newest first",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Statements", Body: customer.ListStatementsResponse{}},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/statement", ID: "getAccountStatement", Tag: tagAccounts,
+		Summary: "Statement of a period, from and to inclusive",
+		Parameters: []*Parameter{
+			requiredQuery("from", "First day", "date"),
+			requiredQuery("to", "Last day", "date"),
+			query("format", "Defaults to json", "json", "csv"),
+		},
+		Responses: []Response{
+			{
+				Status: http.StatusOK, Description: "Statement in the format asked for", Body: customer.StatementResponse{},
+				Alternatives: map[string]any{contentTypeCSV: textBody("")},
+			},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/statement/{message}", ID: "getISO20022Statement", Tag: tagAccounts,
+		Summary: "camt.053 statement or camt.052 intraday report of a period, from and to inclusive",
+		Parameters: []*Parameter{
+			requiredQuery("from", "First day", "date"),
+			requiredQuery("to", "Last day", "date"),
+		},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "ISO 20022 document", Body: xmlBody(""), ContentType: contentTypeXML},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/accounts/{account_id}/payments", ID: "createPayment", Tag: tagPayments,
+		Summary: "Send money to an account at another bank, above the approval threshold it waits for approval",
+		Request: customer.CreatePaymentRequest{},
+		Responses: []Response{
+			{Status: http.StatusCreated, Description: "Payment accepted for the next payment file", Body: customer.PaymentResponse{}},
+			{Status: http.StatusAccepted, Description: "Waiting for approval", Body: customer.PaymentResponse{}},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/payments", ID: "listPayments", Tag: tagPayments,
+		Summary: "External payments of the account",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Payments", Body: []customer.PaymentResponse{}},
+			badRequest, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/accounts/{account_id}/payments/{payment_id}", ID: "getPayment", Tag: tagPayments,
+		Summary: "External payment with its status",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Payment", Body: customer.PaymentResponse{}},
+			badRequest, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/transactions", ID: "createTransferFunds", Tag: tagTransactions,
+		Summary: "Transfer between accounts, above the approval threshold it waits for approval",
+		Request: customer.CreateTransferFundsRequest{},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Transferred"},
+			{Status: http.StatusAccepted, Description: "Waiting for approval", Body: customer.TransferApprovalResponse{}},
+			badRequest, internalError,
+		},
+	},
+
+	// Admin
+	{
+		Method: http.MethodPost, Path: "/admin/adjustments", ID: "createAdjustment", Tag: tagAdmin,
+		Summary:    "Request a manual balance adjustment, it needs a second person's approval",
+		Request:    admin.CreateAdjustmentRequest{},
+		Parameters: []*Parameter{userRoles},
+		Responses: []Response{
+			{Status: http.StatusAccepted, Description: "Waiting for approval", Body: admin.ApprovalResponse{}},
+			badRequest, unauthorized, notApprover, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/approvals", ID: "listApprovals", Tag: tagAdmin,
+		Summary:    "Approval requests by status",
+		Parameters: []*Parameter{query("status", "Defaults to PENDING_APPROVAL", approvalStatuses...)},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Approval requests", Body: []admin.ApprovalResponse{}},
+			badRequest, unauthorized, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/approvals/{approval_id}", ID: "getApproval", Tag: tagAdmin,
+		Summary: "Approval request with its events",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Approval request", Body: admin.ApprovalResponse{}},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/approvals/{approval_id}/approve", ID: "approve", Tag: tagAdmin,
+		Summary:    "Approve and execute a request made by someone else",
+		Request:    admin.DecideApprovalRequest{},
+		Parameters: []*Parameter{userRoles},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Executed", Body: admin.ApprovalResponse{}},
+			badRequest, unauthorized,
+			errorResponse(http.StatusForbidden, "Caller doesn't have the approver role or made the request"),
+			notFound, conflict,
+			{Status: http.StatusUnprocessableEntity, Description: "Approved but failed to execute", Body: admin.ApprovalResponse{}},
+			internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/approvals/{approval_id}/reject", ID: "reject", Tag: tagAdmin,
+		Summary:    "Reject a request made by someone else",
+		Request:    admin.DecideApprovalRequest{},
+		Parameters: []*Parameter{userRoles},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Rejected", Body: admin.ApprovalResponse{}},
+			badRequest, unauthorized,
+			errorResponse(http.StatusForbidden, "Caller doesn't have the approver role or made the request"),
+			notFound, conflict, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/interest-rates", ID: "listInterestRates", Tag: tagAdmin,
+		Summary: "Interest rates by account type and effective date",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Interest rates", Body: []admin.InterestRateResponse{}},
+			unauthorized, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/interest-rates", ID: "createInterestRate", Tag: tagAdmin,
+		Summary: "Set the annual rate of an account type from a date",
+		Request: admin.CreateInterestRateRequest{},
+		Responses: []Response{
+			{Status: http.StatusCreated, Description: "Interest rate", Body: admin.InterestRateResponse{}},
+			badRequest, unauthorized, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/payment-status-reports", ID: "applyPaymentStatusReport", Tag: tagAdmin,
+		Summary: "Apply a pain.002 status report, rejected payments are reversed",
+		Request: xmlBody(""),
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Applied", Body: admin.PaymentStatusReportResponse{}},
+			badRequest, unauthorized,
+			errorResponse(http.StatusNotFound, "Unknown original message"),
+			internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/payment-files/{message_id}", ID: "getPaymentFile", Tag: tagAdmin,
+		Summary: "pain.001 payment file sent to the clearing partner",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Payment file", Body: xmlBody(""), ContentType: contentTypeXML},
+			unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/webhooks", ID: "listWebhookSubscriptions", Tag: tagAdmin,
+		Summary: "Webhook subscriptions",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Subscriptions", Body: []admin.WebhookSubscriptionResponse{}},
+			unauthorized, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/webhooks", ID: "createWebhookSubscription", Tag: tagAdmin,
+		Summary: "Subscribe a URL to event types",
+		Request: admin.CreateWebhookSubscriptionRequest{},
+		Responses: []Response{
+			{Status: http.StatusCreated, Description: "Subscription", Body: admin.WebhookSubscriptionResponse{}},
+			badRequest, unauthorized, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/webhooks/{webhook_id}", ID: "getWebhookSubscription", Tag: tagAdmin,
+		Summary: "Webhook subscription",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Subscription", Body: admin.WebhookSubscriptionResponse{}},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/admin/webhooks/{webhook_id}", ID: "updateWebhookSubscription", Tag: tagAdmin,
+		Summary: "Replace a webhook subscription, the secret is kept when left empty",
+		Request: admin.UpdateWebhookSubscriptionRequest{},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Subscription", Body: admin.WebhookSubscriptionResponse{}},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/admin/webhooks/{webhook_id}", ID: "deleteWebhookSubscription", Tag: tagAdmin,
+		Summary: "Delete a webhook subscription with its deliveries",
+		Responses: []Response{
+			{Status: http.StatusNoContent, Description: "Deleted"},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/webhooks/{webhook_id}/deliveries", ID: "listWebhookDeliveries", Tag: tagAdmin,
+		Summary:    "Delivery log of a subscription, newest first",
+		Parameters: []*Parameter{query("status", "Only deliveries with the status", "PENDING", "DELIVERED", "DEAD")},
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Deliveries", Body: []admin.WebhookDeliveryResponse{}},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/webhook-deliveries/{delivery_id}", ID: "getWebhookDelivery", Tag: tagAdmin,
+		Summary: "Delivery with its payload and attempts",
+		Responses: []Response{
+			{Status: http.StatusOK, Description: "Delivery", Body: admin.WebhookDeliveryResponse{}},
+			badRequest, unauthorized, notFound, internalError,
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/webhook-deliveries/{delivery_id}/redeliver", ID: "redeliverWebhook", Tag: tagAdmin,
+		Summary: "Send a delivered or dead delivery again",
+		Responses: []Response{
+			{Status: http.StatusAccepted, Description: "Delivery scheduled", Body: admin.WebhookDeliveryResponse{}},
+			badRequest, unauthorized, notFound,
+			errorResponse(http.StatusConflict, "Delivery still pending"),
+			internalError,
+		},
+	},
+}
@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the API needs
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+const componentPrefix = "#/components/schemas/"
+
+var (
+	decimalType    = reflect.TypeOf(decimal.Decimal{})
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaGenerator turns Go types into schemas the way encoding/json marshals them. Named
+// structs become components referenced by their type name.
+type schemaGenerator struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: map[string]*Schema{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case decimalType:
+		return &Schema{Type: "string", Format: "decimal"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{Description: "Any JSON value"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		zero := 0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" {
+		if existing, ok := g.types[name]; ok {
+			if existing != t {
+				panic(fmt.Sprintf("openapi: %s and %s share the schema name %s", existing, t, name))
+			}
+			return &Schema{Ref: componentPrefix + name}
+		}
+		g.types[name] = t
+	}
+
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	if name != "" {
+		g.components[name] = s
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName, omitEmpty := jsonField(field)
+		if jsonName == "-" {
+			continue
+		}
+
+		property := g.schemaOf(field.Type)
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		applyValidation(property, rules)
+		s.Properties[jsonName] = property
+
+		if isRequired(field, rules, omitEmpty) {
+			s.Required = append(s.Required, jsonName)
+			property.Nullable = false
+		}
+	}
+
+	if name != "" {
+		return &Schema{Ref: componentPrefix + name}
+	}
+	return s
+}
+
+// jsonField returns the name encoding/json gives the field and whether it is left out when empty
+func jsonField(field reflect.StructField) (name string, omitEmpty bool) {
+	tag := field.Tag.Get("json")
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+// isRequired tells whether the field is always present: request fields the validator requires, and
+// response fields that are never left out
+func isRequired(field reflect.StructField, rules []string, omitEmpty bool) bool {
+	if _, ok := field.Tag.Lookup("validate"); ok {
+		for _, rule := range rules {
+			if rule == "required" || rule == "decimal_required" {
+				return true
+			}
+		}
+		return false
+	}
+	return !omitEmpty
+}
+
+// applyValidation documents the validator rules that have an OpenAPI counterpart
+func applyValidation(s *Schema, rules []string) {
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "max":
+			if s.Type == "string" {
+				if n, err := strconv.Atoi(param); err == nil {
+					s.MaxLength = &n
+				}
+			}
+		case "datetime":
+			if param == time.DateOnly {
+				s.Format = "date"
+			}
+		case "decimal_positive":
+			s.Description = "Greater than 0"
+		case "decimal_non_negative":
+			s.Description = "Greater than or equal to 0"
+		}
+	}
+}
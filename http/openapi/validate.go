@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ValidateResponse checks a response of the route pattern against the document: the status must
+// be documented, the content type must be one of its content types and a JSON body must match
+// its schema. Bodies of other content types aren't checked.
+func (d *Document) ValidateResponse(method, pattern string, status int, contentType string, body []byte) error {
+	op, ok := d.Operation(method, pattern)
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, pattern)
+	}
+
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented", method, pattern, status)
+	}
+
+	if len(resp.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s: status %d has a body, none is documented", method, pattern, status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := resp.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d has content type %q, it is not documented", method, pattern, status, contentType)
+	}
+
+	if mediaType != contentTypeJSON {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("%s %s: status %d has invalid JSON: %w", method, pattern, status, err)
+	}
+
+	if err := d.validateValue(content.Schema, value, "body"); err != nil {
+		return fmt.Errorf("%s %s: status %d: %w", method, pattern, status, err)
+	}
+	return nil
+}
+
+func (d *Document) validateValue(s *Schema, value any, path string) error {
+	if s.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, componentPrefix)]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, s.Ref)
+		}
+		s = resolved
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s: is null", path)
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object", path)
+		}
+		return d.validateObject(s, object, path)
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array", path)
+		}
+		for i, item := range array {
+			if err := d.validateValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean", path)
+		}
+		return nil
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected a %s", path, s.Type)
+		}
+		if s.Type == "integer" {
+			if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+				if _, err := strconv.ParseUint(n.String(), 10, 64); err != nil {
+					return fmt.Errorf("%s: expected an integer, got %s", path, n)
+				}
+			}
+		}
+		if s.Minimum != nil {
+			if f, _ := n.Float64(); f < float64(*s.Minimum) {
+				return fmt.Errorf("%s: %s is below the minimum %d", path, n, *s.Minimum)
+			}
+		}
+		return nil
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string", path)
+		}
+		return validateString(s, str, path)
+	}
+	return fmt.Errorf("%s: unknown type %s", path, s.Type)
+}
+
+func (d *Document) validateObject(s *Schema, object map[string]any, path string) error {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: %s is required", path, name)
+		}
+	}
+
+	for name, value := range object {
+		property, ok := s.Properties[name]
+		if !ok {
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: %s is not documented", path, name)
+				}
+			case *Schema:
+				if err := d.validateValue(additional, value, path+"."+name); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := d.validateValue(property, value, path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(s *Schema, str, path string) error {
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+		return fmt.Errorf("%s: %q is not one of %v", path, str, s.Enum)
+	}
+	if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+		return fmt.Errorf("%s: longer than %d", path, *s.MaxLength)
+	}
+
+	var err error
+	switch s.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339Nano, str)
+	case "date":
+		_, err = time.Parse(time.DateOnly, str)
+	case "decimal":
+		_, err = decimal.NewFromString(str)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a valid %s", path, str, s.Format)
+	}
+	return nil
+}
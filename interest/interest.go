@@ -0,0 +1,339 @@
+package interest
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/transaction"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// accrualPrecision is the number of decimal places kept on each daily accrual
+	accrualPrecision = 12
+	// ledgerPrecision is the number of decimal places of a posted amount
+	ledgerPrecision = 6
+
+	pqUniqueViolation = "23505"
+)
+
+// daysInYear is the actual/365 fixed day count used for daily accrual
+var daysInYear = decimal.NewFromInt(365)
+
+type InterestDomain struct {
+	db                *sql.DB
+	queries           *sqlc.Queries
+	transactionDomain *transaction.TransactionDomain
+	logger            *logger.Logger
+	expenseAccountID  uint64
+	lookbackDays      int
+}
+
+func NewInterestDomain(db *sql.DB, sqlc *sqlc.Queries, transactionDomain *transaction.TransactionDomain, cfg *config.Config, logger *logger.Logger) (*InterestDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.InterestExpenseAccountID == 0 {
+		return nil, errors.New("interest expense account id is required")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "interest")
+	return &InterestDomain{
+		db:                db,
+		queries:           sqlc,
+		transactionDomain: transactionDomain,
+		logger:            log,
+		expenseAccountID:  cfg.InterestExpenseAccountID,
+		lookbackDays:      max(cfg.InterestAccrualLookbackDays, 1),
+	}, nil
+}
+
+// DailyInterest returns the interest earned for one day on an end-of-day balance.
+// It uses an actual/365 fixed day count and rounds half away from zero to 12 decimal
+// places. Zero and negative balances earn nothing.
+func DailyInterest(balance, annualRate decimal.Decimal) decimal.Decimal {
+	if !balance.IsPositive() || !annualRate.IsPositive() {
+		return decimal.Zero
+	}
+	return balance.Mul(annualRate).DivRound(daysInYear, accrualPrecision)
+}
+
+// CapitalizedAmount rounds the accrued interest of a month to ledger precision
+// using banker's rounding (half to even).
+func CapitalizedAmount(accrued decimal.Decimal) decimal.Decimal {
+	return accrued.RoundBank(ledgerPrecision)
+}
+
+// CreateInterestRate adds a rate that applies to the account type from its effective date on.
+func (d *InterestDomain) CreateInterestRate(ctx context.Context, param entity.CreateInterestRate) (entity.InterestRate, error) {
+	if err := param.Validate(); err != nil {
+		return entity.InterestRate{}, err
+	}
+
+	rate, err := d.queries.CreateInterestRate(ctx, sqlc.CreateInterestRateParams{
+		AccountType:   string(param.AccountType),
+		AnnualRate:    param.AnnualRate.String(),
+		EffectiveFrom: truncateToDay(param.EffectiveFrom),
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+			return entity.InterestRate{}, fmt.Errorf("%w: a rate is already effective from that date", entity.ErrValidation)
+		}
+		d.logger.Error(ctx, "param=%+v, failed to create interest rate: %v", param, err)
+		return entity.InterestRate{}, fmt.Errorf("failed to create interest rate: %w", err)
+	}
+
+	return toInterestRate(rate)
+}
+
+// ListInterestRates returns the whole rate table.
+func (d *InterestDomain) ListInterestRates(ctx context.Context) ([]entity.InterestRate, error) {
+	rates, err := d.queries.ListInterestRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest rates: %w", err)
+	}
+
+	result := make([]entity.InterestRate, 0, len(rates))
+	for _, rate := range rates {
+		r, err := toInterestRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// GetAccruedInterest returns the interest accrued on an account that is not capitalized yet.
+func (d *InterestDomain) GetAccruedInterest(ctx context.Context, accountID uint64) (entity.AccruedInterest, error) {
+	exists, err := d.queries.CheckAccountExists(ctx, int64(accountID))
+	if err != nil {
+		return entity.AccruedInterest{}, fmt.Errorf("failed to check account exists: %w", err)
+	}
+
+	if !exists {
+		return entity.AccruedInterest{}, entity.ErrNoRows
+	}
+
+	accrued, err := d.queries.GetUncapitalizedInterest(ctx, int64(accountID))
+	if err != nil {
+		return entity.AccruedInterest{}, fmt.Errorf("failed to get accrued interest: %w", err)
+	}
+
+	amount, err := decimal.NewFromString(accrued)
+	if err != nil {
+		return entity.AccruedInterest{}, fmt.Errorf("invalid accrued interest %q: %w", accrued, err)
+	}
+
+	return entity.AccruedInterest{AccountID: accountID, Amount: amount}, nil
+}
+
+// AccrueInterest accrues savings interest for every complete day in the lookback window
+// up to the day before now. Days already accrued for an account are skipped, so running
+// it repeatedly is safe. Returns the number of accruals recorded.
+func (d *InterestDomain) AccrueInterest(ctx context.Context, now time.Time) (int, error) {
+	today := truncateToDay(now)
+	total := 0
+	for i := d.lookbackDays; i >= 1; i-- {
+		accrued, err := d.AccrueInterestForDay(ctx, today.AddDate(0, 0, -i))
+		if err != nil {
+			return total, err
+		}
+		total += accrued
+	}
+	return total, nil
+}
+
+// AccrueInterestForDay accrues one day of interest for every SAVINGS account, based on
+// its balance at the end of that day (UTC) and the rate effective on that day.
+func (d *InterestDomain) AccrueInterestForDay(ctx context.Context, day time.Time) (int, error) {
+	day = truncateToDay(day)
+	rate, err := d.queries.GetInterestRateAt(ctx, sqlc.GetInterestRateAtParams{
+		AccountType:   string(entity.AccountTypeSavings),
+		EffectiveFrom: day,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get interest rate: %w", err)
+	}
+
+	annualRate, err := decimal.NewFromString(rate.AnnualRate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid annual rate %q: %w", rate.AnnualRate, err)
+	}
+
+	balances, err := d.queries.ListUnaccruedAccountBalancesAt(ctx, sqlc.ListUnaccruedAccountBalancesAtParams{
+		Cutoff:      day.AddDate(0, 0, 1),
+		AccountType: string(entity.AccountTypeSavings),
+		AccrualDate: day,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list account balances: %w", err)
+	}
+
+	accrued := 0
+	for _, b := range balances {
+		balance, err := decimal.NewFromString(b.Balance)
+		if err != nil {
+			return accrued, fmt.Errorf("invalid balance %q for account_id=%d: %w", b.Balance, b.AccountID, err)
+		}
+
+		inserted, err := d.queries.CreateInterestAccrual(ctx, sqlc.CreateInterestAccrualParams{
+			AccountID:   b.AccountID,
+			AccrualDate: day,
+			Balance:     b.Balance,
+			AnnualRate:  rate.AnnualRate,
+			Amount:      DailyInterest(balance, annualRate),
+		})
+		if err != nil {
+			d.logger.Error(ctx, "failed to accrue interest for account_id=%d day=%s: %v", b.AccountID, day.Format(time.DateOnly), err)
+			return accrued, fmt.Errorf("failed to create interest accrual: %w", err)
+		}
+		accrued += int(inserted)
+	}
+
+	return accrued, nil
+}
+
+// CapitalizeInterest posts the interest accrued during every completed month as a balanced
+// transfer from the interest expense account, one transfer per account and month. Accruals
+// are marked in the same transaction, so a month is never paid twice.
+// Returns the number of capitalizations.
+func (d *InterestDomain) CapitalizeInterest(ctx context.Context, now time.Time) (int, error) {
+	monthStart := truncateToMonth(now)
+	accountIDs, err := d.queries.ListAccountsWithUncapitalizedInterest(ctx, monthStart)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts with accrued interest: %w", err)
+	}
+
+	if len(accountIDs) > 0 {
+		if err := d.queries.EnsureAccount(ctx, int64(d.expenseAccountID)); err != nil {
+			return 0, fmt.Errorf("failed to ensure interest expense account: %w", err)
+		}
+	}
+
+	capitalized := 0
+	for _, accountID := range accountIDs {
+		n, err := d.capitalizeAccount(ctx, accountID, monthStart)
+		if err != nil {
+			d.logger.Error(ctx, "failed to capitalize interest for account_id=%d: %v", accountID, err)
+			return capitalized, err
+		}
+		capitalized += n
+	}
+	return capitalized, nil
+}
+
+func (d *InterestDomain) capitalizeAccount(ctx context.Context, accountID int64, before time.Time) (int, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	accruals, err := qtx.LockUncapitalizedInterestAccruals(ctx, sqlc.LockUncapitalizedInterestAccrualsParams{
+		AccountID:   accountID,
+		AccrualDate: before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock interest accruals: %w", err)
+	}
+
+	// Group by month, rows come ordered by accrual date
+	capitalized := 0
+	for start := 0; start < len(accruals); {
+		month := truncateToMonth(accruals[start].AccrualDate)
+		end := start
+		total := decimal.Zero
+		ids := []int64{}
+		for ; end < len(accruals) && truncateToMonth(accruals[end].AccrualDate).Equal(month); end++ {
+			total = total.Add(accruals[end].Amount)
+			ids = append(ids, accruals[end].ID)
+		}
+		start = end
+
+		transferID := sql.NullInt64{}
+		if amount := CapitalizedAmount(total); amount.IsPositive() {
+			result, err := d.transactionDomain.PostTransferTx(ctx, tx, entity.CreateTransferFundsParams{
+				SourceAccountID:      d.expenseAccountID,
+				DestinationAccountID: uint64(accountID),
+				Amount:               amount,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to post interest for %s: %w", month.Format("2006-01"), err)
+			}
+			transferID = sql.NullInt64{Int64: int64(result.TransferID), Valid: true}
+			capitalized++
+		}
+
+		err := qtx.MarkInterestAccrualsCapitalized(ctx, sqlc.MarkInterestAccrualsCapitalizedParams{
+			TransferID: transferID,
+			Ids:        ids,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark interest accruals capitalized: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return capitalized, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func truncateToMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func toInterestRate(r sqlc.InterestRate) (entity.InterestRate, error) {
+	annualRate, err := decimal.NewFromString(r.AnnualRate)
+	if err != nil {
+		return entity.InterestRate{}, fmt.Errorf("invalid annual rate %q: %w", r.AnnualRate, err)
+	}
+
+	return entity.InterestRate{
+		Model: entity.Model{
+			ID:        uint64(r.ID),
+			CreatedAt: r.CreatedAt.Time,
+		},
+		AccountType:   entity.AccountType(r.AccountType),
+		AnnualRate:    annualRate,
+		EffectiveFrom: r.EffectiveFrom,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package interest_test
+
+import (
+	"bank/config"
+	"bank/interest"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const expenseAccountID = 900000002
+
+func TestDailyInterest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		balance    string
+		annualRate string
+		expected   string
+	}{
+		{name: "whole result", balance: "36500", annualRate: "0.05", expected: "5"},
+		{name: "rounded to 12 places", balance: "1000", annualRate: "0.035", expected: "0.095890410959"},
+		{name: "tiny balance", balance: "0.000073", annualRate: "0.05", expected: "0.00000001"},
+		{name: "zero balance", balance: "0", annualRate: "0.05", expected: "0"},
+		{name: "negative balance", balance: "-100", annualRate: "0.05", expected: "0"},
+		{name: "zero rate", balance: "100", annualRate: "0", expected: "0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := interest.DailyInterest(decimal.RequireFromString(tc.balance), decimal.RequireFromString(tc.annualRate))
+			if !got.Equal(decimal.RequireFromString(tc.expected)) {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCapitalizedAmount(t *testing.T) {
+	testCases := []struct {
+		accrued  string
+		expected string
+	}{
+		{accrued: "2.876712328770", expected: "2.876712"},
+		{accrued: "0.0000005", expected: "0"},
+		{accrued: "0.0000015", expected: "0.000002"},
+		{accrued: "1.0000025", expected: "1.000002"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accrued, func(t *testing.T) {
+			got := interest.CapitalizedAmount(decimal.RequireFromString(tc.accrued))
+			if !got.Equal(decimal.RequireFromString(tc.expected)) {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAccrueAndCapitalizeInterest(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		testLogger := logger.NewLogger("debug")
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create transaction domain: %v", err)
+		}
+
+		domain, err := interest.NewInterestDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+			InterestExpenseAccountID:    expenseAccountID,
+			InterestAccrualLookbackDays: 3,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create interest domain: %v", err)
+		}
+
+		// Account 100 holds 36500 from the start of September, account 200 is a credit account
+		start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+		for _, acc := range []struct {
+			id          uint64
+			accountType string
+		}{{100, "SAVINGS"}, {200, "CREDIT"}} {
+			_, err := testDB.DB.Exec("INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES ($1, $2, $3, $3)", acc.id, acc.accountType, start)
+			if err != nil {
+				t.Fatalf("failed to create account: %v", err)
+			}
+			_, err = testDB.DB.Exec("INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES ($1, 36500, 'CREDIT', $2)", acc.id, start)
+			if err != nil {
+				t.Fatalf("failed to create transaction: %v", err)
+			}
+		}
+		_, err = testDB.DB.Exec("INSERT INTO interest_rates (account_type, annual_rate, effective_from) VALUES ('SAVINGS', 0.05, '2026-01-01')")
+		if err != nil {
+			t.Fatalf("failed to create interest rate: %v", err)
+		}
+
+		// Accrue the last 3 days of September twice, the second run must be a no-op
+		now := time.Date(2026, time.October, 1, 1, 0, 0, 0, time.UTC)
+		for run, expected := range []int{3, 0} {
+			accrued, err := domain.AccrueInterest(ctx, now)
+			if err != nil {
+				t.Fatalf("failed to accrue interest: %v", err)
+			}
+			if accrued != expected {
+				t.Errorf("run %d: expected %d accruals, got %d", run, expected, accrued)
+			}
+		}
+
+		accrued, err := domain.GetAccruedInterest(ctx, 100)
+		if err != nil {
+			t.Fatalf("failed to get accrued interest: %v", err)
+		}
+		if !accrued.Amount.Equal(decimal.NewFromInt(15)) {
+			t.Errorf("expected accrued interest 15, got %s", accrued.Amount)
+		}
+
+		// Capitalize twice, interest is only posted once
+		for run, expected := range []int{1, 0} {
+			capitalized, err := domain.CapitalizeInterest(ctx, now)
+			if err != nil {
+				t.Fatalf("failed to capitalize interest: %v", err)
+			}
+			if capitalized != expected {
+				t.Errorf("run %d: expected %d capitalizations, got %d", run, expected, capitalized)
+			}
+		}
+
+		var balance, expense decimal.Decimal
+		err = testDB.DB.QueryRow("SELECT get_account_balance(100), get_account_balance($1)", expenseAccountID).Scan(&balance, &expense)
+		if err != nil {
+			t.Fatalf("failed to query balances: %v", err)
+		}
+		if !balance.Equal(decimal.NewFromInt(36515)) {
+			t.Errorf("expected balance 36515, got %s", balance)
+		}
+		if !expense.Equal(decimal.NewFromInt(-15)) {
+			t.Errorf("expected interest expense balance -15, got %s", expense)
+		}
+	})
+}
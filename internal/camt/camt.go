@@ -0,0 +1,329 @@
+// Package camt renders account statements as ISO 20022 cash management messages:
+// camt.053 (bank to customer statement) and camt.052 (bank to customer account report).
+package camt
+
+import (
+	"bank/entity"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MessageType string
+
+const (
+	// MessageTypeStatement is the end-of-day statement
+	MessageTypeStatement MessageType = "camt.053.001.08"
+	// MessageTypeReport is the intraday account report
+	MessageTypeReport MessageType = "camt.052.001.08"
+)
+
+// Namespace returns the XML namespace of the message type
+func (m MessageType) Namespace() string {
+	return "urn:iso:std:iso:20022:tech:xsd:" + string(m)
+}
+
+func (m MessageType) Valid() bool {
+	return m == MessageTypeStatement || m == MessageTypeReport
+}
+
+// amountPrecision is the number of fraction digits ActiveOrHistoricCurrencyAndAmount allows,
+// ledger amounts have one more and are rounded half to even
+const amountPrecision = 5
+
+// Balance and entry codes from the ISO 20022 external code sets
+const (
+	balanceOpeningBooked = "OPBD"
+	balanceClosingBooked = "CLBD"
+	balanceInterimBooked = "ITBD"
+
+	indicatorCredit = "CRDT"
+	indicatorDebit  = "DBIT"
+
+	entryStatusBooked = "BOOK"
+)
+
+type Options struct {
+	// MessageID identifies the message for the receiver, it must be unique per message
+	MessageID string
+	CreatedAt time.Time
+	Currency  entity.CurrencyCode
+}
+
+// Writer streams one statement or report as XML. It has the methods of account.StatementWriter,
+// so the statement is never held in memory.
+type Writer struct {
+	out         io.Writer
+	enc         *xml.Encoder
+	messageType MessageType
+	opts        Options
+}
+
+func NewWriter(w io.Writer, messageType MessageType, opts Options) (*Writer, error) {
+	if !messageType.Valid() {
+		return nil, fmt.Errorf("unsupported message type %q", messageType)
+	}
+
+	if opts.MessageID == "" {
+		return nil, errors.New("message id is required")
+	}
+
+	if opts.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+
+	if opts.CreatedAt.IsZero() {
+		opts.CreatedAt = time.Now()
+	}
+
+	return &Writer{
+		out:         w,
+		enc:         xml.NewEncoder(w),
+		messageType: messageType,
+		opts:        opts,
+	}, nil
+}
+
+// The XML element layout, in the order the schema requires
+
+type groupHeader struct {
+	MsgID    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	MsgPgntn struct {
+		PgNb      int  `xml:"PgNb"`
+		LastPgInd bool `xml:"LastPgInd"`
+	} `xml:"MsgPgntn"`
+}
+
+type fromToDate struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type accountID struct {
+	Othr struct {
+		ID string `xml:"Id"`
+	} `xml:"Othr"`
+}
+
+type cashAccount struct {
+	ID  accountID `xml:"Id"`
+	Ccy string    `xml:"Ccy"`
+}
+
+type amount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type balance struct {
+	Tp struct {
+		CdOrPrtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amt       amount `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+	Dt        struct {
+		DtTm string `xml:"DtTm"`
+	} `xml:"Dt"`
+}
+
+type entry struct {
+	NtryRef   string `xml:"NtryRef"`
+	Amt       amount `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+	Sts       struct {
+		Cd string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookgDt struct {
+		DtTm string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	ValDt struct {
+		Dt string `xml:"Dt"`
+	} `xml:"ValDt"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	BkTxCd      struct {
+		Prtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"Prtry"`
+	} `xml:"BkTxCd"`
+	NtryDtls *entryDetails `xml:"NtryDtls,omitempty"`
+}
+
+type entryDetails struct {
+	TxDtls struct {
+		Refs struct {
+			AcctSvcrRef string `xml:"AcctSvcrRef"`
+		} `xml:"Refs"`
+		RltdPties *relatedParties `xml:"RltdPties,omitempty"`
+	} `xml:"TxDtls"`
+}
+
+type relatedParties struct {
+	DbtrAcct *relatedAccount `xml:"DbtrAcct,omitempty"`
+	CdtrAcct *relatedAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type relatedAccount struct {
+	ID accountID `xml:"Id"`
+}
+
+// rootElement and statementElement differ between the statement and the report
+func (w *Writer) rootElement() string {
+	if w.messageType == MessageTypeReport {
+		return "BkToCstmrAcctRpt"
+	}
+	return "BkToCstmrStmt"
+}
+
+func (w *Writer) statementElement() string {
+	if w.messageType == MessageTypeReport {
+		return "Rpt"
+	}
+	return "Stmt"
+}
+
+// closingBalanceCode is CLBD for a statement, an intraday report only has an interim balance
+func (w *Writer) closingBalanceCode() string {
+	if w.messageType == MessageTypeReport {
+		return balanceInterimBooked
+	}
+	return balanceClosingBooked
+}
+
+func (w *Writer) Begin(s entity.AccountStatement) error {
+	if _, err := io.WriteString(w.out, xml.Header); err != nil {
+		return err
+	}
+
+	document := xml.StartElement{
+		Name: xml.Name{Local: "Document"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: w.messageType.Namespace()}},
+	}
+	if err := w.start(document, xml.StartElement{Name: xml.Name{Local: w.rootElement()}}); err != nil {
+		return err
+	}
+
+	header := groupHeader{MsgID: w.opts.MessageID, CreDtTm: formatDateTime(w.opts.CreatedAt)}
+	header.MsgPgntn.PgNb = 1
+	header.MsgPgntn.LastPgInd = true
+	if err := w.enc.EncodeElement(header, element("GrpHdr")); err != nil {
+		return err
+	}
+
+	if err := w.start(element(w.statementElement())); err != nil {
+		return err
+	}
+
+	statementID := fmt.Sprintf("%d-%s-%s", s.AccountID, s.From.UTC().Format("20060102"), s.To.UTC().Format("20060102"))
+	if err := w.enc.EncodeElement(statementID, element("Id")); err != nil {
+		return err
+	}
+
+	if err := w.enc.EncodeElement(formatDateTime(w.opts.CreatedAt), element("CreDtTm")); err != nil {
+		return err
+	}
+
+	period := fromToDate{FrDtTm: formatDateTime(s.From), ToDtTm: formatDateTime(s.To)}
+	if err := w.enc.EncodeElement(period, element("FrToDt")); err != nil {
+		return err
+	}
+
+	acct := cashAccount{ID: newAccountID(s.AccountID), Ccy: string(w.opts.Currency)}
+	if err := w.enc.EncodeElement(acct, element("Acct")); err != nil {
+		return err
+	}
+
+	if err := w.enc.EncodeElement(w.balance(balanceOpeningBooked, s.OpeningBalance, s.From), element("Bal")); err != nil {
+		return err
+	}
+
+	return w.enc.EncodeElement(w.balance(w.closingBalanceCode(), s.ClosingBalance, s.To), element("Bal"))
+}
+
+func (w *Writer) WriteEntry(e entity.StatementEntry) error {
+	ntry := entry{
+		NtryRef:     strconv.FormatUint(e.ID, 10),
+		Amt:         w.amount(e.Amount),
+		CdtDbtInd:   indicatorCredit,
+		AcctSvcrRef: strconv.FormatUint(e.ID, 10),
+	}
+	if e.TrxType == entity.TrxTypeDebit {
+		ntry.CdtDbtInd = indicatorDebit
+	}
+	ntry.Sts.Cd = entryStatusBooked
+	ntry.BookgDt.DtTm = formatDateTime(e.CreatedAt)
+	ntry.ValDt.Dt = e.CreatedAt.UTC().Format(time.DateOnly)
+	ntry.BkTxCd.Prtry.Cd = string(e.TrxType)
+
+	if e.TransferID.Valid {
+		ntry.BkTxCd.Prtry.Cd = "TRANSFER"
+		details := &entryDetails{}
+		details.TxDtls.Refs.AcctSvcrRef = strconv.FormatInt(e.TransferID.Int64, 10)
+		if e.CounterpartyAccountID.Valid {
+			counterparty := &relatedAccount{ID: newAccountID(uint64(e.CounterpartyAccountID.Int64))}
+			// The counterparty paid a credit and received a debit
+			if e.TrxType == entity.TrxTypeCredit {
+				details.TxDtls.RltdPties = &relatedParties{DbtrAcct: counterparty}
+			} else {
+				details.TxDtls.RltdPties = &relatedParties{CdtrAcct: counterparty}
+			}
+		}
+		ntry.NtryDtls = details
+	}
+
+	return w.enc.EncodeElement(ntry, element("Ntry"))
+}
+
+func (w *Writer) End(entity.AccountStatement) error {
+	for _, name := range []string{w.statementElement(), w.rootElement(), "Document"} {
+		if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return w.enc.Flush()
+}
+
+func (w *Writer) start(elements ...xml.StartElement) error {
+	for _, e := range elements {
+		if err := w.enc.EncodeToken(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) balance(code string, value decimal.Decimal, at time.Time) balance {
+	b := balance{Amt: w.amount(value), CdtDbtInd: indicatorCredit}
+	if value.IsNegative() {
+		b.CdtDbtInd = indicatorDebit
+	}
+	b.Tp.CdOrPrtry.Cd = code
+	b.Dt.DtTm = formatDateTime(at)
+	return b
+}
+
+// amount is always positive, the direction is given by the credit debit indicator
+func (w *Writer) amount(value decimal.Decimal) amount {
+	return amount{Ccy: string(w.opts.Currency), Value: value.Abs().RoundBank(amountPrecision).String()}
+}
+
+func newAccountID(id uint64) accountID {
+	a := accountID{}
+	a.Othr.ID = strconv.FormatUint(id, 10)
+	return a
+}
+
+func element(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,417 @@
+package camt_test
+
+import (
+	"bank/entity"
+	"bank/internal/camt"
+	"bytes"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// node is a parsed XML element
+type node struct {
+	name     string
+	space    string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+func parse(t *testing.T, data []byte) *node {
+	t.Helper()
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*node
+	var root *node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: tok.Name.Local, space: tok.Name.Space, attrs: map[string]string{}}
+			for _, a := range tok.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += strings.TrimSpace(string(tok))
+			}
+		}
+	}
+	return root
+}
+
+// particle is one element of an xs:sequence
+type particle struct {
+	name     string
+	min, max int // max -1 is unbounded
+}
+
+func seq(particles ...particle) []particle { return particles }
+func one(name string) particle             { return particle{name, 1, 1} }
+func opt(name string) particle             { return particle{name, 0, 1} }
+func many(name string, min int) particle   { return particle{name, min, -1} }
+
+// choice marks a complex type whose content is exactly one of the listed elements
+type choice []string
+
+var (
+	isoDateTime = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	isoDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// ActiveOrHistoricCurrencyAndAmount: 18 total digits, 5 fraction digits, not negative
+	isoAmount   = regexp.MustCompile(`^\d{1,13}(\.\d{1,5})?$`)
+	currency    = regexp.MustCompile(`^[A-Z]{3}$`)
+	max35Text   = regexp.MustCompile(`^.{1,35}$`)
+	max5Numeric = regexp.MustCompile(`^\d{1,5}$`)
+	balanceCode = regexp.MustCompile(`^(OPBD|CLBD|ITBD|PRCD|CLAV|ITAV|FWAV|INFO)$`)
+	indicator   = regexp.MustCompile(`^(CRDT|DBIT)$`)
+	entryStatus = regexp.MustCompile(`^(BOOK|PDNG|INFO)$`)
+	boolean     = regexp.MustCompile(`^(true|false)$`)
+)
+
+// schema is the subset of the camt.052/camt.053 XSD the writer produces: the content model of
+// every complex type by element path, and the lexical pattern of every simple type
+type schema struct {
+	sequences map[string][]particle
+	choices   map[string]choice
+	simple    map[string]*regexp.Regexp
+}
+
+func newSchema(root, statement string) schema {
+	s := schema{sequences: map[string][]particle{}, choices: map[string]choice{}, simple: map[string]*regexp.Regexp{}}
+	doc := "Document/" + root
+	stmt := doc + "/" + statement
+
+	s.sequences["Document"] = seq(one(root))
+	s.sequences[doc] = seq(one("GrpHdr"), many(statement, 1), opt("SplmtryData"))
+	s.sequences[doc+"/GrpHdr"] = seq(one("MsgId"), one("CreDtTm"), opt("MsgRcpt"), opt("MsgPgntn"), opt("OrgnlBizQry"), opt("AddtlInf"))
+	s.simple[doc+"/GrpHdr/MsgId"] = max35Text
+	s.simple[doc+"/GrpHdr/CreDtTm"] = isoDateTime
+	s.sequences[doc+"/GrpHdr/MsgPgntn"] = seq(one("PgNb"), one("LastPgInd"))
+	s.simple[doc+"/GrpHdr/MsgPgntn/PgNb"] = max5Numeric
+	s.simple[doc+"/GrpHdr/MsgPgntn/LastPgInd"] = boolean
+
+	s.sequences[stmt] = seq(
+		one("Id"), opt("StmtPgntn"), opt("RptPgntn"), opt("ElctrncSeqNb"), opt("RptgSeq"), opt("LglSeqNb"), opt("CreDtTm"),
+		opt("FrToDt"), opt("CpyDplctInd"), opt("RptgSrc"), one("Acct"), opt("RltdAcct"), many("Intrst", 0),
+		many("Bal", 1), opt("TxsSummry"), many("Ntry", 0), opt("AddtlStmtInf"), opt("AddtlRptInf"),
+	)
+	s.simple[stmt+"/Id"] = max35Text
+	s.simple[stmt+"/CreDtTm"] = isoDateTime
+	s.sequences[stmt+"/FrToDt"] = seq(one("FrDtTm"), one("ToDtTm"))
+	s.simple[stmt+"/FrToDt/FrDtTm"] = isoDateTime
+	s.simple[stmt+"/FrToDt/ToDtTm"] = isoDateTime
+
+	s.sequences[stmt+"/Acct"] = seq(one("Id"), opt("Tp"), opt("Ccy"), opt("Nm"), opt("Prxy"), opt("Ownr"), opt("Svcr"))
+	accountID(s, stmt+"/Acct/Id")
+	s.simple[stmt+"/Acct/Ccy"] = currency
+
+	bal := stmt + "/Bal"
+	s.sequences[bal] = seq(one("Tp"), many("CdtLine", 0), one("Amt"), one("CdtDbtInd"), one("Dt"), many("Avlbty", 0))
+	s.sequences[bal+"/Tp"] = seq(one("CdOrPrtry"), opt("SubTp"))
+	s.choices[bal+"/Tp/CdOrPrtry"] = choice{"Cd", "Prtry"}
+	s.simple[bal+"/Tp/CdOrPrtry/Cd"] = balanceCode
+	s.simple[bal+"/Amt"] = isoAmount
+	s.simple[bal+"/CdtDbtInd"] = indicator
+	s.choices[bal+"/Dt"] = choice{"Dt", "DtTm"}
+	s.simple[bal+"/Dt/Dt"] = isoDate
+	s.simple[bal+"/Dt/DtTm"] = isoDateTime
+
+	ntry := stmt + "/Ntry"
+	s.sequences[ntry] = seq(
+		opt("NtryRef"), one("Amt"), one("CdtDbtInd"), opt("RvslInd"), one("Sts"), opt("BookgDt"), opt("ValDt"),
+		opt("AcctSvcrRef"), many("Avlbty", 0), one("BkTxCd"), opt("ComssnWvrInd"), opt("AddtlInfInd"), opt("AmtDtls"),
+		opt("Chrgs"), opt("TechInptChanl"), opt("Intrst"), opt("CardTx"), many("NtryDtls", 0), opt("AddtlNtryInf"),
+	)
+	s.simple[ntry+"/NtryRef"] = max35Text
+	s.simple[ntry+"/Amt"] = isoAmount
+	s.simple[ntry+"/CdtDbtInd"] = indicator
+	s.choices[ntry+"/Sts"] = choice{"Cd", "Prtry"}
+	s.simple[ntry+"/Sts/Cd"] = entryStatus
+	s.choices[ntry+"/BookgDt"] = choice{"Dt", "DtTm"}
+	s.simple[ntry+"/BookgDt/DtTm"] = isoDateTime
+	s.choices[ntry+"/ValDt"] = choice{"Dt", "DtTm"}
+	s.simple[ntry+"/ValDt/Dt"] = isoDate
+	s.simple[ntry+"/AcctSvcrRef"] = max35Text
+	s.sequences[ntry+"/BkTxCd"] = seq(opt("Domn"), opt("Prtry"))
+	s.sequences[ntry+"/BkTxCd/Prtry"] = seq(one("Cd"), opt("Issr"))
+	s.simple[ntry+"/BkTxCd/Prtry/Cd"] = max35Text
+
+	dtls := ntry + "/NtryDtls"
+	s.sequences[dtls] = seq(opt("Btch"), many("TxDtls", 0))
+	s.sequences[dtls+"/TxDtls"] = seq(
+		opt("Refs"), opt("Amt"), opt("CdtDbtInd"), opt("AmtDtls"), many("Avlbty", 0), opt("BkTxCd"), many("Chrgs", 0),
+		opt("Intrst"), opt("RltdPties"), opt("RltdAgts"), opt("LclInstrm"), opt("Purp"), many("RltdRmtInf", 0),
+		opt("RmtInf"), opt("RltdDts"), opt("RltdPric"), many("RltdQties", 0), opt("FinInstrmId"), opt("Tax"),
+		opt("RtrInf"), opt("CorpActn"), opt("SfkpgAcct"), opt("CshDpst"), opt("CardTx"), opt("AddtlTxInf"), opt("SplmtryData"),
+	)
+	s.sequences[dtls+"/TxDtls/Refs"] = seq(
+		opt("MsgId"), opt("AcctSvcrRef"), opt("PmtInfId"), opt("InstrId"), opt("EndToEndId"), opt("UETR"), opt("TxId"),
+		opt("MndtId"), opt("ChqNb"), opt("ClrSysRef"), opt("AcctOwnrTxId"), opt("AcctSvcrTxId"), opt("MktInfrstrctrTxId"),
+		opt("PrcgId"), many("Prtry", 0),
+	)
+	s.simple[dtls+"/TxDtls/Refs/AcctSvcrRef"] = max35Text
+	s.sequences[dtls+"/TxDtls/RltdPties"] = seq(
+		opt("InitgPty"), opt("Dbtr"), opt("DbtrAcct"), opt("UltmtDbtr"), opt("Cdtr"), opt("CdtrAcct"), opt("UltmtCdtr"),
+		opt("TradgPty"), many("Prtry", 0),
+	)
+	for _, acct := range []string{"DbtrAcct", "CdtrAcct"} {
+		path := dtls + "/TxDtls/RltdPties/" + acct
+		s.sequences[path] = seq(one("Id"), opt("Tp"), opt("Ccy"), opt("Nm"), opt("Prxy"))
+		accountID(s, path+"/Id")
+	}
+	return s
+}
+
+func accountID(s schema, path string) {
+	s.choices[path] = choice{"IBAN", "Othr"}
+	s.sequences[path+"/Othr"] = seq(one("Id"), opt("SchmeNm"), opt("Issr"))
+	s.simple[path+"/Othr/Id"] = regexp.MustCompile(`^.{1,34}$`)
+}
+
+// validate checks n and its descendants against the schema and returns every violation
+func (s schema) validate(n *node, path string) []string {
+	errs := []string{}
+	if pattern, ok := s.simple[path]; ok {
+		if len(n.children) > 0 {
+			errs = append(errs, fmt.Sprintf("%s: simple type must not have child elements", path))
+		}
+		if !pattern.MatchString(n.text) {
+			errs = append(errs, fmt.Sprintf("%s: value %q does not match %s", path, n.text, pattern))
+		}
+		return errs
+	}
+
+	if alternatives, ok := s.choices[path]; ok {
+		if len(n.children) != 1 {
+			return append(errs, fmt.Sprintf("%s: choice must have exactly one element, got %d", path, len(n.children)))
+		}
+		found := false
+		for _, a := range alternatives {
+			found = found || n.children[0].name == a
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: %s is not one of %v", path, n.children[0].name, alternatives))
+		}
+	} else if particles, ok := s.sequences[path]; ok {
+		i := 0
+		for _, p := range particles {
+			count := 0
+			for i < len(n.children) && n.children[i].name == p.name && (p.max == -1 || count < p.max) {
+				count++
+				i++
+			}
+			if count < p.min {
+				errs = append(errs, fmt.Sprintf("%s: expected %s at least %d times, got %d", path, p.name, p.min, count))
+			}
+		}
+		if i < len(n.children) {
+			errs = append(errs, fmt.Sprintf("%s: unexpected element %s", path, n.children[i].name))
+		}
+	} else {
+		return append(errs, fmt.Sprintf("%s: element is not in the schema", path))
+	}
+
+	for _, child := range n.children {
+		errs = append(errs, s.validate(child, path+"/"+child.name)...)
+	}
+	return errs
+}
+
+func find(n *node, path ...string) []*node {
+	if len(path) == 0 {
+		return []*node{n}
+	}
+	result := []*node{}
+	for _, child := range n.children {
+		if child.name == path[0] {
+			result = append(result, find(child, path[1:]...)...)
+		}
+	}
+	return result
+}
+
+func render(t *testing.T, messageType camt.MessageType) []byte {
+	t.Helper()
+	statement := entity.AccountStatement{
+		GetAccountStatement: entity.GetAccountStatement{
+			AccountID: 500,
+			From:      time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+			To:        time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		},
+		OpeningBalance: decimal.NewFromInt(1000),
+		ClosingBalance: decimal.RequireFromString("-49.5"),
+	}
+	entries := []entity.StatementEntry{
+		{
+			Transaction: entity.Transaction{
+				Model:      entity.Model{ID: 2, CreatedAt: time.Date(2026, time.September, 3, 10, 0, 0, 0, time.UTC)},
+				AccountID:  500,
+				TransferID: sql.NullInt64{Int64: 70, Valid: true},
+				Amount:     decimal.RequireFromString("250.5"),
+				TrxType:    entity.TrxTypeCredit,
+			},
+			CounterpartyAccountID: sql.NullInt64{Int64: 501, Valid: true},
+			Balance:               decimal.RequireFromString("1250.5"),
+		},
+		{
+			Transaction: entity.Transaction{
+				Model:      entity.Model{ID: 3, CreatedAt: time.Date(2026, time.September, 20, 8, 30, 0, 0, time.UTC)},
+				AccountID:  500,
+				TransferID: sql.NullInt64{Int64: 71, Valid: true},
+				Amount:     decimal.NewFromInt(1300),
+				TrxType:    entity.TrxTypeDebit,
+			},
+			CounterpartyAccountID: sql.NullInt64{Int64: 502, Valid: true},
+			Balance:               decimal.RequireFromString("-49.5"),
+		},
+		{
+			Transaction: entity.Transaction{
+				Model:     entity.Model{ID: 4, CreatedAt: time.Date(2026, time.September, 30, 23, 0, 0, 0, time.UTC)},
+				AccountID: 500,
+				Amount:    decimal.RequireFromString("0.0000015"),
+				TrxType:   entity.TrxTypeCredit,
+			},
+			Balance: decimal.RequireFromString("-49.5"),
+		},
+	}
+
+	var buf bytes.Buffer
+	w, err := camt.NewWriter(&buf, messageType, camt.Options{
+		MessageID: "STMT-500-20260930",
+		CreatedAt: time.Date(2026, time.October, 1, 2, 0, 0, 0, time.UTC),
+		Currency:  entity.CurrencyCodeUSD,
+	})
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	if err := w.Begin(statement); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	for _, e := range entries {
+		if err := w.WriteEntry(e); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if err := w.End(statement); err != nil {
+		t.Fatalf("failed to end: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriter(t *testing.T) {
+	testCases := []struct {
+		messageType camt.MessageType
+		root        string
+		statement   string
+		closingCode string
+	}{
+		{messageType: camt.MessageTypeStatement, root: "BkToCstmrStmt", statement: "Stmt", closingCode: "CLBD"},
+		{messageType: camt.MessageTypeReport, root: "BkToCstmrAcctRpt", statement: "Rpt", closingCode: "ITBD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.messageType), func(t *testing.T) {
+			doc := parse(t, render(t, tc.messageType))
+
+			if doc.name != "Document" || doc.space != tc.messageType.Namespace() {
+				t.Fatalf("expected Document in %s, got %s in %s", tc.messageType.Namespace(), doc.name, doc.space)
+			}
+
+			for _, err := range newSchema(tc.root, tc.statement).validate(doc, "Document") {
+				t.Error(err)
+			}
+
+			balances := find(doc, tc.root, tc.statement, "Bal")
+			if len(balances) != 2 {
+				t.Fatalf("expected 2 balances, got %d", len(balances))
+			}
+			expectedBalances := []struct{ code, amount, indicator string }{
+				{"OPBD", "1000", "CRDT"},
+				{tc.closingCode, "49.5", "DBIT"},
+			}
+			for i, expected := range expectedBalances {
+				code := find(balances[i], "Tp", "CdOrPrtry", "Cd")[0].text
+				amt := find(balances[i], "Amt")[0]
+				indicator := find(balances[i], "CdtDbtInd")[0].text
+				if code != expected.code || amt.text != expected.amount || indicator != expected.indicator || amt.attrs["Ccy"] != "USD" {
+					t.Errorf("balance %d: expected %+v, got %s %s %s %s", i, expected, code, amt.text, amt.attrs["Ccy"], indicator)
+				}
+			}
+
+			entries := find(doc, tc.root, tc.statement, "Ntry")
+			if len(entries) != 3 {
+				t.Fatalf("expected 3 entries, got %d", len(entries))
+			}
+
+			expectedEntries := []struct{ amount, indicator, code string }{
+				{"250.5", "CRDT", "TRANSFER"},
+				{"1300", "DBIT", "TRANSFER"},
+				// Rounded to the 5 fraction digits the schema allows
+				{"0.00000", "CRDT", "CREDIT"},
+			}
+			for i, expected := range expectedEntries {
+				amt := find(entries[i], "Amt")[0].text
+				indicator := find(entries[i], "CdtDbtInd")[0].text
+				code := find(entries[i], "BkTxCd", "Prtry", "Cd")[0].text
+				if !decimal.RequireFromString(amt).Equal(decimal.RequireFromString(expected.amount)) || indicator != expected.indicator || code != expected.code {
+					t.Errorf("entry %d: expected %+v, got %s %s %s", i, expected, amt, indicator, code)
+				}
+			}
+
+			// The counterparty is the debtor of a credit and the creditor of a debit
+			if got := find(entries[0], "NtryDtls", "TxDtls", "RltdPties", "DbtrAcct", "Id", "Othr", "Id"); len(got) != 1 || got[0].text != "501" {
+				t.Errorf("expected debtor account 501 on the credit entry")
+			}
+			if got := find(entries[1], "NtryDtls", "TxDtls", "RltdPties", "CdtrAcct", "Id", "Othr", "Id"); len(got) != 1 || got[0].text != "502" {
+				t.Errorf("expected creditor account 502 on the debit entry")
+			}
+			if got := find(entries[2], "NtryDtls"); len(got) != 0 {
+				t.Errorf("expected no entry details without a transfer")
+			}
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		messageType camt.MessageType
+		opts        camt.Options
+		expectedErr string
+	}{
+		{name: "unsupported message type", messageType: "camt.054.001.08", opts: camt.Options{MessageID: "1", Currency: "USD"}, expectedErr: `unsupported message type "camt.054.001.08"`},
+		{name: "missing message id", messageType: camt.MessageTypeStatement, opts: camt.Options{Currency: "USD"}, expectedErr: "message id is required"},
+		{name: "missing currency", messageType: camt.MessageTypeReport, opts: camt.Options{MessageID: "1"}, expectedErr: "currency is required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := camt.NewWriter(io.Discard, tc.messageType, tc.opts)
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,20 +1,27 @@
 package db
 
 import (
+	"bank/internal/tracing"
 	"database/sql"
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// DSN is the connection string of the database, for connections opened outside of the pool
+func DSN(host, port, customer, pass, name string) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, customer, pass, name)
+}
+
+// New opens the pool of connections, every statement run on it is traced
 func New(host, port, customer, pass, name string) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, customer, pass, name)
-	db, err := sql.Open("postgres", dsn)
+	connector, err := pq.NewConnector(DSN(host, port, customer, pass, name))
 	if err != nil {
 		return nil, err
 	}
 
+	db := sql.OpenDB(tracing.WrapConnector(connector))
 	if err := db.Ping(); err != nil {
 		return nil, err
 	}
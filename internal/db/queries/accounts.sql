@@ -1,15 +1,34 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (id, created_at, updated_at)
-VALUES ($1, NOW(), NOW())
-RETURNING id, created_at, updated_at;
+INSERT INTO accounts (id, account_type, credit_limit, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING id, created_at, updated_at, account_type, credit_limit;
 
 -- name: GetAccountByID :one
-SELECT id, created_at, updated_at
+SELECT id, created_at, updated_at, account_type, credit_limit
 FROM accounts
 WHERE id = $1;
 
+-- name: ListAccountsByType :many
+SELECT id, created_at, updated_at, account_type, credit_limit
+FROM accounts
+WHERE account_type = $1
+ORDER BY id;
+
 -- name: GetAccountBalanceByAccountID :one
 SELECT get_account_balance($1, $2);
 
 -- name: CheckAccountExists :one
-SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1);
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1);
+
+-- name: EnsureAccount :exec
+SELECT ensure_system_account($1);
+
+-- name: LockAccount :one
+SELECT id FROM accounts WHERE id = $1 FOR UPDATE;
+
+-- name: ListExistingAccountIDs :many
+SELECT id FROM accounts WHERE id = ANY(@ids::bigint[]);
+
+-- name: NextTransferIDs :many
+SELECT nextval(pg_get_serial_sequence('transfers', 'id'))::bigint AS id
+FROM generate_series(1, @count::int);
@@ -0,0 +1,17 @@
+-- name: UpsertACHFile :one
+INSERT INTO ach_files (immediate_origin, immediate_destination, file_created_at, file_id_modifier, file_hash, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+ON CONFLICT (immediate_origin, immediate_destination, file_created_at, file_id_modifier)
+DO UPDATE SET file_hash = ach_files.file_hash
+RETURNING id, immediate_origin, immediate_destination, file_created_at, file_id_modifier, file_hash, created_at;
+
+-- name: CreateACHEntry :one
+INSERT INTO ach_entries (ach_file_id, batch_number, trace_number, transaction_code, account_number, amount, status, return_reason_code, transfer_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+ON CONFLICT (ach_file_id, trace_number) DO NOTHING
+RETURNING id;
+
+-- name: GetACHEntryByTraceNumber :one
+SELECT id, ach_file_id, batch_number, trace_number, transaction_code, account_number, amount, status, return_reason_code, transfer_id, created_at
+FROM ach_entries
+WHERE ach_file_id = $1 AND trace_number = $2;
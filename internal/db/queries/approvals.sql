@@ -0,0 +1,43 @@
+-- name: CreateApprovalRequest :one
+INSERT INTO approval_requests (kind, status, source_account_id, destination_account_id, amount, requested_by, reason, expires_at, created_at, updated_at)
+VALUES ($1, 'PENDING_APPROVAL', $2, $3, $4, $5, $6, $7, NOW(), NOW())
+RETURNING id, kind, status, source_account_id, destination_account_id, amount, requested_by, decided_by, reason, transfer_id, expires_at, created_at, updated_at;
+
+-- name: GetApprovalRequestByID :one
+SELECT id, kind, status, source_account_id, destination_account_id, amount, requested_by, decided_by, reason, transfer_id, expires_at, created_at, updated_at
+FROM approval_requests
+WHERE id = $1;
+
+-- name: GetApprovalRequestByIDForUpdate :one
+SELECT id, kind, status, source_account_id, destination_account_id, amount, requested_by, decided_by, reason, transfer_id, expires_at, created_at, updated_at
+FROM approval_requests
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListApprovalRequestsByStatus :many
+SELECT id, kind, status, source_account_id, destination_account_id, amount, requested_by, decided_by, reason, transfer_id, expires_at, created_at, updated_at
+FROM approval_requests
+WHERE status = $1
+ORDER BY id
+LIMIT $2;
+
+-- name: UpdateApprovalRequestDecision :exec
+UPDATE approval_requests
+SET status = $2, decided_by = $3, transfer_id = $4, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ExpireApprovalRequests :many
+UPDATE approval_requests
+SET status = 'EXPIRED', updated_at = NOW()
+WHERE status = 'PENDING_APPROVAL' AND expires_at <= NOW()
+RETURNING id;
+
+-- name: CreateApprovalEvent :exec
+INSERT INTO approval_events (approval_request_id, event_type, actor, note, created_at)
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: ListApprovalEventsByApprovalRequestID :many
+SELECT id, approval_request_id, event_type, actor, note, created_at
+FROM approval_events
+WHERE approval_request_id = $1
+ORDER BY id;
@@ -0,0 +1,20 @@
+-- name: LockAuditChain :exec
+-- Serializes the writers of the chain until the end of the transaction
+SELECT pg_advisory_xact_lock(@lock_key::bigint);
+
+-- name: GetAuditChainHead :one
+SELECT seq, hash
+FROM audit_records
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: CreateAuditRecord :exec
+INSERT INTO audit_records (seq, occurred_at, actor, action, resource, request_id, ip, payload, status, outcome, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: ListAuditRecordsAfterSeq :many
+SELECT seq, occurred_at, actor, action, resource, request_id, ip, payload, status, outcome, prev_hash, hash
+FROM audit_records
+WHERE seq > @after_seq
+ORDER BY seq
+LIMIT @batch_size;
@@ -0,0 +1,20 @@
+-- name: CreateCreditStatement :one
+INSERT INTO credit_statements (
+    account_id, period_start, period_end, closing_balance, statement_balance, minimum_payment, due_date,
+    interest_charged, late_fee_charged, interest_transfer_id, late_fee_transfer_id, created_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+RETURNING id, account_id, period_start, period_end, closing_balance, statement_balance, minimum_payment, due_date, interest_charged, late_fee_charged, interest_transfer_id, late_fee_transfer_id, created_at;
+
+-- name: GetLatestCreditStatementByAccountID :one
+SELECT id, account_id, period_start, period_end, closing_balance, statement_balance, minimum_payment, due_date, interest_charged, late_fee_charged, interest_transfer_id, late_fee_transfer_id, created_at
+FROM credit_statements
+WHERE account_id = $1
+ORDER BY period_start DESC
+LIMIT 1;
+
+-- name: ListCreditStatementsByAccountID :many
+SELECT id, account_id, period_start, period_end, closing_balance, statement_balance, minimum_payment, due_date, interest_charged, late_fee_charged, interest_transfer_id, late_fee_transfer_id, created_at
+FROM credit_statements
+WHERE account_id = $1
+ORDER BY period_start DESC;
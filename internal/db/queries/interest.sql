@@ -0,0 +1,57 @@
+-- name: CreateInterestRate :one
+INSERT INTO interest_rates (account_type, annual_rate, effective_from, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, account_type, annual_rate, effective_from, created_at;
+
+-- name: ListInterestRates :many
+SELECT id, account_type, annual_rate, effective_from, created_at
+FROM interest_rates
+ORDER BY account_type, effective_from;
+
+-- name: GetInterestRateAt :one
+SELECT id, account_type, annual_rate, effective_from, created_at
+FROM interest_rates
+WHERE account_type = $1 AND effective_from <= $2
+ORDER BY effective_from DESC
+LIMIT 1;
+
+-- name: ListUnaccruedAccountBalancesAt :many
+SELECT a.id AS account_id,
+    COALESCE(SUM(CASE WHEN t.trx_type = 'CREDIT' THEN t.amount WHEN t.trx_type = 'DEBIT' THEN -t.amount END), 0)::decimal(20, 6) AS balance
+FROM accounts a
+LEFT JOIN transactions t ON t.account_id = a.id AND t.created_at < @cutoff::timestamptz
+WHERE a.account_type = @account_type
+  AND a.created_at < @cutoff::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM interest_accruals ia WHERE ia.account_id = a.id AND ia.accrual_date = @accrual_date::date
+  )
+GROUP BY a.id
+ORDER BY a.id;
+
+-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (account_id, accrual_date, balance, annual_rate, amount, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListAccountsWithUncapitalizedInterest :many
+SELECT DISTINCT account_id
+FROM interest_accruals
+WHERE capitalized_at IS NULL AND accrual_date < $1
+ORDER BY account_id;
+
+-- name: LockUncapitalizedInterestAccruals :many
+SELECT id, account_id, accrual_date, balance, annual_rate, amount, capitalized_at, transfer_id, created_at
+FROM interest_accruals
+WHERE account_id = $1 AND capitalized_at IS NULL AND accrual_date < $2
+ORDER BY accrual_date
+FOR UPDATE;
+
+-- name: MarkInterestAccrualsCapitalized :exec
+UPDATE interest_accruals
+SET capitalized_at = NOW(), transfer_id = @transfer_id
+WHERE id = ANY(@ids::bigint[]);
+
+-- name: GetUncapitalizedInterest :one
+SELECT COALESCE(SUM(amount), 0)::decimal(24, 12) AS accrued_interest
+FROM interest_accruals
+WHERE account_id = $1 AND capitalized_at IS NULL;
@@ -0,0 +1,9 @@
+-- name: ListLockWaits :many
+SELECT COALESCE(wait_event, '')::text AS wait_event,
+       count(*)::int AS waiting,
+       COALESCE(EXTRACT(EPOCH FROM max(clock_timestamp() - query_start)), 0)::float8 AS longest_seconds
+FROM pg_catalog.pg_stat_activity
+WHERE datname = current_database()
+  AND wait_event_type = 'Lock'
+GROUP BY wait_event
+ORDER BY wait_event;
@@ -0,0 +1,27 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (event_type, account_ids, payload, created_at)
+VALUES (@event_type, @account_ids::bigint[], @payload, NOW())
+RETURNING id;
+
+-- name: LockOutboxRelay :exec
+-- Serializes the relays until the end of the transaction
+SELECT pg_advisory_xact_lock(@lock_key::bigint);
+
+-- name: ListPendingOutboxEvents :many
+SELECT id, event_type, account_ids, payload, attempts, last_error, created_at, published_at, next_attempt_at, dead_at
+FROM outbox
+WHERE published_at IS NULL
+  AND dead_at IS NULL
+  AND id > @after_id
+ORDER BY id
+LIMIT @page_size;
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = NOW()
+WHERE id = ANY(@ids::bigint[]);
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = @last_error, next_attempt_at = @next_attempt_at, dead_at = @dead_at
+WHERE id = @id;
@@ -0,0 +1,63 @@
+-- name: CreateExternalPayment :one
+INSERT INTO external_payments (
+    account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, transfer_id, approval_request_id, created_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+RETURNING id, account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, status_reason, transfer_id, reversal_transfer_id, payment_file_id, created_at, updated_at, approval_request_id;
+
+-- name: GetExternalPaymentByID :one
+SELECT id, account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, status_reason, transfer_id, reversal_transfer_id, payment_file_id, created_at, updated_at, approval_request_id
+FROM external_payments
+WHERE id = $1;
+
+-- name: ListExternalPaymentsByAccountID :many
+SELECT id, account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, status_reason, transfer_id, reversal_transfer_id, payment_file_id, created_at, updated_at, approval_request_id
+FROM external_payments
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: LockPendingExternalPayments :many
+SELECT id, account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, status_reason, transfer_id, reversal_transfer_id, payment_file_id, created_at, updated_at, approval_request_id
+FROM external_payments
+WHERE status = 'PENDING'
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: LockExternalPaymentsByPaymentFileID :many
+SELECT id, account_id, amount, creditor_name, creditor_account, creditor_agent, remittance_information, status, status_reason, transfer_id, reversal_transfer_id, payment_file_id, created_at, updated_at, approval_request_id
+FROM external_payments
+WHERE payment_file_id = $1
+ORDER BY id
+FOR UPDATE;
+
+-- name: MarkExternalPaymentsSent :exec
+UPDATE external_payments
+SET status = 'SENT', payment_file_id = @payment_file_id, updated_at = NOW()
+WHERE id = ANY(@ids::bigint[]);
+
+-- name: ReleaseApprovedExternalPayment :exec
+UPDATE external_payments
+SET status = 'PENDING', transfer_id = @transfer_id, updated_at = NOW()
+WHERE approval_request_id = @approval_request_id AND status = 'PENDING_APPROVAL';
+
+-- name: RejectUnapprovedExternalPayments :exec
+UPDATE external_payments
+SET status = 'REJECTED', status_reason = @status_reason, updated_at = NOW()
+WHERE approval_request_id = ANY(@approval_request_ids::bigint[]) AND status = 'PENDING_APPROVAL';
+
+-- name: UpdateExternalPaymentStatus :exec
+UPDATE external_payments
+SET status = $2, status_reason = $3, reversal_transfer_id = $4, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreatePaymentFile :one
+INSERT INTO payment_files (message_id, payment_count, control_sum, content, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, message_id, payment_count, control_sum, content, created_at;
+
+-- name: GetPaymentFileByMessageID :one
+SELECT id, message_id, payment_count, control_sum, content, created_at
+FROM payment_files
+WHERE message_id = $1;
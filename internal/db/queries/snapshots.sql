@@ -0,0 +1,21 @@
+-- name: ListAccountIDsToSnapshot :many
+SELECT a.id
+FROM accounts a
+WHERE EXISTS (
+    SELECT 1
+    FROM transactions t
+    WHERE t.account_id = a.id
+      AND t.id > COALESCE((
+          SELECT s.last_transaction_id
+          FROM account_balance_snapshots s
+          WHERE s.account_id = a.id
+          ORDER BY s.created_at DESC
+          LIMIT 1
+      ), 0)
+)
+ORDER BY a.id;
+
+-- name: CreateBalanceSnapshot :one
+INSERT INTO account_balance_snapshots (account_id, balance, last_transaction_id, created_at)
+VALUES (@account_id, @balance, @last_transaction_id, clock_timestamp())
+RETURNING id, account_id, balance, last_transaction_id, created_at;
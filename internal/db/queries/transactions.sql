@@ -9,4 +9,53 @@ VALUES ($1, $2, $3, 'DEBIT', NOW())
 RETURNING id, account_id, transfer_id, amount, trx_type, created_at;
 
 -- name: CreateTransferTransaction :one
-SELECT transfer_funds($1, $2, $3) as result;
\ No newline at end of file
+SELECT * FROM transfer_funds($1, $2, $3);
+
+-- name: CreateTransfer :one
+INSERT INTO transfers (from_account_id, to_account_id, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, from_account_id, to_account_id, created_at;
+
+-- name: GetAccountBalanceAt :one
+SELECT get_account_balance_at(@account_id, @cutoff::timestamptz)::decimal(20, 6) AS balance;
+
+-- name: SumAccountCreditsBetween :one
+SELECT COALESCE(SUM(amount), 0)::decimal(20, 6) AS total
+FROM transactions
+WHERE account_id = @account_id
+  AND trx_type = 'CREDIT'
+  AND created_at >= @from_time::timestamptz
+  AND created_at < @to_time::timestamptz;
+-- name: ListAccountTransactionsBetween :many
+SELECT t.id, t.account_id, t.transfer_id, t.amount, t.trx_type, t.created_at,
+       COALESCE(CASE WHEN t.trx_type = 'CREDIT' THEN tr.from_account_id ELSE tr.to_account_id END, 0)::bigint AS counterparty_account_id
+FROM transactions t
+LEFT JOIN transfers tr ON tr.id = t.transfer_id
+WHERE t.account_id = @account_id
+  AND t.created_at >= @from_time::timestamptz
+  AND t.created_at < @to_time::timestamptz
+  AND t.id > @after_id
+ORDER BY t.id
+LIMIT @page_size;
+
+-- name: ListAccountTransactionsAfterID :many
+SELECT t.id, t.account_id, t.transfer_id, t.amount, t.trx_type, t.created_at,
+       COALESCE(CASE WHEN t.trx_type = 'CREDIT' THEN tr.from_account_id ELSE tr.to_account_id END, 0)::bigint AS counterparty_account_id
+FROM transactions t
+LEFT JOIN transfers tr ON tr.id = t.transfer_id
+WHERE t.account_id = @account_id
+  AND t.id > @after_id
+ORDER BY t.id
+LIMIT @page_size;
+
+-- name: SumAccountTransactionsAfterID :one
+-- The change of the balance since the transaction after_id
+SELECT COALESCE(SUM(CASE WHEN trx_type = 'CREDIT' THEN amount ELSE -amount END), 0)::decimal(20, 6) AS total
+FROM transactions
+WHERE account_id = @account_id
+  AND id > @after_id;
+
+-- name: GetLastAccountTransactionID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS id
+FROM transactions
+WHERE account_id = $1;
@@ -0,0 +1,82 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, event_types, secret, active, created_at, updated_at)
+VALUES (@url, @event_types::varchar[], @secret, TRUE, NOW(), NOW())
+RETURNING id, url, event_types, secret, active, created_at, updated_at;
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, event_types, secret, active, created_at, updated_at
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, event_types, secret, active, created_at, updated_at
+FROM webhook_subscriptions
+ORDER BY id;
+
+-- name: ListActiveWebhookSubscriptionsByEventType :many
+SELECT id, url, event_types, secret, active, created_at, updated_at
+FROM webhook_subscriptions
+WHERE active AND @event_type::varchar = ANY(event_types)
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = @url, event_types = @event_types::varchar[], secret = COALESCE(NULLIF(@secret::varchar, ''), secret), active = @active, updated_at = NOW()
+WHERE id = @id
+RETURNING id, url, event_types, secret, active, created_at, updated_at;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, 'PENDING', NOW(), NOW(), NOW())
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = @lease_until::timestamptz
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at, id
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret;
+
+-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4, last_response_status = $5, last_error = $6,
+    delivered_at = $7, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_status, error, duration_ms, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW());
+
+-- name: GetWebhookDeliveryByID :one
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, delivered_at, created_at, updated_at
+FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: ListWebhookDeliveriesBySubscriptionID :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_status, last_error, delivered_at, created_at, updated_at
+FROM webhook_deliveries
+WHERE subscription_id = @subscription_id AND (@status::varchar = '' OR status = @status::varchar)
+ORDER BY id DESC
+LIMIT @row_limit;
+
+-- name: ListWebhookDeliveryAttemptsByDeliveryID :many
+SELECT id, delivery_id, attempt, response_status, error, duration_ms, created_at
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY id;
+
+-- name: RedeliverWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'PENDING', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL, updated_at = NOW()
+WHERE id = $1 AND status <> 'PENDING';
@@ -7,6 +7,8 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const checkAccountExists = `-- name: CheckAccountExists :one
@@ -21,18 +23,39 @@ func (q *Queries) CheckAccountExists(ctx context.Context, id int64) (bool, error
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (id, created_at, updated_at)
-VALUES ($1, NOW(), NOW())
-RETURNING id, created_at, updated_at
+INSERT INTO accounts (id, account_type, credit_limit, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING id, created_at, updated_at, account_type, credit_limit
 `
 
-func (q *Queries) CreateAccount(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, id)
+type CreateAccountParams struct {
+	ID          int64  `db:"id" json:"id"`
+	AccountType string `db:"account_type" json:"account_type"`
+	CreditLimit string `db:"credit_limit" json:"credit_limit"`
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createAccount, arg.ID, arg.AccountType, arg.CreditLimit)
 	var i Account
-	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AccountType,
+		&i.CreditLimit,
+	)
 	return i, err
 }
 
+const ensureAccount = `-- name: EnsureAccount :exec
+SELECT ensure_system_account($1)
+`
+
+func (q *Queries) EnsureAccount(ctx context.Context, paramAccountID int64) error {
+	_, err := q.db.ExecContext(ctx, ensureAccount, paramAccountID)
+	return err
+}
+
 const getAccountBalanceByAccountID = `-- name: GetAccountBalanceByAccountID :one
 SELECT get_account_balance($1, $2)
 `
@@ -50,7 +73,7 @@ func (q *Queries) GetAccountBalanceByAccountID(ctx context.Context, arg GetAccou
 }
 
 const getAccountByID = `-- name: GetAccountByID :one
-SELECT id, created_at, updated_at
+SELECT id, created_at, updated_at, account_type, credit_limit
 FROM accounts
 WHERE id = $1
 `
@@ -58,6 +81,113 @@ WHERE id = $1
 func (q *Queries) GetAccountByID(ctx context.Context, id int64) (Account, error) {
 	row := q.db.QueryRowContext(ctx, getAccountByID, id)
 	var i Account
-	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AccountType,
+		&i.CreditLimit,
+	)
 	return i, err
 }
+
+const listAccountsByType = `-- name: ListAccountsByType :many
+SELECT id, created_at, updated_at, account_type, credit_limit
+FROM accounts
+WHERE account_type = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAccountsByType(ctx context.Context, accountType string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByType, accountType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AccountType,
+			&i.CreditLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccount = `-- name: LockAccount :one
+SELECT id FROM accounts WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) LockAccount(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, lockAccount, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const listExistingAccountIDs = `-- name: ListExistingAccountIDs :many
+SELECT id FROM accounts WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) ListExistingAccountIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExistingAccountIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextTransferIDs = `-- name: NextTransferIDs :many
+SELECT nextval(pg_get_serial_sequence('transfers', 'id'))::bigint AS id
+FROM generate_series(1, $1::int)
+`
+
+func (q *Queries) NextTransferIDs(ctx context.Context, count int32) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, nextTransferIDs, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -6,14 +6,18 @@ package sqlc
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
 
 type Account struct {
-	ID        int64        `db:"id" json:"id"`
-	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
-	UpdatedAt sql.NullTime `db:"updated_at" json:"updated_at"`
+	ID          int64        `db:"id" json:"id"`
+	CreatedAt   sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt   sql.NullTime `db:"updated_at" json:"updated_at"`
+	AccountType string       `db:"account_type" json:"account_type"`
+	CreditLimit string       `db:"credit_limit" json:"credit_limit"`
 }
 
 type AccountBalanceSnapshot struct {
@@ -24,6 +28,146 @@ type AccountBalanceSnapshot struct {
 	CreatedAt         sql.NullTime `db:"created_at" json:"created_at"`
 }
 
+type AchEntry struct {
+	ID               int64           `db:"id" json:"id"`
+	AchFileID        int64           `db:"ach_file_id" json:"ach_file_id"`
+	BatchNumber      int32           `db:"batch_number" json:"batch_number"`
+	TraceNumber      string          `db:"trace_number" json:"trace_number"`
+	TransactionCode  int32           `db:"transaction_code" json:"transaction_code"`
+	AccountNumber    string          `db:"account_number" json:"account_number"`
+	Amount           decimal.Decimal `db:"amount" json:"amount"`
+	Status           string          `db:"status" json:"status"`
+	ReturnReasonCode sql.NullString  `db:"return_reason_code" json:"return_reason_code"`
+	TransferID       sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	CreatedAt        sql.NullTime    `db:"created_at" json:"created_at"`
+}
+
+type AchFile struct {
+	ID                   int64        `db:"id" json:"id"`
+	ImmediateOrigin      string       `db:"immediate_origin" json:"immediate_origin"`
+	ImmediateDestination string       `db:"immediate_destination" json:"immediate_destination"`
+	FileCreatedAt        time.Time    `db:"file_created_at" json:"file_created_at"`
+	FileIDModifier       string       `db:"file_id_modifier" json:"file_id_modifier"`
+	FileHash             string       `db:"file_hash" json:"file_hash"`
+	CreatedAt            sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+type ApprovalEvent struct {
+	ID                int64        `db:"id" json:"id"`
+	ApprovalRequestID int64        `db:"approval_request_id" json:"approval_request_id"`
+	EventType         string       `db:"event_type" json:"event_type"`
+	Actor             string       `db:"actor" json:"actor"`
+	Note              string       `db:"note" json:"note"`
+	CreatedAt         sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+type ApprovalRequest struct {
+	ID                   int64           `db:"id" json:"id"`
+	Kind                 string          `db:"kind" json:"kind"`
+	Status               string          `db:"status" json:"status"`
+	SourceAccountID      int64           `db:"source_account_id" json:"source_account_id"`
+	DestinationAccountID int64           `db:"destination_account_id" json:"destination_account_id"`
+	Amount               decimal.Decimal `db:"amount" json:"amount"`
+	RequestedBy          string          `db:"requested_by" json:"requested_by"`
+	DecidedBy            sql.NullString  `db:"decided_by" json:"decided_by"`
+	Reason               string          `db:"reason" json:"reason"`
+	TransferID           sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	ExpiresAt            time.Time       `db:"expires_at" json:"expires_at"`
+	CreatedAt            sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt            sql.NullTime    `db:"updated_at" json:"updated_at"`
+}
+
+type AuditRecord struct {
+	Seq        int64     `db:"seq" json:"seq"`
+	OccurredAt time.Time `db:"occurred_at" json:"occurred_at"`
+	Actor      string    `db:"actor" json:"actor"`
+	Action     string    `db:"action" json:"action"`
+	Resource   string    `db:"resource" json:"resource"`
+	RequestID  string    `db:"request_id" json:"request_id"`
+	Ip         string    `db:"ip" json:"ip"`
+	Payload    []byte    `db:"payload" json:"payload"`
+	Status     string    `db:"status" json:"status"`
+	Outcome    string    `db:"outcome" json:"outcome"`
+	PrevHash   string    `db:"prev_hash" json:"prev_hash"`
+	Hash       string    `db:"hash" json:"hash"`
+}
+
+type CreditStatement struct {
+	ID                 int64         `db:"id" json:"id"`
+	AccountID          int64         `db:"account_id" json:"account_id"`
+	PeriodStart        time.Time     `db:"period_start" json:"period_start"`
+	PeriodEnd          time.Time     `db:"period_end" json:"period_end"`
+	ClosingBalance     string        `db:"closing_balance" json:"closing_balance"`
+	StatementBalance   string        `db:"statement_balance" json:"statement_balance"`
+	MinimumPayment     string        `db:"minimum_payment" json:"minimum_payment"`
+	DueDate            time.Time     `db:"due_date" json:"due_date"`
+	InterestCharged    string        `db:"interest_charged" json:"interest_charged"`
+	LateFeeCharged     string        `db:"late_fee_charged" json:"late_fee_charged"`
+	InterestTransferID sql.NullInt64 `db:"interest_transfer_id" json:"interest_transfer_id"`
+	LateFeeTransferID  sql.NullInt64 `db:"late_fee_transfer_id" json:"late_fee_transfer_id"`
+	CreatedAt          sql.NullTime  `db:"created_at" json:"created_at"`
+}
+
+type ExternalPayment struct {
+	ID                    int64           `db:"id" json:"id"`
+	AccountID             int64           `db:"account_id" json:"account_id"`
+	Amount                decimal.Decimal `db:"amount" json:"amount"`
+	CreditorName          string          `db:"creditor_name" json:"creditor_name"`
+	CreditorAccount       string          `db:"creditor_account" json:"creditor_account"`
+	CreditorAgent         string          `db:"creditor_agent" json:"creditor_agent"`
+	RemittanceInformation string          `db:"remittance_information" json:"remittance_information"`
+	Status                string          `db:"status" json:"status"`
+	StatusReason          string          `db:"status_reason" json:"status_reason"`
+	TransferID            sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	ReversalTransferID    sql.NullInt64   `db:"reversal_transfer_id" json:"reversal_transfer_id"`
+	PaymentFileID         sql.NullInt64   `db:"payment_file_id" json:"payment_file_id"`
+	CreatedAt             sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt             sql.NullTime    `db:"updated_at" json:"updated_at"`
+	ApprovalRequestID     sql.NullInt64   `db:"approval_request_id" json:"approval_request_id"`
+}
+
+type InterestAccrual struct {
+	ID            int64           `db:"id" json:"id"`
+	AccountID     int64           `db:"account_id" json:"account_id"`
+	AccrualDate   time.Time       `db:"accrual_date" json:"accrual_date"`
+	Balance       string          `db:"balance" json:"balance"`
+	AnnualRate    string          `db:"annual_rate" json:"annual_rate"`
+	Amount        decimal.Decimal `db:"amount" json:"amount"`
+	CapitalizedAt sql.NullTime    `db:"capitalized_at" json:"capitalized_at"`
+	TransferID    sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	CreatedAt     sql.NullTime    `db:"created_at" json:"created_at"`
+}
+
+type InterestRate struct {
+	ID            int64        `db:"id" json:"id"`
+	AccountType   string       `db:"account_type" json:"account_type"`
+	AnnualRate    string       `db:"annual_rate" json:"annual_rate"`
+	EffectiveFrom time.Time    `db:"effective_from" json:"effective_from"`
+	CreatedAt     sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+type Outbox struct {
+	ID            int64           `db:"id" json:"id"`
+	EventType     string          `db:"event_type" json:"event_type"`
+	AccountIds    []int64         `db:"account_ids" json:"account_ids"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	Attempts      int32           `db:"attempts" json:"attempts"`
+	LastError     string          `db:"last_error" json:"last_error"`
+	CreatedAt     sql.NullTime    `db:"created_at" json:"created_at"`
+	PublishedAt   sql.NullTime    `db:"published_at" json:"published_at"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	DeadAt        sql.NullTime    `db:"dead_at" json:"dead_at"`
+}
+
+type PaymentFile struct {
+	ID           int64        `db:"id" json:"id"`
+	MessageID    string       `db:"message_id" json:"message_id"`
+	PaymentCount int32        `db:"payment_count" json:"payment_count"`
+	ControlSum   string       `db:"control_sum" json:"control_sum"`
+	Content      string       `db:"content" json:"content"`
+	CreatedAt    sql.NullTime `db:"created_at" json:"created_at"`
+}
+
 type Transaction struct {
 	ID         int64           `db:"id" json:"id"`
 	AccountID  int64           `db:"account_id" json:"account_id"`
@@ -39,3 +183,39 @@ type Transfer struct {
 	ToAccountID   int64        `db:"to_account_id" json:"to_account_id"`
 	CreatedAt     sql.NullTime `db:"created_at" json:"created_at"`
 }
+
+type WebhookDelivery struct {
+	ID                 int64           `db:"id" json:"id"`
+	SubscriptionID     int64           `db:"subscription_id" json:"subscription_id"`
+	EventID            int64           `db:"event_id" json:"event_id"`
+	EventType          string          `db:"event_type" json:"event_type"`
+	Payload            json.RawMessage `db:"payload" json:"payload"`
+	Status             string          `db:"status" json:"status"`
+	Attempts           int32           `db:"attempts" json:"attempts"`
+	NextAttemptAt      time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastResponseStatus sql.NullInt32   `db:"last_response_status" json:"last_response_status"`
+	LastError          string          `db:"last_error" json:"last_error"`
+	DeliveredAt        sql.NullTime    `db:"delivered_at" json:"delivered_at"`
+	CreatedAt          sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt          sql.NullTime    `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64         `db:"id" json:"id"`
+	DeliveryID     int64         `db:"delivery_id" json:"delivery_id"`
+	Attempt        int32         `db:"attempt" json:"attempt"`
+	ResponseStatus sql.NullInt32 `db:"response_status" json:"response_status"`
+	Error          string        `db:"error" json:"error"`
+	DurationMs     int32         `db:"duration_ms" json:"duration_ms"`
+	CreatedAt      sql.NullTime  `db:"created_at" json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID         int64        `db:"id" json:"id"`
+	Url        string       `db:"url" json:"url"`
+	EventTypes []string     `db:"event_types" json:"event_types"`
+	Secret     string       `db:"secret" json:"secret"`
+	Active     bool         `db:"active" json:"active"`
+	CreatedAt  sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt  sql.NullTime `db:"updated_at" json:"updated_at"`
+}
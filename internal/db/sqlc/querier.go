@@ -6,16 +6,93 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	CheckAccountExists(ctx context.Context, id int64) (bool, error)
-	CreateAccount(ctx context.Context, id int64) (Account, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	CreateACHEntry(ctx context.Context, arg CreateACHEntryParams) (int64, error)
+	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateApprovalEvent(ctx context.Context, arg CreateApprovalEventParams) error
+	CreateApprovalRequest(ctx context.Context, arg CreateApprovalRequestParams) (ApprovalRequest, error)
+	CreateAuditRecord(ctx context.Context, arg CreateAuditRecordParams) error
+	CreateBalanceSnapshot(ctx context.Context, arg CreateBalanceSnapshotParams) (AccountBalanceSnapshot, error)
 	CreateCreditTransaction(ctx context.Context, arg CreateCreditTransactionParams) (Transaction, error)
 	CreateDebitTransaction(ctx context.Context, arg CreateDebitTransactionParams) (Transaction, error)
-	CreateTransferTransaction(ctx context.Context, arg CreateTransferTransactionParams) (interface{}, error)
+	CreateCreditStatement(ctx context.Context, arg CreateCreditStatementParams) (CreditStatement, error)
+	CreateExternalPayment(ctx context.Context, arg CreateExternalPaymentParams) (ExternalPayment, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestRate(ctx context.Context, arg CreateInterestRateParams) (InterestRate, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (int64, error)
+	CreatePaymentFile(ctx context.Context, arg CreatePaymentFileParams) (PaymentFile, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferTransaction(ctx context.Context, arg CreateTransferTransactionParams) (CreateTransferTransactionRow, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error)
+	EnsureAccount(ctx context.Context, paramAccountID int64) error
+	ExpireApprovalRequests(ctx context.Context) ([]int64, error)
+	GetACHEntryByTraceNumber(ctx context.Context, arg GetACHEntryByTraceNumberParams) (AchEntry, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (string, error)
 	GetAccountBalanceByAccountID(ctx context.Context, arg GetAccountBalanceByAccountIDParams) (string, error)
 	GetAccountByID(ctx context.Context, id int64) (Account, error)
+	GetApprovalRequestByID(ctx context.Context, id int64) (ApprovalRequest, error)
+	GetApprovalRequestByIDForUpdate(ctx context.Context, id int64) (ApprovalRequest, error)
+	GetAuditChainHead(ctx context.Context) (GetAuditChainHeadRow, error)
+	GetExternalPaymentByID(ctx context.Context, id int64) (ExternalPayment, error)
+	GetInterestRateAt(ctx context.Context, arg GetInterestRateAtParams) (InterestRate, error)
+	GetLastAccountTransactionID(ctx context.Context, accountID int64) (int64, error)
+	GetLatestCreditStatementByAccountID(ctx context.Context, accountID int64) (CreditStatement, error)
+	GetPaymentFileByMessageID(ctx context.Context, messageID string) (PaymentFile, error)
+	GetUncapitalizedInterest(ctx context.Context, accountID int64) (string, error)
+	GetWebhookDeliveryByID(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id int64) (WebhookSubscription, error)
+	ListAccountIDsToSnapshot(ctx context.Context) ([]int64, error)
+	ListAccountTransactionsAfterID(ctx context.Context, arg ListAccountTransactionsAfterIDParams) ([]ListAccountTransactionsAfterIDRow, error)
+	ListAccountTransactionsBetween(ctx context.Context, arg ListAccountTransactionsBetweenParams) ([]ListAccountTransactionsBetweenRow, error)
+	ListAccountsByType(ctx context.Context, accountType string) ([]Account, error)
+	ListAccountsWithUncapitalizedInterest(ctx context.Context, accrualDate time.Time) ([]int64, error)
+	ListActiveWebhookSubscriptionsByEventType(ctx context.Context, eventType string) ([]WebhookSubscription, error)
+	ListApprovalEventsByApprovalRequestID(ctx context.Context, approvalRequestID int64) ([]ApprovalEvent, error)
+	ListApprovalRequestsByStatus(ctx context.Context, arg ListApprovalRequestsByStatusParams) ([]ApprovalRequest, error)
+	ListAuditRecordsAfterSeq(ctx context.Context, arg ListAuditRecordsAfterSeqParams) ([]AuditRecord, error)
+	ListCreditStatementsByAccountID(ctx context.Context, accountID int64) ([]CreditStatement, error)
+	ListExistingAccountIDs(ctx context.Context, ids []int64) ([]int64, error)
+	ListExternalPaymentsByAccountID(ctx context.Context, arg ListExternalPaymentsByAccountIDParams) ([]ExternalPayment, error)
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	ListLockWaits(ctx context.Context) ([]ListLockWaitsRow, error)
+	ListPendingOutboxEvents(ctx context.Context, arg ListPendingOutboxEventsParams) ([]Outbox, error)
+	ListUnaccruedAccountBalancesAt(ctx context.Context, arg ListUnaccruedAccountBalancesAtParams) ([]ListUnaccruedAccountBalancesAtRow, error)
+	ListWebhookDeliveriesBySubscriptionID(ctx context.Context, arg ListWebhookDeliveriesBySubscriptionIDParams) ([]WebhookDelivery, error)
+	ListWebhookDeliveryAttemptsByDeliveryID(ctx context.Context, deliveryID int64) ([]WebhookDeliveryAttempt, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	LockAccount(ctx context.Context, id int64) (int64, error)
+	// Serializes the writers of the chain until the end of the transaction
+	LockAuditChain(ctx context.Context, lockKey int64) error
+	LockExternalPaymentsByPaymentFileID(ctx context.Context, paymentFileID sql.NullInt64) ([]ExternalPayment, error)
+	// Serializes the relays until the end of the transaction
+	LockOutboxRelay(ctx context.Context, lockKey int64) error
+	LockPendingExternalPayments(ctx context.Context, limit int32) ([]ExternalPayment, error)
+	LockUncapitalizedInterestAccruals(ctx context.Context, arg LockUncapitalizedInterestAccrualsParams) ([]InterestAccrual, error)
+	MarkExternalPaymentsSent(ctx context.Context, arg MarkExternalPaymentsSentParams) error
+	MarkInterestAccrualsCapitalized(ctx context.Context, arg MarkInterestAccrualsCapitalizedParams) error
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	NextTransferIDs(ctx context.Context, count int32) ([]int64, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	RedeliverWebhookDelivery(ctx context.Context, id int64) (int64, error)
+	RejectUnapprovedExternalPayments(ctx context.Context, arg RejectUnapprovedExternalPaymentsParams) error
+	ReleaseApprovedExternalPayment(ctx context.Context, arg ReleaseApprovedExternalPaymentParams) error
+	SumAccountCreditsBetween(ctx context.Context, arg SumAccountCreditsBetweenParams) (string, error)
+	// The change of the balance since the transaction after_id
+	SumAccountTransactionsAfterID(ctx context.Context, arg SumAccountTransactionsAfterIDParams) (string, error)
+	UpdateApprovalRequestDecision(ctx context.Context, arg UpdateApprovalRequestDecisionParams) error
+	UpdateExternalPaymentStatus(ctx context.Context, arg UpdateExternalPaymentStatusParams) error
+	UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) error
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	UpsertACHFile(ctx context.Context, arg UpsertACHFileParams) (AchFile, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,6 +8,7 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -64,8 +65,31 @@ func (q *Queries) CreateDebitTransaction(ctx context.Context, arg CreateDebitTra
 	return i, err
 }
 
+const createTransfer = `-- name: CreateTransfer :one
+INSERT INTO transfers (from_account_id, to_account_id, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, from_account_id, to_account_id, created_at
+`
+
+type CreateTransferParams struct {
+	FromAccountID int64 `db:"from_account_id" json:"from_account_id"`
+	ToAccountID   int64 `db:"to_account_id" json:"to_account_id"`
+}
+
+func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createTransferTransaction = `-- name: CreateTransferTransaction :one
-SELECT transfer_funds($1, $2, $3) as result
+SELECT transfer_id, error_code, error_message FROM transfer_funds($1, $2, $3)
 `
 
 type CreateTransferTransactionParams struct {
@@ -74,9 +98,212 @@ type CreateTransferTransactionParams struct {
 	ParamAmount        string `db:"param_amount" json:"param_amount"`
 }
 
-func (q *Queries) CreateTransferTransaction(ctx context.Context, arg CreateTransferTransactionParams) (interface{}, error) {
+type CreateTransferTransactionRow struct {
+	TransferID   sql.NullInt64  `db:"transfer_id" json:"transfer_id"`
+	ErrorCode    sql.NullInt32  `db:"error_code" json:"error_code"`
+	ErrorMessage sql.NullString `db:"error_message" json:"error_message"`
+}
+
+func (q *Queries) CreateTransferTransaction(ctx context.Context, arg CreateTransferTransactionParams) (CreateTransferTransactionRow, error) {
 	row := q.db.QueryRowContext(ctx, createTransferTransaction, arg.ParamFromAccountID, arg.ParamToAccountID, arg.ParamAmount)
-	var result interface{}
-	err := row.Scan(&result)
-	return result, err
+	var i CreateTransferTransactionRow
+	err := row.Scan(&i.TransferID, &i.ErrorCode, &i.ErrorMessage)
+	return i, err
+}
+
+const getAccountBalanceAt = `-- name: GetAccountBalanceAt :one
+SELECT get_account_balance_at($1, $2::timestamptz)::decimal(20, 6) AS balance
+`
+
+type GetAccountBalanceAtParams struct {
+	AccountID int64     `db:"account_id" json:"account_id"`
+	Cutoff    time.Time `db:"cutoff" json:"cutoff"`
+}
+
+func (q *Queries) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getAccountBalanceAt, arg.AccountID, arg.Cutoff)
+	var balance string
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const sumAccountCreditsBetween = `-- name: SumAccountCreditsBetween :one
+SELECT COALESCE(SUM(amount), 0)::decimal(20, 6) AS total
+FROM transactions
+WHERE account_id = $1
+  AND trx_type = 'CREDIT'
+  AND created_at >= $2::timestamptz
+  AND created_at < $3::timestamptz
+`
+
+type SumAccountCreditsBetweenParams struct {
+	AccountID int64     `db:"account_id" json:"account_id"`
+	FromTime  time.Time `db:"from_time" json:"from_time"`
+	ToTime    time.Time `db:"to_time" json:"to_time"`
+}
+
+func (q *Queries) SumAccountCreditsBetween(ctx context.Context, arg SumAccountCreditsBetweenParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountCreditsBetween, arg.AccountID, arg.FromTime, arg.ToTime)
+	var total string
+	err := row.Scan(&total)
+	return total, err
+}
+
+const listAccountTransactionsBetween = `-- name: ListAccountTransactionsBetween :many
+SELECT t.id, t.account_id, t.transfer_id, t.amount, t.trx_type, t.created_at,
+       COALESCE(CASE WHEN t.trx_type = 'CREDIT' THEN tr.from_account_id ELSE tr.to_account_id END, 0)::bigint AS counterparty_account_id
+FROM transactions t
+LEFT JOIN transfers tr ON tr.id = t.transfer_id
+WHERE t.account_id = $1
+  AND t.created_at >= $2::timestamptz
+  AND t.created_at < $3::timestamptz
+  AND t.id > $4
+ORDER BY t.id
+LIMIT $5
+`
+
+type ListAccountTransactionsBetweenParams struct {
+	AccountID int64     `db:"account_id" json:"account_id"`
+	FromTime  time.Time `db:"from_time" json:"from_time"`
+	ToTime    time.Time `db:"to_time" json:"to_time"`
+	AfterID   int64     `db:"after_id" json:"after_id"`
+	PageSize  int32     `db:"page_size" json:"page_size"`
+}
+
+type ListAccountTransactionsBetweenRow struct {
+	ID                    int64           `db:"id" json:"id"`
+	AccountID             int64           `db:"account_id" json:"account_id"`
+	TransferID            sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	Amount                decimal.Decimal `db:"amount" json:"amount"`
+	TrxType               string          `db:"trx_type" json:"trx_type"`
+	CreatedAt             sql.NullTime    `db:"created_at" json:"created_at"`
+	CounterpartyAccountID int64           `db:"counterparty_account_id" json:"counterparty_account_id"`
+}
+
+func (q *Queries) ListAccountTransactionsBetween(ctx context.Context, arg ListAccountTransactionsBetweenParams) ([]ListAccountTransactionsBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransactionsBetween,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountTransactionsBetweenRow{}
+	for rows.Next() {
+		var i ListAccountTransactionsBetweenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.TransferID,
+			&i.Amount,
+			&i.TrxType,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransactionsAfterID = `-- name: ListAccountTransactionsAfterID :many
+SELECT t.id, t.account_id, t.transfer_id, t.amount, t.trx_type, t.created_at,
+       COALESCE(CASE WHEN t.trx_type = 'CREDIT' THEN tr.from_account_id ELSE tr.to_account_id END, 0)::bigint AS counterparty_account_id
+FROM transactions t
+LEFT JOIN transfers tr ON tr.id = t.transfer_id
+WHERE t.account_id = $1
+  AND t.id > $2
+ORDER BY t.id
+LIMIT $3
+`
+
+type ListAccountTransactionsAfterIDParams struct {
+	AccountID int64 `db:"account_id" json:"account_id"`
+	AfterID   int64 `db:"after_id" json:"after_id"`
+	PageSize  int32 `db:"page_size" json:"page_size"`
+}
+
+type ListAccountTransactionsAfterIDRow struct {
+	ID                    int64           `db:"id" json:"id"`
+	AccountID             int64           `db:"account_id" json:"account_id"`
+	TransferID            sql.NullInt64   `db:"transfer_id" json:"transfer_id"`
+	Amount                decimal.Decimal `db:"amount" json:"amount"`
+	TrxType               string          `db:"trx_type" json:"trx_type"`
+	CreatedAt             sql.NullTime    `db:"created_at" json:"created_at"`
+	CounterpartyAccountID int64           `db:"counterparty_account_id" json:"counterparty_account_id"`
+}
+
+func (q *Queries) ListAccountTransactionsAfterID(ctx context.Context, arg ListAccountTransactionsAfterIDParams) ([]ListAccountTransactionsAfterIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransactionsAfterID, arg.AccountID, arg.AfterID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountTransactionsAfterIDRow{}
+	for rows.Next() {
+		var i ListAccountTransactionsAfterIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.TransferID,
+			&i.Amount,
+			&i.TrxType,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumAccountTransactionsAfterID = `-- name: SumAccountTransactionsAfterID :one
+SELECT COALESCE(SUM(CASE WHEN trx_type = 'CREDIT' THEN amount ELSE -amount END), 0)::decimal(20, 6) AS total
+FROM transactions
+WHERE account_id = $1
+  AND id > $2
+`
+
+type SumAccountTransactionsAfterIDParams struct {
+	AccountID int64 `db:"account_id" json:"account_id"`
+	AfterID   int64 `db:"after_id" json:"after_id"`
+}
+
+// The change of the balance since the transaction after_id
+func (q *Queries) SumAccountTransactionsAfterID(ctx context.Context, arg SumAccountTransactionsAfterIDParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountTransactionsAfterID, arg.AccountID, arg.AfterID)
+	var total string
+	err := row.Scan(&total)
+	return total, err
+}
+
+const getLastAccountTransactionID = `-- name: GetLastAccountTransactionID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS id
+FROM transactions
+WHERE account_id = $1
+`
+
+func (q *Queries) GetLastAccountTransactionID(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccountTransactionID, accountID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
 }
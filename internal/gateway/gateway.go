@@ -0,0 +1,58 @@
+// Package gateway tells the requests that come from the gateway in front of the service, the only
+// ones whose caller identity is trusted
+package gateway
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Networks are the addresses the gateway connects from
+type Networks []netip.Prefix
+
+// ParseNetworks parses CIDRs such as 10.0.0.0/8, a bare address stands for itself
+func ParseNetworks(cidrs []string) (Networks, error) {
+	networks := make(Networks, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gateway address %q: %w", cidr, err)
+			}
+			networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway network %q: %w", cidr, err)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// Contains tells whether the remote address of a connection, host:port or a bare host, is one of
+// the gateway's
+func (n Networks) Contains(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, network := range n {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bank/internal/requestid"
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+)
+
+type requestFieldsKey struct{}
+
+// requestFields are the fields set while a request is served. They are shared by pointer down the
+// handler chain, so the access log, which runs before routing and authentication, sees the fields
+// set deeper in the chain.
+type requestFields struct {
+	mu         sync.Mutex
+	principal  string
+	accountIDs []uint64
+}
+
+// WithRequestFields returns a copy of ctx that collects the fields set with SetPrincipal and
+// AddAccountIDs, for every log line of the request to carry them
+func WithRequestFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestFieldsKey{}, &requestFields{})
+}
+
+// SetPrincipal records the caller of the request. It does nothing outside a context made by
+// WithRequestFields.
+func SetPrincipal(ctx context.Context, principal string) {
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.principal = principal
+}
+
+// AddAccountIDs records the accounts the request acts on. It does nothing outside a context made
+// by WithRequestFields.
+func AddAccountIDs(ctx context.Context, accountIDs ...uint64) {
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	for _, id := range accountIDs {
+		if !slices.Contains(fields.accountIDs, id) {
+			fields.accountIDs = append(fields.accountIDs, id)
+		}
+	}
+}
+
+// requestHook adds the fields of the request in the context of the event: its id, its chi route
+// pattern, and the principal and accounts recorded so far, the account_id of the path included
+type requestHook struct{}
+
+func (requestHook) Run(e *zerolog.Event, level zerolog.Level, message string) {
+	ctx := e.GetCtx()
+	if id := requestid.FromContext(ctx); id != "" {
+		e.Str("request_id", id)
+	}
+
+	var accountIDs []uint64
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		if route := rctx.RoutePattern(); route != "" {
+			e.Str("route", route)
+		}
+		if id, err := strconv.ParseUint(rctx.URLParam("account_id"), 10, 64); err == nil {
+			accountIDs = append(accountIDs, id)
+		}
+	}
+
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if ok {
+		fields.mu.Lock()
+		if fields.principal != "" {
+			e.Str("principal", fields.principal)
+		}
+		for _, id := range fields.accountIDs {
+			if !slices.Contains(accountIDs, id) {
+				accountIDs = append(accountIDs, id)
+			}
+		}
+		fields.mu.Unlock()
+	}
+
+	if len(accountIDs) > 0 {
+		e.Uints64("account_ids", accountIDs)
+	}
+}
@@ -2,9 +2,11 @@ package logger
 
 import (
 	"context"
+	"io"
 	"os"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger struct {
@@ -12,19 +14,36 @@ type Logger struct {
 }
 
 func NewLogger(level string) *Logger {
+	return NewLoggerWithWriter(os.Stdout, level)
+}
+
+// NewLoggerWithWriter returns a logger writing its JSON lines to w
+func NewLoggerWithWriter(w io.Writer, level string) *Logger {
 	logLevel := parseLogLevel(level)
 
-	logger := zerolog.New(os.Stdout).
+	logger := zerolog.New(w).
 		Level(logLevel).
 		With().
 		Timestamp().
-		Logger()
+		Logger().
+		Hook(traceHook{}, requestHook{})
 
 	return &Logger{
 		logger: logger,
 	}
 }
 
+// traceHook adds the ids of the span in the context of the event, so a log line leads to its trace
+type traceHook struct{}
+
+func (traceHook) Run(e *zerolog.Event, level zerolog.Level, message string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+	e.Str("trace_id", spanContext.TraceID().String()).Str("span_id", spanContext.SpanID().String())
+}
+
 func parseLogLevel(level string) zerolog.Level {
 	switch level {
 	case "debug":
@@ -0,0 +1,202 @@
+// Package metrics holds the Prometheus collectors of the bank. They are registered on Registry,
+// which Handler serves; the web server and the worker each serve their own on a separate port.
+package metrics
+
+import (
+	"bank/entity"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shopspring/decimal"
+)
+
+const namespace = "bank"
+
+// Reasons a transfer failed, the values of the reason label
+const (
+	ReasonInsufficientFunds = "insufficient_funds"
+	ReasonAccountNotFound   = "account_not_found"
+	ReasonValidation        = "validation"
+	ReasonError             = "error"
+)
+
+// APIs that serve calls, the values of the api label
+const (
+	APIHTTP = "http"
+	APIGRPC = "grpc"
+)
+
+// Registry holds every collector of the process, the Go runtime and process ones included
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, chi route pattern and status code.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	transfers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Customer transfers by outcome, and by reason for the failed ones.",
+	}, []string{"outcome", "reason"})
+
+	transferAmount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount",
+		Help:      "Amounts of the succeeded customer transfers by currency.",
+		Buckets:   prometheus.ExponentialBuckets(1, 10, 8),
+	}, []string{"currency"})
+
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "worker_job_runs_total",
+		Help:      "Runs of the worker jobs by job and outcome.",
+	}, []string{"job", "outcome"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "worker_job_duration_seconds",
+		Help:      "Duration of the worker job runs by job.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"job"})
+
+	auditWriteFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_write_failures_total",
+		Help:      "Audit records of calls that failed to be written, by API.",
+	}, []string{"api"})
+
+	balanceSnapshots = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "balance_snapshots_total",
+		Help:      "Balance snapshots recorded.",
+	})
+)
+
+// lastSnapshotRun is when the balance snapshots last caught up with the ledger, the start of the
+// process until they first do
+var lastSnapshotRun = struct {
+	sync.Mutex
+	at time.Time
+}{at: time.Now()}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		transfers,
+		transferAmount,
+		jobRuns,
+		jobDuration,
+		auditWriteFailures,
+	)
+
+	// The series exist from the start, so an alert on their increase fires on the first failure
+	auditWriteFailures.WithLabelValues(APIHTTP)
+	auditWriteFailures.WithLabelValues(APIGRPC)
+}
+
+// Handler serves the collectors of Registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exposes the connection pool stats of db, the sql.DB.Stats gauges and counters
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records a served HTTP request. route is the chi route pattern, so ids in paths
+// don't make a series each.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	httpRequests.With(labels).Inc()
+	httpRequestDuration.With(labels).Observe(duration.Seconds())
+}
+
+// TransferSucceeded records a committed customer transfer
+func TransferSucceeded(currency entity.CurrencyCode, amount decimal.Decimal) {
+	transfers.WithLabelValues("succeeded", "").Inc()
+	transferAmount.WithLabelValues(string(currency)).Observe(amount.InexactFloat64())
+}
+
+// TransferFailed records a customer transfer that was refused or failed, reason is one of the
+// Reason constants
+func TransferFailed(reason string) {
+	transfers.WithLabelValues("failed", reason).Inc()
+}
+
+// AuditWriteFailed records an audit record of a call that failed to be written: the call was
+// refused, or it was served without the record of its outcome. api is one of the API constants
+func AuditWriteFailed(api string) {
+	auditWriteFailures.WithLabelValues(api).Inc()
+}
+
+// ObserveJob records a run of a worker job
+func ObserveJob(job string, duration time.Duration, err error) {
+	outcome := "succeeded"
+	if err != nil {
+		outcome = "failed"
+	}
+	jobRuns.WithLabelValues(job, outcome).Inc()
+	jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
+// RegisterBalanceSnapshots exposes the balance snapshot metrics, in the process that runs the
+// balance snapshots only: elsewhere the lag would grow forever
+func RegisterBalanceSnapshots() error {
+	lag := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "balance_snapshot_lag_seconds",
+		Help:      "Seconds since the balance snapshots last caught up with the ledger.",
+	}, func() float64 {
+		lastSnapshotRun.Lock()
+		defer lastSnapshotRun.Unlock()
+		return time.Since(lastSnapshotRun.at).Seconds()
+	})
+
+	for _, c := range []prometheus.Collector{balanceSnapshots, lag} {
+		if err := Registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BalanceSnapshotsTaken records a run of the balance snapshots that snapshotted every account
+// with new transactions
+func BalanceSnapshotsTaken(snapshotted int) {
+	balanceSnapshots.Add(float64(snapshotted))
+
+	lastSnapshotRun.Lock()
+	defer lastSnapshotRun.Unlock()
+	lastSnapshotRun.at = time.Now()
+}
+
+// NewServer serves Handler on path, on a listener of its own so the API port never exposes it
+func NewServer(addr, path string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(path, Handler())
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
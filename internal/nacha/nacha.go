@@ -0,0 +1,236 @@
+// Package nacha reads and writes NACHA ACH files: fixed width 94 character records grouped in
+// batches, with control records carrying counts, hashes and totals of what they close.
+package nacha
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const recordLength = 94
+
+// blockingFactor is the number of records per block, files are padded with 9s to a full block
+const blockingFactor = 10
+
+// Record type codes, the first character of every record
+const (
+	recordFileHeader   = '1'
+	recordBatchHeader  = '5'
+	recordEntryDetail  = '6'
+	recordAddenda      = '7'
+	recordBatchControl = '8'
+	recordFileControl  = '9'
+)
+
+// Service class codes of a batch
+const (
+	ServiceClassMixed   = 200
+	ServiceClassCredits = 220
+	ServiceClassDebits  = 225
+)
+
+// Transaction codes of entry details
+const (
+	CheckingReturnCredit  = 21
+	CheckingCredit        = 22
+	CheckingCreditPrenote = 23
+	CheckingReturnDebit   = 26
+	CheckingDebit         = 27
+	CheckingDebitPrenote  = 28
+	SavingsReturnCredit   = 31
+	SavingsCredit         = 32
+	SavingsCreditPrenote  = 33
+	SavingsReturnDebit    = 36
+	SavingsDebit          = 37
+	SavingsDebitPrenote   = 38
+)
+
+// Addenda type codes
+const (
+	AddendaPaymentRelated = "05"
+	AddendaReturn         = "99"
+)
+
+type File struct {
+	Header  FileHeader
+	Batches []Batch
+}
+
+type FileHeader struct {
+	// ImmediateDestination and ImmediateOrigin are routing numbers, usually with a leading space
+	ImmediateDestination     string
+	ImmediateOrigin          string
+	CreatedAt                time.Time
+	FileIDModifier           string
+	ImmediateDestinationName string
+	ImmediateOriginName      string
+	ReferenceCode            string
+}
+
+type Batch struct {
+	Header  BatchHeader
+	Entries []Entry
+}
+
+type BatchHeader struct {
+	ServiceClassCode         int
+	CompanyName              string
+	CompanyDiscretionaryData string
+	CompanyIdentification    string
+	StandardEntryClassCode   string
+	CompanyEntryDescription  string
+	CompanyDescriptiveDate   string
+	// EffectiveEntryDate is YYMMDD as the originator wrote it
+	EffectiveEntryDate   string
+	OriginatorStatusCode string
+	// OriginatingDFI is the first 8 digits of the originating bank's routing number
+	OriginatingDFI string
+	BatchNumber    int
+}
+
+type Entry struct {
+	TransactionCode int
+	// RDFIRoutingNumber is the first 8 digits of the receiving bank's routing number, followed
+	// by CheckDigit
+	RDFIRoutingNumber  string
+	CheckDigit         string
+	DFIAccountNumber   string
+	Amount             int64 // cents
+	IndividualIDNumber string
+	IndividualName     string
+	DiscretionaryData  string
+	TraceNumber        string
+	Addenda            []Addenda
+	// Return is the addenda of a return entry
+	Return *ReturnAddenda
+}
+
+type Addenda struct {
+	PaymentRelatedInformation string
+	SequenceNumber            int
+}
+
+type ReturnAddenda struct {
+	ReturnReasonCode    string
+	OriginalTraceNumber string
+	DateOfDeath         string
+	OriginalRDFI        string
+	AddendaInformation  string
+}
+
+// IsCredit reports whether the entry moves money into the receiver's account
+func (e Entry) IsCredit() bool {
+	switch e.TransactionCode {
+	case CheckingCredit, CheckingCreditPrenote, CheckingReturnCredit, SavingsCredit, SavingsCreditPrenote, SavingsReturnCredit:
+		return true
+	}
+	return false
+}
+
+// IsPrenote reports whether the entry is a zero dollar account validation
+func (e Entry) IsPrenote() bool {
+	switch e.TransactionCode {
+	case CheckingCreditPrenote, CheckingDebitPrenote, SavingsCreditPrenote, SavingsDebitPrenote:
+		return true
+	}
+	return false
+}
+
+// IsReturn reports whether the entry returns an entry the receiver originated
+func (e Entry) IsReturn() bool {
+	switch e.TransactionCode {
+	case CheckingReturnCredit, CheckingReturnDebit, SavingsReturnCredit, SavingsReturnDebit:
+		return true
+	}
+	return false
+}
+
+func (e Entry) addendaCount() int {
+	if e.Return != nil {
+		return len(e.Addenda) + 1
+	}
+	return len(e.Addenda)
+}
+
+// ReturnTransactionCode is the code of the entry that returns an entry or prenote with the given code
+func ReturnTransactionCode(code int) (int, error) {
+	switch code {
+	case CheckingCredit, CheckingCreditPrenote:
+		return CheckingReturnCredit, nil
+	case CheckingDebit, CheckingDebitPrenote:
+		return CheckingReturnDebit, nil
+	case SavingsCredit, SavingsCreditPrenote:
+		return SavingsReturnCredit, nil
+	case SavingsDebit, SavingsDebitPrenote:
+		return SavingsReturnDebit, nil
+	}
+	return 0, fmt.Errorf("transaction code %d can't be returned", code)
+}
+
+func validTransactionCode(code int) bool {
+	switch code {
+	case CheckingReturnCredit, CheckingCredit, CheckingCreditPrenote,
+		CheckingReturnDebit, CheckingDebit, CheckingDebitPrenote,
+		SavingsReturnCredit, SavingsCredit, SavingsCreditPrenote,
+		SavingsReturnDebit, SavingsDebit, SavingsDebitPrenote:
+		return true
+	}
+	return false
+}
+
+// CheckDigit computes the check digit of the first 8 digits of a routing number
+func CheckDigit(routing string) (string, error) {
+	if len(routing) != 8 {
+		return "", fmt.Errorf("routing number %q must have 8 digits", routing)
+	}
+
+	weights := [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+	sum := 0
+	for i, c := range routing {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("routing number %q must have 8 digits", routing)
+		}
+		sum += int(c-'0') * weights[i]
+	}
+	return fmt.Sprint((10 - sum%10) % 10), nil
+}
+
+// entryHash sums the receiving routing numbers, keeping the 10 rightmost digits
+func entryHash(hash int64, e Entry) int64 {
+	routing, _ := strconv.ParseInt(e.RDFIRoutingNumber, 10, 64)
+	return (hash + routing) % 10_000_000_000
+}
+
+// totals are the values control records carry
+type totals struct {
+	entryAddendaCount int
+	entryHash         int64
+	totalDebit        int64
+	totalCredit       int64
+}
+
+func (t *totals) addEntry(e Entry) {
+	t.entryAddendaCount += 1 + e.addendaCount()
+	t.entryHash = entryHash(t.entryHash, e)
+	if e.IsCredit() {
+		t.totalCredit += e.Amount
+	} else {
+		t.totalDebit += e.Amount
+	}
+}
+
+func (t *totals) add(other totals) {
+	t.entryAddendaCount += other.entryAddendaCount
+	t.entryHash = (t.entryHash + other.entryHash) % 10_000_000_000
+	t.totalDebit += other.totalDebit
+	t.totalCredit += other.totalCredit
+}
+
+func batchTotals(b Batch) totals {
+	t := totals{}
+	for _, e := range b.Entries {
+		t.addEntry(e)
+	}
+	return t
+}
@@ -0,0 +1,262 @@
+package nacha_test
+
+import (
+	"bank/internal/nacha"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleFile() *nacha.File {
+	return &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination:     " 091000019",
+			ImmediateOrigin:          " 071000013",
+			CreatedAt:                time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC),
+			FileIDModifier:           "A",
+			ImmediateDestinationName: "BANK",
+			ImmediateOriginName:      "SPONSOR BANK",
+			ReferenceCode:            "REF1",
+		},
+		Batches: []nacha.Batch{
+			{
+				Header: nacha.BatchHeader{
+					ServiceClassCode:        nacha.ServiceClassMixed,
+					CompanyName:             "ACME PAYROLL",
+					CompanyIdentification:   "1234567890",
+					StandardEntryClassCode:  "PPD",
+					CompanyEntryDescription: "PAYROLL",
+					EffectiveEntryDate:      "261019",
+					OriginatorStatusCode:    "1",
+					OriginatingDFI:          "07100001",
+					BatchNumber:             1,
+				},
+				Entries: []nacha.Entry{
+					{
+						TransactionCode:    nacha.CheckingCredit,
+						RDFIRoutingNumber:  "09100001",
+						CheckDigit:         "9",
+						DFIAccountNumber:   "500",
+						Amount:             125050,
+						IndividualIDNumber: "EMP001",
+						IndividualName:     "JANE DOE",
+						TraceNumber:        "071000010000001",
+						Addenda: []nacha.Addenda{
+							{PaymentRelatedInformation: "OCTOBER SALARY", SequenceNumber: 1},
+						},
+					},
+					{
+						TransactionCode:   nacha.SavingsDebit,
+						RDFIRoutingNumber: "09100001",
+						CheckDigit:        "9",
+						DFIAccountNumber:  "501",
+						Amount:            2000,
+						IndividualName:    "JOHN DOE",
+						TraceNumber:       "071000010000002",
+						Addenda:           []nacha.Addenda{},
+					},
+				},
+			},
+			{
+				Header: nacha.BatchHeader{
+					ServiceClassCode:        nacha.ServiceClassDebits,
+					CompanyName:             "UTILITY CO",
+					CompanyIdentification:   "9876543210",
+					StandardEntryClassCode:  "WEB",
+					CompanyEntryDescription: "BILL",
+					EffectiveEntryDate:      "261019",
+					OriginatorStatusCode:    "1",
+					OriginatingDFI:          "07100001",
+					BatchNumber:             2,
+				},
+				Entries: []nacha.Entry{
+					{
+						TransactionCode:   nacha.CheckingReturnCredit,
+						RDFIRoutingNumber: "09100001",
+						CheckDigit:        "9",
+						DFIAccountNumber:  "502",
+						Amount:            999,
+						IndividualName:    "RETURNED",
+						TraceNumber:       "071000010000003",
+						Addenda:           []nacha.Addenda{},
+						Return: &nacha.ReturnAddenda{
+							ReturnReasonCode:    "R01",
+							OriginalTraceNumber: "091000010000009",
+							OriginalRDFI:        "07100001",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func write(t *testing.T, f *nacha.File) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := nacha.Write(&buf, f); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriteAndParse(t *testing.T) {
+	content := write(t, sampleFile())
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines)%10 != 0 {
+		t.Errorf("expected the file to be padded to a full block, got %d records", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 94 {
+			t.Errorf("line %d: expected 94 characters, got %d", i+1, len(line))
+		}
+	}
+
+	expectedRecords := map[int]string{
+		// batch 1 control: 3 entries and addenda, hash 2 x 09100001, 20.00 debit and 1250.50 credit
+		6: "82000000030018200002000000002000000000125050",
+		// file control: 2 batches, 2 blocks, 5 entries and addenda, debit 20.00, credit 1250.50 + 9.99 return
+		11: "9000002000002000000050027300003000000002000000000126049",
+	}
+	for i, prefix := range expectedRecords {
+		if !strings.HasPrefix(lines[i-1], prefix) {
+			t.Errorf("line %d: expected prefix %s, got %s", i, prefix, lines[i-1])
+		}
+	}
+
+	parsed, err := nacha.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("failed to parse file: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, sampleFile()) {
+		t.Errorf("expected parsed file to equal the written one\nexpected: %+v\ngot:      %+v", sampleFile(), parsed)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid := strings.Split(write(t, sampleFile()), "\n")
+
+	testCases := []struct {
+		name          string
+		modify        func(lines []string) []string
+		expectedLine  int
+		expectedError string
+	}{
+		{
+			name: "short record",
+			modify: func(lines []string) []string {
+				lines[2] = lines[2][:90]
+				return lines
+			},
+			expectedLine:  3,
+			expectedError: "record must be 94 characters",
+		},
+		{
+			name: "batch total credit",
+			modify: func(lines []string) []string {
+				lines[5] = lines[5][:32] + "000000125051" + lines[5][44:]
+				return lines
+			},
+			expectedLine:  6,
+			expectedError: "total credit amount is 125051, records add up to 125050",
+		},
+		{
+			name: "entry hash",
+			modify: func(lines []string) []string {
+				lines[5] = lines[5][:10] + "0000000001" + lines[5][20:]
+				return lines
+			},
+			expectedLine:  6,
+			expectedError: "entry hash is 1, records add up to 18200002",
+		},
+		{
+			name: "file batch count",
+			modify: func(lines []string) []string {
+				lines[10] = "9000003" + lines[10][7:]
+				return lines
+			},
+			expectedLine:  11,
+			expectedError: "batch count is 3, records add up to 2",
+		},
+		{
+			name: "check digit",
+			modify: func(lines []string) []string {
+				lines[2] = lines[2][:11] + "1" + lines[2][12:]
+				return lines
+			},
+			expectedLine:  3,
+			expectedError: "invalid check digit",
+		},
+		{
+			name: "missing addenda",
+			modify: func(lines []string) []string {
+				return append(lines[:3], lines[4:]...)
+			},
+			expectedLine:  4,
+			expectedError: "has addenda record indicator 1 but no addenda",
+		},
+		{
+			name: "duplicate trace number",
+			modify: func(lines []string) []string {
+				lines[4] = lines[4][:79] + lines[2][79:]
+				return lines
+			},
+			expectedLine:  5,
+			expectedError: "duplicate trace number 071000010000001, first used on line 3",
+		},
+		{
+			name: "missing file control",
+			modify: func(lines []string) []string {
+				return lines[:10]
+			},
+			expectedLine:  10,
+			expectedError: "missing file control",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := tc.modify(append([]string{}, valid...))
+			_, err := nacha.Parse(strings.NewReader(strings.Join(lines, "\n")))
+
+			var parseErr *nacha.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a parse error, got %v", err)
+			}
+
+			if parseErr.Line != tc.expectedLine || !strings.Contains(parseErr.Msg, tc.expectedError) {
+				t.Errorf("expected %q on line %d, got %v", tc.expectedError, tc.expectedLine, err)
+			}
+		})
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	testCases := []struct {
+		routing  string
+		expected string
+	}{
+		{routing: "09100001", expected: "9"},
+		{routing: "07100001", expected: "3"},
+		{routing: "02100002", expected: "1"},
+	}
+
+	for _, tc := range testCases {
+		digit, err := nacha.CheckDigit(tc.routing)
+		if err != nil {
+			t.Fatalf("failed to compute check digit of %s: %v", tc.routing, err)
+		}
+		if digit != tc.expected {
+			t.Errorf("expected check digit of %s to be %s, got %s", tc.routing, tc.expected, digit)
+		}
+	}
+
+	if _, err := nacha.CheckDigit("0910000A"); err == nil {
+		t.Error("expected an error for a non numeric routing number")
+	}
+}
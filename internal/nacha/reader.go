@@ -0,0 +1,379 @@
+package nacha
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError reports the line of a malformed record or of a control record whose totals don't
+// match the records it closes
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// record is one line, fields are read by their 1-based positions as the specification lists them
+type record string
+
+func (r record) field(start, end int) string {
+	return strings.TrimSpace(string(r[start-1 : end]))
+}
+
+// raw keeps the padding, for fields such as the immediate destination where it is significant
+func (r record) raw(start, end int) string {
+	return string(r[start-1 : end])
+}
+
+func (r record) number(start, end int) (int64, error) {
+	value := r.raw(start, end)
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || strings.TrimSpace(value) != value {
+		return 0, fmt.Errorf("positions %d-%d must be numeric, got %q", start, end, value)
+	}
+	return n, nil
+}
+
+type reader struct {
+	line int
+	file *File
+	// batch is the open batch, nil between a batch control and the next batch header
+	batch *Batch
+	// hasAddenda is the addenda record indicator of the last entry, addendaRead counts its addenda
+	hasAddenda  bool
+	addendaRead int
+	fileTotals  totals
+	// traceNumbers are unique within a file, they identify the entries
+	traceNumbers map[string]int
+	records      int
+	done         bool
+}
+
+// Parse reads a NACHA file and validates its structure and the control records
+func Parse(r io.Reader) (*File, error) {
+	scanner := bufio.NewScanner(r)
+	rd := &reader{traceNumbers: map[string]int{}}
+	for scanner.Scan() {
+		rd.line++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if err := rd.read(record(line)); err != nil {
+			return nil, &ParseError{Line: rd.line, Msg: err.Error()}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if rd.file == nil {
+		return nil, &ParseError{Line: rd.line, Msg: "missing file header"}
+	}
+
+	if !rd.done {
+		return nil, &ParseError{Line: rd.line, Msg: "missing file control"}
+	}
+
+	return rd.file, nil
+}
+
+func (rd *reader) read(r record) error {
+	if len(r) != recordLength {
+		return fmt.Errorf("record must be %d characters, got %d", recordLength, len(r))
+	}
+
+	if rd.done {
+		// Blocks are padded with records of 9s after the file control
+		if strings.Trim(string(r), "9") == "" {
+			return nil
+		}
+		return fmt.Errorf("unexpected record after file control")
+	}
+
+	rd.records++
+	switch r[0] {
+	case recordFileHeader:
+		return rd.readFileHeader(r)
+	case recordBatchHeader:
+		return rd.readBatchHeader(r)
+	case recordEntryDetail:
+		return rd.readEntry(r)
+	case recordAddenda:
+		return rd.readAddenda(r)
+	case recordBatchControl:
+		return rd.readBatchControl(r)
+	case recordFileControl:
+		return rd.readFileControl(r)
+	default:
+		return fmt.Errorf("unknown record type %q", r[0])
+	}
+}
+
+func (rd *reader) readFileHeader(r record) error {
+	if rd.file != nil {
+		return fmt.Errorf("duplicate file header")
+	}
+
+	if r.raw(35, 37) != "094" {
+		return fmt.Errorf("record size must be 094, got %q", r.raw(35, 37))
+	}
+
+	createdAt, err := time.Parse("0601021504", r.raw(24, 33))
+	if err != nil {
+		return fmt.Errorf("invalid file creation date and time %q", r.raw(24, 33))
+	}
+
+	rd.file = &File{
+		Header: FileHeader{
+			ImmediateDestination:     r.raw(4, 13),
+			ImmediateOrigin:          r.raw(14, 23),
+			CreatedAt:                createdAt,
+			FileIDModifier:           r.raw(34, 34),
+			ImmediateDestinationName: r.field(41, 63),
+			ImmediateOriginName:      r.field(64, 86),
+			ReferenceCode:            r.field(87, 94),
+		},
+		Batches: []Batch{},
+	}
+	return nil
+}
+
+func (rd *reader) readBatchHeader(r record) error {
+	if rd.file == nil {
+		return fmt.Errorf("batch header before file header")
+	}
+
+	if rd.batch != nil {
+		return fmt.Errorf("batch header before the control of the previous batch")
+	}
+
+	serviceClass, err := r.number(2, 4)
+	if err != nil {
+		return fmt.Errorf("invalid service class code: %w", err)
+	}
+
+	batchNumber, err := r.number(88, 94)
+	if err != nil {
+		return fmt.Errorf("invalid batch number: %w", err)
+	}
+
+	rd.batch = &Batch{
+		Header: BatchHeader{
+			ServiceClassCode:         int(serviceClass),
+			CompanyName:              r.field(5, 20),
+			CompanyDiscretionaryData: r.field(21, 40),
+			CompanyIdentification:    r.field(41, 50),
+			StandardEntryClassCode:   r.field(51, 53),
+			CompanyEntryDescription:  r.field(54, 63),
+			CompanyDescriptiveDate:   r.field(64, 69),
+			EffectiveEntryDate:       r.field(70, 75),
+			OriginatorStatusCode:     r.field(79, 79),
+			OriginatingDFI:           r.field(80, 87),
+			BatchNumber:              int(batchNumber),
+		},
+		Entries: []Entry{},
+	}
+	return nil
+}
+
+func (rd *reader) readEntry(r record) error {
+	if rd.batch == nil {
+		return fmt.Errorf("entry detail outside a batch")
+	}
+
+	if err := rd.checkAddenda(); err != nil {
+		return err
+	}
+
+	code, err := r.number(2, 3)
+	if err != nil || !validTransactionCode(int(code)) {
+		return fmt.Errorf("unsupported transaction code %q", r.raw(2, 3))
+	}
+
+	if _, err := r.number(4, 11); err != nil {
+		return fmt.Errorf("invalid receiving DFI identification: %w", err)
+	}
+
+	checkDigit, err := CheckDigit(r.raw(4, 11))
+	if err != nil || checkDigit != r.raw(12, 12) {
+		return fmt.Errorf("invalid check digit %q for routing number %s", r.raw(12, 12), r.raw(4, 11))
+	}
+
+	amount, err := r.number(30, 39)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
+	if first, ok := rd.traceNumbers[r.raw(80, 94)]; ok {
+		return fmt.Errorf("duplicate trace number %s, first used on line %d", r.raw(80, 94), first)
+	}
+	rd.traceNumbers[r.raw(80, 94)] = rd.line
+
+	switch r.raw(79, 79) {
+	case "0":
+		rd.hasAddenda = false
+	case "1":
+		rd.hasAddenda = true
+	default:
+		return fmt.Errorf("invalid addenda record indicator %q", r.raw(79, 79))
+	}
+	rd.addendaRead = 0
+
+	rd.batch.Entries = append(rd.batch.Entries, Entry{
+		TransactionCode:    int(code),
+		RDFIRoutingNumber:  r.raw(4, 11),
+		CheckDigit:         checkDigit,
+		DFIAccountNumber:   r.field(13, 29),
+		Amount:             amount,
+		IndividualIDNumber: r.field(40, 54),
+		IndividualName:     r.field(55, 76),
+		DiscretionaryData:  r.field(77, 78),
+		TraceNumber:        r.raw(80, 94),
+		Addenda:            []Addenda{},
+	})
+	return nil
+}
+
+// checkAddenda fails when the last entry announced addenda that never came
+func (rd *reader) checkAddenda() error {
+	if rd.batch == nil || len(rd.batch.Entries) == 0 {
+		return nil
+	}
+
+	if rd.hasAddenda && rd.addendaRead == 0 {
+		return fmt.Errorf("entry %s has addenda record indicator 1 but no addenda", rd.batch.Entries[len(rd.batch.Entries)-1].TraceNumber)
+	}
+	return nil
+}
+
+func (rd *reader) readAddenda(r record) error {
+	if rd.batch == nil || len(rd.batch.Entries) == 0 {
+		return fmt.Errorf("addenda without an entry detail")
+	}
+
+	entry := &rd.batch.Entries[len(rd.batch.Entries)-1]
+	if !rd.hasAddenda {
+		return fmt.Errorf("addenda for an entry with addenda record indicator 0")
+	}
+	rd.addendaRead++
+
+	switch r.raw(2, 3) {
+	case AddendaPaymentRelated:
+		sequence, err := r.number(84, 87)
+		if err != nil {
+			return fmt.Errorf("invalid addenda sequence number: %w", err)
+		}
+		entry.Addenda = append(entry.Addenda, Addenda{
+			PaymentRelatedInformation: r.field(4, 83),
+			SequenceNumber:            int(sequence),
+		})
+	case AddendaReturn:
+		if entry.Return != nil {
+			return fmt.Errorf("duplicate return addenda")
+		}
+		entry.Return = &ReturnAddenda{
+			ReturnReasonCode:    r.field(4, 6),
+			OriginalTraceNumber: r.field(7, 21),
+			DateOfDeath:         r.field(22, 27),
+			OriginalRDFI:        r.field(28, 35),
+			AddendaInformation:  r.field(36, 79),
+		}
+	default:
+		return fmt.Errorf("unsupported addenda type code %q", r.raw(2, 3))
+	}
+	return nil
+}
+
+func (rd *reader) readBatchControl(r record) error {
+	if rd.batch == nil {
+		return fmt.Errorf("batch control without a batch header")
+	}
+
+	if err := rd.checkAddenda(); err != nil {
+		return err
+	}
+
+	batch := rd.batch
+	expected := batchTotals(*batch)
+	if err := checkNumber(r, 2, 4, "service class code", int64(batch.Header.ServiceClassCode)); err != nil {
+		return err
+	}
+	if err := checkTotals(r, expected, [4][2]int{{5, 10}, {11, 20}, {21, 32}, {33, 44}}); err != nil {
+		return err
+	}
+	if err := checkNumber(r, 88, 94, "batch number", int64(batch.Header.BatchNumber)); err != nil {
+		return err
+	}
+
+	rd.file.Batches = append(rd.file.Batches, *batch)
+	rd.fileTotals.add(expected)
+	rd.batch = nil
+	return nil
+}
+
+func (rd *reader) readFileControl(r record) error {
+	if rd.file == nil {
+		return fmt.Errorf("file control before file header")
+	}
+
+	if rd.batch != nil {
+		return fmt.Errorf("file control before the control of the last batch")
+	}
+
+	if err := checkNumber(r, 2, 7, "batch count", int64(len(rd.file.Batches))); err != nil {
+		return err
+	}
+
+	blocks := (rd.records + blockingFactor - 1) / blockingFactor
+	if err := checkNumber(r, 8, 13, "block count", int64(blocks)); err != nil {
+		return err
+	}
+
+	if err := checkTotals(r, rd.fileTotals, [4][2]int{{14, 21}, {22, 31}, {32, 43}, {44, 55}}); err != nil {
+		return err
+	}
+
+	rd.done = true
+	return nil
+}
+
+// checkTotals compares the entry/addenda count, entry hash, total debit and total credit at the
+// given positions
+func checkTotals(r record, t totals, positions [4][2]int) error {
+	fields := []struct {
+		name     string
+		expected int64
+	}{
+		{"entry/addenda count", int64(t.entryAddendaCount)},
+		{"entry hash", t.entryHash},
+		{"total debit amount", t.totalDebit},
+		{"total credit amount", t.totalCredit},
+	}
+	for i, f := range fields {
+		if err := checkNumber(r, positions[i][0], positions[i][1], f.name, f.expected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkNumber(r record, start, end int, name string, expected int64) error {
+	value, err := r.number(start, end)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if value != expected {
+		return fmt.Errorf("%s is %d, records add up to %d", name, value, expected)
+	}
+	return nil
+}
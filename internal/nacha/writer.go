@@ -0,0 +1,145 @@
+package nacha
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Write renders the file with its control records and pads it to a full block
+func Write(w io.Writer, f *File) error {
+	out := &writer{w: bufio.NewWriter(w)}
+
+	h := f.Header
+	out.record(
+		string(recordFileHeader), "01",
+		alpha(h.ImmediateDestination, 10), alpha(h.ImmediateOrigin, 10),
+		h.CreatedAt.Format("0601021504"), alpha(h.FileIDModifier, 1),
+		"094", "10", "1",
+		alpha(h.ImmediateDestinationName, 23), alpha(h.ImmediateOriginName, 23), alpha(h.ReferenceCode, 8),
+	)
+
+	fileTotals := totals{}
+	for _, b := range f.Batches {
+		bh := b.Header
+		out.record(
+			string(recordBatchHeader), numeric(int64(bh.ServiceClassCode), 3),
+			alpha(bh.CompanyName, 16), alpha(bh.CompanyDiscretionaryData, 20), alpha(bh.CompanyIdentification, 10),
+			alpha(bh.StandardEntryClassCode, 3), alpha(bh.CompanyEntryDescription, 10),
+			alpha(bh.CompanyDescriptiveDate, 6), alpha(bh.EffectiveEntryDate, 6),
+			alpha("", 3), alpha(bh.OriginatorStatusCode, 1), alpha(bh.OriginatingDFI, 8),
+			numeric(int64(bh.BatchNumber), 7),
+		)
+
+		for _, e := range b.Entries {
+			if err := out.entry(e); err != nil {
+				return err
+			}
+		}
+
+		t := batchTotals(b)
+		out.record(
+			string(recordBatchControl), numeric(int64(bh.ServiceClassCode), 3),
+			numeric(int64(t.entryAddendaCount), 6), numeric(t.entryHash, 10),
+			numeric(t.totalDebit, 12), numeric(t.totalCredit, 12),
+			alpha(bh.CompanyIdentification, 10), alpha("", 19), alpha("", 6),
+			alpha(bh.OriginatingDFI, 8), numeric(int64(bh.BatchNumber), 7),
+		)
+		fileTotals.add(t)
+	}
+
+	records := out.records + 1
+	blocks := (records + blockingFactor - 1) / blockingFactor
+	out.record(
+		string(recordFileControl), numeric(int64(len(f.Batches)), 6), numeric(int64(blocks), 6),
+		numeric(int64(fileTotals.entryAddendaCount), 8), numeric(fileTotals.entryHash, 10),
+		numeric(fileTotals.totalDebit, 12), numeric(fileTotals.totalCredit, 12), alpha("", 39),
+	)
+
+	for out.records%blockingFactor != 0 {
+		out.record(strings.Repeat("9", recordLength))
+	}
+
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+type writer struct {
+	w       *bufio.Writer
+	records int
+	err     error
+}
+
+func (out *writer) entry(e Entry) error {
+	if !validTransactionCode(e.TransactionCode) {
+		return fmt.Errorf("unsupported transaction code %d", e.TransactionCode)
+	}
+
+	checkDigit, err := CheckDigit(e.RDFIRoutingNumber)
+	if err != nil {
+		return err
+	}
+
+	if len(e.TraceNumber) != 15 {
+		return fmt.Errorf("trace number %q must have 15 digits", e.TraceNumber)
+	}
+
+	addendaIndicator := "0"
+	if e.addendaCount() > 0 {
+		addendaIndicator = "1"
+	}
+
+	out.record(
+		string(recordEntryDetail), numeric(int64(e.TransactionCode), 2),
+		e.RDFIRoutingNumber, checkDigit, alpha(e.DFIAccountNumber, 17), numeric(e.Amount, 10),
+		alpha(e.IndividualIDNumber, 15), alpha(e.IndividualName, 22), alpha(e.DiscretionaryData, 2),
+		addendaIndicator, e.TraceNumber,
+	)
+
+	for _, a := range e.Addenda {
+		out.record(
+			string(recordAddenda), AddendaPaymentRelated, alpha(a.PaymentRelatedInformation, 80),
+			numeric(int64(a.SequenceNumber), 4), e.TraceNumber[8:],
+		)
+	}
+
+	if r := e.Return; r != nil {
+		out.record(
+			string(recordAddenda), AddendaReturn, alpha(r.ReturnReasonCode, 3), alpha(r.OriginalTraceNumber, 15),
+			alpha(r.DateOfDeath, 6), alpha(r.OriginalRDFI, 8), alpha(r.AddendaInformation, 44), e.TraceNumber,
+		)
+	}
+	return nil
+}
+
+func (out *writer) record(fields ...string) {
+	if out.err != nil {
+		return
+	}
+
+	line := strings.Join(fields, "")
+	if len(line) != recordLength {
+		out.err = fmt.Errorf("record %q must be %d characters, got %d", line, recordLength, len(line))
+		return
+	}
+
+	out.records++
+	_, out.err = out.w.WriteString(line + "\n")
+}
+
+// alpha left justifies s in a field of the given width, truncating what doesn't fit
+func alpha(s string, width int) string {
+	s = strings.ToUpper(s)
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// numeric right justifies n with zeros in a field of the given width
+func numeric(n int64, width int) string {
+	return fmt.Sprintf("%0*d", width, n)
+}
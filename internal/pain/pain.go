@@ -0,0 +1,251 @@
+// Package pain writes ISO 20022 customer credit transfer initiations (pain.001) for the clearing
+// partner and reads the payment status reports (pain.002) it sends back.
+package pain
+
+import (
+	"bank/entity"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	CreditTransferNamespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+	// statusReportNamespacePrefix accepts any pain.002 version, the elements we read are the same
+	statusReportNamespacePrefix = "urn:iso:std:iso:20022:tech:xsd:pain.002."
+)
+
+// Payment status codes from the ISO 20022 ExternalPaymentTransactionStatus code set
+const (
+	StatusAcceptedTechnicalValidation = "ACTC"
+	StatusAcceptedCustomerProfile     = "ACCP"
+	StatusAcceptedSettlementInProcess = "ACSP"
+	StatusAcceptedSettlementCompleted = "ACSC"
+	StatusAcceptedWithChange          = "ACWC"
+	StatusAcceptedCreditSettlement    = "ACCC"
+	StatusPending                     = "PDNG"
+	StatusPartiallyAccepted           = "PART"
+	StatusRejected                    = "RJCT"
+)
+
+// amountPrecision is the number of fraction digits of a USD amount
+const amountPrecision = 2
+
+var iban = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{1,30}$`)
+
+type Debtor struct {
+	Name    string
+	Account string
+	// AgentBIC is the BIC of the bank holding the debtor account
+	AgentBIC string
+}
+
+type Options struct {
+	// MessageID identifies the message for the clearing partner, it must be unique per message and
+	// is quoted back in status reports
+	MessageID              string
+	CreatedAt              time.Time
+	RequestedExecutionDate time.Time
+	Currency               entity.CurrencyCode
+	Debtor                 Debtor
+}
+
+type Payment struct {
+	// EndToEndID is quoted back in status reports to identify the payment
+	EndToEndID            string
+	Amount                decimal.Decimal
+	CreditorName          string
+	CreditorAccount       string
+	CreditorAgentBIC      string
+	RemittanceInformation string
+}
+
+// ControlSum is the sum of the payment amounts as written in the file
+func ControlSum(payments []Payment) decimal.Decimal {
+	sum := decimal.Zero
+	for _, p := range payments {
+		sum = sum.Add(p.Amount.Round(amountPrecision))
+	}
+	return sum
+}
+
+// The XML element layout, in the order the schema requires
+
+type creditTransferDocument struct {
+	XMLName          xml.Name `xml:"Document"`
+	Xmlns            string   `xml:"xmlns,attr"`
+	CstmrCdtTrfInitn struct {
+		GrpHdr grpHdr `xml:"GrpHdr"`
+		PmtInf pmtInf `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+type grpHdr struct {
+	MsgID    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	NbOfTxs  int    `xml:"NbOfTxs"`
+	CtrlSum  string `xml:"CtrlSum"`
+	InitgPty party  `xml:"InitgPty"`
+}
+
+type party struct {
+	Nm string `xml:"Nm"`
+}
+
+type pmtInf struct {
+	PmtInfID    string `xml:"PmtInfId"`
+	PmtMtd      string `xml:"PmtMtd"`
+	NbOfTxs     int    `xml:"NbOfTxs"`
+	CtrlSum     string `xml:"CtrlSum"`
+	ReqdExctnDt struct {
+		Dt string `xml:"Dt"`
+	} `xml:"ReqdExctnDt"`
+	Dbtr        party              `xml:"Dbtr"`
+	DbtrAcct    account            `xml:"DbtrAcct"`
+	DbtrAgt     agent              `xml:"DbtrAgt"`
+	CdtTrfTxInf []creditTransferTx `xml:"CdtTrfTxInf"`
+}
+
+type account struct {
+	ID struct {
+		IBAN string `xml:"IBAN,omitempty"`
+		Othr *struct {
+			ID string `xml:"Id"`
+		} `xml:"Othr,omitempty"`
+	} `xml:"Id"`
+}
+
+type agent struct {
+	FinInstnID struct {
+		BICFI string `xml:"BICFI"`
+	} `xml:"FinInstnId"`
+}
+
+type amount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type creditTransferTx struct {
+	PmtID struct {
+		InstrID    string `xml:"InstrId"`
+		EndToEndID string `xml:"EndToEndId"`
+	} `xml:"PmtId"`
+	Amt struct {
+		InstdAmt amount `xml:"InstdAmt"`
+	} `xml:"Amt"`
+	CdtrAgt  agent   `xml:"CdtrAgt"`
+	Cdtr     party   `xml:"Cdtr"`
+	CdtrAcct account `xml:"CdtrAcct"`
+	RmtInf   *struct {
+		Ustrd string `xml:"Ustrd"`
+	} `xml:"RmtInf,omitempty"`
+}
+
+// WriteCreditTransfer writes the payments as one pain.001 message with a single payment information
+// block debiting the debtor account
+func WriteCreditTransfer(w io.Writer, opts Options, payments []Payment) error {
+	if opts.MessageID == "" {
+		return errors.New("message id is required")
+	}
+	if opts.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if opts.Debtor.Name == "" || opts.Debtor.Account == "" || opts.Debtor.AgentBIC == "" {
+		return errors.New("debtor name, account and agent are required")
+	}
+	if len(payments) == 0 {
+		return errors.New("at least one payment is required")
+	}
+	if opts.CreatedAt.IsZero() {
+		opts.CreatedAt = time.Now()
+	}
+	if opts.RequestedExecutionDate.IsZero() {
+		opts.RequestedExecutionDate = opts.CreatedAt
+	}
+
+	controlSum := formatAmount(ControlSum(payments))
+
+	doc := creditTransferDocument{Xmlns: CreditTransferNamespace}
+	doc.CstmrCdtTrfInitn.GrpHdr = grpHdr{
+		MsgID:    opts.MessageID,
+		CreDtTm:  opts.CreatedAt.UTC().Format(time.RFC3339),
+		NbOfTxs:  len(payments),
+		CtrlSum:  controlSum,
+		InitgPty: party{Nm: opts.Debtor.Name},
+	}
+
+	info := pmtInf{
+		PmtInfID: opts.MessageID,
+		PmtMtd:   "TRF",
+		NbOfTxs:  len(payments),
+		CtrlSum:  controlSum,
+		Dbtr:     party{Nm: opts.Debtor.Name},
+		DbtrAcct: newAccount(opts.Debtor.Account),
+		DbtrAgt:  newAgent(opts.Debtor.AgentBIC),
+	}
+	info.ReqdExctnDt.Dt = opts.RequestedExecutionDate.UTC().Format(time.DateOnly)
+
+	for _, p := range payments {
+		if !p.Amount.IsPositive() {
+			return fmt.Errorf("payment %s: amount must be greater than 0", p.EndToEndID)
+		}
+
+		tx := creditTransferTx{
+			CdtrAgt:  newAgent(p.CreditorAgentBIC),
+			Cdtr:     party{Nm: p.CreditorName},
+			CdtrAcct: newAccount(p.CreditorAccount),
+		}
+		tx.PmtID.InstrID = p.EndToEndID
+		tx.PmtID.EndToEndID = p.EndToEndID
+		tx.Amt.InstdAmt = amount{Ccy: string(opts.Currency), Value: formatAmount(p.Amount)}
+		if p.RemittanceInformation != "" {
+			tx.RmtInf = &struct {
+				Ustrd string `xml:"Ustrd"`
+			}{Ustrd: p.RemittanceInformation}
+		}
+		info.CdtTrfTxInf = append(info.CdtTrfTxInf, tx)
+	}
+	doc.CstmrCdtTrfInitn.PmtInf = info
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// newAccount identifies the account by IBAN when it is one, otherwise by the plain account number
+func newAccount(id string) account {
+	a := account{}
+	normalized := strings.ToUpper(strings.ReplaceAll(id, " ", ""))
+	if iban.MatchString(normalized) {
+		a.ID.IBAN = normalized
+		return a
+	}
+	a.ID.Othr = &struct {
+		ID string `xml:"Id"`
+	}{ID: id}
+	return a
+}
+
+func newAgent(bic string) agent {
+	a := agent{}
+	a.FinInstnID.BICFI = bic
+	return a
+}
+
+func formatAmount(value decimal.Decimal) string {
+	return value.StringFixed(amountPrecision)
+}
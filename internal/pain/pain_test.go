@@ -0,0 +1,186 @@
+package pain_test
+
+import (
+	"bank/entity"
+	"bank/internal/pain"
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWriteCreditTransfer(t *testing.T) {
+	opts := pain.Options{
+		MessageID:              "PAIN-20261018-1",
+		CreatedAt:              time.Date(2026, time.October, 18, 17, 0, 0, 0, time.UTC),
+		RequestedExecutionDate: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Currency:               entity.CurrencyCodeUSD,
+		Debtor:                 pain.Debtor{Name: "BANK", Account: "900000006", AgentBIC: "BANKUS33"},
+	}
+	payments := []pain.Payment{
+		{
+			EndToEndID:            "PMT-1",
+			Amount:                decimal.RequireFromString("100.5"),
+			CreditorName:          "Jane Doe",
+			CreditorAccount:       "de89 3704 0044 0532 0130 00",
+			CreditorAgentBIC:      "COBADEFFXXX",
+			RemittanceInformation: "invoice 42",
+		},
+		{
+			EndToEndID:       "PMT-2",
+			Amount:           decimal.RequireFromString("20"),
+			CreditorName:     "ACME & Sons",
+			CreditorAccount:  "123456789",
+			CreditorAgentBIC: "CHASUS33",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := pain.WriteCreditTransfer(&buf, opts, payments); err != nil {
+		t.Fatalf("failed to write credit transfer: %v", err)
+	}
+	content := buf.String()
+
+	expected := []string{
+		`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">`,
+		`<MsgId>PAIN-20261018-1</MsgId>`,
+		`<CreDtTm>2026-10-18T17:00:00Z</CreDtTm>`,
+		`<NbOfTxs>2</NbOfTxs>`,
+		`<CtrlSum>120.50</CtrlSum>`,
+		`<Dt>2026-10-19</Dt>`,
+		`<BICFI>BANKUS33</BICFI>`,
+		`<EndToEndId>PMT-1</EndToEndId>`,
+		`<InstdAmt Ccy="USD">100.50</InstdAmt>`,
+		`<IBAN>DE89370400440532013000</IBAN>`,
+		`<Ustrd>invoice 42</Ustrd>`,
+		`<Nm>ACME &amp; Sons</Nm>`,
+		`<Othr>`,
+		`<Id>123456789</Id>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(content, e) {
+			t.Errorf("expected the message to contain %s, got\n%s", e, content)
+		}
+	}
+
+	if strings.Count(content, "<RmtInf>") != 1 {
+		t.Errorf("expected remittance information only on the first payment, got\n%s", content)
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), new(struct{})); err != nil {
+		t.Errorf("expected well formed XML: %v", err)
+	}
+
+	if err := pain.WriteCreditTransfer(&buf, opts, nil); err == nil {
+		t.Error("expected an error without payments")
+	}
+}
+
+func TestParseStatusReport(t *testing.T) {
+	report := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10">
+  <CstmrPmtStsRpt>
+    <GrpHdr><MsgId>STS-1</MsgId><CreDtTm>2026-10-19T08:00:00Z</CreDtTm></GrpHdr>
+    <OrgnlGrpInfAndSts>
+      <OrgnlMsgId>PAIN-20261018-1</OrgnlMsgId>
+      <OrgnlMsgNmId>pain.001.001.09</OrgnlMsgNmId>
+      <GrpSts>PART</GrpSts>
+    </OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>PAIN-20261018-1</OrgnlPmtInfId>
+      <TxInfAndSts>
+        <OrgnlEndToEndId>PMT-1</OrgnlEndToEndId>
+        <TxSts>ACSC</TxSts>
+      </TxInfAndSts>
+      <TxInfAndSts>
+        <OrgnlEndToEndId>PMT-2</OrgnlEndToEndId>
+        <TxSts>RJCT</TxSts>
+        <StsRsnInf><Rsn><Cd>AC04</Cd></Rsn><AddtlInf>account closed</AddtlInf></StsRsnInf>
+      </TxInfAndSts>
+    </OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>`
+
+	parsed, err := pain.ParseStatusReport(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("failed to parse status report: %v", err)
+	}
+
+	if parsed.MessageID != "STS-1" || parsed.OriginalMessageID != "PAIN-20261018-1" || parsed.GroupStatus != pain.StatusPartiallyAccepted {
+		t.Errorf("unexpected group information: %+v", parsed)
+	}
+
+	testCases := []struct {
+		endToEndID     string
+		expectedStatus string
+		expectedReason string
+	}{
+		{endToEndID: "PMT-1", expectedStatus: pain.StatusAcceptedSettlementCompleted},
+		{endToEndID: "PMT-2", expectedStatus: pain.StatusRejected, expectedReason: "AC04 account closed"},
+		// Payments the report doesn't list get the group status
+		{endToEndID: "PMT-3", expectedStatus: pain.StatusPartiallyAccepted},
+	}
+	for _, tc := range testCases {
+		status, reason := parsed.StatusOf(tc.endToEndID)
+		if status != tc.expectedStatus || reason != tc.expectedReason {
+			t.Errorf("%s: expected status %s with reason %q, got %s with %q", tc.endToEndID, tc.expectedStatus, tc.expectedReason, status, reason)
+		}
+	}
+}
+
+func TestParseStatusReportErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		report        string
+		expectedError string
+	}{
+		{
+			name:          "not XML",
+			report:        "status: ok",
+			expectedError: "invalid XML",
+		},
+		{
+			name:          "other message",
+			report:        `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"></Document>`,
+			expectedError: "expected pain.002",
+		},
+		{
+			name:          "missing original message id",
+			report:        `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10"><CstmrPmtStsRpt></CstmrPmtStsRpt></Document>`,
+			expectedError: "original message id is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := pain.ParseStatusReport(strings.NewReader(tc.report))
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	testCases := []struct {
+		status   string
+		accepted bool
+		rejected bool
+	}{
+		{status: pain.StatusAcceptedSettlementCompleted, accepted: true},
+		{status: pain.StatusAcceptedTechnicalValidation, accepted: true},
+		{status: pain.StatusRejected, rejected: true},
+		{status: pain.StatusPending},
+		{status: pain.StatusPartiallyAccepted},
+		{status: ""},
+	}
+
+	for _, tc := range testCases {
+		if pain.IsAccepted(tc.status) != tc.accepted || pain.IsRejected(tc.status) != tc.rejected {
+			t.Errorf("%q: expected accepted %v and rejected %v", tc.status, tc.accepted, tc.rejected)
+		}
+	}
+}
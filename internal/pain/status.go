@@ -0,0 +1,155 @@
+package pain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StatusReport is a pain.002 customer payment status report
+type StatusReport struct {
+	MessageID         string
+	OriginalMessageID string
+	// GroupStatus applies to every payment of the original message that has no status of its own
+	GroupStatus       string
+	GroupStatusReason string
+	Transactions      []TransactionStatus
+}
+
+type TransactionStatus struct {
+	OriginalEndToEndID string
+	Status             string
+	Reason             string
+}
+
+// IsRejected reports whether the status code rejects the payment
+func IsRejected(status string) bool {
+	return status == StatusRejected
+}
+
+// IsAccepted reports whether the status code accepts the payment. Pending and partially accepted
+// statuses are neither, the payment waits for a later report.
+func IsAccepted(status string) bool {
+	switch status {
+	case StatusAcceptedTechnicalValidation, StatusAcceptedCustomerProfile, StatusAcceptedSettlementInProcess,
+		StatusAcceptedSettlementCompleted, StatusAcceptedWithChange, StatusAcceptedCreditSettlement:
+		return true
+	}
+	return false
+}
+
+// StatusOf returns the status of a payment of the original message: its own status when the report
+// has one, otherwise the status of its payment information block or group
+func (r *StatusReport) StatusOf(endToEndID string) (status, reason string) {
+	for _, tx := range r.Transactions {
+		if tx.OriginalEndToEndID == endToEndID && tx.Status != "" {
+			return tx.Status, tx.Reason
+		}
+	}
+	return r.GroupStatus, r.GroupStatusReason
+}
+
+// The XML element layout of the parts we read
+
+type statusReportDocument struct {
+	XMLName        xml.Name `xml:"Document"`
+	CstmrPmtStsRpt struct {
+		GrpHdr struct {
+			MsgID string `xml:"MsgId"`
+		} `xml:"GrpHdr"`
+		OrgnlGrpInfAndSts struct {
+			OrgnlMsgID string         `xml:"OrgnlMsgId"`
+			GrpSts     string         `xml:"GrpSts"`
+			StsRsnInf  []statusReason `xml:"StsRsnInf"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		OrgnlPmtInfAndSts []struct {
+			PmtInfSts   string         `xml:"PmtInfSts"`
+			StsRsnInf   []statusReason `xml:"StsRsnInf"`
+			TxInfAndSts []struct {
+				OrgnlEndToEndID string         `xml:"OrgnlEndToEndId"`
+				TxSts           string         `xml:"TxSts"`
+				StsRsnInf       []statusReason `xml:"StsRsnInf"`
+			} `xml:"TxInfAndSts"`
+		} `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type statusReason struct {
+	Rsn struct {
+		Cd    string `xml:"Cd"`
+		Prtry string `xml:"Prtry"`
+	} `xml:"Rsn"`
+	AddtlInf []string `xml:"AddtlInf"`
+}
+
+// ParseStatusReport reads a pain.002 message. A status set on a payment information block is given
+// to its transactions without a status of their own.
+func ParseStatusReport(r io.Reader) (*StatusReport, error) {
+	var doc statusReportDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid XML: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.XMLName.Space, statusReportNamespacePrefix) {
+		return nil, fmt.Errorf("unsupported message %q, expected pain.002", doc.XMLName.Space)
+	}
+
+	rpt := doc.CstmrPmtStsRpt
+	if rpt.OrgnlGrpInfAndSts.OrgnlMsgID == "" {
+		return nil, errors.New("original message id is required")
+	}
+
+	report := &StatusReport{
+		MessageID:         rpt.GrpHdr.MsgID,
+		OriginalMessageID: rpt.OrgnlGrpInfAndSts.OrgnlMsgID,
+		GroupStatus:       rpt.OrgnlGrpInfAndSts.GrpSts,
+		GroupStatusReason: formatReasons(rpt.OrgnlGrpInfAndSts.StsRsnInf),
+		Transactions:      []TransactionStatus{},
+	}
+
+	for _, info := range rpt.OrgnlPmtInfAndSts {
+		// Messages we write have a single payment information block, its status without
+		// transaction details covers the whole message
+		if len(info.TxInfAndSts) == 0 && info.PmtInfSts != "" && report.GroupStatus == "" {
+			report.GroupStatus = info.PmtInfSts
+			report.GroupStatusReason = formatReasons(info.StsRsnInf)
+		}
+
+		for _, tx := range info.TxInfAndSts {
+			if tx.OrgnlEndToEndID == "" {
+				return nil, errors.New("transaction status without original end to end id")
+			}
+
+			status := TransactionStatus{
+				OriginalEndToEndID: tx.OrgnlEndToEndID,
+				Status:             tx.TxSts,
+				Reason:             formatReasons(tx.StsRsnInf),
+			}
+			if status.Status == "" {
+				status.Status = info.PmtInfSts
+				status.Reason = formatReasons(info.StsRsnInf)
+			}
+			report.Transactions = append(report.Transactions, status)
+		}
+	}
+
+	return report, nil
+}
+
+// formatReasons joins the reason codes and their additional information, e.g. "AC04 account closed"
+func formatReasons(reasons []statusReason) string {
+	parts := []string{}
+	for _, r := range reasons {
+		code := r.Rsn.Cd
+		if code == "" {
+			code = r.Rsn.Prtry
+		}
+		part := strings.TrimSpace(code + " " + strings.Join(r.AddtlInf, " "))
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
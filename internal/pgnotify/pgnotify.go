@@ -0,0 +1,161 @@
+// Package pgnotify fans out the notifications of a Postgres channel to subscribers in the
+// process, over a single LISTEN connection.
+package pgnotify
+
+import (
+	"bank/internal/logger"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval detects a dead connection that would otherwise go unnoticed while it is quiet
+	pingInterval = time.Minute
+)
+
+// Listener signals subscribers of a notification payload whenever it is notified. Signals carry
+// no data and coalesce: a subscriber that is busy gets one signal for any number of
+// notifications, so subscribers read the current state when signalled.
+type Listener struct {
+	listener *pq.Listener
+	logger   *logger.Logger
+
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func NewListener(dsn, channel string, logger *logger.Logger) (*Listener, error) {
+	if dsn == "" {
+		return nil, errors.New("dsn is empty")
+	}
+
+	if channel == "" {
+		return nil, errors.New("channel is empty")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithFields(map[string]interface{}{"component": "pgnotify", "channel": channel})
+	l := &Listener{
+		logger:      log,
+		subscribers: map[string]map[chan struct{}]struct{}{},
+	}
+	l.listener = pq.NewListener(dsn, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn(context.Background(), "listener event %d: %v", event, err)
+		}
+	})
+
+	if err := l.listener.Listen(channel); err != nil {
+		l.listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+	return l, nil
+}
+
+// Run dispatches notifications until ctx is done, then closes every subscription
+func (l *Listener) Run(ctx context.Context) {
+	defer l.close()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-l.listener.Notify:
+			// A nil notification follows a reconnect, notifications may have been missed meanwhile
+			if n == nil {
+				l.signalAll()
+				continue
+			}
+			l.signal(n.Extra)
+		case <-ticker.C:
+			go func() {
+				if err := l.listener.Ping(); err != nil {
+					l.logger.Warn(ctx, "failed to ping: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// Subscribe returns a channel signalled on every notification with the payload. The channel is
+// closed when the listener stops, cancel ends the subscription.
+func (l *Listener) Subscribe(payload string) (signals <-chan struct{}, cancel func()) {
+	ch := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if l.subscribers[payload] == nil {
+		l.subscribers[payload] = map[chan struct{}]struct{}{}
+	}
+	l.subscribers[payload][ch] = struct{}{}
+
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers[payload], ch)
+		if len(l.subscribers[payload]) == 0 {
+			delete(l.subscribers, payload)
+		}
+	}
+}
+
+func (l *Listener) signal(payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.subscribers[payload] {
+		notify(ch)
+	}
+}
+
+func (l *Listener) signalAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, subscribers := range l.subscribers {
+		for ch := range subscribers {
+			notify(ch)
+		}
+	}
+}
+
+func (l *Listener) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for _, subscribers := range l.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+	}
+	l.subscribers = map[string]map[chan struct{}]struct{}{}
+
+	if err := l.listener.Close(); err != nil {
+		l.logger.Warn(context.Background(), "failed to close listener: %v", err)
+	}
+}
+
+// notify signals without blocking, a pending signal already covers this one
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
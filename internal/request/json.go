@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -17,6 +18,15 @@ var validate = validator.New()
 const maxRequestSize = 1 << 20 // 1MB
 
 func init() {
+	// Field errors name the JSON field, the display name comes from the struct field
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
 	// Register custom validators for decimal.Decimal
 	validate.RegisterValidation("decimal_required", validateDecimalRequired)
 	validate.RegisterValidation("decimal_positive", validateDecimalPositive)
@@ -42,25 +52,57 @@ func BindJSON[T any](r *http.Request, v *T) error {
 	}
 
 	if err := validate.Struct(v); err != nil {
-		return errors.New(formatValidationError(err))
+		return formatValidationError(err)
 	}
 
 	return nil
 }
 
-// formatValidationError converts validator errors to human-readable messages
-func formatValidationError(err error) string {
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldError := range validationErrors {
-			return getHumanReadableError(fieldError)
+// FieldError is a field that failed validation
+type FieldError struct {
+	// Field is the JSON name of the field, nested fields are dotted
+	Field string `json:"field"`
+	// Rule is the validation rule it failed
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of the request that failed validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// formatValidationError converts validator errors to human-readable messages, one per failing field
+func formatValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return errors.New("validation failed")
+	}
+
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		// The namespace starts with the name of the request struct
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		fields[i] = FieldError{
+			Field:   field,
+			Rule:    fieldError.Tag(),
+			Message: getHumanReadableError(fieldError),
 		}
 	}
-	return "validation failed"
+	return &ValidationError{Fields: fields}
 }
 
 // getHumanReadableError converts a single field error to human-readable message
 func getHumanReadableError(fe validator.FieldError) string {
-	fieldName := getFieldDisplayName(fe.Field())
+	fieldName := getFieldDisplayName(fe.StructField())
 
 	switch fe.Tag() {
 	case "required":
@@ -91,6 +133,10 @@ func getHumanReadableError(fe validator.FieldError) string {
 		return fmt.Sprintf("%s must be less than %s", fieldName, fe.Param())
 	case "lte":
 		return fmt.Sprintf("%s must be less than or equal to %s", fieldName, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", fieldName, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "bic":
+		return fmt.Sprintf("%s must be a valid BIC", fieldName)
 	default:
 		return fmt.Sprintf("%s is invalid", fieldName)
 	}
@@ -108,10 +154,10 @@ func getFieldDisplayName(fieldName string) string {
 	case "Amount":
 		return "amount"
 	default:
-		// Convert PascalCase to space-separated lowercase
+		// Convert PascalCase to space-separated lowercase, keeping acronyms like ID together
 		result := ""
 		for i, r := range fieldName {
-			if i > 0 && r >= 'A' && r <= 'Z' {
+			if i > 0 && r >= 'A' && r <= 'Z' && !(fieldName[i-1] >= 'A' && fieldName[i-1] <= 'Z') {
 				result += " "
 			}
 			result += strings.ToLower(string(r))
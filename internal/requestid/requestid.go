@@ -0,0 +1,48 @@
+// Package requestid carries the id that correlates a request with its responses and logs
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header carries the request id, from the client or the gateway in front of the service and back
+// on the response
+const Header = "X-Request-ID"
+
+// maxLength bounds ids from clients, longer ones are replaced
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a random id
+func New() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid tells whether an id from a client can be kept: not empty, bounded and printable ASCII
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
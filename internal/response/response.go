@@ -1,10 +1,50 @@
 package response
 
 import (
+	"bank/internal/request"
+	"bank/internal/requestid"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
+// ErrorCode tells clients what went wrong without matching on the message. Codes are stable,
+// messages may change.
+type ErrorCode string
+
+const (
+	CodeInvalidRequest         ErrorCode = "INVALID_REQUEST"
+	CodeInvalidParameter       ErrorCode = "INVALID_PARAMETER"
+	CodeValidationFailed       ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized           ErrorCode = "UNAUTHORIZED"
+	CodeForbidden              ErrorCode = "FORBIDDEN"
+	CodeInsufficientFunds      ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeAccountNotFound        ErrorCode = "ACCOUNT_NOT_FOUND"
+	CodePaymentNotFound        ErrorCode = "PAYMENT_NOT_FOUND"
+	CodePaymentFileNotFound    ErrorCode = "PAYMENT_FILE_NOT_FOUND"
+	CodeApprovalNotFound       ErrorCode = "APPROVAL_NOT_FOUND"
+	CodeApprovalNotPending     ErrorCode = "APPROVAL_NOT_PENDING"
+	CodeApprovalExpired        ErrorCode = "APPROVAL_EXPIRED"
+	CodeSelfApproval           ErrorCode = "SELF_APPROVAL"
+	CodeWebhookNotFound        ErrorCode = "WEBHOOK_NOT_FOUND"
+	CodeWebhookDeliveryPending ErrorCode = "WEBHOOK_DELIVERY_PENDING"
+	CodeAuditUnavailable       ErrorCode = "AUDIT_UNAVAILABLE"
+	CodeInternal               ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrorResponse is the body of every error
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Details lists every field that failed validation
+	Details   []request.FieldError `json:"details,omitempty"`
+	RequestID string               `json:"request_id,omitempty"`
+}
+
 func StatusOnly(w http.ResponseWriter, status int) {
 	w.WriteHeader(status)
 }
@@ -22,6 +62,23 @@ func Json(w http.ResponseWriter, status int, data interface{}) {
 	w.Write(jsonData)
 }
 
-func JsonError(w http.ResponseWriter, status int, message string) {
-	Json(w, status, map[string]string{"error": message})
+func JsonError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	writeError(w, status, Error{Code: code, Message: message})
+}
+
+// JsonBindError answers a request whose body request.BindJSON refused, with every failing field
+// when it failed validation
+func JsonBindError(w http.ResponseWriter, err error) {
+	var validationErr *request.ValidationError
+	if errors.As(err, &validationErr) {
+		writeError(w, http.StatusBadRequest, Error{Code: CodeValidationFailed, Message: err.Error(), Details: validationErr.Fields})
+		return
+	}
+	writeError(w, http.StatusBadRequest, Error{Code: CodeInvalidRequest, Message: err.Error()})
+}
+
+// writeError fills in the request id the RequestID middleware set on the response
+func writeError(w http.ResponseWriter, status int, e Error) {
+	e.RequestID = w.Header().Get(requestid.Header)
+	Json(w, status, ErrorResponse{Error: e})
 }
@@ -1,13 +1,17 @@
 package server
 
 import (
+	"bank/http/middleware"
+	"bank/internal/logger"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 )
 
-func NewServer(port string, r *chi.Mux) *http.Server {
+func NewServer(port string, r *chi.Mux, log *logger.Logger) *http.Server {
+	r.Use(middleware.RequestID)
+	r.Use(middleware.AccessLog(log))
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{""},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"},
@@ -0,0 +1,109 @@
+package statementfile
+
+import (
+	"bank/entity"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BAI2 type codes of the balances and transactions written
+const (
+	bai2OpeningLedger    = "010"
+	bai2ClosingLedger    = "015"
+	bai2IncomingTransfer = "195"
+	bai2MiscCredit       = "399"
+	bai2OutgoingTransfer = "495"
+	bai2MiscDebit        = "699"
+	// bai2FundsUnknown is the funds type of an entry whose availability is not reported
+	bai2FundsUnknown = "Z"
+)
+
+// bai2Writer writes a BAI2 file with one group holding one account. The trailer records carry
+// control totals (the sum of every amount, in cents) and record counts, kept while streaming.
+type bai2Writer struct {
+	w              io.Writer
+	opts           Options
+	accountTotal   decimal.Decimal
+	accountRecords int
+}
+
+func (b *bai2Writer) Begin(s entity.AccountStatement) error {
+	createdAt := b.opts.CreatedAt.UTC()
+	asOf := s.To.Add(-time.Nanosecond).UTC()
+	opening, closing := bai2Amount(s.OpeningBalance), bai2Amount(s.ClosingBalance)
+
+	err := b.writeRecords(
+		fmt.Sprintf("01,%s,%s,%s,%s,%s,,,2", b.opts.SenderID, b.opts.ReceiverID, createdAt.Format("060102"), createdAt.Format("1504"), b.opts.Reference),
+		fmt.Sprintf("02,%s,%s,1,%s,,%s,2", b.opts.ReceiverID, b.opts.SenderID, asOf.Format("060102"), b.opts.Currency),
+		fmt.Sprintf("03,%d,%s,%s,%s,,,%s,%s,,", s.AccountID, b.opts.Currency, bai2OpeningLedger, opening, bai2ClosingLedger, closing),
+	)
+	if err != nil {
+		return err
+	}
+
+	b.accountTotal = bai2Cents(s.OpeningBalance).Add(bai2Cents(s.ClosingBalance))
+	b.accountRecords = 1
+	return nil
+}
+
+func (b *bai2Writer) WriteEntry(e entity.StatementEntry) error {
+	typeCode, customerRef, text := bai2MiscCredit, "", string(e.TrxType)
+	if e.TrxType == entity.TrxTypeDebit {
+		typeCode = bai2MiscDebit
+	}
+
+	if e.TransferID.Valid {
+		typeCode, customerRef, text = bai2IncomingTransfer, strconv.FormatInt(e.TransferID.Int64, 10), "TRANSFER"
+		direction := "FROM"
+		if e.TrxType == entity.TrxTypeDebit {
+			typeCode, direction = bai2OutgoingTransfer, "TO"
+		}
+		if e.CounterpartyAccountID.Valid {
+			text = fmt.Sprintf("TRANSFER %s %d", direction, e.CounterpartyAccountID.Int64)
+		}
+	}
+
+	amount := bai2Cents(e.Amount.Abs())
+	err := b.writeRecords(fmt.Sprintf("16,%s,%s,%s,%d,%s,%s", typeCode, amount, bai2FundsUnknown, e.ID, customerRef, text))
+	if err != nil {
+		return err
+	}
+
+	b.accountTotal = b.accountTotal.Add(amount)
+	b.accountRecords++
+	return nil
+}
+
+func (b *bai2Writer) End(entity.AccountStatement) error {
+	// Record counts include the header and trailer of each level
+	accountRecords := b.accountRecords + 1
+	groupRecords := accountRecords + 2
+	fileRecords := groupRecords + 2
+	return b.writeRecords(
+		fmt.Sprintf("49,%s,%d", b.accountTotal, accountRecords),
+		fmt.Sprintf("98,%s,1,%d", b.accountTotal, groupRecords),
+		fmt.Sprintf("99,%s,1,%d", b.accountTotal, fileRecords),
+	)
+}
+
+func (b *bai2Writer) writeRecords(records ...string) error {
+	for _, record := range records {
+		if _, err := io.WriteString(b.w, record+"/\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bai2Cents returns the amount in cents, BAI2 amounts have no decimal point
+func bai2Cents(amount decimal.Decimal) decimal.Decimal {
+	return roundAmount(amount).Shift(currencyPrecision)
+}
+
+func bai2Amount(amount decimal.Decimal) string {
+	return bai2Cents(amount).String()
+}
@@ -0,0 +1,92 @@
+package statementfile
+
+import (
+	"bank/entity"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// mt940Writer writes the text block of an MT940 customer statement, without the SWIFT FIN
+// envelope, the way corporate file exchanges carry it
+type mt940Writer struct {
+	w    io.Writer
+	opts Options
+}
+
+func (m *mt940Writer) Begin(s entity.AccountStatement) error {
+	lines := []string{
+		":20:" + truncate(m.opts.Reference, 16),
+		":25:" + strconv.FormatUint(s.AccountID, 10),
+		// Statement number is the day of the year of the statement, it has a single page
+		fmt.Sprintf(":28C:%d/1", s.From.UTC().YearDay()),
+		":60F:" + m.balance(s.OpeningBalance, s.From),
+	}
+	return m.writeLines(lines...)
+}
+
+func (m *mt940Writer) WriteEntry(e entity.StatementEntry) error {
+	mark := "C"
+	if e.TrxType == entity.TrxTypeDebit {
+		mark = "D"
+	}
+
+	// Transaction type, reference for the account owner and the account servicing institution
+	code, ownerRef := "NMSC", "NONREF"
+	info := string(e.TrxType)
+	if e.TransferID.Valid {
+		code, ownerRef = "NTRF", strconv.FormatInt(e.TransferID.Int64, 10)
+		info = "TRANSFER"
+		if e.CounterpartyAccountID.Valid {
+			direction := "TO"
+			if e.TrxType == entity.TrxTypeCredit {
+				direction = "FROM"
+			}
+			info = fmt.Sprintf("TRANSFER %s %d", direction, e.CounterpartyAccountID.Int64)
+		}
+	}
+
+	bookedAt := e.CreatedAt.UTC()
+	return m.writeLines(
+		fmt.Sprintf(":61:%s%s%s%s%s%s//%d", bookedAt.Format("060102"), bookedAt.Format("0102"), mark, mt940Amount(e.Amount), code, ownerRef, e.ID),
+		":86:"+info,
+	)
+}
+
+func (m *mt940Writer) End(s entity.AccountStatement) error {
+	return m.writeLines(":62F:"+m.balance(s.ClosingBalance, s.To.Add(-time.Nanosecond)), "-")
+}
+
+// balance is the debit/credit mark, date, currency and amount of a :60F: or :62F: field
+func (m *mt940Writer) balance(amount decimal.Decimal, at time.Time) string {
+	mark := "C"
+	if amount.IsNegative() {
+		mark = "D"
+	}
+	return mark + at.UTC().Format("060102") + string(m.opts.Currency) + mt940Amount(amount)
+}
+
+func (m *mt940Writer) writeLines(lines ...string) error {
+	for _, line := range lines {
+		if _, err := io.WriteString(m.w, line+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mt940Amount formats an amount without sign and with a decimal comma, as in 1250,50
+func mt940Amount(amount decimal.Decimal) string {
+	return strings.Replace(roundAmount(amount).Abs().StringFixed(currencyPrecision), ".", ",", 1)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
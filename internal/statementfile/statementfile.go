@@ -0,0 +1,80 @@
+// Package statementfile renders account statements as the plain text files treasury systems
+// exchange: SWIFT MT940 and BAI2.
+package statementfile
+
+import (
+	"bank/entity"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Format string
+
+const (
+	FormatMT940 Format = "mt940"
+	FormatBAI2  Format = "bai2"
+)
+
+// Extension returns the file extension commonly used for the format
+func (f Format) Extension() string {
+	if f == FormatBAI2 {
+		return "bai"
+	}
+	return "sta"
+}
+
+// currencyPrecision is the number of decimal places of the amounts in both formats. Ledger
+// amounts have 6 and are rounded half to even.
+const currencyPrecision = 2
+
+type Options struct {
+	// Reference identifies the file for the receiver: the MT940 transaction reference
+	// (:20:, 16 characters) or the BAI2 file identification number
+	Reference string
+	// SenderID and ReceiverID identify both parties in the BAI2 file header
+	SenderID   string
+	ReceiverID string
+	CreatedAt  time.Time
+	Currency   entity.CurrencyCode
+}
+
+// Writer streams one statement, it has the methods of account.StatementWriter
+type Writer interface {
+	Begin(statement entity.AccountStatement) error
+	WriteEntry(entry entity.StatementEntry) error
+	End(statement entity.AccountStatement) error
+}
+
+func NewWriter(w io.Writer, format Format, opts Options) (Writer, error) {
+	if opts.Reference == "" {
+		return nil, errors.New("reference is required")
+	}
+
+	if opts.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+
+	if opts.CreatedAt.IsZero() {
+		opts.CreatedAt = time.Now()
+	}
+
+	switch format {
+	case FormatMT940:
+		return &mt940Writer{w: w, opts: opts}, nil
+	case FormatBAI2:
+		if opts.SenderID == "" || opts.ReceiverID == "" {
+			return nil, errors.New("sender id and receiver id are required for BAI2")
+		}
+		return &bai2Writer{w: w, opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func roundAmount(amount decimal.Decimal) decimal.Decimal {
+	return amount.RoundBank(currencyPrecision)
+}
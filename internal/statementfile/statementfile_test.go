@@ -0,0 +1,137 @@
+package statementfile_test
+
+import (
+	"bank/entity"
+	"bank/internal/statementfile"
+	"bytes"
+	"database/sql"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func render(t *testing.T, format statementfile.Format) string {
+	t.Helper()
+	statement := entity.AccountStatement{
+		GetAccountStatement: entity.GetAccountStatement{
+			AccountID: 500,
+			From:      time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC),
+			To:        time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		},
+		OpeningBalance: decimal.NewFromInt(1000),
+		ClosingBalance: decimal.RequireFromString("-49.505"),
+	}
+	entries := []entity.StatementEntry{
+		{
+			Transaction: entity.Transaction{
+				Model:      entity.Model{ID: 2, CreatedAt: time.Date(2026, time.September, 30, 10, 0, 0, 0, time.UTC)},
+				TransferID: sql.NullInt64{Int64: 70, Valid: true},
+				Amount:     decimal.RequireFromString("250.495"),
+				TrxType:    entity.TrxTypeCredit,
+			},
+			CounterpartyAccountID: sql.NullInt64{Int64: 501, Valid: true},
+		},
+		{
+			Transaction: entity.Transaction{
+				Model:      entity.Model{ID: 3, CreatedAt: time.Date(2026, time.September, 30, 12, 0, 0, 0, time.UTC)},
+				TransferID: sql.NullInt64{Int64: 71, Valid: true},
+				Amount:     decimal.NewFromInt(1300),
+				TrxType:    entity.TrxTypeDebit,
+			},
+			CounterpartyAccountID: sql.NullInt64{Int64: 502, Valid: true},
+		},
+		{
+			Transaction: entity.Transaction{
+				Model:   entity.Model{ID: 4, CreatedAt: time.Date(2026, time.September, 30, 23, 0, 0, 0, time.UTC)},
+				Amount:  decimal.RequireFromString("0.5"),
+				TrxType: entity.TrxTypeDebit,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	w, err := statementfile.NewWriter(&buf, format, statementfile.Options{
+		Reference:  "500-20260930",
+		SenderID:   "BANK",
+		ReceiverID: "TREASURY",
+		CreatedAt:  time.Date(2026, time.October, 1, 2, 5, 0, 0, time.UTC),
+		Currency:   entity.CurrencyCodeUSD,
+	})
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	if err := w.Begin(statement); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	for _, e := range entries {
+		if err := w.WriteEntry(e); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if err := w.End(statement); err != nil {
+		t.Fatalf("failed to end: %v", err)
+	}
+	return buf.String()
+}
+
+func TestMT940(t *testing.T) {
+	expected := ":20:500-20260930\r\n" +
+		":25:500\r\n" +
+		":28C:273/1\r\n" +
+		":60F:C260930USD1000,00\r\n" +
+		// 250.495 is rounded half to even
+		":61:2609300930C250,50NTRF70//2\r\n" +
+		":86:TRANSFER FROM 501\r\n" +
+		":61:2609300930D1300,00NTRF71//3\r\n" +
+		":86:TRANSFER TO 502\r\n" +
+		":61:2609300930D0,50NMSCNONREF//4\r\n" +
+		":86:DEBIT\r\n" +
+		":62F:D260930USD49,50\r\n" +
+		"-\r\n"
+
+	if got := render(t, statementfile.FormatMT940); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestBAI2(t *testing.T) {
+	// Control total: 100000 - 4950 + 25050 + 130000 + 50 = 250150
+	expected := "01,BANK,TREASURY,261001,0205,500-20260930,,,2/\n" +
+		"02,TREASURY,BANK,1,260930,,USD,2/\n" +
+		"03,500,USD,010,100000,,,015,-4950,,/\n" +
+		"16,195,25050,Z,2,70,TRANSFER FROM 501/\n" +
+		"16,495,130000,Z,3,71,TRANSFER TO 502/\n" +
+		"16,699,50,Z,4,,DEBIT/\n" +
+		"49,250150,5/\n" +
+		"98,250150,1,7/\n" +
+		"99,250150,1,9/\n"
+
+	if got := render(t, statementfile.FormatBAI2); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		format      statementfile.Format
+		opts        statementfile.Options
+		expectedErr string
+	}{
+		{name: "unsupported format", format: "mt942", opts: statementfile.Options{Reference: "1", Currency: "USD"}, expectedErr: `unsupported format "mt942"`},
+		{name: "missing reference", format: statementfile.FormatMT940, opts: statementfile.Options{Currency: "USD"}, expectedErr: "reference is required"},
+		{name: "missing currency", format: statementfile.FormatMT940, opts: statementfile.Options{Reference: "1"}, expectedErr: "currency is required"},
+		{name: "bai2 without parties", format: statementfile.FormatBAI2, opts: statementfile.Options{Reference: "1", Currency: "USD"}, expectedErr: "sender id and receiver id are required for BAI2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := statementfile.NewWriter(io.Discard, tc.format, tc.opts)
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapConnector traces every statement run on the connections of c, each in a span named after
+// its sqlc query, or its first keyword for the queries written by hand. Rows buffered by COPY
+// aren't statements and aren't traced.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return &connector{Connector: c}
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn}, nil
+}
+
+// conn forwards to the connection of the driver, which must implement the context variants of
+// its methods, as lib/pq does
+type conn struct {
+	driver.Conn
+}
+
+var (
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+)
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	preparer, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return nil, errors.New("driver doesn't support PrepareContext")
+	}
+	st, err := preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: st, text: query, copy: isCopy(query)}, nil
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginner, ok := c.Conn.(driver.ConnBeginTx)
+	if !ok {
+		return nil, errors.New("driver doesn't support BeginTx")
+	}
+
+	beginCtx, span := startStatement(ctx, "BEGIN", "BEGIN")
+	t, err := beginner.BeginTx(beginCtx, opts)
+	End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, ctx: ctx}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, statementName(query), query)
+	res, err := execer.ExecContext(ctx, query, args)
+	End(span, err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, statementName(query), query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	End(span, err)
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tx traces the end of the transaction in the context it began in
+type tx struct {
+	driver.Tx
+	ctx context.Context
+}
+
+func (t *tx) Commit() error {
+	_, span := startStatement(t.ctx, "COMMIT", "COMMIT")
+	err := t.Tx.Commit()
+	End(span, err)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, span := startStatement(t.ctx, "ROLLBACK", "ROLLBACK")
+	err := t.Tx.Rollback()
+	End(span, err)
+	return err
+}
+
+type stmt struct {
+	driver.Stmt
+	text string
+	copy bool
+}
+
+var (
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if s.copy {
+		return s.exec(ctx, args)
+	}
+
+	ctx, span := startStatement(ctx, statementName(s.text), s.text)
+	res, err := s.exec(ctx, args)
+	End(span, err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := startStatement(ctx, statementName(s.text), s.text)
+	rows, err := s.query(ctx, args)
+	End(span, err)
+	return rows, err
+}
+
+// exec falls back to Exec for the statements without a context variant, like the COPY of lib/pq
+func (s *stmt) exec(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *stmt) query(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("driver doesn't support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func startStatement(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	return Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation(query)),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// statementName is the name of the sqlc query, whose text starts with "-- name: Name :kind"
+func statementName(query string) string {
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	return operation(query)
+}
+
+// operation is the first keyword of the statement, after the sqlc name comment
+func operation(query string) string {
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		keyword, _, _ := strings.Cut(line, " ")
+		return strings.ToUpper(keyword)
+	}
+	return ""
+}
+
+func isCopy(query string) bool {
+	return operation(query) == "COPY"
+}
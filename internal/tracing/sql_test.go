@@ -0,0 +1,121 @@
+package tracing_test
+
+import (
+	"bank/internal/tracing"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeConnector records the statements it is sent, they all succeed except "SELECT fail"
+type fakeConnector struct {
+	statements []string
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ c *fakeConnector }
+
+func (f *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{f.c, query}, nil
+}
+func (f *fakeConn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return f.Prepare(query)
+}
+func (f *fakeConn) Close() error              { return nil }
+func (f *fakeConn) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return f, nil
+}
+func (f *fakeConn) Commit() error   { return nil }
+func (f *fakeConn) Rollback() error { return nil }
+func (f *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	f.c.statements = append(f.c.statements, query)
+	if query == "SELECT fail" {
+		return nil, errors.New("failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// fakeStmt only has the methods without a context, like the COPY statement of lib/pq
+type fakeStmt struct {
+	c     *fakeConnector
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.statements = append(s.c.statements, s.query)
+	return driver.RowsAffected(int64(len(args))), nil
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) { return nil, io.EOF }
+
+func TestWrapConnector(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	connector := &fakeConnector{}
+	db := sql.OpenDB(tracing.WrapConnector(connector))
+	defer db.Close()
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "-- name: LockAccount :one\nSELECT id FROM accounts WHERE id = $1 FOR UPDATE", 1); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT fail"); err == nil {
+		t.Fatal("expected the statement to fail")
+	}
+
+	// COPY rows are buffered, they aren't statements of their own
+	stmt, err := tx.PrepareContext(ctx, `COPY "accounts" ("id") FROM STDIN`)
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+	for id := range 3 {
+		if _, err := stmt.ExecContext(ctx, int64(id)); err != nil {
+			t.Fatalf("failed to copy row: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	parent.End()
+
+	if len(connector.statements) != 5 {
+		t.Errorf("expected every statement to reach the driver, got %q", connector.statements)
+	}
+
+	names := []string{}
+	failed := []string{}
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		if span.Name() != "parent" && span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected span %s to be a child of the request", span.Name())
+		}
+		if span.Status().Code.String() == "Error" {
+			failed = append(failed, span.Name())
+		}
+	}
+
+	expected := []string{"BEGIN", "LockAccount", "SELECT", "COMMIT", "parent"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected spans %q, got %q", expected, names)
+	}
+	if !slices.Equal(failed, []string{"SELECT"}) {
+		t.Errorf("expected only the failed statement to be marked failed, got %q", failed)
+	}
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/tracing"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Job is a unit of background work run on a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Worker struct {
+	jobs   []Job
+	logger *logger.Logger
+}
+
+func NewWorker(logger *logger.Logger, jobs ...Job) (*Worker, error) {
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	for _, job := range jobs {
+		if job.Name == "" || job.Run == nil {
+			return nil, errors.New("job name and run are required")
+		}
+
+		if job.Interval <= 0 {
+			return nil, errors.New("job interval must be positive: " + job.Name)
+		}
+	}
+
+	return &Worker{
+		jobs:   jobs,
+		logger: logger.WithField("component", "worker"),
+	}, nil
+}
+
+// Start runs every job once immediately and then on its interval until ctx is cancelled.
+// It blocks until all running jobs have returned.
+func (w *Worker) Start(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for _, job := range w.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			w.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.run(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job Job) {
+	// Every run is a trace of its own, with the statements of the job under it
+	ctx, span := tracing.Start(ctx, "job "+job.Name, trace.WithNewRoot())
+	defer span.End()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ObserveJob(job.Name, time.Since(start), err)
+		w.logger.Error(ctx, "job=%s failed after %s: %v", job.Name, time.Since(start), err)
+		return
+	}
+	metrics.ObserveJob(job.Name, time.Since(start), nil)
+	w.logger.Debug(ctx, "job=%s finished in %s", job.Name, time.Since(start))
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    kind varchar NOT NULL, -- enum: TRANSFER, ADJUSTMENT
+    status varchar NOT NULL, -- enum: PENDING_APPROVAL, EXECUTED, FAILED, REJECTED, EXPIRED
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(20, 6) NOT NULL,
+    requested_by varchar NOT NULL,
+    decided_by varchar,
+    reason text NOT NULL DEFAULT '',
+    transfer_id bigint,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id)
+);
+CREATE INDEX IF NOT EXISTS idx_approval_requests_status_expires_at ON approval_requests (status, expires_at);
+
+CREATE TABLE IF NOT EXISTS approval_events (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    approval_request_id bigint NOT NULL,
+    event_type varchar NOT NULL, -- enum: REQUESTED, APPROVED, EXECUTED, EXECUTION_FAILED, REJECTED, EXPIRED
+    actor varchar NOT NULL,
+    note text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (approval_request_id) REFERENCES approval_requests(id)
+);
+CREATE INDEX IF NOT EXISTS idx_approval_events_approval_request_id ON approval_events (approval_request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS approval_events;
+DROP TABLE IF EXISTS approval_requests;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS account_type varchar NOT NULL DEFAULT 'SAVINGS'; -- enum: SAVINGS, CREDIT, SYSTEM
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts DROP COLUMN IF EXISTS account_type;
+-- +goose StatementEnd
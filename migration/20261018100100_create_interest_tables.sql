@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS interest_rates (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    account_type varchar NOT NULL, -- enum: SAVINGS, CREDIT
+    annual_rate decimal(10, 6) NOT NULL, -- 0.035000 is 3.5% per year
+    effective_from date NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_type, effective_from)
+);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    account_id bigint NOT NULL,
+    accrual_date date NOT NULL,
+    balance decimal(20, 6) NOT NULL, -- end-of-day balance the interest was computed on
+    annual_rate decimal(10, 6) NOT NULL,
+    amount decimal(24, 12) NOT NULL, -- kept beyond ledger precision, rounded on capitalization
+    capitalized_at TIMESTAMPTZ,
+    transfer_id bigint,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id),
+    UNIQUE (account_id, accrual_date)
+);
+CREATE INDEX IF NOT EXISTS idx_interest_accruals_uncapitalized ON interest_accruals (account_id, accrual_date) WHERE capitalized_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_rates;
+-- +goose StatementEnd
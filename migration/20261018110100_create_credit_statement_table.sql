@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS credit_statements (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    account_id bigint NOT NULL,
+    period_start date NOT NULL,
+    period_end date NOT NULL, -- exclusive, also the statement date
+    closing_balance decimal(20, 6) NOT NULL, -- ledger balance at period end, after interest and fees
+    statement_balance decimal(20, 6) NOT NULL, -- amount owed, zero when the account is in credit
+    minimum_payment decimal(20, 6) NOT NULL,
+    due_date date NOT NULL,
+    interest_charged decimal(20, 6) NOT NULL DEFAULT 0,
+    late_fee_charged decimal(20, 6) NOT NULL DEFAULT 0,
+    interest_transfer_id bigint,
+    late_fee_transfer_id bigint,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (interest_transfer_id) REFERENCES transfers(id),
+    FOREIGN KEY (late_fee_transfer_id) REFERENCES transfers(id),
+    UNIQUE (account_id, period_start)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS credit_statements;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Balance of an account from the transactions created before the cutoff. It starts from the
+-- latest snapshot that only covers such transactions, the same way get_account_balance does,
+-- so both agree on the current balance.
+CREATE OR REPLACE FUNCTION get_account_balance_at(
+    filter_account_id BIGINT,
+    filter_cutoff TIMESTAMPTZ
+)
+RETURNS DECIMAL(20, 6)
+LANGUAGE plpgsql
+STABLE
+AS $$
+DECLARE
+    v_last_transaction_id BIGINT;
+    v_snapshot_balance DECIMAL(20, 6);
+    v_transaction_delta DECIMAL(20, 6) := 0;
+BEGIN
+    SELECT s.balance, s.last_transaction_id
+    INTO v_snapshot_balance, v_last_transaction_id
+    FROM account_balance_snapshots s
+    JOIN transactions t ON t.id = s.last_transaction_id
+    WHERE s.account_id = filter_account_id
+      AND t.created_at < filter_cutoff
+    ORDER BY s.created_at DESC
+    LIMIT 1;
+
+    SELECT COALESCE(
+        SUM(CASE
+            WHEN trx_type = 'CREDIT' THEN amount
+            WHEN trx_type = 'DEBIT' THEN -amount
+            ELSE 0
+        END), 0
+    )
+    INTO v_transaction_delta
+    FROM transactions
+    WHERE account_id = filter_account_id
+      AND id > COALESCE(v_last_transaction_id, 0)
+      AND created_at < filter_cutoff;
+
+    RETURN COALESCE(v_snapshot_balance, 0) + v_transaction_delta;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS get_account_balance_at;
+-- +goose StatementEnd
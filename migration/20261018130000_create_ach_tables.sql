@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ach_files (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    -- The file header fields that identify a file, a resent file has the same ones
+    immediate_origin varchar NOT NULL,
+    immediate_destination varchar NOT NULL,
+    file_created_at timestamptz NOT NULL,
+    file_id_modifier varchar NOT NULL,
+    file_hash varchar NOT NULL, -- sha256 of the content, a different file under the same identity is refused
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (immediate_origin, immediate_destination, file_created_at, file_id_modifier)
+);
+
+CREATE TABLE IF NOT EXISTS ach_entries (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ach_file_id bigint NOT NULL,
+    batch_number integer NOT NULL,
+    trace_number varchar NOT NULL,
+    transaction_code integer NOT NULL,
+    account_number varchar NOT NULL,
+    amount decimal(20, 6) NOT NULL,
+    status varchar NOT NULL, -- enum: POSTED, RETURNED, SKIPPED
+    return_reason_code varchar,
+    transfer_id bigint,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (ach_file_id) REFERENCES ach_files(id),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id),
+    UNIQUE (ach_file_id, trace_number)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ach_entries;
+DROP TABLE IF EXISTS ach_files;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payment_files (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    message_id varchar NOT NULL UNIQUE,
+    payment_count integer NOT NULL,
+    control_sum decimal(20, 6) NOT NULL,
+    content text NOT NULL, -- the pain.001 document as sent to the clearing partner
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS external_payments (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    account_id bigint NOT NULL,
+    amount decimal(20, 6) NOT NULL,
+    creditor_name varchar NOT NULL,
+    creditor_account varchar NOT NULL, -- IBAN or account number at the creditor agent
+    creditor_agent varchar NOT NULL, -- BIC
+    remittance_information varchar NOT NULL DEFAULT '',
+    status varchar NOT NULL, -- enum: PENDING, SENT, ACCEPTED, REJECTED
+    status_reason varchar NOT NULL DEFAULT '',
+    transfer_id bigint NOT NULL, -- customer account to the clearing account
+    reversal_transfer_id bigint, -- clearing account back to the customer account, when rejected
+    payment_file_id bigint,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id),
+    FOREIGN KEY (reversal_transfer_id) REFERENCES transfers(id),
+    FOREIGN KEY (payment_file_id) REFERENCES payment_files(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_payments_status ON external_payments (status, id);
+CREATE INDEX IF NOT EXISTS idx_external_payments_account_id ON external_payments (account_id, id);
+CREATE INDEX IF NOT EXISTS idx_external_payments_payment_file_id ON external_payments (payment_file_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS external_payments;
+DROP TABLE IF EXISTS payment_files;
+-- +goose StatementEnd
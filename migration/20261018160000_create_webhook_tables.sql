@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    url varchar NOT NULL,
+    event_types varchar[] NOT NULL, -- outbox event types delivered to the url
+    secret varchar NOT NULL, -- HMAC-SHA256 key of the signature header
+    active boolean NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    subscription_id bigint NOT NULL,
+    event_id bigint NOT NULL,
+    event_type varchar NOT NULL,
+    payload jsonb NOT NULL, -- the request body, the same on every attempt
+    status varchar NOT NULL, -- enum: PENDING, DELIVERED, DEAD
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_response_status integer,
+    last_error varchar NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES outbox(id),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at, id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    delivery_id bigint NOT NULL,
+    attempt integer NOT NULL,
+    response_status integer, -- NULL when no response was received
+    error varchar NOT NULL DEFAULT '',
+    duration_ms integer NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts (delivery_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notifies the account id of every inserted transaction on account_transactions. Notifications are
+-- sent on commit and Postgres folds identical ones of a transaction, so a transfer notifies each
+-- of its accounts once and a rolled back insert never notifies.
+CREATE OR REPLACE FUNCTION notify_account_transaction()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    PERFORM pg_notify('account_transactions', NEW.account_id::text);
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER transactions_notify_account
+AFTER INSERT ON transactions
+FOR EACH ROW EXECUTE FUNCTION notify_account_transaction();
+
+-- Reads the transactions of an account after a given one
+CREATE INDEX IF NOT EXISTS idx_transactions_account_id_id ON transactions (account_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_account_id_id;
+DROP TRIGGER IF EXISTS transactions_notify_account ON transactions;
+DROP FUNCTION IF EXISTS notify_account_transaction;
+-- +goose StatementEnd
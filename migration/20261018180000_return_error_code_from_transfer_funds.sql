@@ -0,0 +1,175 @@
+-- +goose Up
+-- +goose StatementBegin
+-- transfer_funds returns its result as OUT columns with a numeric error code instead of a success
+-- flag, so callers read typed columns and map failures from the code rather than the message:
+--   0 success, 1 amount not positive, 2 same account, 3 source account does not exist,
+--   4 destination account does not exist, 5 insufficient funds, 99 unexpected error (SQLERRM)
+-- Its return type changes, so it is dropped and created again.
+DROP FUNCTION IF EXISTS transfer_funds(BIGINT, BIGINT, DECIMAL);
+
+CREATE FUNCTION transfer_funds(
+    param_from_account_id BIGINT,
+    param_to_account_id BIGINT,
+    param_amount DECIMAL(20,6),
+    OUT transfer_id BIGINT,
+    OUT error_code INTEGER,
+    OUT error_message TEXT
+)
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    v_from_balance DECIMAL(20,6);
+    v_from_credit_limit DECIMAL(20,6);
+    v_first_account BIGINT;
+    v_second_account BIGINT;
+    v_first_locked BOOLEAN;
+    v_second_locked BOOLEAN;
+BEGIN
+    error_code := 0;
+
+    -- Validate input parameters
+    IF param_amount <= 0 THEN
+        error_code := 1;
+        error_message := 'Transfer amount must be positive';
+        RETURN;
+    END IF;
+
+    IF param_from_account_id = param_to_account_id THEN
+        error_code := 2;
+        error_message := 'Cannot transfer to the same account';
+        RETURN;
+    END IF;
+
+    -- Lock accounts in consistent order to prevent deadlocks
+    v_first_account := LEAST(param_from_account_id, param_to_account_id);
+    v_second_account := GREATEST(param_from_account_id, param_to_account_id);
+
+    -- An account exists for the transfer only if it was locked. One committed after its lock was
+    -- tried is visible to the next statement, checking it again would post to it unlocked.
+    PERFORM 1 FROM accounts WHERE id = v_first_account FOR UPDATE;
+    v_first_locked := FOUND;
+    PERFORM 1 FROM accounts WHERE id = v_second_account FOR UPDATE;
+    v_second_locked := FOUND;
+
+    IF NOT CASE WHEN param_from_account_id = v_first_account THEN v_first_locked ELSE v_second_locked END THEN
+        error_code := 3;
+        error_message := 'From account does not exist';
+        RETURN;
+    END IF;
+
+    IF NOT CASE WHEN param_to_account_id = v_first_account THEN v_first_locked ELSE v_second_locked END THEN
+        error_code := 4;
+        error_message := 'To account does not exist';
+        RETURN;
+    END IF;
+
+    -- Get and lock account's balance, CREDIT accounts may spend up to their credit limit
+    SELECT get_account_balance(param_from_account_id, true) INTO v_from_balance;
+    SELECT credit_limit INTO v_from_credit_limit FROM accounts WHERE id = param_from_account_id;
+
+    IF v_from_balance IS NULL OR v_from_balance + COALESCE(v_from_credit_limit, 0) < param_amount THEN
+        error_code := 5;
+        error_message := 'Insufficient funds';
+        RETURN;
+    END IF;
+
+    INSERT INTO transfers (from_account_id, to_account_id)
+    VALUES (param_from_account_id, param_to_account_id)
+    RETURNING id INTO transfer_id;
+
+    INSERT INTO transactions (account_id, transfer_id, amount, trx_type)
+    VALUES
+        (param_from_account_id, transfer_funds.transfer_id, param_amount, 'DEBIT'),
+        (param_to_account_id, transfer_funds.transfer_id, param_amount, 'CREDIT');
+
+EXCEPTION
+    WHEN OTHERS THEN
+        transfer_id := NULL;
+        error_code := 99;
+        error_message := SQLERRM;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS transfer_funds(BIGINT, BIGINT, DECIMAL);
+
+CREATE FUNCTION transfer_funds(
+    param_from_account_id BIGINT,
+    param_to_account_id BIGINT,
+    param_amount DECIMAL(20,6)
+)
+RETURNS TABLE(
+    transfer_id BIGINT,
+    success BOOLEAN,
+    error_message TEXT
+) 
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    v_transfer_id BIGINT;
+    v_from_balance DECIMAL(20,6);
+    v_from_credit_limit DECIMAL(20,6);
+    v_first_account BIGINT;
+    v_second_account BIGINT;
+BEGIN
+    -- Validate input parameters
+    IF param_amount <= 0 THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, 'Transfer amount must be positive';
+        RETURN;
+    END IF;
+    
+    IF param_from_account_id = param_to_account_id THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, 'Cannot transfer to the same account';
+        RETURN;
+    END IF;
+    
+    -- Lock accounts in consistent order to prevent deadlocks
+    v_first_account := LEAST(param_from_account_id, param_to_account_id);
+    v_second_account := GREATEST(param_from_account_id, param_to_account_id);
+    
+    -- Lock both accounts in order
+    PERFORM 1 FROM accounts WHERE id = v_first_account FOR UPDATE;
+    PERFORM 1 FROM accounts WHERE id = v_second_account FOR UPDATE;
+    
+    -- Verify both accounts exist
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE id = param_from_account_id) THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, 'From account does not exist';
+        RETURN;
+    END IF;
+    
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE id = param_to_account_id) THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, 'To account does not exist';
+        RETURN;
+    END IF;
+
+    -- Get and lock account's balance
+    SELECT get_account_balance(param_from_account_id, true) INTO v_from_balance;
+    SELECT credit_limit INTO v_from_credit_limit FROM accounts WHERE id = param_from_account_id;
+    
+    -- Check sufficient funds
+    IF v_from_balance IS NULL OR v_from_balance + COALESCE(v_from_credit_limit, 0) < param_amount THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, 'Insufficient funds';
+        RETURN;
+    END IF;
+    
+    -- Create transfer record
+    INSERT INTO transfers (from_account_id, to_account_id)
+    VALUES (param_from_account_id, param_to_account_id)
+    RETURNING id INTO v_transfer_id;
+    
+    -- Create transactions atomically
+    INSERT INTO transactions (account_id, transfer_id, amount, trx_type)
+    VALUES 
+        (param_from_account_id, v_transfer_id, param_amount, 'DEBIT'),
+        (param_to_account_id, v_transfer_id, param_amount, 'CREDIT');
+    
+    RETURN QUERY SELECT v_transfer_id, TRUE, 'Transfer completed successfully'::TEXT;
+    
+EXCEPTION
+    WHEN OTHERS THEN
+        RETURN QUERY SELECT NULL::BIGINT, FALSE, SQLERRM;
+END;
+$$;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Append only record of the mutating calls of the APIs and of the jobs and commands. Each record holds the hash of the one
+-- before it, so changing or deleting a record breaks the chain from there on.
+CREATE TABLE IF NOT EXISTS audit_records (
+    seq bigint PRIMARY KEY, -- position in the chain, from 1 and without gaps
+    occurred_at TIMESTAMPTZ NOT NULL,
+    actor varchar NOT NULL, -- caller identity, empty for anonymous calls
+    action varchar NOT NULL, -- HTTP method and route pattern, gRPC method, job or command
+    resource varchar NOT NULL, -- request path, empty for gRPC
+    request_id varchar NOT NULL,
+    ip varchar NOT NULL,
+    payload bytea NOT NULL, -- request body as received, truncated to the audit payload limit
+    status varchar NOT NULL, -- HTTP status or gRPC code, empty when RECEIVED
+    outcome varchar NOT NULL, -- enum: RECEIVED, SUCCEEDED, REJECTED, FAILED
+    prev_hash varchar NOT NULL, -- hash of the record before, empty for the first one
+    hash varchar NOT NULL -- hex SHA-256 of prev_hash and the fields of the record
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_records;
+-- +goose StatementEnd
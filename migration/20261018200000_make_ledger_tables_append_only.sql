@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The ledger tables only ever get new rows: balances are sums of transactions, snapshots and the
+-- audit chain rest on the rows before them. A wrong posting is corrected by a compensating one,
+-- an admin adjustment, never by changing or deleting it. The triggers reject UPDATE and DELETE
+-- statements for every role, the owner included; TRUNCATE is left to privileges, the app role has
+-- none on these tables.
+CREATE OR REPLACE FUNCTION reject_ledger_change()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION '% on % is not allowed, ledger rows are append only', TG_OP, TG_TABLE_NAME
+        USING ERRCODE = 'insufficient_privilege',
+              HINT = 'Correct a posting with a compensating one, an admin adjustment.';
+END;
+$$;
+
+CREATE TRIGGER transfers_append_only
+BEFORE UPDATE OR DELETE ON transfers
+FOR EACH STATEMENT EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER transactions_append_only
+BEFORE UPDATE OR DELETE ON transactions
+FOR EACH STATEMENT EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER account_balance_snapshots_append_only
+BEFORE UPDATE OR DELETE ON account_balance_snapshots
+FOR EACH STATEMENT EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER audit_records_append_only
+BEFORE UPDATE OR DELETE ON audit_records
+FOR EACH STATEMENT EXECUTE FUNCTION reject_ledger_change();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_records_append_only ON audit_records;
+DROP TRIGGER IF EXISTS account_balance_snapshots_append_only ON account_balance_snapshots;
+DROP TRIGGER IF EXISTS transactions_append_only ON transactions;
+DROP TRIGGER IF EXISTS transfers_append_only ON transfers;
+DROP FUNCTION IF EXISTS reject_ledger_change;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bank_app holds the privileges the web server and the worker need and no more: they read and
+-- write the tables but can't alter the schema, and only insert into the ledger tables. It can't
+-- log in, the login roles of the services are granted it:
+--   CREATE ROLE bank_web LOGIN PASSWORD '...' IN ROLE bank_app;
+-- Roles are shared by the databases of the cluster, only the privileges are per database.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = 'bank_app') THEN
+        CREATE ROLE bank_app NOLOGIN;
+    END IF;
+END;
+$$;
+
+GRANT USAGE ON SCHEMA public TO bank_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO bank_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO bank_app;
+
+REVOKE UPDATE, DELETE ON transfers, transactions, account_balance_snapshots, audit_records FROM bank_app;
+REVOKE ALL ON goose_db_version FROM bank_app;
+
+-- Tables of later migrations get the same privileges, ledger ones must revoke UPDATE and DELETE
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO bank_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO bank_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The role may hold privileges in the other databases of the cluster, it is left in place
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM bank_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM bank_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM bank_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM bank_app;
+REVOKE USAGE ON SCHEMA public FROM bank_app;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A failed event waits until next_attempt_at before it is published again, and is dead once it
+-- failed too many times
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (id) WHERE published_at IS NULL AND dead_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS dead_at,
+    DROP COLUMN IF EXISTS next_attempt_at;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ensure_system_account creates a system account unless it exists, and fails when the id is taken
+-- by an account that isn't a system account, so the bank never posts to a customer's account
+-- thinking it is its own
+CREATE FUNCTION ensure_system_account(param_account_id BIGINT)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    v_account_type TEXT;
+BEGIN
+    INSERT INTO accounts (id, account_type, created_at, updated_at)
+    VALUES (param_account_id, 'SYSTEM', NOW(), NOW())
+    ON CONFLICT (id) DO NOTHING;
+
+    SELECT account_type INTO v_account_type FROM accounts WHERE id = param_account_id;
+
+    IF v_account_type IS DISTINCT FROM 'SYSTEM' THEN
+        RAISE EXCEPTION 'account % exists and is not a system account', param_account_id;
+    END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS ensure_system_account(BIGINT);
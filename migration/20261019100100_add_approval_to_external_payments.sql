@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Payments above the approval threshold wait in PENDING_APPROVAL for an approval request of kind
+-- PAYMENT, without a transfer until it is approved. A payment whose request is rejected, expires or
+-- fails is REJECTED without ever leaving the account.
+ALTER TABLE external_payments ALTER COLUMN transfer_id DROP NOT NULL;
+ALTER TABLE external_payments ADD COLUMN approval_request_id bigint REFERENCES approval_requests(id);
+CREATE INDEX IF NOT EXISTS idx_external_payments_approval_request_id ON external_payments (approval_request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_external_payments_approval_request_id;
+ALTER TABLE external_payments DROP COLUMN IF EXISTS approval_request_id;
+DELETE FROM external_payments WHERE transfer_id IS NULL;
+ALTER TABLE external_payments ALTER COLUMN transfer_id SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+// Package outbox records domain events in the database transaction of the change they describe
+// and relays them to a Publisher once committed.
+package outbox
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// EventWriter inserts outbox rows, *sqlc.Queries and the queries of a store.Tx are one
+type EventWriter interface {
+	CreateOutboxEvent(ctx context.Context, arg sqlc.CreateOutboxEventParams) (int64, error)
+}
+
+// Write records the event with queries bound to the transaction of the change, so the event
+// exists exactly when the change does
+func Write(ctx context.Context, queries EventWriter, payload entity.EventPayload) error {
+	params, err := NewEventParams(payload)
+	if err != nil {
+		return err
+	}
+
+	if _, err := queries.CreateOutboxEvent(ctx, params); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", payload.EventType(), err)
+	}
+	return nil
+}
+
+// NewEventParams is the outbox row of the event, for writers that insert rows in bulk
+func NewEventParams(payload entity.EventPayload) (sqlc.CreateOutboxEventParams, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return sqlc.CreateOutboxEventParams{}, fmt.Errorf("failed to marshal %s event: %w", payload.EventType(), err)
+	}
+
+	accountIDs := make([]int64, 0, len(payload.AccountIDs()))
+	for _, id := range payload.AccountIDs() {
+		accountIDs = append(accountIDs, int64(id))
+	}
+
+	return sqlc.CreateOutboxEventParams{
+		EventType:  string(payload.EventType()),
+		AccountIds: accountIDs,
+		Payload:    body,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package outbox_test
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/outbox"
+	"bank/test"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// recordingPublisher fails every event of the accounts in failing
+type recordingPublisher struct {
+	failing   map[uint64]bool
+	published []entity.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event entity.Event) error {
+	for _, id := range event.AccountIDs {
+		if p.failing[id] {
+			return errors.New("broker unavailable")
+		}
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		queries := sqlc.New(testDB.DB)
+
+		payloads := []entity.EventPayload{
+			entity.AccountCreated{AccountID: 1, AccountType: entity.AccountTypeSavings, InitialBalance: decimal.NewFromInt(100)},
+			entity.AccountCreated{AccountID: 2, AccountType: entity.AccountTypeSavings},
+			entity.AccountCreated{AccountID: 3, AccountType: entity.AccountTypeSavings},
+			// Held back behind the failed event of account 2, and so holds back account 1 too, which
+			// holds back account 3 in turn
+			entity.TransferCompleted{TransferID: 1, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)},
+			entity.TransferFailed{SourceAccountID: 1, DestinationAccountID: 3, Amount: decimal.NewFromInt(500), Reason: "insufficient funds"},
+			entity.TransferCompleted{TransferID: 2, SourceAccountID: 3, DestinationAccountID: 4, Amount: decimal.NewFromInt(5)},
+		}
+		for _, payload := range payloads {
+			if err := outbox.Write(ctx, queries, payload); err != nil {
+				t.Fatalf("failed to write event: %v", err)
+			}
+		}
+
+		publisher := &recordingPublisher{failing: map[uint64]bool{2: true}}
+		relay, err := outbox.NewRelay(testDB.DB, queries, publisher, relayConfig(100, 3), logger.NewLogger("debug"))
+		if err != nil {
+			t.Fatalf("failed to create relay: %v", err)
+		}
+
+		result, err := relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+
+		expected := entity.RelayOutboxResult{Published: 2, Failed: 1, Deferred: 3}
+		if result != expected {
+			t.Errorf("expected %+v, got %+v", expected, result)
+		}
+		assertAccounts(t, publisher.published, [][]uint64{{1}, {3}})
+
+		var attempts int
+		var lastError string
+		if err := testDB.DB.QueryRow("SELECT attempts, last_error FROM outbox WHERE 2 = ANY(account_ids) ORDER BY id LIMIT 1").Scan(&attempts, &lastError); err != nil {
+			t.Fatalf("failed to get failed event: %v", err)
+		}
+		if attempts != 1 || lastError != "broker unavailable" {
+			t.Errorf("expected 1 attempt with the publisher error, got %d with %q", attempts, lastError)
+		}
+
+		// The failed event waits for its retry, and so do the events held back behind it
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		assertAccounts(t, publisher.published, [][]uint64{{1}, {3}})
+
+		// Once the publisher recovers the held back events follow in their original order
+		publisher.failing = nil
+		publisher.published = nil
+		if _, err := testDB.DB.Exec("UPDATE outbox SET next_attempt_at = NOW() WHERE published_at IS NULL"); err != nil {
+			t.Fatalf("failed to make the failed event due: %v", err)
+		}
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		assertAccounts(t, publisher.published, [][]uint64{{2}, {1, 2}, {1, 3}, {3, 4}})
+
+		var payload entity.TransferFailed
+		if err := json.Unmarshal(publisher.published[2].Payload, &payload); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+		if publisher.published[2].Type != entity.EventTypeTransferFailed || payload.Reason != "insufficient funds" || !payload.Amount.Equal(decimal.NewFromInt(500)) {
+			t.Errorf("unexpected transfer failed event: %+v %+v", publisher.published[2], payload)
+		}
+
+		result, err = relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		if result != (entity.RelayOutboxResult{}) {
+			t.Errorf("expected nothing left to publish, got %+v", result)
+		}
+	})
+}
+
+func TestRelay_FailingEventsDontStarveOthers(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		queries := sqlc.New(testDB.DB)
+
+		// More failing events than fit in a batch, ahead of the events that can be published
+		for _, accountID := range []uint64{1, 2, 3, 4, 5} {
+			if err := outbox.Write(ctx, queries, entity.AccountCreated{AccountID: accountID, AccountType: entity.AccountTypeSavings}); err != nil {
+				t.Fatalf("failed to write event: %v", err)
+			}
+		}
+
+		publisher := &recordingPublisher{failing: map[uint64]bool{1: true, 2: true, 3: true}}
+		relay, err := outbox.NewRelay(testDB.DB, queries, publisher, relayConfig(2, 2), logger.NewLogger("debug"))
+		if err != nil {
+			t.Fatalf("failed to create relay: %v", err)
+		}
+
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		assertAccounts(t, publisher.published, [][]uint64{{4}, {5}})
+
+		var retrying int
+		if err := testDB.DB.QueryRow("SELECT COUNT(*) FROM outbox WHERE attempts = 1 AND next_attempt_at > NOW() AND dead_at IS NULL").Scan(&retrying); err != nil {
+			t.Fatalf("failed to count failed events: %v", err)
+		}
+		if retrying != 3 {
+			t.Errorf("expected the 3 failed events to wait for a retry, got %d", retrying)
+		}
+
+		// The last attempt fails too, the events are dead and never selected again
+		if _, err := testDB.DB.Exec("UPDATE outbox SET next_attempt_at = NOW() WHERE published_at IS NULL"); err != nil {
+			t.Fatalf("failed to make the failed events due: %v", err)
+		}
+		result, err := relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		if expected := (entity.RelayOutboxResult{Failed: 2, Dead: 2}); result != expected {
+			t.Errorf("expected %+v, got %+v", expected, result)
+		}
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+
+		publisher.failing = nil
+		if _, err := testDB.DB.Exec("UPDATE outbox SET next_attempt_at = NOW() WHERE published_at IS NULL"); err != nil {
+			t.Fatalf("failed to make the failed events due: %v", err)
+		}
+		result, err = relay.RelayBatch(ctx)
+		if err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		if result != (entity.RelayOutboxResult{}) {
+			t.Errorf("expected dead events to be left alone, got %+v", result)
+		}
+	})
+}
+
+func relayConfig(batchSize, maxAttempts int) *config.Config {
+	return &config.Config{
+		OutboxBatchSize:   batchSize,
+		OutboxMaxAttempts: maxAttempts,
+		OutboxBackoffBase: time.Minute,
+		OutboxBackoffMax:  time.Hour,
+	}
+}
+
+func assertAccounts(t *testing.T, events []entity.Event, expected [][]uint64) {
+	t.Helper()
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d published events, got %d", len(expected), len(events))
+	}
+
+	for i, e := range events {
+		if len(e.AccountIDs) != len(expected[i]) {
+			t.Errorf("event %d: expected accounts %v, got %v", i, expected[i], e.AccountIDs)
+			continue
+		}
+		for j, id := range e.AccountIDs {
+			if id != expected[i][j] {
+				t.Errorf("event %d: expected accounts %v, got %v", i, expected[i], e.AccountIDs)
+				break
+			}
+		}
+		if i > 0 && e.ID <= events[i-1].ID {
+			t.Errorf("expected events in write order, got %d after %d", e.ID, events[i-1].ID)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package outbox
+
+import (
+	"bank/entity"
+	"bank/internal/logger"
+	"context"
+)
+
+// LogPublisher writes every event to the log. It is the publisher until a broker is wired in.
+type LogPublisher struct {
+	logger *logger.Logger
+}
+
+func NewLogPublisher(logger *logger.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger.WithField("component", "log_publisher")}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event entity.Event) error {
+	p.logger.Info(ctx, "event_id=%d type=%s accounts=%v payload=%s", event.ID, event.Type, event.AccountIDs, event.Payload)
+	return nil
+}
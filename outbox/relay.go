@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// relayLockKey is the advisory lock that serializes the relays
+const relayLockKey = 0x6f7574626f78
+
+// Publisher delivers events to downstream consumers. An event is only marked published once
+// Publish returned nil, a failed or interrupted delivery is retried, so consumers must tolerate
+// duplicates.
+type Publisher interface {
+	Publish(ctx context.Context, event entity.Event) error
+}
+
+type Relay struct {
+	db          *sql.DB
+	queries     *sqlc.Queries
+	publisher   Publisher
+	logger      *logger.Logger
+	batchSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func NewRelay(db *sql.DB, sqlc *sqlc.Queries, publisher Publisher, cfg *config.Config, logger *logger.Logger) (*Relay, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if publisher == nil {
+		return nil, errors.New("publisher is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.OutboxBatchSize <= 0 {
+		return nil, errors.New("outbox batch size must be positive")
+	}
+
+	if cfg.OutboxMaxAttempts <= 0 {
+		return nil, errors.New("outbox max attempts must be positive")
+	}
+
+	if cfg.OutboxBackoffBase <= 0 || cfg.OutboxBackoffMax < cfg.OutboxBackoffBase {
+		return nil, errors.New("outbox backoff must be positive and not exceed its maximum")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("component", "outbox_relay")
+	return &Relay{
+		db:          db,
+		queries:     sqlc,
+		publisher:   publisher,
+		logger:      log,
+		batchSize:   cfg.OutboxBatchSize,
+		maxAttempts: cfg.OutboxMaxAttempts,
+		backoffBase: cfg.OutboxBackoffBase,
+		backoffMax:  cfg.OutboxBackoffMax,
+	}, nil
+}
+
+// RelayBatch publishes the oldest due events in write order. Relays take turns, so a second relay
+// waits instead of publishing the same events out of order. A failed event is retried after a
+// backoff, until then it holds back the later events sharing one of its accounts, which keeps the
+// order per account while other accounts move on. An event is dead once it failed
+// OutboxMaxAttempts times, it is no longer retried nor holds anything back.
+func (r *Relay) RelayBatch(ctx context.Context) (entity.RelayOutboxResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.RelayOutboxResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.LockOutboxRelay(ctx, relayLockKey); err != nil {
+		return entity.RelayOutboxResult{}, fmt.Errorf("failed to lock outbox relay: %w", err)
+	}
+
+	events, err := r.dueEvents(ctx, qtx)
+	if err != nil {
+		return entity.RelayOutboxResult{}, err
+	}
+
+	result := entity.RelayOutboxResult{}
+	blocked := map[int64]bool{}
+	published := make([]int64, 0, len(events))
+	for _, e := range events {
+		if isBlocked(blocked, e.AccountIds) {
+			block(blocked, e.AccountIds)
+			result.Deferred++
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, toEvent(e)); err != nil {
+			attempt := int(e.Attempts) + 1
+			now := time.Now()
+			failure := sqlc.RecordOutboxEventFailureParams{
+				ID:            e.ID,
+				LastError:     err.Error(),
+				NextAttemptAt: now.Add(r.backoff(attempt)),
+			}
+			if attempt >= r.maxAttempts {
+				r.logger.Error(ctx, "event_id=%d type=%s is dead after %d attempts: %v", e.ID, e.EventType, attempt, err)
+				failure.DeadAt = sql.NullTime{Time: now, Valid: true}
+				result.Dead++
+			} else {
+				r.logger.Warn(ctx, "failed to publish event_id=%d type=%s attempt=%d: %v", e.ID, e.EventType, attempt, err)
+				block(blocked, e.AccountIds)
+			}
+			result.Failed++
+
+			err = qtx.RecordOutboxEventFailure(ctx, failure)
+			if err != nil {
+				return entity.RelayOutboxResult{}, fmt.Errorf("failed to record outbox event failure: %w", err)
+			}
+			continue
+		}
+
+		published = append(published, e.ID)
+		result.Published++
+	}
+
+	// Events published before a shutdown are still marked, the context may already be cancelled
+	if err := qtx.MarkOutboxEventsPublished(context.WithoutCancel(ctx), published); err != nil {
+		return entity.RelayOutboxResult{}, fmt.Errorf("failed to mark outbox events published: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.RelayOutboxResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// Run relays batches until no due event is left. Every batch moves its events out of the next
+// one: they are published, wait for a retry, or wait behind an event that does.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		result, err := r.RelayBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if result.Published+result.Failed > 0 {
+			r.logger.Debug(ctx, "published %d events, %d failed, %d dead, %d deferred", result.Published, result.Failed, result.Dead, result.Deferred)
+		}
+
+		if result.Published+result.Failed+result.Deferred < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// dueEvents walks the pending events in write order and returns the first batch of those that can
+// be published. An event waiting for its retry holds back the later events sharing one of its
+// accounts, and those hold back the events sharing theirs.
+func (r *Relay) dueEvents(ctx context.Context, qtx *sqlc.Queries) ([]sqlc.Outbox, error) {
+	now := time.Now()
+	held := map[int64]bool{}
+	due := make([]sqlc.Outbox, 0, r.batchSize)
+	var afterID int64
+	for {
+		page, err := qtx.ListPendingOutboxEvents(ctx, sqlc.ListPendingOutboxEventsParams{
+			AfterID:  afterID,
+			PageSize: int32(r.batchSize),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list outbox events: %w", err)
+		}
+
+		for _, e := range page {
+			if e.NextAttemptAt.After(now) || isBlocked(held, e.AccountIds) {
+				block(held, e.AccountIds)
+				continue
+			}
+
+			due = append(due, e)
+			if len(due) == r.batchSize {
+				return due, nil
+			}
+		}
+
+		if len(page) < r.batchSize {
+			return due, nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+// backoff returns the wait before the attempt after the given one
+func (r *Relay) backoff(attempt int) time.Duration {
+	wait := r.backoffBase
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= r.backoffMax {
+			return r.backoffMax
+		}
+	}
+	return wait
+}
+
+func isBlocked(blocked map[int64]bool, accountIDs []int64) bool {
+	for _, id := range accountIDs {
+		if blocked[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func block(blocked map[int64]bool, accountIDs []int64) {
+	for _, id := range accountIDs {
+		blocked[id] = true
+	}
+}
+
+func toEvent(e sqlc.Outbox) entity.Event {
+	accountIDs := make([]uint64, 0, len(e.AccountIds))
+	for _, id := range e.AccountIds {
+		accountIDs = append(accountIDs, uint64(id))
+	}
+
+	return entity.Event{
+		ID:         uint64(e.ID),
+		Type:       entity.EventType(e.EventType),
+		AccountIDs: accountIDs,
+		Payload:    e.Payload,
+		CreatedAt:  e.CreatedAt.Time,
+	}
+}
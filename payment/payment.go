@@ -0,0 +1,428 @@
+package payment
+
+import (
+	"bank/approval"
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/pain"
+	"bank/transaction"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const listLimit = 100
+
+type PaymentDomain struct {
+	db                *sql.DB
+	queries           *sqlc.Queries
+	transactionDomain *transaction.TransactionDomain
+	approvalDomain    *approval.ApprovalDomain
+	logger            *logger.Logger
+	clearingAccountID uint64
+	debtor            pain.Debtor
+	maxFilePayments   int
+}
+
+func NewPaymentDomain(db *sql.DB, sqlc *sqlc.Queries, transactionDomain *transaction.TransactionDomain, approvalDomain *approval.ApprovalDomain, cfg *config.Config, logger *logger.Logger) (*PaymentDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if approvalDomain == nil {
+		return nil, errors.New("approval domain is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.ClearingAccountID == 0 {
+		return nil, errors.New("clearing account id is required")
+	}
+
+	if cfg.PaymentDebtorName == "" || cfg.PaymentDebtorAccount == "" || cfg.PaymentDebtorAgentBIC == "" {
+		return nil, errors.New("payment debtor name, account and agent bic are required")
+	}
+
+	if cfg.PaymentFileMaxPayments <= 0 {
+		return nil, errors.New("payment file max payments must be positive")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "payment")
+	return &PaymentDomain{
+		db:                db,
+		queries:           sqlc,
+		transactionDomain: transactionDomain,
+		approvalDomain:    approvalDomain,
+		logger:            log,
+		clearingAccountID: cfg.ClearingAccountID,
+		debtor: pain.Debtor{
+			Name:     cfg.PaymentDebtorName,
+			Account:  cfg.PaymentDebtorAccount,
+			AgentBIC: cfg.PaymentDebtorAgentBIC,
+		},
+		maxFilePayments: cfg.PaymentFileMaxPayments,
+	}, nil
+}
+
+// CreatePayment debits the customer account into the clearing account and records the payment as
+// PENDING, both in one transaction. The payment leaves the bank with the next payment file.
+// Payments above the approval threshold are recorded as PENDING_APPROVAL with an approval request
+// instead, nothing is debited until a second person approves it.
+func (d *PaymentDomain) CreatePayment(ctx context.Context, param entity.CreateExternalPayment) (entity.ExternalPayment, error) {
+	if err := param.Validate(); err != nil {
+		return entity.ExternalPayment{}, err
+	}
+
+	if param.AccountID == d.clearingAccountID {
+		return entity.ExternalPayment{}, fmt.Errorf("%w: payments can't be made from the clearing account", entity.ErrValidation)
+	}
+
+	if err := d.queries.EnsureAccount(ctx, int64(d.clearingAccountID)); err != nil {
+		return entity.ExternalPayment{}, fmt.Errorf("failed to ensure clearing account: %w", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.ExternalPayment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	account, err := qtx.GetAccountByID(ctx, int64(param.AccountID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ExternalPayment{}, entity.ErrDataNotFound
+		}
+		return entity.ExternalPayment{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if entity.AccountType(account.AccountType) == entity.AccountTypeSystem {
+		return entity.ExternalPayment{}, fmt.Errorf("%w: payments can't be made from system accounts", entity.ErrValidation)
+	}
+
+	transfer := entity.CreateTransferFundsParams{
+		SourceAccountID:      param.AccountID,
+		DestinationAccountID: d.clearingAccountID,
+		Amount:               param.Amount,
+	}
+	create := sqlc.CreateExternalPaymentParams{
+		AccountID:             int64(param.AccountID),
+		Amount:                param.Amount,
+		CreditorName:          param.CreditorName,
+		CreditorAccount:       param.CreditorAccount,
+		CreditorAgent:         param.CreditorAgent,
+		RemittanceInformation: param.RemittanceInformation,
+		Status:                string(entity.ExternalPaymentStatusPending),
+	}
+
+	if d.approvalDomain.RequiresApproval(param.Amount) {
+		request, err := d.approvalDomain.RequestPaymentTx(ctx, tx, entity.CreateTransferApproval{
+			CreateTransferFundsParams: transfer,
+			RequestedBy:               param.RequestedBy,
+		})
+		if err != nil {
+			return entity.ExternalPayment{}, err
+		}
+		create.Status = string(entity.ExternalPaymentStatusPendingApproval)
+		create.ApprovalRequestID = sql.NullInt64{Int64: int64(request.ID), Valid: true}
+	} else {
+		result, err := d.transactionDomain.CreateTransferFundsTx(ctx, tx, transfer)
+		if err != nil {
+			return entity.ExternalPayment{}, err
+		}
+		create.TransferID = sql.NullInt64{Int64: int64(result.TransferID), Valid: true}
+	}
+
+	payment, err := qtx.CreateExternalPayment(ctx, create)
+	if err != nil {
+		d.logger.Error(ctx, "failed to create external payment for account_id=%d: %v", param.AccountID, err)
+		return entity.ExternalPayment{}, fmt.Errorf("failed to create external payment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.ExternalPayment{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return toExternalPayment(payment), nil
+}
+
+// GetPayment returns a payment of the account, payments of other accounts are not found
+func (d *PaymentDomain) GetPayment(ctx context.Context, accountID, paymentID uint64) (entity.ExternalPayment, error) {
+	payment, err := d.queries.GetExternalPaymentByID(ctx, int64(paymentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ExternalPayment{}, entity.ErrDataNotFound
+		}
+		return entity.ExternalPayment{}, fmt.Errorf("failed to get external payment: %w", err)
+	}
+
+	if uint64(payment.AccountID) != accountID {
+		return entity.ExternalPayment{}, entity.ErrDataNotFound
+	}
+
+	return toExternalPayment(payment), nil
+}
+
+// ListPayments returns the latest payments of the account
+func (d *PaymentDomain) ListPayments(ctx context.Context, accountID uint64) ([]entity.ExternalPayment, error) {
+	payments, err := d.queries.ListExternalPaymentsByAccountID(ctx, sqlc.ListExternalPaymentsByAccountIDParams{
+		AccountID: int64(accountID),
+		Limit:     listLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list external payments: %w", err)
+	}
+
+	result := make([]entity.ExternalPayment, 0, len(payments))
+	for _, payment := range payments {
+		result = append(result, toExternalPayment(payment))
+	}
+	return result, nil
+}
+
+// EmitPaymentFile writes the pending payments into a pain.001 file, stores it and marks the
+// payments SENT, all in one transaction. The stored file is what must be delivered to the clearing
+// partner, a failed delivery is retried from the store rather than by emitting again. Returns
+// entity.ErrNoRows when no payment is pending.
+func (d *PaymentDomain) EmitPaymentFile(ctx context.Context, now time.Time) (entity.PaymentFile, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	// Payments being created or emitted concurrently are skipped, they go into the next file
+	pending, err := qtx.LockPendingExternalPayments(ctx, int32(d.maxFilePayments))
+	if err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to lock pending external payments: %w", err)
+	}
+
+	if len(pending) == 0 {
+		return entity.PaymentFile{}, entity.ErrNoRows
+	}
+
+	ids := make([]int64, 0, len(pending))
+	payments := make([]pain.Payment, 0, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+		payments = append(payments, pain.Payment{
+			EndToEndID:            toExternalPayment(p).EndToEndID(),
+			Amount:                p.Amount,
+			CreditorName:          p.CreditorName,
+			CreditorAccount:       p.CreditorAccount,
+			CreditorAgentBIC:      p.CreditorAgent,
+			RemittanceInformation: p.RemittanceInformation,
+		})
+	}
+
+	// The first payment id keeps message ids unique even for files emitted in the same second
+	messageID := fmt.Sprintf("PAIN-%s-%d", now.UTC().Format("20060102150405"), pending[0].ID)
+	var content bytes.Buffer
+	err = pain.WriteCreditTransfer(&content, pain.Options{
+		MessageID: messageID,
+		CreatedAt: now,
+		Currency:  entity.CurrencyCodeUSD,
+		Debtor:    d.debtor,
+	}, payments)
+	if err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to write payment file: %w", err)
+	}
+
+	file, err := qtx.CreatePaymentFile(ctx, sqlc.CreatePaymentFileParams{
+		MessageID:    messageID,
+		PaymentCount: int32(len(payments)),
+		ControlSum:   pain.ControlSum(payments).String(),
+		Content:      content.String(),
+	})
+	if err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to create payment file: %w", err)
+	}
+
+	err = qtx.MarkExternalPaymentsSent(ctx, sqlc.MarkExternalPaymentsSentParams{
+		PaymentFileID: sql.NullInt64{Int64: file.ID, Valid: true},
+		Ids:           ids,
+	})
+	if err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to mark external payments sent: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.PaymentFile{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	d.logger.Info(ctx, "emitted payment file message_id=%s with %d payments", messageID, len(payments))
+	return toPaymentFile(file), nil
+}
+
+// GetPaymentFile returns a stored payment file by the message id it was sent with
+func (d *PaymentDomain) GetPaymentFile(ctx context.Context, messageID string) (entity.PaymentFile, error) {
+	file, err := d.queries.GetPaymentFileByMessageID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.PaymentFile{}, entity.ErrDataNotFound
+		}
+		return entity.PaymentFile{}, fmt.Errorf("failed to get payment file: %w", err)
+	}
+	return toPaymentFile(file), nil
+}
+
+// ApplyStatusReport applies a pain.002 status report to the payments of the file it answers.
+// Accepted payments are final. Rejected payments are refunded from the clearing account to the
+// customer. Only SENT payments change, so applying the same report again has no effect, and
+// payments the report leaves pending wait for a later one.
+func (d *PaymentDomain) ApplyStatusReport(ctx context.Context, r io.Reader) (entity.ApplyPaymentStatusReportResult, error) {
+	report, err := pain.ParseStatusReport(r)
+	if err != nil {
+		return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("%w: %v", entity.ErrValidation, err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	file, err := qtx.GetPaymentFileByMessageID(ctx, report.OriginalMessageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("%w: payment file %s", entity.ErrDataNotFound, report.OriginalMessageID)
+		}
+		return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("failed to get payment file: %w", err)
+	}
+
+	payments, err := qtx.LockExternalPaymentsByPaymentFileID(ctx, sql.NullInt64{Int64: file.ID, Valid: true})
+	if err != nil {
+		return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("failed to lock external payments: %w", err)
+	}
+
+	known := make(map[string]bool, len(payments))
+	result := entity.ApplyPaymentStatusReportResult{PaymentFileID: uint64(file.ID)}
+	for _, p := range payments {
+		payment := toExternalPayment(p)
+		known[payment.EndToEndID()] = true
+		status, reason := report.StatusOf(payment.EndToEndID())
+
+		if payment.Status != entity.ExternalPaymentStatusSent {
+			result.Unchanged++
+			continue
+		}
+
+		switch {
+		case pain.IsAccepted(status):
+			err = qtx.UpdateExternalPaymentStatus(ctx, sqlc.UpdateExternalPaymentStatusParams{
+				ID:           p.ID,
+				Status:       string(entity.ExternalPaymentStatusAccepted),
+				StatusReason: reason,
+			})
+			result.Accepted++
+		case pain.IsRejected(status):
+			err = d.reverse(ctx, tx, qtx, p, reason)
+			result.Rejected++
+		default:
+			result.Unchanged++
+		}
+		if err != nil {
+			d.logger.Error(ctx, "failed to apply status %s to payment_id=%d: %v", status, p.ID, err)
+			return entity.ApplyPaymentStatusReportResult{}, err
+		}
+	}
+
+	for _, status := range report.Transactions {
+		if !known[status.OriginalEndToEndID] {
+			d.logger.Warn(ctx, "status report for message_id=%s has unknown payment %s", report.OriginalMessageID, status.OriginalEndToEndID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.ApplyPaymentStatusReportResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	d.logger.Info(ctx, "applied status report for message_id=%s: %d accepted, %d rejected, %d unchanged", report.OriginalMessageID, result.Accepted, result.Rejected, result.Unchanged)
+	return result, nil
+}
+
+// reverse refunds a rejected payment from the clearing account, which may go negative
+func (d *PaymentDomain) reverse(ctx context.Context, tx *sql.Tx, qtx *sqlc.Queries, p sqlc.ExternalPayment, reason string) error {
+	reversal, err := d.transactionDomain.PostTransferTx(ctx, tx, entity.CreateTransferFundsParams{
+		SourceAccountID:      d.clearingAccountID,
+		DestinationAccountID: uint64(p.AccountID),
+		Amount:               p.Amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reverse external payment: %w", err)
+	}
+
+	err = qtx.UpdateExternalPaymentStatus(ctx, sqlc.UpdateExternalPaymentStatusParams{
+		ID:                 p.ID,
+		Status:             string(entity.ExternalPaymentStatusRejected),
+		StatusReason:       reason,
+		ReversalTransferID: sql.NullInt64{Int64: int64(reversal.TransferID), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update external payment: %w", err)
+	}
+	return nil
+}
+
+func toExternalPayment(p sqlc.ExternalPayment) entity.ExternalPayment {
+	return entity.ExternalPayment{
+		ModelWithUpdatedAt: entity.ModelWithUpdatedAt{
+			Model: entity.Model{
+				ID:        uint64(p.ID),
+				CreatedAt: p.CreatedAt.Time,
+			},
+			UpdatedAt: p.UpdatedAt.Time,
+		},
+		AccountID:             uint64(p.AccountID),
+		Amount:                p.Amount,
+		CreditorName:          p.CreditorName,
+		CreditorAccount:       p.CreditorAccount,
+		CreditorAgent:         p.CreditorAgent,
+		RemittanceInformation: p.RemittanceInformation,
+		Status:                entity.ExternalPaymentStatus(p.Status),
+		StatusReason:          p.StatusReason,
+		TransferID:            uint64(p.TransferID.Int64),
+		ReversalTransferID:    uint64(p.ReversalTransferID.Int64),
+		PaymentFileID:         uint64(p.PaymentFileID.Int64),
+		ApprovalRequestID:     uint64(p.ApprovalRequestID.Int64),
+	}
+}
+
+func toPaymentFile(f sqlc.PaymentFile) entity.PaymentFile {
+	// control_sum is a numeric column, it always parses
+	controlSum, _ := decimal.NewFromString(f.ControlSum)
+	return entity.PaymentFile{
+		Model: entity.Model{
+			ID:        uint64(f.ID),
+			CreatedAt: f.CreatedAt.Time,
+		},
+		MessageID:    f.MessageID,
+		PaymentCount: int(f.PaymentCount),
+		ControlSum:   controlSum,
+		Content:      f.Content,
+	}
+}
@@ -0,0 +1,292 @@
+package payment_test
+
+import (
+	"bank/approval"
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/pain"
+	"bank/payment"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const clearingAccountID = 900000006
+
+func statusReport(originalMessageID string, statuses map[string]string) string {
+	var txs strings.Builder
+	for endToEndID, status := range statuses {
+		fmt.Fprintf(&txs, `<TxInfAndSts><OrgnlEndToEndId>%s</OrgnlEndToEndId><TxSts>%s</TxSts>`, endToEndID, status)
+		if status == pain.StatusRejected {
+			txs.WriteString(`<StsRsnInf><Rsn><Cd>AC04</Cd></Rsn></StsRsnInf>`)
+		}
+		txs.WriteString(`</TxInfAndSts>`)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10">
+  <CstmrPmtStsRpt>
+    <GrpHdr><MsgId>STS-1</MsgId></GrpHdr>
+    <OrgnlGrpInfAndSts><OrgnlMsgId>%s</OrgnlMsgId></OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>%s</OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>`, originalMessageID, txs.String())
+}
+
+// newPaymentDomain wires the payment domain with payments above 100 needing approval
+func newPaymentDomain(t *testing.T, testDB *test.TestDB) (*payment.PaymentDomain, *approval.ApprovalDomain) {
+	t.Helper()
+	testLogger := logger.NewLogger("debug")
+	ledger, err := store.NewPostgres(testDB.DB)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	cfg := &config.Config{
+		ApprovalTransferThreshold: decimal.NewFromInt(100),
+		ApprovalTTL:               time.Hour,
+		AdjustmentAccountID:       900000001,
+		ClearingAccountID:         clearingAccountID,
+		PaymentDebtorName:         "BANK",
+		PaymentDebtorAccount:      "900000006",
+		PaymentDebtorAgentBIC:     "BANKUS33XXX",
+		PaymentFileMaxPayments:    1000,
+	}
+
+	approvalDomain, err := approval.NewApprovalDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create approval domain: %v", err)
+	}
+
+	domain, err := payment.NewPaymentDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, approvalDomain, cfg, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create payment domain: %v", err)
+	}
+	return domain, approvalDomain
+}
+
+func TestPaymentLifecycle(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		domain, _ := newPaymentDomain(t, testDB)
+
+		statements := []string{
+			"INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES (700, 'SAVINGS', now(), now())",
+			"INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES (700, 100, 'CREDIT', now())",
+		}
+		for _, stmt := range statements {
+			if _, err := testDB.DB.Exec(stmt); err != nil {
+				t.Fatalf("failed to seed data: %v", err)
+			}
+		}
+
+		if _, err := domain.EmitPaymentFile(ctx, time.Now()); !errors.Is(err, entity.ErrNoRows) {
+			t.Fatalf("expected no payment file without pending payments, got %v", err)
+		}
+
+		payments := []entity.ExternalPayment{}
+		for _, amount := range []string{"10", "20", "30"} {
+			p, err := domain.CreatePayment(ctx, entity.CreateExternalPayment{
+				AccountID:       700,
+				Amount:          decimal.RequireFromString(amount),
+				CreditorName:    "Jane Doe",
+				CreditorAccount: "DE89370400440532013000",
+				CreditorAgent:   "COBADEFFXXX",
+			})
+			if err != nil {
+				t.Fatalf("failed to create payment of %s: %v", amount, err)
+			}
+			if p.Status != entity.ExternalPaymentStatusPending {
+				t.Errorf("expected payment to be PENDING, got %s", p.Status)
+			}
+			payments = append(payments, p)
+		}
+
+		_, err := domain.CreatePayment(ctx, entity.CreateExternalPayment{
+			AccountID:       700,
+			Amount:          decimal.RequireFromString("50"),
+			CreditorName:    "Jane Doe",
+			CreditorAccount: "DE89370400440532013000",
+			CreditorAgent:   "COBADEFFXXX",
+		})
+		if !errors.Is(err, entity.ErrInsufficientFunds) {
+			t.Fatalf("expected insufficient funds, got %v", err)
+		}
+
+		file, err := domain.EmitPaymentFile(ctx, time.Date(2026, time.October, 18, 17, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("failed to emit payment file: %v", err)
+		}
+
+		if file.PaymentCount != 3 || !file.ControlSum.Equal(decimal.NewFromInt(60)) {
+			t.Errorf("expected 3 payments summing to 60, got %d summing to %s", file.PaymentCount, file.ControlSum)
+		}
+		for _, p := range payments {
+			if !strings.Contains(file.Content, "<EndToEndId>"+p.EndToEndID()+"</EndToEndId>") {
+				t.Errorf("expected the file to contain payment %s", p.EndToEndID())
+			}
+		}
+
+		if _, err := domain.EmitPaymentFile(ctx, time.Now()); !errors.Is(err, entity.ErrNoRows) {
+			t.Fatalf("expected sent payments not to be emitted again, got %v", err)
+		}
+
+		report := statusReport(file.MessageID, map[string]string{
+			payments[0].EndToEndID(): pain.StatusAcceptedSettlementCompleted,
+			payments[1].EndToEndID(): pain.StatusRejected,
+			payments[2].EndToEndID(): pain.StatusPending,
+		})
+
+		// The second application must not reverse the rejected payment again
+		for run, expected := range []entity.ApplyPaymentStatusReportResult{
+			{PaymentFileID: file.ID, Accepted: 1, Rejected: 1, Unchanged: 1},
+			{PaymentFileID: file.ID, Unchanged: 3},
+		} {
+			result, err := domain.ApplyStatusReport(ctx, bytes.NewBufferString(report))
+			if err != nil {
+				t.Fatalf("run %d: failed to apply status report: %v", run, err)
+			}
+			if result != expected {
+				t.Errorf("run %d: expected %+v, got %+v", run, expected, result)
+			}
+		}
+
+		expectedStatuses := []entity.ExternalPaymentStatus{
+			entity.ExternalPaymentStatusAccepted,
+			entity.ExternalPaymentStatusRejected,
+			entity.ExternalPaymentStatusSent,
+		}
+		for i, p := range payments {
+			got, err := domain.GetPayment(ctx, 700, p.ID)
+			if err != nil {
+				t.Fatalf("failed to get payment %d: %v", p.ID, err)
+			}
+			if got.Status != expectedStatuses[i] {
+				t.Errorf("expected payment %d to be %s, got %s", p.ID, expectedStatuses[i], got.Status)
+			}
+		}
+
+		rejected, _ := domain.GetPayment(ctx, 700, payments[1].ID)
+		if rejected.StatusReason != "AC04" || rejected.ReversalTransferID == 0 {
+			t.Errorf("expected the rejected payment to be reversed with reason AC04, got %+v", rejected)
+		}
+
+		if _, err := domain.GetPayment(ctx, 701, payments[0].ID); !errors.Is(err, entity.ErrDataNotFound) {
+			t.Errorf("expected payments of other accounts not to be found, got %v", err)
+		}
+
+		if _, err := domain.ApplyStatusReport(ctx, bytes.NewBufferString(statusReport("UNKNOWN", nil))); !errors.Is(err, entity.ErrDataNotFound) {
+			t.Errorf("expected a report for an unknown file not to be found, got %v", err)
+		}
+
+		expectedBalances := map[uint64]string{700: "60", clearingAccountID: "40"}
+		for accountID, expected := range expectedBalances {
+			var balance string
+			if err := testDB.DB.QueryRow("SELECT get_account_balance($1, false)", accountID).Scan(&balance); err != nil {
+				t.Fatalf("failed to get balance of account %d: %v", accountID, err)
+			}
+			if !decimal.RequireFromString(balance).Equal(decimal.RequireFromString(expected)) {
+				t.Errorf("expected balance of account %d to be %s, got %s", accountID, expected, balance)
+			}
+		}
+	})
+}
+
+func TestPaymentApproval(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		domain, approvalDomain := newPaymentDomain(t, testDB)
+
+		statements := []string{
+			"INSERT INTO accounts (id, account_type, created_at, updated_at) VALUES (700, 'SAVINGS', now(), now())",
+			"INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES (700, 500, 'CREDIT', now())",
+		}
+		for _, stmt := range statements {
+			if _, err := testDB.DB.Exec(stmt); err != nil {
+				t.Fatalf("failed to seed data: %v", err)
+			}
+		}
+
+		payments := []entity.ExternalPayment{}
+		for _, amount := range []string{"150", "120"} {
+			p, err := domain.CreatePayment(ctx, entity.CreateExternalPayment{
+				AccountID:       700,
+				Amount:          decimal.RequireFromString(amount),
+				CreditorName:    "Jane Doe",
+				CreditorAccount: "DE89370400440532013000",
+				CreditorAgent:   "COBADEFFXXX",
+				RequestedBy:     "alice",
+			})
+			if err != nil {
+				t.Fatalf("failed to create payment of %s: %v", amount, err)
+			}
+			if p.Status != entity.ExternalPaymentStatusPendingApproval || p.ApprovalRequestID == 0 || p.TransferID != 0 {
+				t.Errorf("expected payment to wait for approval without a transfer, got %+v", p)
+			}
+			payments = append(payments, p)
+		}
+
+		if _, err := domain.EmitPaymentFile(ctx, time.Now()); !errors.Is(err, entity.ErrNoRows) {
+			t.Fatalf("expected payments waiting for approval not to be emitted, got %v", err)
+		}
+
+		_, err := approvalDomain.Approve(ctx, entity.DecideApproval{ApprovalRequestID: payments[0].ApprovalRequestID, Actor: "bob"})
+		if err != nil {
+			t.Fatalf("failed to approve payment: %v", err)
+		}
+		_, err = approvalDomain.Reject(ctx, entity.DecideApproval{ApprovalRequestID: payments[1].ApprovalRequestID, Actor: "bob"})
+		if err != nil {
+			t.Fatalf("failed to reject payment: %v", err)
+		}
+
+		approved, err := domain.GetPayment(ctx, 700, payments[0].ID)
+		if err != nil {
+			t.Fatalf("failed to get payment: %v", err)
+		}
+		if approved.Status != entity.ExternalPaymentStatusPending || approved.TransferID == 0 {
+			t.Errorf("expected the approved payment to be PENDING with a transfer, got %+v", approved)
+		}
+
+		rejected, err := domain.GetPayment(ctx, 700, payments[1].ID)
+		if err != nil {
+			t.Fatalf("failed to get payment: %v", err)
+		}
+		if rejected.Status != entity.ExternalPaymentStatusRejected || rejected.TransferID != 0 {
+			t.Errorf("expected the rejected payment to be REJECTED without a transfer, got %+v", rejected)
+		}
+
+		file, err := domain.EmitPaymentFile(ctx, time.Now())
+		if err != nil {
+			t.Fatalf("failed to emit payment file: %v", err)
+		}
+		if file.PaymentCount != 1 || !strings.Contains(file.Content, "<EndToEndId>"+approved.EndToEndID()+"</EndToEndId>") {
+			t.Errorf("expected the file to hold the approved payment only, got %d payments", file.PaymentCount)
+		}
+
+		expectedBalances := map[uint64]string{700: "350", clearingAccountID: "150"}
+		for accountID, expected := range expectedBalances {
+			var balance string
+			if err := testDB.DB.QueryRow("SELECT get_account_balance($1, false)", accountID).Scan(&balance); err != nil {
+				t.Fatalf("failed to get balance of account %d: %v", accountID, err)
+			}
+			if !decimal.RequireFromString(balance).Equal(decimal.RequireFromString(expected)) {
+				t.Errorf("expected balance of account %d to be %s, got %s", accountID, expected, balance)
+			}
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"bank/entity"
+	"bank/rpc/bankpb"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateAccount(ctx context.Context, req *bankpb.CreateAccountRequest) (*bankpb.CreateAccountResponse, error) {
+	initialBalance, err := parseAmount("initial balance", req.GetInitialBalance(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	creditLimit, err := parseAmount("credit limit", req.GetCreditLimit(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &entity.CreateAccount{
+		AccountID:      req.GetAccountId(),
+		InitialBalance: initialBalance,
+		CreditLimit:    creditLimit,
+	}
+	switch req.GetAccountType() {
+	case bankpb.AccountType_ACCOUNT_TYPE_UNSPECIFIED:
+	case bankpb.AccountType_ACCOUNT_TYPE_SAVINGS:
+		account.AccountType = entity.AccountTypeSavings
+	case bankpb.AccountType_ACCOUNT_TYPE_CREDIT:
+		account.AccountType = entity.AccountTypeCredit
+	default:
+		return nil, status.Error(codes.InvalidArgument, "account type must be SAVINGS or CREDIT")
+	}
+
+	if err := s.accountDomain.CreateAccount(ctx, account); err != nil {
+		return nil, s.statusError(ctx, "create account", err)
+	}
+
+	return &bankpb.CreateAccountResponse{}, nil
+}
+
+func (s *Server) GetAccountBalance(ctx context.Context, req *bankpb.GetAccountBalanceRequest) (*bankpb.GetAccountBalanceResponse, error) {
+	balance, err := s.accountDomain.GetAccountBalance(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, s.statusError(ctx, "get account balance", err)
+	}
+
+	return &bankpb.GetAccountBalanceResponse{
+		AccountId: req.GetAccountId(),
+		Balance:   balance.String(),
+	}, nil
+}
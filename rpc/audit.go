@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"bank/audit"
+	"bank/entity"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/requestid"
+	"bank/rpc/bankpb"
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// readOnlyMethods aren't audited, a method missing from here is
+var readOnlyMethods = map[string]bool{
+	bankpb.BankService_GetAccountBalance_FullMethodName: true,
+	bankpb.BankService_ListTransactions_FullMethodName:  true,
+}
+
+// AuditInterceptor records every call of a mutating method in the audit trail, the way the Audit
+// middleware records the HTTP requests: a RECEIVED record before the call runs, which the call is
+// refused with Unavailable without, and the record of its outcome once it is served. The caller
+// is the x-user-id metadata GatewayInterceptor lets through, and an outcome that fails to be
+// written is logged and counted.
+func AuditInterceptor(auditDomain *audit.AuditDomain, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if readOnlyMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var payload []byte
+		if message, ok := req.(proto.Message); ok {
+			payload, _ = protojson.Marshal(message)
+		}
+		record := entity.CreateAuditRecord{
+			OccurredAt: time.Now(),
+			Actor:      userID(ctx),
+			Action:     info.FullMethod,
+			RequestID:  metadataValue(ctx, strings.ToLower(requestid.Header)),
+			IP:         peerIP(ctx),
+			Payload:    payload,
+		}
+		if err := auditDomain.Received(ctx, record); err != nil {
+			log.Error(ctx, "failed to record audit of %s: %v", info.FullMethod, err)
+			metrics.AuditWriteFailed(metrics.APIGRPC)
+			return nil, status.Error(codes.Unavailable, "the call can't be recorded, please retry later")
+		}
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		record.Status = code.String()
+		record.Outcome = auditOutcome(code)
+		if _, recordErr := auditDomain.Record(context.WithoutCancel(ctx), record); recordErr != nil {
+			log.Error(ctx, "failed to record audit of %s: %v", info.FullMethod, recordErr)
+			metrics.AuditWriteFailed(metrics.APIGRPC)
+		}
+		return resp, err
+	}
+}
+
+// auditOutcome tells the codes of the caller's mistakes from those of failures on our side
+func auditOutcome(code codes.Code) entity.AuditOutcome {
+	switch code {
+	case codes.OK:
+		return entity.AuditOutcomeSucceeded
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded, codes.Unimplemented:
+		return entity.AuditOutcomeFailed
+	default:
+		return entity.AuditOutcomeRejected
+	}
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,41 @@
+package rpc_test
+
+import (
+	"bank/audit"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/rpc"
+	"bank/rpc/bankpb"
+	"context"
+	"database/sql"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditInterceptor_WriteFailure(t *testing.T) {
+	// A closed database fails every write of the audit trail
+	db, err := sql.Open("postgres", "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	testLogger := logger.NewLogger("debug")
+	auditDomain, err := audit.NewAuditDomain(db, sqlc.New(db), testLogger)
+	if err != nil {
+		t.Fatalf("failed to create audit domain: %v", err)
+	}
+
+	served := false
+	interceptor := rpc.AuditInterceptor(auditDomain, testLogger)
+	_, err = interceptor(context.Background(), &bankpb.CreateTransferFundsRequest{}, &grpc.UnaryServerInfo{FullMethod: bankpb.BankService_CreateTransferFunds_FullMethodName}, func(ctx context.Context, req any) (any, error) {
+		served = true
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unavailable || served {
+		t.Errorf("expected the call to be refused with Unavailable before it is served, got %v, served %v", err, served)
+	}
+}
@@ -0,0 +1,823 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: bank/v1/bank.proto
+
+package bankpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AccountType int32
+
+const (
+	AccountType_ACCOUNT_TYPE_UNSPECIFIED AccountType = 0
+	AccountType_ACCOUNT_TYPE_SAVINGS     AccountType = 1
+	AccountType_ACCOUNT_TYPE_CREDIT      AccountType = 2
+)
+
+// Enum value maps for AccountType.
+var (
+	AccountType_name = map[int32]string{
+		0: "ACCOUNT_TYPE_UNSPECIFIED",
+		1: "ACCOUNT_TYPE_SAVINGS",
+		2: "ACCOUNT_TYPE_CREDIT",
+	}
+	AccountType_value = map[string]int32{
+		"ACCOUNT_TYPE_UNSPECIFIED": 0,
+		"ACCOUNT_TYPE_SAVINGS":     1,
+		"ACCOUNT_TYPE_CREDIT":      2,
+	}
+)
+
+func (x AccountType) Enum() *AccountType {
+	p := new(AccountType)
+	*p = x
+	return p
+}
+
+func (x AccountType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccountType) Descriptor() protoreflect.EnumDescriptor {
+	return file_bank_v1_bank_proto_enumTypes[0].Descriptor()
+}
+
+func (AccountType) Type() protoreflect.EnumType {
+	return &file_bank_v1_bank_proto_enumTypes[0]
+}
+
+func (x AccountType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccountType.Descriptor instead.
+func (AccountType) EnumDescriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{0}
+}
+
+type TransactionType int32
+
+const (
+	TransactionType_TRANSACTION_TYPE_UNSPECIFIED TransactionType = 0
+	TransactionType_TRANSACTION_TYPE_CREDIT      TransactionType = 1
+	TransactionType_TRANSACTION_TYPE_DEBIT       TransactionType = 2
+)
+
+// Enum value maps for TransactionType.
+var (
+	TransactionType_name = map[int32]string{
+		0: "TRANSACTION_TYPE_UNSPECIFIED",
+		1: "TRANSACTION_TYPE_CREDIT",
+		2: "TRANSACTION_TYPE_DEBIT",
+	}
+	TransactionType_value = map[string]int32{
+		"TRANSACTION_TYPE_UNSPECIFIED": 0,
+		"TRANSACTION_TYPE_CREDIT":      1,
+		"TRANSACTION_TYPE_DEBIT":       2,
+	}
+)
+
+func (x TransactionType) Enum() *TransactionType {
+	p := new(TransactionType)
+	*p = x
+	return p
+}
+
+func (x TransactionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransactionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_bank_v1_bank_proto_enumTypes[1].Descriptor()
+}
+
+func (TransactionType) Type() protoreflect.EnumType {
+	return &file_bank_v1_bank_proto_enumTypes[1]
+}
+
+func (x TransactionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransactionType.Descriptor instead.
+func (TransactionType) EnumDescriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{1}
+}
+
+type CreateAccountRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// account_type defaults to savings
+	AccountType    AccountType `protobuf:"varint,2,opt,name=account_type,json=accountType,proto3,enum=bank.v1.AccountType" json:"account_type,omitempty"`
+	InitialBalance string      `protobuf:"bytes,3,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
+	CreditLimit    string      `protobuf:"bytes,4,opt,name=credit_limit,json=creditLimit,proto3" json:"credit_limit,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateAccountRequest) Reset() {
+	*x = CreateAccountRequest{}
+	mi := &file_bank_v1_bank_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountRequest) ProtoMessage() {}
+
+func (x *CreateAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountRequest) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateAccountRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *CreateAccountRequest) GetAccountType() AccountType {
+	if x != nil {
+		return x.AccountType
+	}
+	return AccountType_ACCOUNT_TYPE_UNSPECIFIED
+}
+
+func (x *CreateAccountRequest) GetInitialBalance() string {
+	if x != nil {
+		return x.InitialBalance
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetCreditLimit() string {
+	if x != nil {
+		return x.CreditLimit
+	}
+	return ""
+}
+
+type CreateAccountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountResponse) Reset() {
+	*x = CreateAccountResponse{}
+	mi := &file_bank_v1_bank_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountResponse) ProtoMessage() {}
+
+func (x *CreateAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccountResponse) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{1}
+}
+
+type GetAccountBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountBalanceRequest) Reset() {
+	*x = GetAccountBalanceRequest{}
+	mi := &file_bank_v1_bank_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountBalanceRequest) ProtoMessage() {}
+
+func (x *GetAccountBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAccountBalanceRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+type GetAccountBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balance       string                 `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountBalanceResponse) Reset() {
+	*x = GetAccountBalanceResponse{}
+	mi := &file_bank_v1_bank_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountBalanceResponse) ProtoMessage() {}
+
+func (x *GetAccountBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAccountBalanceResponse) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetAccountBalanceResponse) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+type CreateTransferFundsRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	SourceAccountId      uint64                 `protobuf:"varint,1,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	DestinationAccountId uint64                 `protobuf:"varint,2,opt,name=destination_account_id,json=destinationAccountId,proto3" json:"destination_account_id,omitempty"`
+	Amount               string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CreateTransferFundsRequest) Reset() {
+	*x = CreateTransferFundsRequest{}
+	mi := &file_bank_v1_bank_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferFundsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferFundsRequest) ProtoMessage() {}
+
+func (x *CreateTransferFundsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferFundsRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransferFundsRequest) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateTransferFundsRequest) GetSourceAccountId() uint64 {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransferFundsRequest) GetDestinationAccountId() uint64 {
+	if x != nil {
+		return x.DestinationAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransferFundsRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type CreateTransferFundsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*CreateTransferFundsResponse_TransferId
+	//	*CreateTransferFundsResponse_Approval
+	Result        isCreateTransferFundsResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransferFundsResponse) Reset() {
+	*x = CreateTransferFundsResponse{}
+	mi := &file_bank_v1_bank_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferFundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferFundsResponse) ProtoMessage() {}
+
+func (x *CreateTransferFundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferFundsResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransferFundsResponse) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateTransferFundsResponse) GetResult() isCreateTransferFundsResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CreateTransferFundsResponse) GetTransferId() uint64 {
+	if x != nil {
+		if x, ok := x.Result.(*CreateTransferFundsResponse_TransferId); ok {
+			return x.TransferId
+		}
+	}
+	return 0
+}
+
+func (x *CreateTransferFundsResponse) GetApproval() *TransferApproval {
+	if x != nil {
+		if x, ok := x.Result.(*CreateTransferFundsResponse_Approval); ok {
+			return x.Approval
+		}
+	}
+	return nil
+}
+
+type isCreateTransferFundsResponse_Result interface {
+	isCreateTransferFundsResponse_Result()
+}
+
+type CreateTransferFundsResponse_TransferId struct {
+	TransferId uint64 `protobuf:"varint,1,opt,name=transfer_id,json=transferId,proto3,oneof"`
+}
+
+type CreateTransferFundsResponse_Approval struct {
+	Approval *TransferApproval `protobuf:"bytes,2,opt,name=approval,proto3,oneof"`
+}
+
+func (*CreateTransferFundsResponse_TransferId) isCreateTransferFundsResponse_Result() {}
+
+func (*CreateTransferFundsResponse_Approval) isCreateTransferFundsResponse_Result() {}
+
+type TransferApproval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApprovalId    uint64                 `protobuf:"varint,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferApproval) Reset() {
+	*x = TransferApproval{}
+	mi := &file_bank_v1_bank_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferApproval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferApproval) ProtoMessage() {}
+
+func (x *TransferApproval) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferApproval.ProtoReflect.Descriptor instead.
+func (*TransferApproval) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TransferApproval) GetApprovalId() uint64 {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return 0
+}
+
+func (x *TransferApproval) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransferApproval) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ListTransactionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// from is inclusive and to is exclusive
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_bank_v1_bank_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTransactionsRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type Transaction struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId         uint64                 `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	TransferId            *int64                 `protobuf:"varint,2,opt,name=transfer_id,json=transferId,proto3,oneof" json:"transfer_id,omitempty"`
+	CounterpartyAccountId *int64                 `protobuf:"varint,3,opt,name=counterparty_account_id,json=counterpartyAccountId,proto3,oneof" json:"counterparty_account_id,omitempty"`
+	Type                  TransactionType        `protobuf:"varint,4,opt,name=type,proto3,enum=bank.v1.TransactionType" json:"type,omitempty"`
+	Amount                string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Balance               string                 `protobuf:"bytes,6,opt,name=balance,proto3" json:"balance,omitempty"`
+	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_bank_v1_bank_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_bank_v1_bank_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_bank_v1_bank_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Transaction) GetTransactionId() uint64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *Transaction) GetTransferId() int64 {
+	if x != nil && x.TransferId != nil {
+		return *x.TransferId
+	}
+	return 0
+}
+
+func (x *Transaction) GetCounterpartyAccountId() int64 {
+	if x != nil && x.CounterpartyAccountId != nil {
+		return *x.CounterpartyAccountId
+	}
+	return 0
+}
+
+func (x *Transaction) GetType() TransactionType {
+	if x != nil {
+		return x.Type
+	}
+	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+}
+
+func (x *Transaction) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Transaction) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_bank_v1_bank_proto protoreflect.FileDescriptor
+
+const file_bank_v1_bank_proto_rawDesc = "" +
+	"\n" +
+	"\x12bank/v1/bank.proto\x12\abank.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xba\x01\n" +
+	"\x14CreateAccountRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x04R\taccountId\x127\n" +
+	"\faccount_type\x18\x02 \x01(\x0e2\x14.bank.v1.AccountTypeR\vaccountType\x12'\n" +
+	"\x0finitial_balance\x18\x03 \x01(\tR\x0einitialBalance\x12!\n" +
+	"\fcredit_limit\x18\x04 \x01(\tR\vcreditLimit\"\x17\n" +
+	"\x15CreateAccountResponse\"9\n" +
+	"\x18GetAccountBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x04R\taccountId\"T\n" +
+	"\x19GetAccountBalanceResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x04R\taccountId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\tR\abalance\"\x96\x01\n" +
+	"\x1aCreateTransferFundsRequest\x12*\n" +
+	"\x11source_account_id\x18\x01 \x01(\x04R\x0fsourceAccountId\x124\n" +
+	"\x16destination_account_id\x18\x02 \x01(\x04R\x14destinationAccountId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\"\x83\x01\n" +
+	"\x1bCreateTransferFundsResponse\x12!\n" +
+	"\vtransfer_id\x18\x01 \x01(\x04H\x00R\n" +
+	"transferId\x127\n" +
+	"\bapproval\x18\x02 \x01(\v2\x19.bank.v1.TransferApprovalH\x00R\bapprovalB\b\n" +
+	"\x06result\"\x86\x01\n" +
+	"\x10TransferApproval\x12\x1f\n" +
+	"\vapproval_id\x18\x01 \x01(\x04R\n" +
+	"approvalId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x94\x01\n" +
+	"\x17ListTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x04R\taccountId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\xde\x02\n" +
+	"\vTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x04R\rtransactionId\x12$\n" +
+	"\vtransfer_id\x18\x02 \x01(\x03H\x00R\n" +
+	"transferId\x88\x01\x01\x12;\n" +
+	"\x17counterparty_account_id\x18\x03 \x01(\x03H\x01R\x15counterpartyAccountId\x88\x01\x01\x12,\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x18.bank.v1.TransactionTypeR\x04type\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\tR\x06amount\x12\x18\n" +
+	"\abalance\x18\x06 \x01(\tR\abalance\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\x0e\n" +
+	"\f_transfer_idB\x1a\n" +
+	"\x18_counterparty_account_id*^\n" +
+	"\vAccountType\x12\x1c\n" +
+	"\x18ACCOUNT_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ACCOUNT_TYPE_SAVINGS\x10\x01\x12\x17\n" +
+	"\x13ACCOUNT_TYPE_CREDIT\x10\x02*l\n" +
+	"\x0fTransactionType\x12 \n" +
+	"\x1cTRANSACTION_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17TRANSACTION_TYPE_CREDIT\x10\x01\x12\x1a\n" +
+	"\x16TRANSACTION_TYPE_DEBIT\x10\x022\xe9\x02\n" +
+	"\vBankService\x12N\n" +
+	"\rCreateAccount\x12\x1d.bank.v1.CreateAccountRequest\x1a\x1e.bank.v1.CreateAccountResponse\x12Z\n" +
+	"\x11GetAccountBalance\x12!.bank.v1.GetAccountBalanceRequest\x1a\".bank.v1.GetAccountBalanceResponse\x12`\n" +
+	"\x13CreateTransferFunds\x12#.bank.v1.CreateTransferFundsRequest\x1a$.bank.v1.CreateTransferFundsResponse\x12L\n" +
+	"\x10ListTransactions\x12 .bank.v1.ListTransactionsRequest\x1a\x14.bank.v1.Transaction0\x01B\x11Z\x0fbank/rpc/bankpbb\x06proto3"
+
+var (
+	file_bank_v1_bank_proto_rawDescOnce sync.Once
+	file_bank_v1_bank_proto_rawDescData []byte
+)
+
+func file_bank_v1_bank_proto_rawDescGZIP() []byte {
+	file_bank_v1_bank_proto_rawDescOnce.Do(func() {
+		file_bank_v1_bank_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bank_v1_bank_proto_rawDesc), len(file_bank_v1_bank_proto_rawDesc)))
+	})
+	return file_bank_v1_bank_proto_rawDescData
+}
+
+var file_bank_v1_bank_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_bank_v1_bank_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_bank_v1_bank_proto_goTypes = []any{
+	(AccountType)(0),                    // 0: bank.v1.AccountType
+	(TransactionType)(0),                // 1: bank.v1.TransactionType
+	(*CreateAccountRequest)(nil),        // 2: bank.v1.CreateAccountRequest
+	(*CreateAccountResponse)(nil),       // 3: bank.v1.CreateAccountResponse
+	(*GetAccountBalanceRequest)(nil),    // 4: bank.v1.GetAccountBalanceRequest
+	(*GetAccountBalanceResponse)(nil),   // 5: bank.v1.GetAccountBalanceResponse
+	(*CreateTransferFundsRequest)(nil),  // 6: bank.v1.CreateTransferFundsRequest
+	(*CreateTransferFundsResponse)(nil), // 7: bank.v1.CreateTransferFundsResponse
+	(*TransferApproval)(nil),            // 8: bank.v1.TransferApproval
+	(*ListTransactionsRequest)(nil),     // 9: bank.v1.ListTransactionsRequest
+	(*Transaction)(nil),                 // 10: bank.v1.Transaction
+	(*timestamppb.Timestamp)(nil),       // 11: google.protobuf.Timestamp
+}
+var file_bank_v1_bank_proto_depIdxs = []int32{
+	0,  // 0: bank.v1.CreateAccountRequest.account_type:type_name -> bank.v1.AccountType
+	8,  // 1: bank.v1.CreateTransferFundsResponse.approval:type_name -> bank.v1.TransferApproval
+	11, // 2: bank.v1.TransferApproval.expires_at:type_name -> google.protobuf.Timestamp
+	11, // 3: bank.v1.ListTransactionsRequest.from:type_name -> google.protobuf.Timestamp
+	11, // 4: bank.v1.ListTransactionsRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 5: bank.v1.Transaction.type:type_name -> bank.v1.TransactionType
+	11, // 6: bank.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 7: bank.v1.BankService.CreateAccount:input_type -> bank.v1.CreateAccountRequest
+	4,  // 8: bank.v1.BankService.GetAccountBalance:input_type -> bank.v1.GetAccountBalanceRequest
+	6,  // 9: bank.v1.BankService.CreateTransferFunds:input_type -> bank.v1.CreateTransferFundsRequest
+	9,  // 10: bank.v1.BankService.ListTransactions:input_type -> bank.v1.ListTransactionsRequest
+	3,  // 11: bank.v1.BankService.CreateAccount:output_type -> bank.v1.CreateAccountResponse
+	5,  // 12: bank.v1.BankService.GetAccountBalance:output_type -> bank.v1.GetAccountBalanceResponse
+	7,  // 13: bank.v1.BankService.CreateTransferFunds:output_type -> bank.v1.CreateTransferFundsResponse
+	10, // 14: bank.v1.BankService.ListTransactions:output_type -> bank.v1.Transaction
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_bank_v1_bank_proto_init() }
+func file_bank_v1_bank_proto_init() {
+	if File_bank_v1_bank_proto != nil {
+		return
+	}
+	file_bank_v1_bank_proto_msgTypes[5].OneofWrappers = []any{
+		(*CreateTransferFundsResponse_TransferId)(nil),
+		(*CreateTransferFundsResponse_Approval)(nil),
+	}
+	file_bank_v1_bank_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bank_v1_bank_proto_rawDesc), len(file_bank_v1_bank_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bank_v1_bank_proto_goTypes,
+		DependencyIndexes: file_bank_v1_bank_proto_depIdxs,
+		EnumInfos:         file_bank_v1_bank_proto_enumTypes,
+		MessageInfos:      file_bank_v1_bank_proto_msgTypes,
+	}.Build()
+	File_bank_v1_bank_proto = out.File
+	file_bank_v1_bank_proto_goTypes = nil
+	file_bank_v1_bank_proto_depIdxs = nil
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"bank/entity"
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// amountPrecision is the number of fraction digits amounts are stored with
+const amountPrecision = 6
+
+// statusError maps a domain error to a gRPC status the way the HTTP handlers map it to a
+// response. Unexpected errors are logged and hidden behind Internal.
+func (s *Server) statusError(ctx context.Context, op string, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		return status.Error(codes.FailedPrecondition, "your account has insufficient funds")
+	case errors.Is(err, entity.ErrDataNotFound), errors.Is(err, entity.ErrNoRows):
+		return status.Error(codes.NotFound, "invalid account")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		s.logger.Error(ctx, "failed to %s: %v", op, err)
+		return status.Error(codes.Internal, "it's not you, it's us. please contact support")
+	}
+}
+
+// parseAmount reads a decimal string field, an empty optional field is zero
+func parseAmount(field, value string, required bool) (decimal.Decimal, error) {
+	if value == "" {
+		if required {
+			return decimal.Zero, status.Errorf(codes.InvalidArgument, "%s is required", field)
+		}
+		return decimal.Zero, nil
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "%s must be a valid number", field)
+	}
+
+	if -amount.Exponent() > amountPrecision {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "%s has too many decimal places (max %d)", field, amountPrecision)
+	}
+	return amount, nil
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"bank/internal/gateway"
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// GatewayInterceptor drops the x-user-id metadata of calls that don't come from the gateway
+// networks, the way the TrustGateway middleware drops the X-User-ID header. It must run before
+// the other interceptors.
+func GatewayInterceptor(networks gateway.Networks) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(trustGateway(ctx, networks), req)
+	}
+}
+
+// GatewayStreamInterceptor is GatewayInterceptor for streaming calls
+func GatewayStreamInterceptor(networks gateway.Networks) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := trustGateway(ss.Context(), networks)
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// trustGateway returns ctx without the x-user-id metadata unless the call comes from the gateway
+func trustGateway(ctx context.Context, networks gateway.Networks) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(userIDMetadata)) == 0 {
+		return ctx
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil && networks.Contains(p.Addr.String()) {
+		return ctx
+	}
+
+	md = md.Copy()
+	md.Delete(userIDMetadata)
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// serverStream is a stream running in another context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+package rpc_test
+
+import (
+	"bank/internal/gateway"
+	"bank/rpc"
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// fakeStream is a server stream that only carries a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+// callerContext is the context of a call from addr carrying the user 42
+func callerContext(addr string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "42"))
+	return peer.NewContext(ctx, &peer.Peer{Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(addr))})
+}
+
+func TestGatewayInterceptor(t *testing.T) {
+	networks, err := gateway.ParseNetworks([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("failed to parse networks: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		addr string
+		want int
+	}{
+		{name: "gateway", addr: "10.0.0.1:5000", want: 1},
+		{name: "other network", addr: "192.168.0.1:5000", want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got int
+			unary := rpc.GatewayInterceptor(networks)
+			_, err := unary(callerContext(tc.addr), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				got = len(md.Get("x-user-id"))
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("unary call got %d user ids, want %d", got, tc.want)
+			}
+
+			got = -1
+			stream := rpc.GatewayStreamInterceptor(networks)
+			err = stream(nil, &fakeStream{ctx: callerContext(tc.addr)}, &grpc.StreamServerInfo{}, func(srv any, ss grpc.ServerStream) error {
+				md, _ := metadata.FromIncomingContext(ss.Context())
+				got = len(md.Get("x-user-id"))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("streaming call got %d user ids, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package rpc serves the gRPC API, the typed counterpart of the customer HTTP handlers for
+// internal services. It runs over the same domains.
+package rpc
+
+import (
+	"bank/account"
+	"bank/approval"
+	"bank/internal/logger"
+	"bank/rpc/bankpb"
+	"bank/transaction"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	bankpb.UnimplementedBankServiceServer
+
+	accountDomain     *account.AccountDomain
+	approvalDomain    *approval.ApprovalDomain
+	logger            *logger.Logger
+	transactionDomain *transaction.TransactionDomain
+}
+
+func NewServer(accountDomain *account.AccountDomain, transactionDomain *transaction.TransactionDomain, approvalDomain *approval.ApprovalDomain, logger *logger.Logger) (*Server, error) {
+	if accountDomain == nil {
+		return nil, errors.New("account domain is nil")
+	}
+
+	if transactionDomain == nil {
+		return nil, errors.New("transaction domain is nil")
+	}
+
+	if approvalDomain == nil {
+		return nil, errors.New("approval domain is nil")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("handler", "rpc")
+	return &Server{
+		accountDomain:     accountDomain,
+		approvalDomain:    approvalDomain,
+		logger:            log,
+		transactionDomain: transactionDomain,
+	}, nil
+}
+
+// Register adds the service to a gRPC server
+func (s *Server) Register(srv *grpc.Server) {
+	bankpb.RegisterBankServiceServer(srv, s)
+}
@@ -0,0 +1,193 @@
+package rpc_test
+
+import (
+	"bank/account"
+	"bank/approval"
+	"bank/config"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/rpc"
+	"bank/rpc/bankpb"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newClient serves the API over an in-memory connection
+func newClient(t *testing.T, testDB *test.TestDB) bankpb.BankServiceClient {
+	testLogger := logger.NewLogger("debug")
+	ledger, err := store.NewPostgres(testDB.DB)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	accountDomain, err := account.NewAccountDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	approvalDomain, err := approval.NewApprovalDomain(testDB.DB, sqlc.New(testDB.DB), transactionDomain, &config.Config{
+		ApprovalTransferThreshold: decimal.NewFromInt(100000),
+		ApprovalTTL:               time.Hour,
+		AdjustmentAccountID:       900000001,
+	}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create approval domain: %v", err)
+	}
+
+	server, err := rpc.NewServer(accountDomain, transactionDomain, approvalDomain, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	server.Register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return bankpb.NewBankServiceClient(conn)
+}
+
+func expectCode(t *testing.T, err error, code codes.Code, message string) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Errorf("expected code %s, got %v", code, err)
+		return
+	}
+	if message != "" && status.Convert(err).Message() != message {
+		t.Errorf("expected message %q, got %q", message, status.Convert(err).Message())
+	}
+}
+
+func TestAccountsAndTransfers(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		client := newClient(t, testDB)
+
+		for _, req := range []*bankpb.CreateAccountRequest{
+			{AccountId: 800, InitialBalance: "100"},
+			{AccountId: 801, AccountType: bankpb.AccountType_ACCOUNT_TYPE_CREDIT, InitialBalance: "10", CreditLimit: "50"},
+		} {
+			if _, err := client.CreateAccount(ctx, req); err != nil {
+				t.Fatalf("failed to create account %d: %v", req.AccountId, err)
+			}
+		}
+
+		_, err := client.CreateAccount(ctx, &bankpb.CreateAccountRequest{AccountId: 802})
+		expectCode(t, err, codes.InvalidArgument, "initial balance is required")
+
+		_, err = client.CreateAccount(ctx, &bankpb.CreateAccountRequest{AccountId: 802, InitialBalance: "1.1234567"})
+		expectCode(t, err, codes.InvalidArgument, "initial balance has too many decimal places (max 6)")
+
+		_, err = client.CreateAccount(ctx, &bankpb.CreateAccountRequest{AccountId: 802, InitialBalance: "10", CreditLimit: "5"})
+		expectCode(t, err, codes.InvalidArgument, "validation error: credit limit is only allowed on CREDIT accounts")
+
+		transfer, err := client.CreateTransferFunds(ctx, &bankpb.CreateTransferFundsRequest{
+			SourceAccountId:      800,
+			DestinationAccountId: 801,
+			Amount:               "40.5",
+		})
+		if err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+		if transfer.GetTransferId() == 0 {
+			t.Errorf("expected a transfer id, got %v", transfer)
+		}
+
+		balance, err := client.GetAccountBalance(ctx, &bankpb.GetAccountBalanceRequest{AccountId: 800})
+		if err != nil {
+			t.Fatalf("failed to get balance: %v", err)
+		}
+		if balance.GetBalance() != "59.5" {
+			t.Errorf("expected balance 59.5, got %s", balance.GetBalance())
+		}
+
+		_, err = client.GetAccountBalance(ctx, &bankpb.GetAccountBalanceRequest{AccountId: 999})
+		expectCode(t, err, codes.NotFound, "invalid account")
+
+		_, err = client.CreateTransferFunds(ctx, &bankpb.CreateTransferFundsRequest{SourceAccountId: 800, DestinationAccountId: 801, Amount: "1000"})
+		expectCode(t, err, codes.FailedPrecondition, "your account has insufficient funds")
+
+		_, err = client.CreateTransferFunds(ctx, &bankpb.CreateTransferFundsRequest{SourceAccountId: 800, DestinationAccountId: 999, Amount: "1"})
+		expectCode(t, err, codes.NotFound, "invalid account")
+
+		_, err = client.CreateTransferFunds(ctx, &bankpb.CreateTransferFundsRequest{SourceAccountId: 800, DestinationAccountId: 801, Amount: "abc"})
+		expectCode(t, err, codes.InvalidArgument, "amount must be a valid number")
+
+		// Above the threshold the transfer waits for approval
+		approvalCtx := metadata.AppendToOutgoingContext(ctx, "x-user-id", "maker")
+		pending, err := client.CreateTransferFunds(approvalCtx, &bankpb.CreateTransferFundsRequest{SourceAccountId: 800, DestinationAccountId: 801, Amount: "200000"})
+		if err != nil {
+			t.Fatalf("failed to request approval: %v", err)
+		}
+		if pending.GetApproval().GetApprovalId() == 0 || pending.GetApproval().GetStatus() != "PENDING_APPROVAL" {
+			t.Errorf("expected a pending approval, got %v", pending)
+		}
+
+		stream, err := client.ListTransactions(ctx, &bankpb.ListTransactionsRequest{
+			AccountId: 800,
+			From:      timestamppb.New(time.Now().Add(-time.Hour)),
+			To:        timestamppb.New(time.Now().Add(time.Hour)),
+		})
+		if err != nil {
+			t.Fatalf("failed to list transactions: %v", err)
+		}
+
+		transactions := []*bankpb.Transaction{}
+		for {
+			tx, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to receive transaction: %v", err)
+			}
+			transactions = append(transactions, tx)
+		}
+
+		if len(transactions) != 2 {
+			t.Fatalf("expected 2 transactions, got %d", len(transactions))
+		}
+		debit := transactions[1]
+		if debit.GetType() != bankpb.TransactionType_TRANSACTION_TYPE_DEBIT || debit.GetAmount() != "40.5" ||
+			debit.GetBalance() != "59.5" || debit.GetCounterpartyAccountId() != 801 || debit.GetTransferId() != int64(transfer.GetTransferId()) {
+			t.Errorf("unexpected transfer transaction %v", debit)
+		}
+
+		stream, err = client.ListTransactions(ctx, &bankpb.ListTransactionsRequest{AccountId: 800})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		expectCode(t, err, codes.InvalidArgument, "validation error: from is required, to is required")
+	})
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"bank/entity"
+	"bank/rpc/bankpb"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userIDMetadata carries the identity of the caller, like the X-User-ID header of the HTTP API
+const userIDMetadata = "x-user-id"
+
+func (s *Server) CreateTransferFunds(ctx context.Context, req *bankpb.CreateTransferFundsRequest) (*bankpb.CreateTransferFundsResponse, error) {
+	amount, err := parseAmount("amount", req.GetAmount(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	param := entity.CreateTransferFundsParams{
+		SourceAccountID:      req.GetSourceAccountId(),
+		DestinationAccountID: req.GetDestinationAccountId(),
+		Amount:               amount,
+	}
+
+	// Transfers above the threshold wait for a second person, as over HTTP
+	if s.approvalDomain.RequiresApproval(amount) {
+		approval, err := s.approvalDomain.RequestTransfer(ctx, entity.CreateTransferApproval{
+			CreateTransferFundsParams: param,
+			RequestedBy:               userID(ctx),
+		})
+		if err != nil {
+			return nil, s.statusError(ctx, "request transfer approval", err)
+		}
+
+		return &bankpb.CreateTransferFundsResponse{
+			Result: &bankpb.CreateTransferFundsResponse_Approval{
+				Approval: &bankpb.TransferApproval{
+					ApprovalId: approval.ID,
+					Status:     string(approval.Status),
+					ExpiresAt:  timestamppb.New(approval.ExpiresAt),
+				},
+			},
+		}, nil
+	}
+
+	result, err := s.transactionDomain.CreateTransferFunds(ctx, param)
+	if err != nil {
+		return nil, s.statusError(ctx, "create transfer funds", err)
+	}
+
+	return &bankpb.CreateTransferFundsResponse{
+		Result: &bankpb.CreateTransferFundsResponse_TransferId{TransferId: result.TransferID},
+	}, nil
+}
+
+func (s *Server) ListTransactions(req *bankpb.ListTransactionsRequest, stream grpc.ServerStreamingServer[bankpb.Transaction]) error {
+	param := entity.GetAccountStatement{AccountID: req.GetAccountId()}
+	if req.GetFrom() != nil {
+		param.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		param.To = req.GetTo().AsTime()
+	}
+
+	err := s.accountDomain.StreamStatement(stream.Context(), param, &transactionStreamWriter{stream: stream})
+	if err != nil {
+		return s.statusError(stream.Context(), "list transactions", err)
+	}
+	return nil
+}
+
+// transactionStreamWriter sends every statement entry as a message, the balances at both
+// ends of the period aren't part of the stream
+type transactionStreamWriter struct {
+	stream grpc.ServerStreamingServer[bankpb.Transaction]
+}
+
+func (t *transactionStreamWriter) Begin(entity.AccountStatement) error {
+	return nil
+}
+
+func (t *transactionStreamWriter) WriteEntry(e entity.StatementEntry) error {
+	transaction := &bankpb.Transaction{
+		TransactionId: e.ID,
+		Amount:        e.Amount.String(),
+		Balance:       e.Balance.String(),
+		CreatedAt:     timestamppb.New(e.CreatedAt),
+	}
+	if e.TransferID.Valid {
+		transaction.TransferId = &e.TransferID.Int64
+	}
+	if e.CounterpartyAccountID.Valid {
+		transaction.CounterpartyAccountId = &e.CounterpartyAccountID.Int64
+	}
+	switch e.TrxType {
+	case entity.TrxTypeCredit:
+		transaction.Type = bankpb.TransactionType_TRANSACTION_TYPE_CREDIT
+	case entity.TrxTypeDebit:
+		transaction.Type = bankpb.TransactionType_TRANSACTION_TYPE_DEBIT
+	}
+	return t.stream.Send(transaction)
+}
+
+func (t *transactionStreamWriter) End(entity.AccountStatement) error {
+	return nil
+}
+
+// userID returns the caller identity from the request metadata, or an empty string
+func userID(ctx context.Context) string {
+	return strings.TrimSpace(metadataValue(ctx, userIDMetadata))
+}
+
+// metadataValue returns the first value of key in the request metadata, or an empty string
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
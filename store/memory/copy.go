@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CopyFrom inserts the rows one by one with the checks of the other writes. Columns that are left
+// out take the defaults of the tables.
+func (t *tx) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) error {
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("copy into %s: %d values for %d columns", table, len(row), len(columns))
+		}
+
+		values := copyValues{values: make(map[string]any, len(columns)), now: t.now}
+		for i, column := range columns {
+			values.values[column] = row[i]
+		}
+
+		var err error
+		switch table {
+		case "accounts":
+			account := sqlc.Account{
+				ID:          values.int64("id"),
+				CreatedAt:   values.time("created_at"),
+				UpdatedAt:   values.time("updated_at"),
+				AccountType: values.string("account_type", string(entity.AccountTypeSavings)),
+				CreditLimit: values.decimal("credit_limit").StringFixed(precision),
+			}
+			if values.err == nil {
+				err = t.insertAccount(account)
+			}
+		case "transfers":
+			transfer := sqlc.Transfer{
+				ID:            values.int64("id"),
+				FromAccountID: values.int64("from_account_id"),
+				ToAccountID:   values.int64("to_account_id"),
+				CreatedAt:     values.time("created_at"),
+			}
+			if values.err == nil {
+				_, err = t.insertTransfer(transfer)
+			}
+		case "transactions":
+			transaction := sqlc.Transaction{
+				AccountID: values.int64("account_id"),
+				Amount:    values.decimal("amount"),
+				TrxType:   values.string("trx_type", ""),
+				CreatedAt: values.time("created_at"),
+			}
+			if transferID := values.int64("transfer_id"); transferID != 0 {
+				transaction.TransferID = sql.NullInt64{Int64: transferID, Valid: true}
+			}
+			if values.err == nil {
+				_, err = t.insertTransaction(transaction)
+			}
+		case "outbox":
+			event := sqlc.Outbox{
+				EventType:  values.string("event_type", ""),
+				AccountIds: values.int64s("account_ids"),
+				Payload:    []byte(values.string("payload", "")),
+				CreatedAt:  values.time("created_at"),
+			}
+			if values.err == nil {
+				_, err = t.insertOutbox(event)
+			}
+		default:
+			return fmt.Errorf("copy into %s: unknown table", table)
+		}
+
+		if values.err != nil {
+			return fmt.Errorf("copy into %s: %w", table, values.err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyValues reads the values of a copied row by column, keeping the first error
+type copyValues struct {
+	values map[string]any
+	now    time.Time
+	err    error
+}
+
+func (v *copyValues) fail(column string, value any) {
+	if v.err == nil {
+		v.err = fmt.Errorf("unexpected value %v of type %T for column %s", value, value, column)
+	}
+}
+
+func (v *copyValues) int64(column string) int64 {
+	switch value := v.values[column].(type) {
+	case nil:
+		return 0
+	case int64:
+		return value
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	default:
+		v.fail(column, value)
+		return 0
+	}
+}
+
+func (v *copyValues) int64s(column string) []int64 {
+	switch value := v.values[column].(type) {
+	case nil:
+		return nil
+	case []int64:
+		return value
+	default:
+		v.fail(column, value)
+		return nil
+	}
+}
+
+func (v *copyValues) string(column, fallback string) string {
+	switch value := v.values[column].(type) {
+	case nil:
+		return fallback
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		v.fail(column, value)
+		return ""
+	}
+}
+
+func (v *copyValues) decimal(column string) decimal.Decimal {
+	switch value := v.values[column].(type) {
+	case nil:
+		return decimal.Zero
+	case decimal.Decimal:
+		return value
+	case string:
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			v.fail(column, value)
+		}
+		return d
+	default:
+		v.fail(column, value)
+		return decimal.Zero
+	}
+}
+
+func (v *copyValues) time(column string) sql.NullTime {
+	switch value := v.values[column].(type) {
+	case nil:
+		return sql.NullTime{Time: v.now, Valid: true}
+	case time.Time:
+		return sql.NullTime{Time: value, Valid: true}
+	default:
+		v.fail(column, value)
+		return sql.NullTime{}
+	}
+}
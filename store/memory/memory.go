@@ -0,0 +1,458 @@
+// Package memory is an in-memory ledger with the semantics of the Postgres one, for tests of the
+// account and transaction domains that run without a database.
+package memory
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// precision is the number of decimal places of the ledger's DECIMAL(20,6) columns
+const precision = 6
+
+var (
+	errDeadlock = errors.New("deadlock detected")
+	errReadOnly = errors.New("cannot write in a read-only transaction")
+)
+
+// Store keeps the ledger in memory. Like Postgres, a transaction sees its own writes and the
+// writes others committed, or only those committed before it began when it is repeatable read.
+// Locks are held until the transaction ends, and a transaction that would wait for itself fails
+// with a deadlock instead.
+type Store struct {
+	mu sync.Mutex
+	// released is broadcast whenever a transaction releases its locks
+	released *sync.Cond
+	data     ledger
+	// locks maps every locked account to its transaction, waiting maps a transaction to the
+	// account it waits for
+	locks   map[int64]*tx
+	waiting map[*tx]int64
+
+	lastTransactionID int64
+	lastTransferID    int64
+	lastSnapshotID    int64
+	lastOutboxID      int64
+}
+
+var _ store.Store = (*Store)(nil)
+
+func New() *Store {
+	s := &Store{
+		locks:   map[int64]*tx{},
+		waiting: map[*tx]int64{},
+	}
+	s.released = sync.NewCond(&s.mu)
+	return s
+}
+
+// ledger holds the rows of the tables, transactions are kept in id order
+type ledger struct {
+	accounts     []sqlc.Account
+	transfers    []sqlc.Transfer
+	transactions []sqlc.Transaction
+	snapshots    []sqlc.AccountBalanceSnapshot
+	outbox       []sqlc.Outbox
+}
+
+func (l ledger) clone() ledger {
+	return ledger{
+		accounts:     slices.Clone(l.accounts),
+		transfers:    slices.Clone(l.transfers),
+		transactions: slices.Clone(l.transactions),
+		snapshots:    slices.Clone(l.snapshots),
+		outbox:       slices.Clone(l.outbox),
+	}
+}
+
+func (l *ledger) add(other ledger) {
+	l.accounts = append(l.accounts, other.accounts...)
+	l.transfers = append(l.transfers, other.transfers...)
+	l.transactions = append(l.transactions, other.transactions...)
+	l.snapshots = append(l.snapshots, other.snapshots...)
+	l.outbox = append(l.outbox, other.outbox...)
+	slices.SortFunc(l.transactions, func(a, b sqlc.Transaction) int { return cmp.Compare(a.ID, b.ID) })
+}
+
+func (l ledger) account(id int64) (sqlc.Account, bool) {
+	for _, account := range l.accounts {
+		if account.ID == id {
+			return account, true
+		}
+	}
+	return sqlc.Account{}, false
+}
+
+func (l ledger) transfer(id int64) (sqlc.Transfer, bool) {
+	for _, transfer := range l.transfers {
+		if transfer.ID == id {
+			return transfer, true
+		}
+	}
+	return sqlc.Transfer{}, false
+}
+
+func (l ledger) transaction(id int64) (sqlc.Transaction, bool) {
+	for _, transaction := range l.transactions {
+		if transaction.ID == id {
+			return transaction, true
+		}
+	}
+	return sqlc.Transaction{}, false
+}
+
+// latestSnapshot is the latest snapshot of the account that only covers transactions before the
+// cutoff, any snapshot when the cutoff is zero. It is nil when there is none.
+func (l ledger) latestSnapshot(accountID int64, cutoff time.Time) *sqlc.AccountBalanceSnapshot {
+	var latest *sqlc.AccountBalanceSnapshot
+	for i, snapshot := range l.snapshots {
+		if snapshot.AccountID != accountID {
+			continue
+		}
+		if last, ok := l.transaction(snapshot.LastTransactionID); !ok || !before(last, cutoff) {
+			continue
+		}
+		if latest == nil || !snapshot.CreatedAt.Time.Before(latest.CreatedAt.Time) {
+			latest = &l.snapshots[i]
+		}
+	}
+	return latest
+}
+
+// before reports whether the transaction was created before the cutoff, always when it is zero
+func before(t sqlc.Transaction, cutoff time.Time) bool {
+	return cutoff.IsZero() || t.CreatedAt.Time.Before(cutoff)
+}
+
+// balance is get_account_balance_at, a zero cutoff is get_account_balance. It starts from the
+// latest snapshot that only covers transactions before the cutoff.
+func (l ledger) balance(accountID int64, cutoff time.Time) decimal.Decimal {
+	latest := l.latestSnapshot(accountID, cutoff)
+
+	balance, lastTransactionID := decimal.Zero, int64(0)
+	if latest != nil {
+		balance = decimal.RequireFromString(latest.Balance)
+		lastTransactionID = latest.LastTransactionID
+	}
+
+	for _, t := range l.transactions {
+		if t.AccountID != accountID || t.ID <= lastTransactionID || !before(t, cutoff) {
+			continue
+		}
+		switch entity.TrxType(t.TrxType) {
+		case entity.TrxTypeCredit:
+			balance = balance.Add(t.Amount)
+		case entity.TrxTypeDebit:
+			balance = balance.Sub(t.Amount)
+		}
+	}
+	return balance
+}
+
+// counterparty is the other account of the transfer of the transaction, 0 outside a transfer
+func (l ledger) counterparty(t sqlc.Transaction) int64 {
+	if !t.TransferID.Valid {
+		return 0
+	}
+	transfer, ok := l.transfer(t.TransferID.Int64)
+	if !ok {
+		return 0
+	}
+	if entity.TrxType(t.TrxType) == entity.TrxTypeCredit {
+		return transfer.FromAccountID
+	}
+	return transfer.ToAccountID
+}
+
+func (s *Store) BeginTx(ctx context.Context, opts *sql.TxOptions) (store.Tx, error) {
+	t := &tx{store: s, now: time.Now()}
+	if opts != nil {
+		t.readOnly = opts.ReadOnly
+		if opts.Isolation >= sql.LevelRepeatableRead {
+			s.mu.Lock()
+			view := s.data.clone()
+			s.mu.Unlock()
+			t.view = &view
+		}
+	}
+	return t, nil
+}
+
+// CreateBalanceSnapshot records the balance of the account up to and including the transaction,
+// the way the snapshots of the database are taken
+func (s *Store) CreateBalanceSnapshot(accountID int64, balance decimal.Decimal, lastTransactionID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.transaction(lastTransactionID); !ok {
+		return fmt.Errorf("transaction %d does not exist", lastTransactionID)
+	}
+
+	s.lastSnapshotID++
+	s.data.snapshots = append(s.data.snapshots, sqlc.AccountBalanceSnapshot{
+		ID:                s.lastSnapshotID,
+		AccountID:         accountID,
+		Balance:           balance.StringFixed(precision),
+		LastTransactionID: lastTransactionID,
+		CreatedAt:         sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	return nil
+}
+
+// OutboxEvents returns the committed outbox rows in the order they were written
+func (s *Store) OutboxEvents() []sqlc.Outbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.data.outbox)
+}
+
+// lock takes the lock of the account for t, waiting for the transaction holding it to end
+func (s *Store) lock(t *tx, accountID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		owner, locked := s.locks[accountID]
+		if !locked || owner == t {
+			s.locks[accountID] = t
+			return nil
+		}
+
+		if s.waitsFor(owner, t) {
+			return errDeadlock
+		}
+
+		s.waiting[t] = accountID
+		s.released.Wait()
+		delete(s.waiting, t)
+	}
+}
+
+// waitsFor reports whether owner waits, directly or through others, for a lock t holds
+func (s *Store) waitsFor(owner, t *tx) bool {
+	for range len(s.waiting) + 1 {
+		if owner == t {
+			return true
+		}
+
+		accountID, waiting := s.waiting[owner]
+		if !waiting {
+			return false
+		}
+		owner = s.locks[accountID]
+	}
+	return false
+}
+
+// release drops the locks of t, s.mu must be held
+func (s *Store) release(t *tx) {
+	for accountID, owner := range s.locks {
+		if owner == t {
+			delete(s.locks, accountID)
+		}
+	}
+	s.released.Broadcast()
+}
+
+// tx buffers its writes until commit. It is used by one goroutine at a time, like sql.Tx.
+type tx struct {
+	store *Store
+	// now is the time of the transaction, like NOW() in Postgres
+	now      time.Time
+	readOnly bool
+	// view is the ledger as it was when a repeatable read transaction began
+	view    *ledger
+	pending ledger
+	done    bool
+}
+
+// read returns the ledger as the transaction sees it
+func (t *tx) read() (ledger, error) {
+	if t.done {
+		return ledger{}, sql.ErrTxDone
+	}
+
+	// Every statement yields, so concurrent transactions interleave between statements as they
+	// do on a database, even on a single CPU
+	runtime.Gosched()
+
+	var l ledger
+	if t.view != nil {
+		l = t.view.clone()
+	} else {
+		t.store.mu.Lock()
+		l = t.store.data.clone()
+		t.store.mu.Unlock()
+	}
+	l.add(t.pending)
+	return l, nil
+}
+
+func (t *tx) writable() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	if t.readOnly {
+		return errReadOnly
+	}
+	return nil
+}
+
+func (t *tx) lock(accountID int64) error {
+	if err := t.writable(); err != nil {
+		return err
+	}
+	return t.store.lock(t, accountID)
+}
+
+func (t *tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.release(t)
+
+	for _, account := range t.pending.accounts {
+		if _, exists := s.data.account(account.ID); exists {
+			return fmt.Errorf("duplicate key value violates unique constraint \"accounts_pkey\": id=%d", account.ID)
+		}
+	}
+	s.data.add(t.pending)
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	t.store.release(t)
+	return nil
+}
+
+func (t *tx) insertAccount(account sqlc.Account) error {
+	if err := t.writable(); err != nil {
+		return err
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := l.account(account.ID); exists {
+		return fmt.Errorf("duplicate key value violates unique constraint \"accounts_pkey\": id=%d", account.ID)
+	}
+
+	t.pending.accounts = append(t.pending.accounts, account)
+	return nil
+}
+
+func (t *tx) insertTransfer(transfer sqlc.Transfer) (sqlc.Transfer, error) {
+	if err := t.writable(); err != nil {
+		return sqlc.Transfer{}, err
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return sqlc.Transfer{}, err
+	}
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		if _, exists := l.account(accountID); !exists {
+			return sqlc.Transfer{}, fmt.Errorf("insert on table \"transfers\" violates foreign key constraint: account %d does not exist", accountID)
+		}
+	}
+
+	if transfer.ID == 0 {
+		transfer.ID = t.store.nextID(&t.store.lastTransferID)
+	}
+	t.pending.transfers = append(t.pending.transfers, transfer)
+	return transfer, nil
+}
+
+func (t *tx) insertTransaction(transaction sqlc.Transaction) (sqlc.Transaction, error) {
+	if err := t.writable(); err != nil {
+		return sqlc.Transaction{}, err
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return sqlc.Transaction{}, err
+	}
+	if _, exists := l.account(transaction.AccountID); !exists {
+		return sqlc.Transaction{}, fmt.Errorf("insert on table \"transactions\" violates foreign key constraint: account %d does not exist", transaction.AccountID)
+	}
+	if transaction.TransferID.Valid {
+		if _, exists := l.transfer(transaction.TransferID.Int64); !exists {
+			return sqlc.Transaction{}, fmt.Errorf("insert on table \"transactions\" violates foreign key constraint: transfer %d does not exist", transaction.TransferID.Int64)
+		}
+	}
+
+	transaction.ID = t.store.nextID(&t.store.lastTransactionID)
+	transaction.Amount = transaction.Amount.Round(precision)
+	t.pending.transactions = append(t.pending.transactions, transaction)
+	return transaction, nil
+}
+
+func (t *tx) insertSnapshot(snapshot sqlc.AccountBalanceSnapshot) (sqlc.AccountBalanceSnapshot, error) {
+	if err := t.writable(); err != nil {
+		return sqlc.AccountBalanceSnapshot{}, err
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return sqlc.AccountBalanceSnapshot{}, err
+	}
+	if _, exists := l.account(snapshot.AccountID); !exists {
+		return sqlc.AccountBalanceSnapshot{}, fmt.Errorf("insert on table \"account_balance_snapshots\" violates foreign key constraint: account %d does not exist", snapshot.AccountID)
+	}
+	if _, exists := l.transaction(snapshot.LastTransactionID); !exists {
+		return sqlc.AccountBalanceSnapshot{}, fmt.Errorf("insert on table \"account_balance_snapshots\" violates foreign key constraint: transaction %d does not exist", snapshot.LastTransactionID)
+	}
+
+	snapshot.ID = t.store.nextID(&t.store.lastSnapshotID)
+	t.pending.snapshots = append(t.pending.snapshots, snapshot)
+	return snapshot, nil
+}
+
+func (t *tx) insertOutbox(event sqlc.Outbox) (int64, error) {
+	if err := t.writable(); err != nil {
+		return 0, err
+	}
+
+	event.ID = t.store.nextID(&t.store.lastOutboxID)
+	t.pending.outbox = append(t.pending.outbox, event)
+	return event.ID, nil
+}
+
+// nextID is nextval, sequences are not transactional
+func (s *Store) nextID(last *int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*last++
+	return *last
+}
+
+func (t *tx) timestamp() sql.NullTime {
+	return sql.NullTime{Time: t.now, Valid: true}
+}
@@ -0,0 +1,278 @@
+package memory_test
+
+import (
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"bank/store/memory"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func begin(t *testing.T, s store.Store, opts *sql.TxOptions) store.Tx {
+	t.Helper()
+	tx, err := s.BeginTx(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	return tx
+}
+
+// seedAccount commits an account with an opening credit and returns the id of the credit
+func seedAccount(t *testing.T, s store.Store, accountID int64, creditLimit, balance string) int64 {
+	t.Helper()
+	ctx := context.Background()
+	tx := begin(t, s, nil)
+	if _, err := tx.CreateAccount(ctx, sqlc.CreateAccountParams{ID: accountID, AccountType: "SAVINGS", CreditLimit: creditLimit}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	credit, err := tx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{AccountID: accountID, Amount: decimal.RequireFromString(balance)})
+	if err != nil {
+		t.Fatalf("failed to create credit: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return credit.ID
+}
+
+func balance(t *testing.T, queries store.Queries, accountID int64) string {
+	t.Helper()
+	balance, err := queries.GetAccountBalanceByAccountID(context.Background(), sqlc.GetAccountBalanceByAccountIDParams{FilterAccountID: accountID})
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	return balance
+}
+
+func TestBalanceStartsFromLatestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	creditID := seedAccount(t, s, 100, "0", "100")
+
+	// The snapshot disagrees with the ledger on purpose, only later transactions are added to it
+	if err := s.CreateBalanceSnapshot(100, decimal.NewFromInt(500), creditID); err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+
+	tx := begin(t, s, nil)
+	if _, err := tx.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{AccountID: 100, Amount: decimal.RequireFromString("20.5")}); err != nil {
+		t.Fatalf("failed to create debit: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	tx = begin(t, s, nil)
+	defer tx.Rollback()
+	if got := balance(t, tx, 100); got != "479.500000" {
+		t.Errorf("expected balance 479.500000, got %s", got)
+	}
+
+	// Before the debit the snapshot covers everything
+	at, err := tx.GetAccountBalanceAt(ctx, sqlc.GetAccountBalanceAtParams{AccountID: 100, Cutoff: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("failed to get balance at: %v", err)
+	}
+	if at != "0.000000" {
+		t.Errorf("expected balance 0.000000 an hour ago, got %s", at)
+	}
+}
+
+func TestTransactionIsolation(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAccount(t, s, 100, "0", "100")
+
+	repeatable := begin(t, s, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	defer repeatable.Rollback()
+	readCommitted := begin(t, s, nil)
+	defer readCommitted.Rollback()
+
+	writer := begin(t, s, nil)
+	if _, err := writer.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{AccountID: 100, Amount: decimal.NewFromInt(40)}); err != nil {
+		t.Fatalf("failed to create debit: %v", err)
+	}
+	if got := balance(t, writer, 100); got != "60.000000" {
+		t.Errorf("expected the writer to see its debit, got %s", got)
+	}
+	if got := balance(t, readCommitted, 100); got != "100.000000" {
+		t.Errorf("expected uncommitted debit to be invisible, got %s", got)
+	}
+
+	if err := writer.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if got := balance(t, readCommitted, 100); got != "60.000000" {
+		t.Errorf("expected read committed to see the debit, got %s", got)
+	}
+	if got := balance(t, repeatable, 100); got != "100.000000" {
+		t.Errorf("expected repeatable read to keep its snapshot, got %s", got)
+	}
+
+	rolledBack := begin(t, s, nil)
+	if _, err := rolledBack.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{AccountID: 100, Amount: decimal.NewFromInt(60)}); err != nil {
+		t.Fatalf("failed to create debit: %v", err)
+	}
+	if err := rolledBack.Rollback(); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	if got := balance(t, readCommitted, 100); got != "60.000000" {
+		t.Errorf("expected rolled back debit to be discarded, got %s", got)
+	}
+
+	if _, err := repeatable.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{AccountID: 100, Amount: decimal.NewFromInt(1)}); err == nil {
+		t.Error("expected a write in a read-only transaction to fail")
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAccount(t, s, 100, "0", "100")
+
+	tx := begin(t, s, nil)
+	defer tx.Rollback()
+	if _, err := tx.CreateAccount(ctx, sqlc.CreateAccountParams{ID: 100, AccountType: "SAVINGS", CreditLimit: "0"}); err == nil {
+		t.Error("expected a duplicate account to fail")
+	}
+	if _, err := tx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{AccountID: 999, Amount: decimal.NewFromInt(1)}); err == nil {
+		t.Error("expected a transaction of a missing account to fail")
+	}
+	if _, err := tx.CreateTransfer(ctx, sqlc.CreateTransferParams{FromAccountID: 100, ToAccountID: 999}); err == nil {
+		t.Error("expected a transfer to a missing account to fail")
+	}
+	if _, err := tx.LockAccount(ctx, 999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows locking a missing account, got %v", err)
+	}
+}
+
+func TestLockIsHeldUntilCommit(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAccount(t, s, 100, "0", "100")
+
+	first := begin(t, s, nil)
+	if _, err := first.LockAccount(ctx, 100); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		second := begin(t, s, nil)
+		defer second.Rollback()
+		_, err := second.LockAccount(ctx, 100)
+		locked <- err
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the second transaction to wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := first.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := <-locked; err != nil {
+		t.Errorf("expected the second transaction to get the lock, got %v", err)
+	}
+}
+
+func TestDeadlockIsDetected(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	seedAccount(t, s, 100, "0", "100")
+	seedAccount(t, s, 200, "0", "100")
+
+	first := begin(t, s, nil)
+	defer first.Rollback()
+	if _, err := first.LockAccount(ctx, 100); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+
+	second := begin(t, s, nil)
+	defer second.Rollback()
+	if _, err := second.LockAccount(ctx, 200); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+
+	waited := make(chan error)
+	go func() {
+		_, err := first.LockAccount(ctx, 200)
+		waited <- err
+	}()
+
+	// The second transaction can only lock account 100 once the first is waiting for it
+	time.Sleep(50 * time.Millisecond)
+	if _, err := second.LockAccount(ctx, 100); err == nil {
+		t.Fatal("expected a deadlock")
+	}
+	second.Rollback()
+
+	if err := <-waited; err != nil {
+		t.Errorf("expected the first transaction to get the lock, got %v", err)
+	}
+}
+
+func TestTransferFunds(t *testing.T) {
+	testCases := []struct {
+		name         string
+		from, to     int64
+		amount       string
+		expectedCode int32
+		expectedFrom string
+	}{
+		{name: "success", from: 100, to: 200, amount: "60", expectedCode: 0, expectedFrom: "40.000000"},
+		{name: "amount rounds to zero", from: 100, to: 200, amount: "0.0000001", expectedCode: 1, expectedFrom: "100.000000"},
+		{name: "same account", from: 100, to: 100, amount: "1", expectedCode: 2, expectedFrom: "100.000000"},
+		{name: "source account not found", from: 999, to: 200, amount: "1", expectedCode: 3, expectedFrom: "100.000000"},
+		{name: "destination account not found", from: 100, to: 999, amount: "1", expectedCode: 4, expectedFrom: "100.000000"},
+		{name: "insufficient funds", from: 100, to: 200, amount: "100.000001", expectedCode: 5, expectedFrom: "100.000000"},
+		{name: "credit limit", from: 300, to: 200, amount: "150", expectedCode: 0},
+		{name: "beyond credit limit", from: 300, to: 200, amount: "150.000001", expectedCode: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := memory.New()
+			seedAccount(t, s, 100, "0", "100")
+			seedAccount(t, s, 200, "0", "100")
+			seedAccount(t, s, 300, "50", "100")
+
+			tx := begin(t, s, nil)
+			row, err := tx.CreateTransferTransaction(ctx, sqlc.CreateTransferTransactionParams{
+				ParamFromAccountID: tc.from,
+				ParamToAccountID:   tc.to,
+				ParamAmount:        tc.amount,
+			})
+			if err != nil {
+				t.Fatalf("failed to transfer: %v", err)
+			}
+			if err := tx.Commit(); err != nil {
+				t.Fatalf("failed to commit: %v", err)
+			}
+
+			if row.ErrorCode.Int32 != tc.expectedCode {
+				t.Errorf("expected code %d, got %d (%s)", tc.expectedCode, row.ErrorCode.Int32, row.ErrorMessage.String)
+			}
+			if row.TransferID.Valid != (tc.expectedCode == 0) {
+				t.Errorf("expected a transfer id only on success, got %+v", row.TransferID)
+			}
+
+			if tc.expectedFrom == "" {
+				return
+			}
+			tx = begin(t, s, nil)
+			defer tx.Rollback()
+			if got := balance(t, tx, 100); got != tc.expectedFrom {
+				t.Errorf("expected balance %s, got %s", tc.expectedFrom, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package memory
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error codes of transfer_funds
+const (
+	transferSucceeded                  = 0
+	transferAmountNotPositive          = 1
+	transferSameAccount                = 2
+	transferSourceAccountNotFound      = 3
+	transferDestinationAccountNotFound = 4
+	transferInsufficientFunds          = 5
+	transferUnexpectedError            = 99
+)
+
+func (t *tx) CreateAccount(ctx context.Context, arg sqlc.CreateAccountParams) (sqlc.Account, error) {
+	creditLimit, err := decimal.NewFromString(arg.CreditLimit)
+	if err != nil {
+		return sqlc.Account{}, fmt.Errorf("invalid credit limit %q: %w", arg.CreditLimit, err)
+	}
+
+	account := sqlc.Account{
+		ID:          arg.ID,
+		CreatedAt:   t.timestamp(),
+		UpdatedAt:   t.timestamp(),
+		AccountType: arg.AccountType,
+		CreditLimit: creditLimit.StringFixed(precision),
+	}
+	if err := t.insertAccount(account); err != nil {
+		return sqlc.Account{}, err
+	}
+	return account, nil
+}
+
+func (t *tx) EnsureAccount(ctx context.Context, id int64) error {
+	l, err := t.read()
+	if err != nil {
+		return err
+	}
+	if account, exists := l.account(id); exists {
+		if account.AccountType != string(entity.AccountTypeSystem) {
+			return fmt.Errorf("account %d exists and is not a system account", id)
+		}
+		return nil
+	}
+
+	return t.insertAccount(sqlc.Account{
+		ID:          id,
+		CreatedAt:   t.timestamp(),
+		UpdatedAt:   t.timestamp(),
+		AccountType: string(entity.AccountTypeSystem),
+		CreditLimit: decimal.Zero.StringFixed(precision),
+	})
+}
+
+func (t *tx) CheckAccountExists(ctx context.Context, id int64) (bool, error) {
+	l, err := t.read()
+	if err != nil {
+		return false, err
+	}
+	_, exists := l.account(id)
+	return exists, nil
+}
+
+func (t *tx) GetAccountByID(ctx context.Context, id int64) (sqlc.Account, error) {
+	l, err := t.read()
+	if err != nil {
+		return sqlc.Account{}, err
+	}
+	account, exists := l.account(id)
+	if !exists {
+		return sqlc.Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (t *tx) ListExistingAccountIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	l, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := []int64{}
+	for _, account := range l.accounts {
+		if slices.Contains(ids, account.ID) {
+			existing = append(existing, account.ID)
+		}
+	}
+	slices.Sort(existing)
+	return existing, nil
+}
+
+func (t *tx) LockAccount(ctx context.Context, id int64) (int64, error) {
+	exists, err := t.CheckAccountExists(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, sql.ErrNoRows
+	}
+
+	if err := t.lock(id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (t *tx) GetAccountBalanceByAccountID(ctx context.Context, arg sqlc.GetAccountBalanceByAccountIDParams) (string, error) {
+	if arg.FilterLockForUpdate {
+		if _, err := t.LockAccount(ctx, arg.FilterAccountID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return "", err
+	}
+	return l.balance(arg.FilterAccountID, time.Time{}).StringFixed(precision), nil
+}
+
+func (t *tx) GetAccountBalanceAt(ctx context.Context, arg sqlc.GetAccountBalanceAtParams) (string, error) {
+	l, err := t.read()
+	if err != nil {
+		return "", err
+	}
+	return l.balance(arg.AccountID, arg.Cutoff).StringFixed(precision), nil
+}
+
+func (t *tx) GetLastAccountTransactionID(ctx context.Context, accountID int64) (int64, error) {
+	l, err := t.read()
+	if err != nil {
+		return 0, err
+	}
+
+	lastID := int64(0)
+	for _, transaction := range l.transactions {
+		if transaction.AccountID == accountID {
+			lastID = max(lastID, transaction.ID)
+		}
+	}
+	return lastID, nil
+}
+
+func (t *tx) ListAccountTransactionsBetween(ctx context.Context, arg sqlc.ListAccountTransactionsBetweenParams) ([]sqlc.ListAccountTransactionsBetweenRow, error) {
+	l, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []sqlc.ListAccountTransactionsBetweenRow{}
+	for _, transaction := range l.transactions {
+		if len(rows) == int(arg.PageSize) {
+			break
+		}
+		createdAt := transaction.CreatedAt.Time
+		if transaction.AccountID != arg.AccountID || transaction.ID <= arg.AfterID || createdAt.Before(arg.FromTime) || !createdAt.Before(arg.ToTime) {
+			continue
+		}
+		rows = append(rows, sqlc.ListAccountTransactionsBetweenRow{
+			ID:                    transaction.ID,
+			AccountID:             transaction.AccountID,
+			TransferID:            transaction.TransferID,
+			Amount:                transaction.Amount,
+			TrxType:               transaction.TrxType,
+			CreatedAt:             transaction.CreatedAt,
+			CounterpartyAccountID: l.counterparty(transaction),
+		})
+	}
+	return rows, nil
+}
+
+func (t *tx) ListAccountTransactionsAfterID(ctx context.Context, arg sqlc.ListAccountTransactionsAfterIDParams) ([]sqlc.ListAccountTransactionsAfterIDRow, error) {
+	l, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []sqlc.ListAccountTransactionsAfterIDRow{}
+	for _, transaction := range l.transactions {
+		if len(rows) == int(arg.PageSize) {
+			break
+		}
+		if transaction.AccountID != arg.AccountID || transaction.ID <= arg.AfterID {
+			continue
+		}
+		rows = append(rows, sqlc.ListAccountTransactionsAfterIDRow{
+			ID:                    transaction.ID,
+			AccountID:             transaction.AccountID,
+			TransferID:            transaction.TransferID,
+			Amount:                transaction.Amount,
+			TrxType:               transaction.TrxType,
+			CreatedAt:             transaction.CreatedAt,
+			CounterpartyAccountID: l.counterparty(transaction),
+		})
+	}
+	return rows, nil
+}
+
+func (t *tx) SumAccountTransactionsAfterID(ctx context.Context, arg sqlc.SumAccountTransactionsAfterIDParams) (string, error) {
+	l, err := t.read()
+	if err != nil {
+		return "", err
+	}
+
+	total := decimal.Zero
+	for _, transaction := range l.transactions {
+		if transaction.AccountID != arg.AccountID || transaction.ID <= arg.AfterID {
+			continue
+		}
+		switch entity.TrxType(transaction.TrxType) {
+		case entity.TrxTypeCredit:
+			total = total.Add(transaction.Amount)
+		case entity.TrxTypeDebit:
+			total = total.Sub(transaction.Amount)
+		}
+	}
+	return total.StringFixed(precision), nil
+}
+
+func (t *tx) ListAccountIDsToSnapshot(ctx context.Context) ([]int64, error) {
+	l, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int64{}
+	for _, account := range l.accounts {
+		lastTransactionID := int64(0)
+		if latest := l.latestSnapshot(account.ID, time.Time{}); latest != nil {
+			lastTransactionID = latest.LastTransactionID
+		}
+		for _, transaction := range l.transactions {
+			if transaction.AccountID == account.ID && transaction.ID > lastTransactionID {
+				ids = append(ids, account.ID)
+				break
+			}
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// CreateBalanceSnapshot is created at the time of the statement, like clock_timestamp()
+func (t *tx) CreateBalanceSnapshot(ctx context.Context, arg sqlc.CreateBalanceSnapshotParams) (sqlc.AccountBalanceSnapshot, error) {
+	balance, err := decimal.NewFromString(arg.Balance)
+	if err != nil {
+		return sqlc.AccountBalanceSnapshot{}, fmt.Errorf("invalid balance %q: %w", arg.Balance, err)
+	}
+
+	return t.insertSnapshot(sqlc.AccountBalanceSnapshot{
+		AccountID:         arg.AccountID,
+		Balance:           balance.StringFixed(precision),
+		LastTransactionID: arg.LastTransactionID,
+		CreatedAt:         sql.NullTime{Time: time.Now(), Valid: true},
+	})
+}
+
+func (t *tx) NextTransferIDs(ctx context.Context, count int32) ([]int64, error) {
+	if t.done {
+		return nil, sql.ErrTxDone
+	}
+
+	ids := make([]int64, 0, count)
+	for range count {
+		ids = append(ids, t.store.nextID(&t.store.lastTransferID))
+	}
+	return ids, nil
+}
+
+func (t *tx) CreateTransfer(ctx context.Context, arg sqlc.CreateTransferParams) (sqlc.Transfer, error) {
+	return t.insertTransfer(sqlc.Transfer{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		CreatedAt:     t.timestamp(),
+	})
+}
+
+func (t *tx) CreateDebitTransaction(ctx context.Context, arg sqlc.CreateDebitTransactionParams) (sqlc.Transaction, error) {
+	return t.insertTransaction(sqlc.Transaction{
+		AccountID:  arg.AccountID,
+		TransferID: arg.TransferID,
+		Amount:     arg.Amount,
+		TrxType:    string(entity.TrxTypeDebit),
+		CreatedAt:  t.timestamp(),
+	})
+}
+
+func (t *tx) CreateCreditTransaction(ctx context.Context, arg sqlc.CreateCreditTransactionParams) (sqlc.Transaction, error) {
+	return t.insertTransaction(sqlc.Transaction{
+		AccountID:  arg.AccountID,
+		TransferID: arg.TransferID,
+		Amount:     arg.Amount,
+		TrxType:    string(entity.TrxTypeCredit),
+		CreatedAt:  t.timestamp(),
+	})
+}
+
+// CreateTransferTransaction is the transfer_funds stored procedure. Like its exception handler,
+// any error ends it with the unexpected error code and nothing written.
+func (t *tx) CreateTransferTransaction(ctx context.Context, arg sqlc.CreateTransferTransactionParams) (sqlc.CreateTransferTransactionRow, error) {
+	savepoint := t.pending.clone()
+	row, err := t.transferFunds(ctx, arg)
+	if err != nil {
+		t.pending = savepoint
+		return transferResult(transferUnexpectedError, err.Error()), nil
+	}
+	return row, nil
+}
+
+func (t *tx) transferFunds(ctx context.Context, arg sqlc.CreateTransferTransactionParams) (sqlc.CreateTransferTransactionRow, error) {
+	amount, err := decimal.NewFromString(arg.ParamAmount)
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("invalid amount %q: %w", arg.ParamAmount, err)
+	}
+
+	// The amount is a DECIMAL(20,6) parameter
+	amount = amount.Round(precision)
+	if !amount.IsPositive() {
+		return transferResult(transferAmountNotPositive, "Transfer amount must be positive"), nil
+	}
+
+	if arg.ParamFromAccountID == arg.ParamToAccountID {
+		return transferResult(transferSameAccount, "Cannot transfer to the same account"), nil
+	}
+
+	// Lock accounts in consistent order to prevent deadlocks. Like the FOUND of the procedure, an
+	// account exists for the transfer only if it was locked.
+	locked := make(map[int64]bool, 2)
+	for _, accountID := range []int64{min(arg.ParamFromAccountID, arg.ParamToAccountID), max(arg.ParamFromAccountID, arg.ParamToAccountID)} {
+		_, err := t.LockAccount(ctx, accountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return sqlc.CreateTransferTransactionRow{}, err
+		}
+		locked[accountID] = true
+	}
+
+	if !locked[arg.ParamFromAccountID] {
+		return transferResult(transferSourceAccountNotFound, "From account does not exist"), nil
+	}
+
+	if !locked[arg.ParamToAccountID] {
+		return transferResult(transferDestinationAccountNotFound, "To account does not exist"), nil
+	}
+
+	l, err := t.read()
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, err
+	}
+	source, _ := l.account(arg.ParamFromAccountID)
+
+	// CREDIT accounts may spend up to their credit limit
+	creditLimit := decimal.RequireFromString(source.CreditLimit)
+	if l.balance(source.ID, time.Time{}).Add(creditLimit).LessThan(amount) {
+		return transferResult(transferInsufficientFunds, "Insufficient funds"), nil
+	}
+
+	transfer, err := t.CreateTransfer(ctx, sqlc.CreateTransferParams{
+		FromAccountID: arg.ParamFromAccountID,
+		ToAccountID:   arg.ParamToAccountID,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, err
+	}
+
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+	_, err = t.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{
+		AccountID:  arg.ParamFromAccountID,
+		TransferID: transferID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, err
+	}
+
+	_, err = t.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{
+		AccountID:  arg.ParamToAccountID,
+		TransferID: transferID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, err
+	}
+
+	return sqlc.CreateTransferTransactionRow{
+		TransferID: transferID,
+		ErrorCode:  sql.NullInt32{Int32: transferSucceeded, Valid: true},
+	}, nil
+}
+
+func transferResult(code int32, message string) sqlc.CreateTransferTransactionRow {
+	return sqlc.CreateTransferTransactionRow{
+		ErrorCode:    sql.NullInt32{Int32: code, Valid: true},
+		ErrorMessage: sql.NullString{String: message, Valid: true},
+	}
+}
+
+func (t *tx) CreateOutboxEvent(ctx context.Context, arg sqlc.CreateOutboxEventParams) (int64, error) {
+	return t.insertOutbox(sqlc.Outbox{
+		EventType:  arg.EventType,
+		AccountIds: slices.Clone(arg.AccountIds),
+		Payload:    slices.Clone(arg.Payload),
+		CreatedAt:  t.timestamp(),
+	})
+}
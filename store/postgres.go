@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bank/internal/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Postgres is the ledger in the database
+type Postgres struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewPostgres(db *sql.DB) (*Postgres, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	return &Postgres{db: db, queries: sqlc.New(db)}, nil
+}
+
+func (s *Postgres) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresTx{Queries: s.queries.WithTx(tx), tx: tx}, nil
+}
+
+type postgresTx struct {
+	*sqlc.Queries
+	tx *sql.Tx
+}
+
+// CopyFrom runs one COPY FROM STDIN into table
+func (t *postgresTx) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) error {
+	stmt, err := t.tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		values := make([]any, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case []int64:
+				values[i] = pq.Array(v)
+			default:
+				values[i] = v
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("failed to copy row into %s: %w", table, err)
+		}
+	}
+
+	// An empty exec flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	return stmt.Close()
+}
+
+func (t *postgresTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *postgresTx) Rollback() error {
+	return t.tx.Rollback()
+}
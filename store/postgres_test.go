@@ -0,0 +1,89 @@
+package store_test
+
+import (
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"bank/test"
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPostgres_LedgerIsAppendOnly(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		ledger, err := store.NewPostgres(testDB.DB)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		tx, err := ledger.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		for _, id := range []int64{100, 200} {
+			if _, err := tx.CreateAccount(ctx, sqlc.CreateAccountParams{ID: id, AccountType: "SAVINGS", CreditLimit: "0"}); err != nil {
+				t.Fatalf("failed to create account: %v", err)
+			}
+		}
+		if _, err := tx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{AccountID: 100, Amount: decimal.NewFromInt(100)}); err != nil {
+			t.Fatalf("failed to create credit: %v", err)
+		}
+		if _, err := tx.CreateTransferTransaction(ctx, sqlc.CreateTransferTransactionParams{ParamFromAccountID: 100, ParamToAccountID: 200, ParamAmount: "40"}); err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+
+		for _, statement := range []string{
+			"UPDATE transactions SET amount = 1000 WHERE account_id = 200",
+			"DELETE FROM transactions WHERE account_id = 100",
+			"UPDATE transfers SET to_account_id = 100",
+			"DELETE FROM transfers",
+			"DELETE FROM account_balance_snapshots",
+		} {
+			if _, err := testDB.DB.ExecContext(ctx, statement); err == nil {
+				t.Errorf("expected %q to be rejected", statement)
+			}
+		}
+
+		tx, err = ledger.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		defer tx.Rollback()
+		balance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{FilterAccountID: 200})
+		if err != nil {
+			t.Fatalf("failed to get balance: %v", err)
+		}
+		if !decimal.RequireFromString(balance).Equal(decimal.NewFromInt(40)) {
+			t.Errorf("expected the balance to be untouched, got %s", balance)
+		}
+
+		// The app role only inserts into the ledger tables
+		privileges := []struct {
+			table     string
+			privilege string
+			expected  bool
+		}{
+			{"transactions", "INSERT", true},
+			{"transactions", "UPDATE", false},
+			{"transactions", "DELETE", false},
+			{"transactions", "TRUNCATE", false},
+			{"transfers", "UPDATE", false},
+			{"audit_records", "DELETE", false},
+			{"outbox", "UPDATE", true},
+		}
+		for _, p := range privileges {
+			var granted bool
+			if err := testDB.DB.QueryRowContext(ctx, "SELECT has_table_privilege('bank_app', $1, $2)", p.table, p.privilege).Scan(&granted); err != nil {
+				t.Fatalf("failed to check privilege: %v", err)
+			}
+			if granted != p.expected {
+				t.Errorf("expected %s on %s granted=%v, got %v", p.privilege, p.table, p.expected, granted)
+			}
+		}
+	})
+}
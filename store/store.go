@@ -0,0 +1,60 @@
+// Package store is where the account and transaction domains keep the ledger. Postgres is the
+// store of the applications, store/memory is an in-memory ledger with the same semantics for
+// tests that run without a database.
+package store
+
+import (
+	"bank/internal/db/sqlc"
+	"context"
+	"database/sql"
+)
+
+// Queries are the ledger queries of the account and transaction domains. They have the
+// signatures of sqlc, so *sqlc.Queries runs them against Postgres.
+type Queries interface {
+	CreateAccount(ctx context.Context, arg sqlc.CreateAccountParams) (sqlc.Account, error)
+	// EnsureAccount creates the system account unless it exists, failing when the id is taken by
+	// an account that isn't a system account
+	EnsureAccount(ctx context.Context, id int64) error
+	CheckAccountExists(ctx context.Context, id int64) (bool, error)
+	GetAccountByID(ctx context.Context, id int64) (sqlc.Account, error)
+	ListExistingAccountIDs(ctx context.Context, ids []int64) ([]int64, error)
+	// LockAccount locks the account until the transaction ends, sql.ErrNoRows if it doesn't exist
+	LockAccount(ctx context.Context, id int64) (int64, error)
+
+	// GetAccountBalanceByAccountID is get_account_balance, locking the account when asked to
+	GetAccountBalanceByAccountID(ctx context.Context, arg sqlc.GetAccountBalanceByAccountIDParams) (string, error)
+	GetAccountBalanceAt(ctx context.Context, arg sqlc.GetAccountBalanceAtParams) (string, error)
+	GetLastAccountTransactionID(ctx context.Context, accountID int64) (int64, error)
+	ListAccountTransactionsBetween(ctx context.Context, arg sqlc.ListAccountTransactionsBetweenParams) ([]sqlc.ListAccountTransactionsBetweenRow, error)
+	ListAccountTransactionsAfterID(ctx context.Context, arg sqlc.ListAccountTransactionsAfterIDParams) ([]sqlc.ListAccountTransactionsAfterIDRow, error)
+	SumAccountTransactionsAfterID(ctx context.Context, arg sqlc.SumAccountTransactionsAfterIDParams) (string, error)
+
+	// ListAccountIDsToSnapshot lists the accounts with transactions after their latest snapshot
+	ListAccountIDsToSnapshot(ctx context.Context) ([]int64, error)
+	CreateBalanceSnapshot(ctx context.Context, arg sqlc.CreateBalanceSnapshotParams) (sqlc.AccountBalanceSnapshot, error)
+
+	NextTransferIDs(ctx context.Context, count int32) ([]int64, error)
+	CreateTransfer(ctx context.Context, arg sqlc.CreateTransferParams) (sqlc.Transfer, error)
+	CreateDebitTransaction(ctx context.Context, arg sqlc.CreateDebitTransactionParams) (sqlc.Transaction, error)
+	CreateCreditTransaction(ctx context.Context, arg sqlc.CreateCreditTransactionParams) (sqlc.Transaction, error)
+	// CreateTransferTransaction is the transfer_funds stored procedure
+	CreateTransferTransaction(ctx context.Context, arg sqlc.CreateTransferTransactionParams) (sqlc.CreateTransferTransactionRow, error)
+
+	CreateOutboxEvent(ctx context.Context, arg sqlc.CreateOutboxEventParams) (int64, error)
+}
+
+// Tx is a transaction on the ledger. Its queries see its own writes and the committed writes of
+// others, or only those committed before it began when it is repeatable read.
+type Tx interface {
+	Queries
+	// CopyFrom bulk loads rows into table, each row holds the values of columns in order
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) error
+	Commit() error
+	Rollback() error
+}
+
+// Store begins transactions on the ledger
+type Store interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
@@ -0,0 +1,223 @@
+package invariant
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// model is the ledger as the operations that succeeded left it
+type model struct {
+	accounts map[uint64]*modelAccount
+	// opening is the sum of the initial balances, the money in the ledger
+	opening decimal.Decimal
+}
+
+type modelAccount struct {
+	accountType entity.AccountType
+	creditLimit decimal.Decimal
+	opening     decimal.Decimal
+	balance     decimal.Decimal
+}
+
+func newModel() *model {
+	return &model{accounts: map[uint64]*modelAccount{}}
+}
+
+// expect is the outcome the op has on the model, in the order of the checks of transfer_funds
+func (m *model) expect(op Op) string {
+	if op.Kind != OpTransfer && op.Kind != OpDeposit {
+		return ""
+	}
+
+	if !op.Amount.IsPositive() || op.AccountID == op.ToAccountID {
+		return errorClass(entity.ErrValidation)
+	}
+	source, exists := m.accounts[op.AccountID]
+	if _, destinationExists := m.accounts[op.ToAccountID]; !exists || !destinationExists {
+		return errorClass(entity.ErrDataNotFound)
+	}
+	if source.balance.Add(source.creditLimit).LessThan(op.Amount) {
+		return errorClass(entity.ErrInsufficientFunds)
+	}
+	return ""
+}
+
+// apply records an op that succeeded
+func (m *model) apply(op Op) {
+	switch op.Kind {
+	case OpCreateAccount:
+		m.accounts[op.AccountID] = &modelAccount{accountType: op.AccountType, creditLimit: op.CreditLimit, opening: op.Amount, balance: op.Amount}
+		m.opening = m.opening.Add(op.Amount)
+	case OpTransfer, OpDeposit:
+		m.accounts[op.AccountID].balance = m.accounts[op.AccountID].balance.Sub(op.Amount)
+		m.accounts[op.ToAccountID].balance = m.accounts[op.ToAccountID].balance.Add(op.Amount)
+	}
+}
+
+// Check returns every violation of an invariant by the outcome of the scenario and the ledger
+// it left in s
+func Check(ctx context.Context, s store.Store, scenario Scenario, outcome Outcome) ([]string, error) {
+	violations := []string{}
+	m := newModel()
+	transferIDs := map[uint64]Op{}
+
+	results := slices.Concat(append([][]Result{outcome.Setup}, outcome.Streams...)...)
+	sequential := len(outcome.Streams) == 1
+	if !sequential {
+		// Transfers of a worker may use an account another worker created, they only add up
+		// once all accounts are in the model
+		slices.SortStableFunc(results, func(a, b Result) int {
+			return cmp.Compare(order(a.Op), order(b.Op))
+		})
+	}
+	for _, result := range results {
+		got := errorClass(result.Err)
+		if got == "unexpected" || (got != "" && (result.Op.Kind == OpCreateAccount || result.Op.Kind == OpSnapshot)) {
+			violations = append(violations, fmt.Sprintf("%s failed: %v", result.Op, result.Err))
+		}
+
+		// Alone, the worker meets the ledger exactly as the model has it
+		if expected := m.expect(result.Op); sequential && got != expected {
+			violations = append(violations, fmt.Sprintf("%s: expected %q, got %q (%v)", result.Op, expected, got, result.Err))
+		}
+
+		if result.Err == nil {
+			m.apply(result.Op)
+			if result.Op.Kind == OpTransfer || result.Op.Kind == OpDeposit {
+				transferIDs[result.TransferID] = result.Op
+			}
+		}
+	}
+
+	tx, err := s.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids := []int64{CashAccountID}
+	for id := range scenario.Accounts + 1 {
+		ids = append(ids, int64(id+1))
+	}
+	existing, err := tx.ListExistingAccountIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	if len(existing) != len(m.accounts) {
+		violations = append(violations, fmt.Sprintf("expected %d accounts, got %d", len(m.accounts), len(existing)))
+	}
+
+	total := decimal.Zero
+	// postings holds the transactions of each transfer
+	postings := map[uint64][]sqlc.ListAccountTransactionsAfterIDRow{}
+	for _, accountID := range existing {
+		expected, ok := m.accounts[uint64(accountID)]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("account %d exists but was never created", accountID))
+			continue
+		}
+
+		rows, err := tx.ListAccountTransactionsAfterID(ctx, sqlc.ListAccountTransactionsAfterIDParams{
+			AccountID: accountID,
+			PageSize:  math.MaxInt32,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions of account %d: %w", accountID, err)
+		}
+
+		sum, openings := decimal.Zero, 0
+		for _, row := range rows {
+			switch entity.TrxType(row.TrxType) {
+			case entity.TrxTypeCredit:
+				sum = sum.Add(row.Amount)
+			case entity.TrxTypeDebit:
+				sum = sum.Sub(row.Amount)
+			}
+
+			if row.TransferID.Valid {
+				postings[uint64(row.TransferID.Int64)] = append(postings[uint64(row.TransferID.Int64)], row)
+				continue
+			}
+			openings++
+			if entity.TrxType(row.TrxType) != entity.TrxTypeCredit || !row.Amount.Equal(expected.opening) {
+				violations = append(violations, fmt.Sprintf("account %d: unexpected transaction %d outside a transfer: %s %s", accountID, row.ID, row.TrxType, row.Amount))
+			}
+		}
+		if openings != 1 {
+			violations = append(violations, fmt.Sprintf("account %d: expected one opening credit, got %d", accountID, openings))
+		}
+		total = total.Add(sum)
+
+		if !sum.Equal(expected.balance) {
+			violations = append(violations, fmt.Sprintf("account %d: the transactions add up to %s, the operations that succeeded to %s", accountID, sum, expected.balance))
+		}
+
+		balance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{FilterAccountID: accountID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance of account %d: %w", accountID, err)
+		}
+		if !decimal.RequireFromString(balance).Equal(sum) {
+			violations = append(violations, fmt.Sprintf("account %d: the latest snapshot and the transactions after it add up to %s, all transactions to %s", accountID, balance, sum))
+		}
+
+		if sum.Add(expected.creditLimit).IsNegative() {
+			violations = append(violations, fmt.Sprintf("%s account %d: balance %s is below its credit limit %s", expected.accountType, accountID, sum, expected.creditLimit))
+		}
+	}
+
+	if !total.Equal(m.opening) {
+		violations = append(violations, fmt.Sprintf("the balances add up to %s, the opening balances to %s", total, m.opening))
+	}
+
+	for transferID, rows := range postings {
+		op, ok := transferIDs[transferID]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("transfer %d was posted but never reported", transferID))
+			continue
+		}
+		if !balanced(op, rows) {
+			violations = append(violations, fmt.Sprintf("transfer %d of %s is not one debit and one credit of the amount: %+v", transferID, op, rows))
+		}
+	}
+	for transferID, op := range transferIDs {
+		if _, ok := postings[transferID]; !ok {
+			violations = append(violations, fmt.Sprintf("transfer %d of %s was reported but never posted", transferID, op))
+		}
+	}
+
+	return violations, nil
+}
+
+// order puts account creations before the other operations
+func order(op Op) int {
+	if op.Kind == OpCreateAccount {
+		return 0
+	}
+	return 1
+}
+
+// balanced reports whether the transactions of the transfer are a debit of its source and a
+// credit of its destination, both of the amount
+func balanced(op Op, rows []sqlc.ListAccountTransactionsAfterIDRow) bool {
+	if len(rows) != 2 {
+		return false
+	}
+	debit, credit := rows[0], rows[1]
+	if entity.TrxType(debit.TrxType) == entity.TrxTypeCredit {
+		debit, credit = credit, debit
+	}
+	return entity.TrxType(debit.TrxType) == entity.TrxTypeDebit &&
+		entity.TrxType(credit.TrxType) == entity.TrxTypeCredit &&
+		debit.AccountID == int64(op.AccountID) && credit.AccountID == int64(op.ToAccountID) &&
+		debit.CounterpartyAccountID == credit.AccountID && credit.CounterpartyAccountID == debit.AccountID &&
+		debit.Amount.Equal(op.Amount) && credit.Amount.Equal(op.Amount)
+}
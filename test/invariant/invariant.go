@@ -0,0 +1,239 @@
+// Package invariant generates random sequences of ledger operations from a seed, runs them
+// concurrently against the account and transaction domains and checks the invariants of the
+// ledger afterwards:
+//   - money is conserved: the balances add up to the opening balances of the accounts
+//   - SAVINGS balances never go negative, CREDIT balances never go below the credit limit
+//   - the balance from the latest snapshot plus the later transactions is the sum of all of them
+//   - every transfer is one debit and one credit of the same amount
+//
+// Every run is also checked against a model of the ledger: the balances are those of the
+// operations that succeeded, and a run with a single worker has exactly the outcomes the model
+// predicts, operation by operation.
+package invariant
+
+import (
+	"bank/account"
+	"bank/entity"
+	"bank/transaction"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// CashAccountID funds the deposits, the money of the bank itself
+const CashAccountID = 999999
+
+// cashBalance is never spent in a run, so deposits are never refused
+var cashBalance = decimal.NewFromInt(1_000_000_000)
+
+type OpKind string
+
+const (
+	OpCreateAccount OpKind = "create_account"
+	OpTransfer      OpKind = "transfer"
+	// OpDeposit is a transfer from the cash account
+	OpDeposit  OpKind = "deposit"
+	OpSnapshot OpKind = "snapshot"
+)
+
+type Op struct {
+	Kind OpKind
+	// AccountID is the account created, or the source of a transfer
+	AccountID   uint64
+	ToAccountID uint64
+	AccountType entity.AccountType
+	CreditLimit decimal.Decimal
+	// Amount is the initial balance of a created account
+	Amount decimal.Decimal
+}
+
+func (o Op) String() string {
+	switch o.Kind {
+	case OpCreateAccount:
+		return fmt.Sprintf("create %s account %d with %s (limit %s)", o.AccountType, o.AccountID, o.Amount, o.CreditLimit)
+	case OpTransfer, OpDeposit:
+		return fmt.Sprintf("%s %s from %d to %d", o.Kind, o.Amount, o.AccountID, o.ToAccountID)
+	default:
+		return string(o.Kind)
+	}
+}
+
+type Config struct {
+	Seed int64
+	// Accounts is the number of customer accounts, half of them are created while the workers run
+	Accounts int
+	// Ops is the number of operations of each worker
+	Ops     int
+	Workers int
+}
+
+// Scenario is the sequence of operations of a seed
+type Scenario struct {
+	Config
+	// Setup runs before the workers start
+	Setup []Op
+	// Streams holds the operations of each worker, run in order
+	Streams [][]Op
+}
+
+// hotAccounts are picked for half of the transfers, so workers contend for their locks
+const hotAccounts = 3
+
+// Generate builds the scenario of cfg.Seed, the same seed always gives the same operations
+func Generate(cfg Config) Scenario {
+	rng := rand.New(rand.NewPCG(uint64(cfg.Seed), 0))
+	scenario := Scenario{
+		Config:  cfg,
+		Setup:   []Op{{Kind: OpCreateAccount, AccountID: CashAccountID, AccountType: entity.AccountTypeSavings, Amount: cashBalance}},
+		Streams: make([][]Op, cfg.Workers),
+	}
+
+	amount := func(upTo int64) decimal.Decimal {
+		return decimal.New(rng.Int64N(upTo*1_000_000)+1, -6)
+	}
+	pick := func() uint64 {
+		if rng.IntN(2) == 0 {
+			return uint64(rng.IntN(min(hotAccounts, cfg.Accounts)) + 1)
+		}
+		return uint64(rng.IntN(cfg.Accounts) + 1)
+	}
+
+	for i := range cfg.Workers {
+		stream := make([]Op, 0, cfg.Ops)
+		for range cfg.Ops {
+			var op Op
+			switch n := rng.IntN(100); {
+			case n < 60:
+				op = Op{Kind: OpTransfer, AccountID: pick(), ToAccountID: pick(), Amount: amount(80)}
+			case n < 75:
+				op = Op{Kind: OpDeposit, AccountID: CashAccountID, ToAccountID: pick(), Amount: amount(20)}
+			case n < 85:
+				op = Op{Kind: OpSnapshot}
+			case n < 90:
+				// Refused: an account that is never created
+				op = Op{Kind: OpTransfer, AccountID: pick(), ToAccountID: uint64(cfg.Accounts + 1), Amount: amount(1)}
+			case n < 95:
+				op = Op{Kind: OpTransfer, AccountID: pick(), ToAccountID: pick(), Amount: decimal.Zero}
+			default:
+				from := pick()
+				op = Op{Kind: OpTransfer, AccountID: from, ToAccountID: from, Amount: amount(1)}
+			}
+			stream = append(stream, op)
+		}
+		scenario.Streams[i] = stream
+	}
+
+	for id := range uint64(cfg.Accounts) {
+		op := Op{Kind: OpCreateAccount, AccountID: id + 1, AccountType: entity.AccountTypeSavings, Amount: amount(100)}
+		if rng.IntN(4) == 0 {
+			op.AccountType = entity.AccountTypeCredit
+			op.CreditLimit = amount(100)
+		}
+
+		// The other half is created at a random point of a worker, transfers before fail
+		if id%2 == 0 {
+			scenario.Setup = append(scenario.Setup, op)
+			continue
+		}
+		worker := rng.IntN(cfg.Workers)
+		at := rng.IntN(len(scenario.Streams[worker]) + 1)
+		scenario.Streams[worker] = append(scenario.Streams[worker][:at], append([]Op{op}, scenario.Streams[worker][at:]...)...)
+	}
+
+	return scenario
+}
+
+// Result is the outcome of an operation
+type Result struct {
+	Op  Op
+	Err error
+	// TransferID is the transfer of a successful transfer or deposit
+	TransferID uint64
+}
+
+// Outcome holds the results of the setup, then those of each worker in order
+type Outcome struct {
+	Setup   []Result
+	Streams [][]Result
+}
+
+// Domains are the domains the operations run against
+type Domains struct {
+	Account     *account.AccountDomain
+	Transaction *transaction.TransactionDomain
+}
+
+// Run runs the setup, then the streams of the workers concurrently. Only a failing setup is an
+// error, the results of the operations are checked by Check.
+func Run(ctx context.Context, domains Domains, scenario Scenario) (Outcome, error) {
+	outcome := Outcome{Streams: make([][]Result, len(scenario.Streams))}
+	for _, op := range scenario.Setup {
+		result := run(ctx, domains, op)
+		if result.Err != nil {
+			return Outcome{}, fmt.Errorf("failed to %s: %w", op, result.Err)
+		}
+		outcome.Setup = append(outcome.Setup, result)
+	}
+
+	var wg sync.WaitGroup
+	for i, stream := range scenario.Streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results := make([]Result, 0, len(stream))
+			for _, op := range stream {
+				results = append(results, run(ctx, domains, op))
+			}
+			outcome.Streams[i] = results
+		}()
+	}
+	wg.Wait()
+
+	return outcome, nil
+}
+
+func run(ctx context.Context, domains Domains, op Op) Result {
+	result := Result{Op: op}
+	switch op.Kind {
+	case OpCreateAccount:
+		result.Err = domains.Account.CreateAccount(ctx, &entity.CreateAccount{
+			AccountID:      op.AccountID,
+			AccountType:    op.AccountType,
+			InitialBalance: op.Amount,
+			CreditLimit:    op.CreditLimit,
+		})
+	case OpTransfer, OpDeposit:
+		transfer, err := domains.Transaction.CreateTransferFunds(ctx, entity.CreateTransferFundsParams{
+			SourceAccountID:      op.AccountID,
+			DestinationAccountID: op.ToAccountID,
+			Amount:               op.Amount,
+		})
+		result.Err, result.TransferID = err, transfer.TransferID
+	case OpSnapshot:
+		_, result.Err = domains.Account.SnapshotBalances(ctx)
+	default:
+		result.Err = fmt.Errorf("unknown operation %q", op.Kind)
+	}
+	return result
+}
+
+// errorClass names the refusal of err, "" when there is none and "unexpected" for any error that
+// isn't a refusal
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		return "insufficient funds"
+	case errors.Is(err, entity.ErrDataNotFound):
+		return "not found"
+	case errors.Is(err, entity.ErrValidation):
+		return "validation"
+	default:
+		return "unexpected"
+	}
+}
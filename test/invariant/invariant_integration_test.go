@@ -0,0 +1,21 @@
+//go:build integration
+
+package invariant_test
+
+import (
+	"bank/store"
+	"bank/test"
+	"testing"
+)
+
+func TestLedgerInvariants_Postgres(t *testing.T) {
+	runInvariants(t, *workers, *runs, func(t *testing.T, fn func(ledger store.Store)) {
+		test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+			ledger, err := store.NewPostgres(testDB.DB)
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			fn(ledger)
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package invariant_test
+
+import (
+	"bank/account"
+	"bank/config"
+	"bank/internal/logger"
+	"bank/store"
+	"bank/store/memory"
+	"bank/test/invariant"
+	"bank/transaction"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	seed     = flag.Int64("invariant.seed", 0, "seed of the first run, the next runs take the following seeds. Random when 0")
+	runs     = flag.Int("invariant.runs", 20, "number of runs of each test")
+	workers  = flag.Int("invariant.workers", 4, "number of concurrent workers, 1 checks every outcome against the model")
+	ops      = flag.Int("invariant.ops", 50, "number of operations of each worker")
+	accounts = flag.Int("invariant.accounts", 8, "number of customer accounts")
+)
+
+var transferEngines = []string{transaction.EngineProcedure, transaction.EngineGo}
+
+// runInvariants runs a scenario per seed on the store that withStore hands to fn in each run
+func runInvariants(t *testing.T, workers int, runs int, withStore func(t *testing.T, fn func(ledger store.Store))) {
+	first := *seed
+	if first == 0 {
+		first = time.Now().UnixNano()
+	}
+
+	for _, engine := range transferEngines {
+		for run := range int64(runs) {
+			cfg := invariant.Config{Seed: first + run, Accounts: *accounts, Ops: *ops, Workers: workers}
+			t.Run(fmt.Sprintf("%s/seed=%d", engine, cfg.Seed), func(t *testing.T) {
+				withStore(t, func(ledger store.Store) {
+					checkScenario(t, ledger, engine, invariant.Generate(cfg))
+				})
+			})
+		}
+	}
+}
+
+func checkScenario(t *testing.T, ledger store.Store, engine string, scenario invariant.Scenario) {
+	t.Helper()
+	ctx := context.Background()
+	// The refusals are expected, only the errors of the domains would help
+	testLogger := logger.NewLogger("fatal")
+
+	accountDomain, err := account.NewAccountDomain(ledger, &config.Config{}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create account domain: %v", err)
+	}
+
+	transactionDomain, err := transaction.NewTransactionDomain(ledger, &config.Config{TransferEngine: engine}, testLogger)
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+
+	outcome, err := invariant.Run(ctx, invariant.Domains{Account: accountDomain, Transaction: transactionDomain}, scenario)
+	if err != nil {
+		t.Fatalf("failed to run scenario: %v", err)
+	}
+
+	violations, err := invariant.Check(ctx, ledger, scenario, outcome)
+	if err != nil {
+		t.Fatalf("failed to check invariants: %v", err)
+	}
+	if len(violations) > 0 {
+		t.Errorf("%d invariants violated, rerun with -invariant.seed=%d -invariant.runs=1 -invariant.workers=%d:\n%s",
+			len(violations), scenario.Seed, scenario.Workers, strings.Join(violations, "\n"))
+	}
+}
+
+func TestLedgerInvariants_InMemory(t *testing.T) {
+	runInvariants(t, *workers, *runs, func(t *testing.T, fn func(ledger store.Store)) {
+		fn(memory.New())
+	})
+}
+
+func TestLedgerInvariants_Sequential_InMemory(t *testing.T) {
+	runInvariants(t, 1, *runs, func(t *testing.T, fn func(ledger store.Store)) {
+		fn(memory.New())
+	})
+}
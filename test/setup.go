@@ -14,6 +14,8 @@ import (
 type TestDB struct {
 	DB *sql.DB
 	Tx *sql.Tx
+	// DSN connects to the test database outside of DB, e.g. to LISTEN
+	DSN string
 }
 
 // TestSuite provides test environment setup and teardown
@@ -22,6 +24,10 @@ type TestSuite struct {
 	db     *sql.DB
 }
 
+func (ts *TestSuite) dsn() string {
+	return db.DSN(ts.config.DBHost, ts.config.DBPort, ts.config.DBCustomer, ts.config.DBPassword, ts.config.DBName)
+}
+
 // NewTestSuite creates a new test suite with database connection
 func NewTestSuite() (*TestSuite, error) {
 	cfg, err := config.Get()
@@ -57,8 +63,9 @@ func (ts *TestSuite) BeginTransaction(t *testing.T) *TestDB {
 	}
 
 	return &TestDB{
-		DB: ts.db,
-		Tx: tx,
+		DB:  ts.db,
+		Tx:  tx,
+		DSN: ts.dsn(),
 	}
 }
 
@@ -67,7 +74,8 @@ func (ts *TestSuite) BeginWithoutTransaction(t *testing.T) *TestDB {
 	t.Helper()
 
 	return &TestDB{
-		DB: ts.db,
+		DB:  ts.db,
+		DSN: ts.dsn(),
 	}
 }
 
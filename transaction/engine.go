@@ -0,0 +1,119 @@
+package transaction
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/store"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// transferFundsGo is the transfer_funds stored procedure written in Go. It runs on queries bound
+// to the caller's transaction and answers with the same error codes and messages, so
+// createTransferFunds handles both engines alike. Refusals are returned as a result, errors of
+// the database as an error since they abort the transaction.
+func transferFundsGo(ctx context.Context, queries store.Queries, param entity.CreateTransferFundsParams) (sqlc.CreateTransferTransactionRow, error) {
+	// transfer_funds takes the amount as DECIMAL(20,6), which rounds it before any check
+	amount := param.Amount.Round(6)
+	if !amount.IsPositive() {
+		return transferRefused(transferAmountNotPositive, "Transfer amount must be positive"), nil
+	}
+
+	if param.SourceAccountID == param.DestinationAccountID {
+		return transferRefused(transferSameAccount, "Cannot transfer to the same account"), nil
+	}
+
+	// Lock accounts in consistent order to prevent deadlocks
+	first, second := param.SourceAccountID, param.DestinationAccountID
+	if first > second {
+		first, second = second, first
+	}
+	exists := make(map[uint64]bool, 2)
+	for _, accountID := range []uint64{first, second} {
+		_, err := queries.LockAccount(ctx, int64(accountID))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to lock account %d: %w", accountID, err)
+		}
+		exists[accountID] = true
+	}
+
+	if !exists[param.SourceAccountID] {
+		return transferRefused(transferSourceAccountNotFound, "From account does not exist"), nil
+	}
+
+	if !exists[param.DestinationAccountID] {
+		return transferRefused(transferDestinationAccountNotFound, "To account does not exist"), nil
+	}
+
+	// Get and lock account's balance, CREDIT accounts may spend up to their credit limit
+	rawBalance, err := queries.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{
+		FilterAccountID:     int64(param.SourceAccountID),
+		FilterLockForUpdate: true,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	balance, err := decimal.NewFromString(rawBalance)
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to parse account balance: %w", err)
+	}
+
+	source, err := queries.GetAccountByID(ctx, int64(param.SourceAccountID))
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to get account: %w", err)
+	}
+	creditLimit, err := decimal.NewFromString(source.CreditLimit)
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to parse credit limit: %w", err)
+	}
+
+	if balance.Add(creditLimit).LessThan(amount) {
+		return transferRefused(transferInsufficientFunds, "Insufficient funds"), nil
+	}
+
+	transfer, err := queries.CreateTransfer(ctx, sqlc.CreateTransferParams{
+		FromAccountID: int64(param.SourceAccountID),
+		ToAccountID:   int64(param.DestinationAccountID),
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+	_, err = queries.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{
+		AccountID:  int64(param.SourceAccountID),
+		TransferID: transferID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to create debit transaction: %w", err)
+	}
+
+	_, err = queries.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{
+		AccountID:  int64(param.DestinationAccountID),
+		TransferID: transferID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return sqlc.CreateTransferTransactionRow{}, fmt.Errorf("failed to create credit transaction: %w", err)
+	}
+
+	return sqlc.CreateTransferTransactionRow{
+		TransferID: transferID,
+		ErrorCode:  sql.NullInt32{Int32: int32(transferSucceeded), Valid: true},
+	}, nil
+}
+
+func transferRefused(code transferErrorCode, message string) sqlc.CreateTransferTransactionRow {
+	return sqlc.CreateTransferTransactionRow{
+		ErrorCode:    sql.NullInt32{Int32: int32(code), Valid: true},
+		ErrorMessage: sql.NullString{String: message, Valid: true},
+	}
+}
@@ -1,39 +1,68 @@
 package transaction
 
 import (
+	"bank/config"
 	"bank/entity"
 	"bank/internal/db/sqlc"
 	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/internal/tracing"
+	"bank/outbox"
+	"bank/store"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transferErrorCode is the error_code column of transfer_funds
+type transferErrorCode int32
+
+const (
+	transferSucceeded                  transferErrorCode = 0
+	transferAmountNotPositive          transferErrorCode = 1
+	transferSameAccount                transferErrorCode = 2
+	transferSourceAccountNotFound      transferErrorCode = 3
+	transferDestinationAccountNotFound transferErrorCode = 4
+	transferInsufficientFunds          transferErrorCode = 5
+	transferUnexpectedError            transferErrorCode = 99
 )
 
+// Transfer engines selectable with config.TransferEngine
 const (
-	// PostgreSQL composite type parsing constants
-	compositeTypeFields   = 3
-	postgresqlBooleanTrue = "t"
-	postgresqlNullValue   = "<NULL>"
-	quoteMark             = "\""
-	escapedQuote          = "\\\""
+	// EngineProcedure runs transfers in the transfer_funds stored procedure
+	EngineProcedure = "procedure"
+	// EngineGo runs transfers in Go, with the same locking, checks and error codes
+	EngineGo = "go"
 )
 
 type TransactionDomain struct {
-	db      *sql.DB
-	queries *sqlc.Queries
-	logger  *logger.Logger
+	store  store.Store
+	engine string
+	logger *logger.Logger
 }
 
-func NewTransactionDomain(db *sql.DB, sqlc *sqlc.Queries, logger *logger.Logger) (*TransactionDomain, error) {
-	if db == nil {
-		return nil, errors.New("db is nil")
+// NewTransactionDomain creates the transaction domain. An empty cfg.TransferEngine runs
+// transfers in the stored procedure.
+func NewTransactionDomain(store store.Store, cfg *config.Config, logger *logger.Logger) (*TransactionDomain, error) {
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
 	}
 
-	if sqlc == nil {
-		return nil, errors.New("sqlc is nil")
+	engine := cfg.TransferEngine
+	switch engine {
+	case "":
+		engine = EngineProcedure
+	case EngineProcedure, EngineGo:
+	default:
+		return nil, fmt.Errorf("unknown transfer engine %q", engine)
 	}
 
 	if logger == nil {
@@ -41,140 +70,244 @@ func NewTransactionDomain(db *sql.DB, sqlc *sqlc.Queries, logger *logger.Logger)
 	}
 
 	log := logger.WithField("domain", "transaction")
-	return &TransactionDomain{db: db, queries: sqlc, logger: log}, nil
+	return &TransactionDomain{store: store, engine: engine, logger: log}, nil
 }
 
 // CreateTransferFunds executes a fund transfer between two accounts atomically.
-// It creates transfer records and corresponding debit/credit transactions, then parses
-// the result to determine success or failure. Returns appropriate domain errors based
-// on the database response (insufficient funds, invalid account, validation errors).
-func (d *TransactionDomain) CreateTransferFunds(ctx context.Context, param entity.CreateTransferFundsParams) (entity.CreateTransferFundsResult, error) {
-	transferFunds, err := d.queries.CreateTransferTransaction(ctx, sqlc.CreateTransferTransactionParams{
-		ParamFromAccountID: int64(param.SourceAccountID),
-		ParamToAccountID:   int64(param.DestinationAccountID),
-		ParamAmount:        param.Amount.String(),
-	})
+// It creates transfer records and corresponding debit/credit transactions, then reads the
+// error code of the result to determine success or failure. Returns appropriate domain errors
+// based on the code (insufficient funds, invalid account, validation errors).
+// A TransferCompleted or TransferFailed event is written in the same transaction.
+func (d *TransactionDomain) CreateTransferFunds(ctx context.Context, param entity.CreateTransferFundsParams) (result entity.CreateTransferFundsResult, err error) {
+	ctx, end := d.startTransfer(ctx, "TransactionDomain.CreateTransferFunds", param)
+	defer func() { end(err) }()
+	logger.AddAccountIDs(ctx, param.SourceAccountID, param.DestinationAccountID)
+
+	tx, err := d.store.BeginTx(ctx, nil)
 	if err != nil {
-		d.logger.Error(ctx, "param=%+v, error=%v", param, err)
-		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to create transfer funds: %w", err)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if transferFunds == nil {
-		d.logger.Error(ctx, "param=%+v, invalid transfer funds result", param)
-		return entity.CreateTransferFundsResult{}, fmt.Errorf("invalid transfer funds result")
+	result, err = d.createTransferFunds(ctx, tx, param)
+	if err != nil && !isTransferRefused(err) {
+		return entity.CreateTransferFundsResult{}, err
 	}
 
-	transferFundsResult, err := d.parseTransferFundsResult(transferFunds)
-	if err != nil {
-		d.logger.Error(ctx, "param=%+v, failed to parse result: %v", param, err)
-		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to parse transfer funds result: %w", err)
+	// A refused transfer posted nothing, its TransferFailed event is committed all the same
+	if commitErr := tx.Commit(); commitErr != nil {
+		d.logger.Error(ctx, "param=%+v, failed to commit transfer funds: %v", param, commitErr)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to commit transaction: %w", commitErr)
 	}
 
-	if !transferFundsResult.Success {
-		d.logger.Error(ctx, "param=%+v, transfer funds failed", param)
-		return entity.CreateTransferFundsResult{}, d.mapTransferError(transferFundsResult.ErrorMessage)
+	return result, err
+}
+
+// startTransfer starts the span of a transfer, the returned func ends it and records the outcome
+func (d *TransactionDomain) startTransfer(ctx context.Context, name string, param entity.CreateTransferFundsParams) (context.Context, func(err error)) {
+	ctx, span := tracing.Start(ctx, name, trace.WithAttributes(
+		attribute.Int64("bank.source_account_id", int64(param.SourceAccountID)),
+		attribute.Int64("bank.destination_account_id", int64(param.DestinationAccountID)),
+		attribute.String("bank.amount", param.Amount.String()),
+		attribute.String("bank.transfer_engine", d.engine),
+	))
+	return ctx, func(err error) {
+		observeTransfer(param, err)
+
+		// A refused transfer is an answer, not a failure of the span
+		if isTransferRefused(err) {
+			span.SetAttributes(attribute.String("bank.transfer_refused", err.Error()))
+			tracing.End(span, nil)
+			return
+		}
+		tracing.End(span, err)
+	}
+}
+
+// observeTransfer records the outcome of a transfer once it is posted or failed
+func observeTransfer(param entity.CreateTransferFundsParams, err error) {
+	switch {
+	case err == nil:
+		// Accounts are held in a single currency
+		metrics.TransferSucceeded(entity.CurrencyCodeUSD, param.Amount)
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		metrics.TransferFailed(metrics.ReasonInsufficientFunds)
+	case errors.Is(err, entity.ErrDataNotFound):
+		metrics.TransferFailed(metrics.ReasonAccountNotFound)
+	case errors.Is(err, entity.ErrValidation):
+		metrics.TransferFailed(metrics.ReasonValidation)
+	default:
+		metrics.TransferFailed(metrics.ReasonError)
 	}
+}
+
+// CreateTransferFundsTx is CreateTransferFunds running inside the caller's database transaction,
+// so the transfer commits or rolls back together with the caller's own writes. Its outcome is
+// recorded before the caller commits.
+func (d *TransactionDomain) CreateTransferFundsTx(ctx context.Context, tx *sql.Tx, param entity.CreateTransferFundsParams) (result entity.CreateTransferFundsResult, err error) {
+	ctx, end := d.startTransfer(ctx, "TransactionDomain.CreateTransferFundsTx", param)
+	defer func() { end(err) }()
 
-	return transferFundsResult, nil
+	return d.createTransferFunds(ctx, sqlc.New(tx), param)
 }
 
-// parseTransferFundsResult parses a PostgreSQL composite type string into a CreateTransferFundsResult
-// The input format is: "(transfer_id,success,error_message)"
-// Example: "(16007,t,\"Transfer completed successfully\")"
-func (d *TransactionDomain) parseTransferFundsResult(result interface{}) (entity.CreateTransferFundsResult, error) {
-	resultStr, err := d.convertToString(result)
+func (d *TransactionDomain) createTransferFunds(ctx context.Context, queries store.Queries, param entity.CreateTransferFundsParams) (entity.CreateTransferFundsResult, error) {
+	var transferFunds sqlc.CreateTransferTransactionRow
+	var err error
+	if d.engine == EngineGo {
+		transferFunds, err = transferFundsGo(ctx, queries, param)
+	} else {
+		transferFunds, err = queries.CreateTransferTransaction(ctx, sqlc.CreateTransferTransactionParams{
+			ParamFromAccountID: int64(param.SourceAccountID),
+			ParamToAccountID:   int64(param.DestinationAccountID),
+			ParamAmount:        param.Amount.String(),
+		})
+	}
 	if err != nil {
-		return entity.CreateTransferFundsResult{}, err
+		d.logger.Error(ctx, "param=%+v, error=%v", param, err)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to create transfer funds: %w", err)
 	}
 
-	content, err := d.extractCompositeContent(resultStr)
-	if err != nil {
-		return entity.CreateTransferFundsResult{}, err
+	if !transferFunds.ErrorCode.Valid {
+		d.logger.Error(ctx, "param=%+v, transfer funds returned no error code", param)
+		return entity.CreateTransferFundsResult{}, errors.New("invalid transfer funds result")
+	}
+
+	if code := transferErrorCode(transferFunds.ErrorCode.Int32); code != transferSucceeded {
+		d.logger.Error(ctx, "param=%+v, transfer funds failed: code=%d, message=%s", param, code, transferFunds.ErrorMessage.String)
+		transferErr := mapTransferError(code, transferFunds.ErrorMessage.String)
+		if !isTransferRefused(transferErr) {
+			return entity.CreateTransferFundsResult{}, transferErr
+		}
+
+		err := outbox.Write(ctx, queries, entity.TransferFailed{
+			SourceAccountID:      param.SourceAccountID,
+			DestinationAccountID: param.DestinationAccountID,
+			Amount:               param.Amount,
+			Reason:               transferErr.Error(),
+		})
+		if err != nil {
+			d.logger.Error(ctx, "param=%+v, failed to write transfer failed event: %v", param, err)
+			return entity.CreateTransferFundsResult{}, err
+		}
+		return entity.CreateTransferFundsResult{}, transferErr
 	}
 
-	fields := strings.Split(content, ",")
-	if len(fields) != compositeTypeFields {
-		return entity.CreateTransferFundsResult{}, fmt.Errorf("invalid composite type content, expected %d fields got %d: %s", compositeTypeFields, len(fields), content)
+	if !transferFunds.TransferID.Valid {
+		d.logger.Error(ctx, "param=%+v, transfer funds succeeded without a transfer id", param)
+		return entity.CreateTransferFundsResult{}, errors.New("invalid transfer funds result")
 	}
+	transferID := uint64(transferFunds.TransferID.Int64)
 
-	transferID, err := d.parseTransferID(fields[0])
+	err = outbox.Write(ctx, queries, entity.TransferCompleted{
+		TransferID:           transferID,
+		SourceAccountID:      param.SourceAccountID,
+		DestinationAccountID: param.DestinationAccountID,
+		Amount:               param.Amount,
+	})
 	if err != nil {
+		d.logger.Error(ctx, "param=%+v, failed to write transfer completed event: %v", param, err)
 		return entity.CreateTransferFundsResult{}, err
 	}
 
-	success := d.parseSuccess(fields[1])
-	errorMessage := d.parseErrorMessage(fields[2])
-
 	return entity.CreateTransferFundsResult{
-		TransferID:   transferID,
-		Success:      success,
-		ErrorMessage: errorMessage,
+		TransferID: transferID,
+		Success:    true,
 	}, nil
 }
 
-// convertToString converts the result to string, handling both string and []byte cases
-func (d *TransactionDomain) convertToString(result interface{}) (string, error) {
-	switch v := result.(type) {
-	case string:
-		return v, nil
-	case []byte:
-		return string(v), nil
-	default:
-		return "", fmt.Errorf("expected string or []byte result, got %T", result)
-	}
+// isTransferRefused reports whether the transfer was refused for a business reason rather than
+// failing on an error of the database
+func isTransferRefused(err error) bool {
+	return errors.Is(err, entity.ErrInsufficientFunds) || errors.Is(err, entity.ErrDataNotFound) || errors.Is(err, entity.ErrValidation)
 }
 
-// extractCompositeContent removes parentheses and returns the inner content
-func (d *TransactionDomain) extractCompositeContent(resultStr string) (string, error) {
-	if !strings.HasPrefix(resultStr, "(") || !strings.HasSuffix(resultStr, ")") {
-		return "", fmt.Errorf("invalid composite type format, expected parentheses: %s", resultStr)
+// PostTransferTx posts a balanced transfer inside the caller's transaction without checking
+// the source balance. It is meant for postings that involve system accounts (adjustments,
+// interest, clearing), which are allowed to go negative. Both accounts are locked in id order.
+func (d *TransactionDomain) PostTransferTx(ctx context.Context, tx *sql.Tx, param entity.CreateTransferFundsParams) (entity.CreateTransferFundsResult, error) {
+	if !param.Amount.IsPositive() {
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("%w: Transfer amount must be positive", entity.ErrValidation)
+	}
+
+	if param.SourceAccountID == param.DestinationAccountID {
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("%w: Cannot transfer to the same account", entity.ErrValidation)
 	}
-	return resultStr[1 : len(resultStr)-1], nil
-}
 
-// parseTransferID parses the transfer_id field
-func (d *TransactionDomain) parseTransferID(field string) (uint64, error) {
-	transferIDStr := strings.TrimSpace(field)
-	if transferIDStr == "" || transferIDStr == postgresqlNullValue {
-		return 0, nil
+	qtx := sqlc.New(tx)
+	first, second := param.SourceAccountID, param.DestinationAccountID
+	if first > second {
+		first, second = second, first
+	}
+	for _, accountID := range []uint64{first, second} {
+		if _, err := qtx.LockAccount(ctx, int64(accountID)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return entity.CreateTransferFundsResult{}, entity.ErrDataNotFound
+			}
+			return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to lock account %d: %w", accountID, err)
+		}
 	}
 
-	parsed, err := strconv.ParseUint(transferIDStr, 10, 64)
+	transfer, err := qtx.CreateTransfer(ctx, sqlc.CreateTransferParams{
+		FromAccountID: int64(param.SourceAccountID),
+		ToAccountID:   int64(param.DestinationAccountID),
+	})
 	if err != nil {
-		return 0, fmt.Errorf("invalid transfer_id: %s", transferIDStr)
+		d.logger.Error(ctx, "param=%+v, failed to create transfer: %v", param, err)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to create transfer: %w", err)
 	}
-	return parsed, nil
-}
 
-// parseSuccess parses the success field
-func (d *TransactionDomain) parseSuccess(field string) bool {
-	successStr := strings.TrimSpace(field)
-	return successStr == postgresqlBooleanTrue
-}
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+	_, err = qtx.CreateDebitTransaction(ctx, sqlc.CreateDebitTransactionParams{
+		AccountID:  int64(param.SourceAccountID),
+		TransferID: transferID,
+		Amount:     param.Amount,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "param=%+v, failed to create debit transaction: %v", param, err)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to create debit transaction: %w", err)
+	}
+
+	_, err = qtx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{
+		AccountID:  int64(param.DestinationAccountID),
+		TransferID: transferID,
+		Amount:     param.Amount,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "param=%+v, failed to create credit transaction: %v", param, err)
+		return entity.CreateTransferFundsResult{}, fmt.Errorf("failed to create credit transaction: %w", err)
+	}
 
-// parseErrorMessage parses the error_message field, removing quotes and unescaping
-func (d *TransactionDomain) parseErrorMessage(field string) string {
-	errorMessage := strings.TrimSpace(field)
-	if strings.HasPrefix(errorMessage, quoteMark) && strings.HasSuffix(errorMessage, quoteMark) {
-		errorMessage = errorMessage[1 : len(errorMessage)-1]
-		// Unescape any escaped quotes
-		errorMessage = strings.ReplaceAll(errorMessage, escapedQuote, quoteMark)
+	err = outbox.Write(ctx, qtx, entity.TransferCompleted{
+		TransferID:           uint64(transfer.ID),
+		SourceAccountID:      param.SourceAccountID,
+		DestinationAccountID: param.DestinationAccountID,
+		Amount:               param.Amount,
+	})
+	if err != nil {
+		d.logger.Error(ctx, "param=%+v, failed to write transfer completed event: %v", param, err)
+		return entity.CreateTransferFundsResult{}, err
 	}
-	return errorMessage
+
+	return entity.CreateTransferFundsResult{
+		TransferID: uint64(transfer.ID),
+		Success:    true,
+	}, nil
 }
 
-// mapTransferError maps database error messages to appropriate domain errors
-func (d *TransactionDomain) mapTransferError(errorMessage string) error {
-	normalizedErr := strings.ToLower(errorMessage)
-	switch {
-	case strings.Contains(normalizedErr, "insufficient funds"):
+// mapTransferError maps the error code of transfer_funds to a domain error. The message is only
+// kept for context, it is never matched.
+func mapTransferError(code transferErrorCode, message string) error {
+	switch code {
+	case transferInsufficientFunds:
 		return entity.ErrInsufficientFunds
-	case strings.Contains(normalizedErr, "account does not exist"):
+	case transferSourceAccountNotFound, transferDestinationAccountNotFound:
 		return entity.ErrDataNotFound
-	case strings.Contains(normalizedErr, "transfer amount must be positive"),
-		strings.Contains(normalizedErr, "cannot transfer to the same account"):
-		return fmt.Errorf("%w: %s", entity.ErrValidation, errorMessage)
+	case transferAmountNotPositive, transferSameAccount:
+		return fmt.Errorf("%w: %s", entity.ErrValidation, message)
+	case transferUnexpectedError:
+		return fmt.Errorf("transfer funds failed: %s", message)
 	default:
-		return fmt.Errorf("transfer funds failed: %s", errorMessage)
+		return fmt.Errorf("transfer funds failed with unknown code %d: %s", code, message)
 	}
 }
@@ -0,0 +1,61 @@
+//go:build integration
+
+package transaction_test
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/store"
+	"bank/test"
+	"bank/transaction"
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCreateTransferFundsTx_Metrics_Postgres(t *testing.T) {
+	testCases := []struct {
+		name    string
+		param   entity.CreateTransferFundsParams
+		outcome string
+		reason  string
+	}{
+		{"success", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(1)}, "succeeded", ""},
+		{"insufficient funds", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(101)}, "failed", metrics.ReasonInsufficientFunds},
+	}
+
+	for _, engine := range transferEngines {
+		for _, tc := range testCases {
+			t.Run(engine+"/"+tc.name, func(t *testing.T) {
+				test.RunWithTransaction(t, func(testDB *test.TestDB) {
+					ledger, err := store.NewPostgres(testDB.DB)
+					if err != nil {
+						t.Fatalf("failed to create store: %v", err)
+					}
+					domain, err := transaction.NewTransactionDomain(ledger, &config.Config{TransferEngine: engine}, logger.NewLogger("debug"))
+					if err != nil {
+						t.Fatalf("failed to create transaction domain: %v", err)
+					}
+
+					for _, accountID := range []int64{100, 200} {
+						if _, err := testDB.Tx.Exec("INSERT INTO accounts (id, created_at, updated_at) VALUES ($1, NOW(), NOW())", accountID); err != nil {
+							t.Fatalf("failed to create account: %v", err)
+						}
+						if _, err := testDB.Tx.Exec("INSERT INTO transactions (account_id, amount, trx_type, created_at) VALUES ($1, 100, 'CREDIT', NOW())", accountID); err != nil {
+							t.Fatalf("failed to create transaction: %v", err)
+						}
+					}
+
+					counted := transfersCounted(t, tc.outcome, tc.reason)
+					domain.CreateTransferFundsTx(context.Background(), testDB.Tx, tc.param)
+					if got := transfersCounted(t, tc.outcome, tc.reason); got != counted+1 {
+						t.Errorf("expected the transfer to be counted as %s %q once, got %v then %v", tc.outcome, tc.reason, counted, got)
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package transaction_test
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/internal/metrics"
+	"bank/store/memory"
+	"bank/transaction"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var transferEngines = []string{transaction.EngineProcedure, transaction.EngineGo}
+
+func newDomain(t *testing.T, engine string) (*transaction.TransactionDomain, *memory.Store) {
+	t.Helper()
+	ledger := memory.New()
+	domain, err := transaction.NewTransactionDomain(ledger, &config.Config{TransferEngine: engine}, logger.NewLogger("debug"))
+	if err != nil {
+		t.Fatalf("failed to create transaction domain: %v", err)
+	}
+	return domain, ledger
+}
+
+func seedAccount(t *testing.T, ledger *memory.Store, accountID int64, creditLimit, balance string) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := ledger.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.CreateAccount(ctx, sqlc.CreateAccountParams{ID: accountID, AccountType: "SAVINGS", CreditLimit: creditLimit}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if _, err := tx.CreateCreditTransaction(ctx, sqlc.CreateCreditTransactionParams{AccountID: accountID, Amount: decimal.RequireFromString(balance)}); err != nil {
+		t.Fatalf("failed to create credit: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+}
+
+func balance(t *testing.T, ledger *memory.Store, accountID int64) decimal.Decimal {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := ledger.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	balance, err := tx.GetAccountBalanceByAccountID(ctx, sqlc.GetAccountBalanceByAccountIDParams{FilterAccountID: accountID})
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	return decimal.RequireFromString(balance)
+}
+
+func TestCreateTransferFunds(t *testing.T) {
+	testCases := []struct {
+		name          string
+		param         entity.CreateTransferFundsParams
+		expectedErr   error
+		expectedEvent entity.EventType
+		expectedFrom  string
+	}{
+		{
+			name:          "success",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.RequireFromString("50.123456")},
+			expectedEvent: entity.EventTypeTransferCompleted,
+			expectedFrom:  "49.876544",
+		},
+		{
+			name:          "spends the credit limit",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 300, DestinationAccountID: 200, Amount: decimal.NewFromInt(150)},
+			expectedEvent: entity.EventTypeTransferCompleted,
+		},
+		{
+			name:          "insufficient funds",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.RequireFromString("100.000001")},
+			expectedErr:   entity.ErrInsufficientFunds,
+			expectedEvent: entity.EventTypeTransferFailed,
+			expectedFrom:  "100",
+		},
+		{
+			name:          "source account not found",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 999, DestinationAccountID: 200, Amount: decimal.NewFromInt(1)},
+			expectedErr:   entity.ErrDataNotFound,
+			expectedEvent: entity.EventTypeTransferFailed,
+		},
+		{
+			name:          "destination account not found",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 999, Amount: decimal.NewFromInt(1)},
+			expectedErr:   entity.ErrDataNotFound,
+			expectedEvent: entity.EventTypeTransferFailed,
+			expectedFrom:  "100",
+		},
+		{
+			name:          "same account",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 100, Amount: decimal.NewFromInt(1)},
+			expectedErr:   entity.ErrValidation,
+			expectedEvent: entity.EventTypeTransferFailed,
+			expectedFrom:  "100",
+		},
+		{
+			name:          "amount not positive",
+			param:         entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.RequireFromString("0.0000001")},
+			expectedErr:   entity.ErrValidation,
+			expectedEvent: entity.EventTypeTransferFailed,
+			expectedFrom:  "100",
+		},
+	}
+
+	for _, engine := range transferEngines {
+		for _, tc := range testCases {
+			t.Run(engine+"/"+tc.name, func(t *testing.T) {
+				domain, ledger := newDomain(t, engine)
+				seedAccount(t, ledger, 100, "0", "100")
+				seedAccount(t, ledger, 200, "0", "100")
+				seedAccount(t, ledger, 300, "50", "100")
+
+				result, err := domain.CreateTransferFunds(context.Background(), tc.param)
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+				}
+				if result.Success != (tc.expectedErr == nil) {
+					t.Errorf("expected success %t, got %+v", tc.expectedErr == nil, result)
+				}
+
+				// A refused transfer commits its TransferFailed event all the same
+				events := ledger.OutboxEvents()
+				if len(events) != 1 || events[0].EventType != string(tc.expectedEvent) {
+					t.Errorf("expected one %s event, got %+v", tc.expectedEvent, events)
+				}
+
+				if tc.expectedFrom != "" {
+					if got := balance(t, ledger, 100); !got.Equal(decimal.RequireFromString(tc.expectedFrom)) {
+						t.Errorf("expected balance %s, got %s", tc.expectedFrom, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCreateTransferFunds_Concurrent(t *testing.T) {
+	for _, engine := range transferEngines {
+		t.Run(engine, func(t *testing.T) {
+			domain, ledger := newDomain(t, engine)
+			seedAccount(t, ledger, 100, "0", "500")
+			seedAccount(t, ledger, 200, "0", "200")
+
+			// Transfers both ways lock the same accounts, 100 can only afford two of those it sends
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			refused := 0
+			for i := range 6 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					param := entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(250)}
+					if i%2 == 1 {
+						param = entity.CreateTransferFundsParams{SourceAccountID: 200, DestinationAccountID: 100, Amount: decimal.NewFromInt(1)}
+					}
+					_, err := domain.CreateTransferFunds(context.Background(), param)
+					if err != nil && !errors.Is(err, entity.ErrInsufficientFunds) {
+						t.Errorf("failed to transfer: %v", err)
+					}
+					if err != nil {
+						mu.Lock()
+						refused++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			// 500 + 3 received can afford two transfers of 250 sent, never three
+			if refused != 1 {
+				t.Errorf("expected 1 refused transfer, got %d", refused)
+			}
+			total := balance(t, ledger, 100).Add(balance(t, ledger, 200))
+			if !total.Equal(decimal.NewFromInt(700)) {
+				t.Errorf("expected the total balance to stay 700, got %s", total)
+			}
+			if balance(t, ledger, 100).IsNegative() {
+				t.Errorf("expected the balance of account 100 to stay positive, got %s", balance(t, ledger, 100))
+			}
+		})
+	}
+}
+
+// transfersCounted returns the value of bank_transfers_total for the outcome and reason
+func transfersCounted(t *testing.T, outcome, reason string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "bank_transfers_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["outcome"] == outcome && labels["reason"] == reason {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestCreateTransferFunds_Metrics(t *testing.T) {
+	testCases := []struct {
+		name    string
+		param   entity.CreateTransferFundsParams
+		outcome string
+		reason  string
+	}{
+		{"success", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(1)}, "succeeded", ""},
+		{"insufficient funds", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 200, Amount: decimal.NewFromInt(101)}, "failed", metrics.ReasonInsufficientFunds},
+		{"account not found", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 999, Amount: decimal.NewFromInt(1)}, "failed", metrics.ReasonAccountNotFound},
+		{"same account", entity.CreateTransferFundsParams{SourceAccountID: 100, DestinationAccountID: 100, Amount: decimal.NewFromInt(1)}, "failed", metrics.ReasonValidation},
+	}
+
+	for _, engine := range transferEngines {
+		for _, tc := range testCases {
+			t.Run(engine+"/"+tc.name, func(t *testing.T) {
+				domain, ledger := newDomain(t, engine)
+				seedAccount(t, ledger, 100, "0", "100")
+				seedAccount(t, ledger, 200, "0", "100")
+
+				counted := transfersCounted(t, tc.outcome, tc.reason)
+				domain.CreateTransferFunds(context.Background(), tc.param)
+				if got := transfersCounted(t, tc.outcome, tc.reason); got != counted+1 {
+					t.Errorf("expected the transfer to be counted as %s %q once, got %v then %v", tc.outcome, tc.reason, counted, got)
+				}
+			})
+		}
+	}
+}
+
+func TestNewTransactionDomain_UnknownEngine(t *testing.T) {
+	_, err := transaction.NewTransactionDomain(memory.New(), &config.Config{TransferEngine: "cobol"}, logger.NewLogger("debug"))
+	if err == nil {
+		t.Error("expected an unknown transfer engine to be refused")
+	}
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxErrorBodySize is how much of a failed response body is kept in the delivery log
+const maxErrorBodySize = 512
+
+type attemptResult struct {
+	responseStatus int
+	err            error
+	duration       time.Duration
+}
+
+// DeliverWebhooks sends a batch of due deliveries concurrently and records every attempt. A failed
+// delivery is retried after an exponential backoff and dead-lettered after the last attempt.
+// Deliveries are claimed until the sends could have timed out, so workers running side by side
+// don't send the same delivery and the claims of a crashed worker expire.
+func (d *WebhookDomain) DeliverWebhooks(ctx context.Context) (entity.DeliverWebhooksResult, error) {
+	claimed, err := d.queries.ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(2 * d.timeout),
+		BatchSize:  int32(d.batchSize),
+	})
+	if err != nil {
+		return entity.DeliverWebhooksResult{}, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	results := make([]attemptResult, len(claimed))
+	var wg sync.WaitGroup
+	for i, delivery := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = d.send(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	// Sends cut short by a shutdown aren't attempts of the receiver, the claims expire instead
+	if err := ctx.Err(); err != nil {
+		return entity.DeliverWebhooksResult{}, err
+	}
+
+	result := entity.DeliverWebhooksResult{}
+	for i, delivery := range claimed {
+		status, err := d.recordAttempt(ctx, delivery, results[i])
+		if err != nil {
+			return result, err
+		}
+
+		switch status {
+		case entity.WebhookDeliveryStatusDelivered:
+			result.Delivered++
+		case entity.WebhookDeliveryStatusDead:
+			d.logger.Warn(ctx, "webhook delivery_id=%d dead after %d attempts: %v", delivery.ID, delivery.Attempts+1, results[i].err)
+			result.Dead++
+		default:
+			result.Retrying++
+		}
+	}
+	return result, nil
+}
+
+// BatchSize is the number of deliveries DeliverWebhooks claims at once
+func (d *WebhookDomain) BatchSize() int {
+	return d.batchSize
+}
+
+func (d *WebhookDomain) send(ctx context.Context, delivery sqlc.ClaimDueWebhookDeliveriesRow) attemptResult {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return attemptResult{err: err}
+	}
+
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return attemptResult{err: err, duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+
+	result := attemptResult{responseStatus: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		result.err = fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	// Draining the body lets the connection be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	result.duration = time.Since(start)
+	return result
+}
+
+// recordAttempt logs the attempt and moves the delivery on: delivered, scheduled for a retry or
+// dead when it was the last attempt
+func (d *WebhookDomain) recordAttempt(ctx context.Context, delivery sqlc.ClaimDueWebhookDeliveriesRow, result attemptResult) (entity.WebhookDeliveryStatus, error) {
+	now := time.Now()
+	attempt := int(delivery.Attempts) + 1
+	responseStatus := sql.NullInt32{Int32: int32(result.responseStatus), Valid: result.responseStatus != 0}
+	lastError := ""
+	if result.err != nil {
+		lastError = result.err.Error()
+	}
+
+	update := sqlc.UpdateWebhookDeliveryAttemptParams{
+		ID:                 delivery.ID,
+		Status:             string(entity.WebhookDeliveryStatusPending),
+		Attempts:           int32(attempt),
+		NextAttemptAt:      now.Add(d.backoff(attempt)),
+		LastResponseStatus: responseStatus,
+		LastError:          lastError,
+	}
+	switch {
+	case result.err == nil:
+		update.Status = string(entity.WebhookDeliveryStatusDelivered)
+		update.NextAttemptAt = now
+		update.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+	case attempt >= d.maxAttempts:
+		update.Status = string(entity.WebhookDeliveryStatusDead)
+		update.NextAttemptAt = now
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := d.queries.WithTx(tx)
+
+	err = qtx.CreateWebhookDeliveryAttempt(ctx, sqlc.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:     delivery.ID,
+		Attempt:        int32(attempt),
+		ResponseStatus: responseStatus,
+		Error:          lastError,
+		DurationMs:     int32(result.duration.Milliseconds()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook delivery attempt: %w", err)
+	}
+
+	if err := qtx.UpdateWebhookDeliveryAttempt(ctx, update); err != nil {
+		return "", fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return entity.WebhookDeliveryStatus(update.Status), nil
+}
+
+// backoff is the wait after the given failed attempt: the base doubling with every attempt, up
+// to the maximum
+func (d *WebhookDomain) backoff(attempt int) time.Duration {
+	wait := d.backoffBase
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= d.backoffMax {
+			return d.backoffMax
+		}
+	}
+	return wait
+}
@@ -0,0 +1,100 @@
+package webhook_test
+
+import (
+	"bank/webhook"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"id":1,"type":"AccountCreated"}`)
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := webhook.Sign(secret, now.Unix(), body)
+
+	testCases := []struct {
+		name          string
+		secret        string
+		timestamp     string
+		signature     string
+		body          string
+		now           time.Time
+		expectedError error
+	}{
+		{
+			name:      "valid",
+			secret:    secret,
+			timestamp: timestamp,
+			signature: signature,
+			body:      string(body),
+			now:       now.Add(time.Minute),
+		},
+		{
+			name:          "tampered body",
+			secret:        secret,
+			timestamp:     timestamp,
+			signature:     signature,
+			body:          `{"id":2,"type":"AccountCreated"}`,
+			now:           now,
+			expectedError: webhook.ErrInvalidSignature,
+		},
+		{
+			name:          "other secret",
+			secret:        "fedcba9876543210",
+			timestamp:     timestamp,
+			signature:     signature,
+			body:          string(body),
+			now:           now,
+			expectedError: webhook.ErrInvalidSignature,
+		},
+		{
+			// The signature covers the timestamp, a replay can't move it forward
+			name:          "other timestamp",
+			secret:        secret,
+			timestamp:     strconv.FormatInt(now.Unix()+1, 10),
+			signature:     signature,
+			body:          string(body),
+			now:           now,
+			expectedError: webhook.ErrInvalidSignature,
+		},
+		{
+			name:          "replayed too late",
+			secret:        secret,
+			timestamp:     timestamp,
+			signature:     signature,
+			body:          string(body),
+			now:           now.Add(6 * time.Minute),
+			expectedError: webhook.ErrInvalidTimestamp,
+		},
+		{
+			name:          "malformed timestamp",
+			secret:        secret,
+			timestamp:     "yesterday",
+			signature:     signature,
+			body:          string(body),
+			now:           now,
+			expectedError: webhook.ErrInvalidTimestamp,
+		},
+		{
+			name:          "unknown version",
+			secret:        secret,
+			timestamp:     timestamp,
+			signature:     "v0=" + signature[3:],
+			body:          string(body),
+			now:           now,
+			expectedError: webhook.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := webhook.Verify(tc.secret, tc.timestamp, tc.signature, []byte(tc.body), tc.now, 5*time.Minute)
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
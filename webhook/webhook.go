@@ -0,0 +1,338 @@
+// Package webhook pushes domain events to the URLs of webhook subscriptions. Events relayed from the
+// outbox become one delivery per matching subscription, deliveries are sent signed and retried
+// with exponential backoff until they succeed or are dead-lettered.
+package webhook
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxListedDeliveries bounds the delivery log returned for a subscription
+const maxListedDeliveries = 100
+
+type WebhookDomain struct {
+	db          *sql.DB
+	queries     *sqlc.Queries
+	client      *http.Client
+	logger      *logger.Logger
+	timeout     time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	batchSize   int
+}
+
+func NewWebhookDomain(db *sql.DB, sqlc *sqlc.Queries, cfg *config.Config, logger *logger.Logger) (*WebhookDomain, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if sqlc == nil {
+		return nil, errors.New("sqlc is nil")
+	}
+
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+
+	if cfg.WebhookTimeout <= 0 {
+		return nil, errors.New("webhook timeout must be positive")
+	}
+
+	if cfg.WebhookMaxAttempts <= 0 {
+		return nil, errors.New("webhook max attempts must be positive")
+	}
+
+	if cfg.WebhookBackoffBase <= 0 || cfg.WebhookBackoffMax < cfg.WebhookBackoffBase {
+		return nil, errors.New("webhook backoff must be positive and not exceed its maximum")
+	}
+
+	if cfg.WebhookBatchSize <= 0 {
+		return nil, errors.New("webhook batch size must be positive")
+	}
+
+	if logger == nil {
+		return nil, errors.New("logger is nil")
+	}
+
+	log := logger.WithField("domain", "webhook")
+	return &WebhookDomain{
+		db:      db,
+		queries: sqlc,
+		client: &http.Client{
+			Timeout: cfg.WebhookTimeout,
+			// A redirect is answered like any other non 2xx response, the subscription URL is
+			// the only place events are sent to
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger:      log,
+		timeout:     cfg.WebhookTimeout,
+		maxAttempts: cfg.WebhookMaxAttempts,
+		backoffBase: cfg.WebhookBackoffBase,
+		backoffMax:  cfg.WebhookBackoffMax,
+		batchSize:   cfg.WebhookBatchSize,
+	}, nil
+}
+
+func (d *WebhookDomain) CreateSubscription(ctx context.Context, params entity.CreateWebhookSubscription) (entity.WebhookSubscription, error) {
+	if err := params.Validate(); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	subscription, err := d.queries.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		Url:        params.URL,
+		EventTypes: fromEventTypes(params.EventTypes),
+		Secret:     params.Secret,
+	})
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return toWebhookSubscription(subscription), nil
+}
+
+func (d *WebhookDomain) GetSubscription(ctx context.Context, id uint64) (entity.WebhookSubscription, error) {
+	subscription, err := d.queries.GetWebhookSubscriptionByID(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.WebhookSubscription{}, entity.ErrDataNotFound
+		}
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return toWebhookSubscription(subscription), nil
+}
+
+func (d *WebhookDomain) ListSubscriptions(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	subscriptions, err := d.queries.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	result := make([]entity.WebhookSubscription, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		result = append(result, toWebhookSubscription(s))
+	}
+	return result, nil
+}
+
+// UpdateSubscription replaces the URL, event types and active flag, and the secret when one is
+// given. Deliveries already created keep their payload and are signed with the current secret.
+func (d *WebhookDomain) UpdateSubscription(ctx context.Context, params entity.UpdateWebhookSubscription) (entity.WebhookSubscription, error) {
+	if err := params.Validate(); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	subscription, err := d.queries.UpdateWebhookSubscription(ctx, sqlc.UpdateWebhookSubscriptionParams{
+		Url:        params.URL,
+		EventTypes: fromEventTypes(params.EventTypes),
+		Secret:     params.Secret,
+		Active:     params.Active,
+		ID:         int64(params.ID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.WebhookSubscription{}, entity.ErrDataNotFound
+		}
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	return toWebhookSubscription(subscription), nil
+}
+
+// DeleteSubscription removes the subscription together with its deliveries
+func (d *WebhookDomain) DeleteSubscription(ctx context.Context, id uint64) error {
+	deleted, err := d.queries.DeleteWebhookSubscription(ctx, int64(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if deleted == 0 {
+		return entity.ErrDataNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a subscription, newest first, optionally of one
+// status
+func (d *WebhookDomain) ListDeliveries(ctx context.Context, subscriptionID uint64, status entity.WebhookDeliveryStatus) ([]entity.WebhookDelivery, error) {
+	if _, err := d.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := d.queries.ListWebhookDeliveriesBySubscriptionID(ctx, sqlc.ListWebhookDeliveriesBySubscriptionIDParams{
+		SubscriptionID: int64(subscriptionID),
+		Status:         string(status),
+		RowLimit:       maxListedDeliveries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	result := make([]entity.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, toWebhookDelivery(delivery))
+	}
+	return result, nil
+}
+
+// GetDelivery returns a delivery with the log of its attempts
+func (d *WebhookDomain) GetDelivery(ctx context.Context, id uint64) (entity.WebhookDelivery, error) {
+	delivery, err := d.queries.GetWebhookDeliveryByID(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.WebhookDelivery{}, entity.ErrDataNotFound
+		}
+		return entity.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	attempts, err := d.queries.ListWebhookDeliveryAttemptsByDeliveryID(ctx, delivery.ID)
+	if err != nil {
+		return entity.WebhookDelivery{}, fmt.Errorf("failed to list webhook delivery attempts: %w", err)
+	}
+
+	result := toWebhookDelivery(delivery)
+	result.AttemptLog = make([]entity.WebhookDeliveryAttempt, 0, len(attempts))
+	for _, a := range attempts {
+		result.AttemptLog = append(result.AttemptLog, toWebhookDeliveryAttempt(a))
+	}
+	return result, nil
+}
+
+// Redeliver sends a delivered or dead delivery again with a fresh set of retries. Returns
+// entity.ErrInvalidState when it is still pending.
+func (d *WebhookDomain) Redeliver(ctx context.Context, id uint64) (entity.WebhookDelivery, error) {
+	updated, err := d.queries.RedeliverWebhookDelivery(ctx, int64(id))
+	if err != nil {
+		return entity.WebhookDelivery{}, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	delivery, err := d.GetDelivery(ctx, id)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	if updated == 0 {
+		return entity.WebhookDelivery{}, fmt.Errorf("%w: delivery is already pending", entity.ErrInvalidState)
+	}
+	return delivery, nil
+}
+
+// body is the JSON request body of a delivery
+type body struct {
+	ID        uint64           `json:"id"`
+	Type      entity.EventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+// Publish creates a delivery of the event for every active subscription of its type. It is the
+// outbox publisher, an event relayed again creates no second delivery.
+func (d *WebhookDomain) Publish(ctx context.Context, event entity.Event) error {
+	subscriptions, err := d.queries.ListActiveWebhookSubscriptionsByEventType(ctx, string(event.Type))
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(body{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	for _, s := range subscriptions {
+		err := d.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			SubscriptionID: s.ID,
+			EventID:        int64(event.ID),
+			EventType:      string(event.Type),
+			Payload:        payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+func fromEventTypes(types []entity.EventType) []string {
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		result = append(result, string(t))
+	}
+	return result
+}
+
+func toWebhookSubscription(s sqlc.WebhookSubscription) entity.WebhookSubscription {
+	eventTypes := make([]entity.EventType, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		eventTypes = append(eventTypes, entity.EventType(t))
+	}
+
+	return entity.WebhookSubscription{
+		ModelWithUpdatedAt: entity.ModelWithUpdatedAt{
+			Model: entity.Model{
+				ID:        uint64(s.ID),
+				CreatedAt: s.CreatedAt.Time,
+			},
+			UpdatedAt: s.UpdatedAt.Time,
+		},
+		URL:        s.Url,
+		EventTypes: eventTypes,
+		Active:     s.Active,
+	}
+}
+
+func toWebhookDelivery(d sqlc.WebhookDelivery) entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ModelWithUpdatedAt: entity.ModelWithUpdatedAt{
+			Model: entity.Model{
+				ID:        uint64(d.ID),
+				CreatedAt: d.CreatedAt.Time,
+			},
+			UpdatedAt: d.UpdatedAt.Time,
+		},
+		SubscriptionID:     uint64(d.SubscriptionID),
+		EventID:            uint64(d.EventID),
+		EventType:          entity.EventType(d.EventType),
+		Payload:            d.Payload,
+		Status:             entity.WebhookDeliveryStatus(d.Status),
+		Attempts:           int(d.Attempts),
+		NextAttemptAt:      d.NextAttemptAt,
+		LastResponseStatus: int(d.LastResponseStatus.Int32),
+		LastError:          d.LastError,
+		DeliveredAt:        d.DeliveredAt.Time,
+	}
+}
+
+func toWebhookDeliveryAttempt(a sqlc.WebhookDeliveryAttempt) entity.WebhookDeliveryAttempt {
+	return entity.WebhookDeliveryAttempt{
+		Model: entity.Model{
+			ID:        uint64(a.ID),
+			CreatedAt: a.CreatedAt.Time,
+		},
+		Attempt:        int(a.Attempt),
+		ResponseStatus: int(a.ResponseStatus.Int32),
+		Error:          a.Error,
+		Duration:       time.Duration(a.DurationMs) * time.Millisecond,
+	}
+}
@@ -0,0 +1,223 @@
+package webhook_test
+
+import (
+	"bank/config"
+	"bank/entity"
+	"bank/internal/db/sqlc"
+	"bank/internal/logger"
+	"bank/outbox"
+	"bank/test"
+	"bank/webhook"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const secret = "0123456789abcdef"
+
+// receiver verifies every request and answers with the next of its statuses, 200 once they run out
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	received []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	err := webhook.Verify(secret, r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature), body, time.Now(), time.Minute)
+	if err != nil {
+		rc.t.Errorf("failed to verify webhook: %v", err)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.received = append(rc.received, string(body))
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	test.RunWithoutTransaction(t, func(testDB *test.TestDB) {
+		ctx := context.Background()
+		testLogger := logger.NewLogger("debug")
+		queries := sqlc.New(testDB.DB)
+
+		domain, err := webhook.NewWebhookDomain(testDB.DB, queries, &config.Config{
+			WebhookTimeout:     time.Second,
+			WebhookMaxAttempts: 2,
+			WebhookBackoffBase: time.Minute,
+			WebhookBackoffMax:  time.Hour,
+			WebhookBatchSize:   10,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create webhook domain: %v", err)
+		}
+
+		relay, err := outbox.NewRelay(testDB.DB, queries, domain, &config.Config{
+			OutboxBatchSize:   100,
+			OutboxMaxAttempts: 3,
+			OutboxBackoffBase: time.Second,
+			OutboxBackoffMax:  time.Minute,
+		}, testLogger)
+		if err != nil {
+			t.Fatalf("failed to create relay: %v", err)
+		}
+
+		flaky := &receiver{t: t, statuses: []int{http.StatusInternalServerError}}
+		flakyServer := httptest.NewServer(flaky)
+		defer flakyServer.Close()
+
+		down := &receiver{t: t, statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+		downServer := httptest.NewServer(down)
+		defer downServer.Close()
+
+		if _, err := domain.CreateSubscription(ctx, entity.CreateWebhookSubscription{
+			URL:        flakyServer.URL,
+			EventTypes: []entity.EventType{entity.EventTypeTransferCompleted},
+			Secret:     "too short",
+		}); !errors.Is(err, entity.ErrValidation) {
+			t.Fatalf("expected a validation error for a short secret, got %v", err)
+		}
+
+		flakySubscription, err := domain.CreateSubscription(ctx, entity.CreateWebhookSubscription{
+			URL:        flakyServer.URL,
+			EventTypes: []entity.EventType{entity.EventTypeTransferCompleted},
+			Secret:     secret,
+		})
+		if err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+
+		downSubscription, err := domain.CreateSubscription(ctx, entity.CreateWebhookSubscription{
+			URL:        downServer.URL,
+			EventTypes: []entity.EventType{entity.EventTypeTransferCompleted, entity.EventTypeAccountCreated},
+			Secret:     secret,
+		})
+		if err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+
+		err = outbox.Write(ctx, queries, entity.TransferCompleted{TransferID: 7, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+		if err != nil {
+			t.Fatalf("failed to write event: %v", err)
+		}
+
+		// Relaying twice must not deliver twice
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+		if _, err := testDB.DB.Exec("UPDATE outbox SET published_at = NULL"); err != nil {
+			t.Fatalf("failed to reset outbox: %v", err)
+		}
+		if err := relay.Run(ctx); err != nil {
+			t.Fatalf("failed to relay events: %v", err)
+		}
+
+		result, err := domain.DeliverWebhooks(ctx)
+		if err != nil {
+			t.Fatalf("failed to deliver webhooks: %v", err)
+		}
+		if result != (entity.DeliverWebhooksResult{Retrying: 2}) {
+			t.Errorf("expected both deliveries to be retried, got %+v", result)
+		}
+
+		// Nothing is due before the backoff elapsed
+		result, err = domain.DeliverWebhooks(ctx)
+		if err != nil {
+			t.Fatalf("failed to deliver webhooks: %v", err)
+		}
+		if result != (entity.DeliverWebhooksResult{}) {
+			t.Errorf("expected nothing due, got %+v", result)
+		}
+
+		if _, err := testDB.DB.Exec("UPDATE webhook_deliveries SET next_attempt_at = NOW()"); err != nil {
+			t.Fatalf("failed to make deliveries due: %v", err)
+		}
+		result, err = domain.DeliverWebhooks(ctx)
+		if err != nil {
+			t.Fatalf("failed to deliver webhooks: %v", err)
+		}
+		if result != (entity.DeliverWebhooksResult{Delivered: 1, Dead: 1}) {
+			t.Errorf("expected one delivered and one dead, got %+v", result)
+		}
+
+		if len(flaky.received) != 2 || flaky.received[0] != flaky.received[1] {
+			t.Fatalf("expected the same body on both attempts, got %v", flaky.received)
+		}
+		var body struct {
+			ID   uint64                   `json:"id"`
+			Type entity.EventType         `json:"type"`
+			Data entity.TransferCompleted `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(flaky.received[0]), &body); err != nil {
+			t.Fatalf("failed to unmarshal body: %v", err)
+		}
+		if body.ID == 0 || body.Type != entity.EventTypeTransferCompleted || body.Data.TransferID != 7 || !body.Data.Amount.Equal(decimal.NewFromInt(10)) {
+			t.Errorf("unexpected body: %s", flaky.received[0])
+		}
+
+		deliveries, err := domain.ListDeliveries(ctx, downSubscription.ID, entity.WebhookDeliveryStatusDead)
+		if err != nil {
+			t.Fatalf("failed to list deliveries: %v", err)
+		}
+		if len(deliveries) != 1 {
+			t.Fatalf("expected 1 dead delivery, got %d", len(deliveries))
+		}
+
+		dead, err := domain.GetDelivery(ctx, deliveries[0].ID)
+		if err != nil {
+			t.Fatalf("failed to get delivery: %v", err)
+		}
+		if len(dead.AttemptLog) != 2 || dead.AttemptLog[0].ResponseStatus != http.StatusServiceUnavailable || dead.AttemptLog[1].ResponseStatus != http.StatusBadGateway {
+			t.Errorf("expected 2 logged attempts with the receiver statuses, got %+v", dead.AttemptLog)
+		}
+		if dead.LastResponseStatus != http.StatusBadGateway || dead.LastError == "" {
+			t.Errorf("expected the last failure on the delivery, got %d %q", dead.LastResponseStatus, dead.LastError)
+		}
+
+		// The receiver is back, a redelivery starts the retries over
+		redelivered, err := domain.Redeliver(ctx, dead.ID)
+		if err != nil {
+			t.Fatalf("failed to redeliver: %v", err)
+		}
+		if redelivered.Status != entity.WebhookDeliveryStatusPending || redelivered.Attempts != 0 {
+			t.Errorf("expected a pending delivery without attempts, got %s with %d", redelivered.Status, redelivered.Attempts)
+		}
+
+		if _, err := domain.Redeliver(ctx, dead.ID); !errors.Is(err, entity.ErrInvalidState) {
+			t.Errorf("expected an invalid state error for a pending delivery, got %v", err)
+		}
+
+		result, err = domain.DeliverWebhooks(ctx)
+		if err != nil {
+			t.Fatalf("failed to deliver webhooks: %v", err)
+		}
+		if result != (entity.DeliverWebhooksResult{Delivered: 1}) {
+			t.Errorf("expected the redelivery to succeed, got %+v", result)
+		}
+
+		delivered, err := domain.ListDeliveries(ctx, flakySubscription.ID, entity.WebhookDeliveryStatusDelivered)
+		if err != nil {
+			t.Fatalf("failed to list deliveries: %v", err)
+		}
+		if len(delivered) != 1 || delivered[0].Attempts != 2 || delivered[0].DeliveredAt.IsZero() {
+			t.Errorf("expected 1 delivery delivered on the second attempt, got %+v", delivered)
+		}
+
+		if _, err := domain.Redeliver(ctx, 999999); !errors.Is(err, entity.ErrDataNotFound) {
+			t.Errorf("expected not found for an unknown delivery, got %v", err)
+		}
+	})
+}